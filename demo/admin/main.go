@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/jmh000527/criker-search/index/doc_codec"
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// 运维工具，通过 gRPC 管理正在运行的 index worker。
+// 备份文件的格式：8 字节大端编码的变更日志序号，之后是 KeyValueDB.Backup 产生的数据。
+//
+// go run ./demo/admin backup -addr=127.0.0.1:5600 -out=data/backup/part0.bak
+// go run ./demo/admin restore -addr=127.0.0.1:5610 -in=data/backup/part0.bak
+// go run ./demo/admin stats -addr=127.0.0.1:5600
+// go run ./demo/admin compact -addr=127.0.0.1:5600 -threshold=0.3
+// go run ./demo/admin migrate -dbType=bolt -dbPath=data/local_db/video_bolt_part0 -codec=zstd
+
+// usage 打印用法并退出
+func usage() {
+	fmt.Fprintf(os.Stderr, "用法: %s <backup|restore|stats|compact|migrate> [参数]\n", os.Args[0])
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	var err error
+	switch os.Args[1] {
+	case "backup":
+		err = backupMain(os.Args[2:])
+	case "restore":
+		err = restoreMain(os.Args[2:])
+	case "stats":
+		err = statsMain(os.Args[2:])
+	case "compact":
+		err = compactMain(os.Args[2:])
+	case "migrate":
+		err = migrateMain(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// dial 连接 index worker
+func dial(addr string) (index_service.IndexServiceClient, func(), error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接 %s 失败: %v", addr, err)
+	}
+	return index_service.NewIndexServiceClient(conn), func() { conn.Close() }, nil
+}
+
+// backupMain 在线备份一个 worker 的正排索引到文件
+func backupMain(args []string) error {
+	flags := flag.NewFlagSet("backup", flag.ExitOnError)
+	addr := flags.String("addr", "", "index worker的地址(host:port)")
+	out := flags.String("out", "", "备份文件的路径")
+	flags.Parse(args)
+	if len(*addr) == 0 || len(*out) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, closeConn, err := dial(*addr)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	stream, err := client.Backup(context.Background(), new(index_service.BackupRequest))
+	if err != nil {
+		return err
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	// 先写到临时文件，备份完整之后再改名，避免留下不完整的备份
+	tmpPath := *out + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint64(header, first.ChangeLogSeq)
+	if _, err := file.Write(header); err != nil {
+		file.Close()
+		return err
+	}
+	var size int64
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return err
+		}
+		if _, err := file.Write(chunk.Data); err != nil {
+			file.Close()
+			return err
+		}
+		size += int64(len(chunk.Data))
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, *out); err != nil {
+		return err
+	}
+	fmt.Printf("已备份 %s 到 %s，共 %d 字节，变更日志序号 %d\n", *addr, *out, size, first.ChangeLogSeq)
+	return nil
+}
+
+// restoreMain 用备份文件替换一个 worker 的索引
+func restoreMain(args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	addr := flags.String("addr", "", "index worker的地址(host:port)")
+	in := flags.String("in", "", "备份文件的路径")
+	flags.Parse(args)
+	if len(*addr) == 0 || len(*in) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(*in)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(file, header); err != nil {
+		return fmt.Errorf("读取备份文件头失败: %v", err)
+	}
+	seq := binary.BigEndian.Uint64(header)
+
+	client, closeConn, err := dial(*addr)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	stream, err := client.Restore(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&index_service.BackupChunk{ChangeLogSeq: seq}); err != nil {
+		return err
+	}
+	buffer := make([]byte, 1<<20)
+	for {
+		n, err := file.Read(buffer)
+		if n > 0 {
+			if err := stream.Send(&index_service.BackupChunk{Data: buffer[:n]}); err != nil {
+				break // 服务端已经返回错误，由 CloseAndRecv 取得
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	result, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("已用 %s 恢复 %s，共 %d 个文档，变更日志序号 %d\n", *in, *addr, result.Count, seq)
+	return nil
+}
+
+// statsMain 打印一个 worker 的索引统计信息
+func statsMain(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	addr := flags.String("addr", "", "index worker的地址(host:port)")
+	flags.Parse(args)
+	if len(*addr) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, closeConn, err := dial(*addr)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	stats, err := client.Stats(context.Background(), new(index_service.StatsRequest))
+	if err != nil {
+		return err
+	}
+	output, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(output))
+	return nil
+}
+
+// compactMain 立即整理一个 worker 的正排索引，回收已删除、已覆盖的文档占用的磁盘空间
+func compactMain(args []string) error {
+	flags := flag.NewFlagSet("compact", flag.ExitOnError)
+	addr := flags.String("addr", "", "index worker的地址(host:port)")
+	threshold := flags.Float64("threshold", 0, "可回收空间的比例达到该值才整理，0表示使用worker的默认值")
+	flags.Parse(args)
+	if len(*addr) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	client, closeConn, err := dial(*addr)
+	if err != nil {
+		return err
+	}
+	defer closeConn()
+	result, err := client.Compact(context.Background(), &index_service.CompactRequest{Threshold: *threshold})
+	if err != nil {
+		return err
+	}
+	if result.Compacted {
+		fmt.Printf("整理完成，正排索引占用空间从 %d 字节变为 %d 字节\n", result.BeforeBytes, result.AfterBytes)
+	} else {
+		fmt.Printf("可回收空间没有达到阈值，没有整理，正排索引占用 %d 字节\n", result.BeforeBytes)
+	}
+	return nil
+}
+
+// migrateMain 把正排索引中的文档改写为指定的编码方式。直接读写数据库文件，需要先停止使用该数据库的 worker。
+func migrateMain(args []string) error {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dbType := flags.String("dbType", kv_db.BOLT, "正排索引的数据库类型: "+strings.Join(kv_db.Backends(), ", "))
+	dbPath := flags.String("dbPath", "", "正排索引数据库的路径")
+	codecName := flags.String("codec", doc_codec.NameProtobuf, "目标编码方式: protobuf, gob, zstd, snappy")
+	batchSize := flags.Int("batch", doc_codec.DefaultMigrateBatchSize, "每批改写的文档数量")
+	flags.Parse(args)
+	if len(*dbPath) == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	codec, err := doc_codec.Get(*codecName)
+	if err != nil {
+		return err
+	}
+	db, err := kv_db.GetKvDB(*dbType, *dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	n, err := doc_codec.Migrate(db, codec, *batchSize, index_service.ForwardExpireTime)
+	if err != nil {
+		return fmt.Errorf("已改写 %d 个文档后失败: %v", n, err)
+	}
+	fmt.Printf("已把 %s 中的 %d 个文档改写为 %s 编码\n", *dbPath, n, codec.Name())
+	return nil
+}
@@ -3,6 +3,7 @@ package demo
 import (
 	"encoding/csv"
 	"github.com/gogo/protobuf/proto"
+	"github.com/jmh000527/criker-search/index/synonym"
 	indexer "github.com/jmh000527/criker-search/index_service"
 	"github.com/jmh000527/criker-search/types"
 	"github.com/jmh000527/criker-search/utils"
@@ -14,17 +15,29 @@ import (
 	"time"
 )
 
+// indexBatchSize 从CSV文件构建索引时，每攒够多少个文档批量写入一次索引
+const indexBatchSize = 500
+
+// IndexSynonyms 写入索引时使用的同义词词典，配置为写入时展开的字段会补充同义词关键词。为 nil 时不展开
+var IndexSynonyms *synonym.Dictionary
+
+// DocWriter 批量写入文档。indexer.Indexer 实现了该接口；IndexServiceWorker 也实现了该接口，
+// 通过它写入的文档会追加到变更日志，从副本可以同步到。
+type DocWriter interface {
+	BatchAddDoc(docs []types.Document) (int, []error)
+}
+
 // BuildIndexFromFile 将CSV文件中的视频信息写入索引。
 //
 // 参数:
 //   - csvFile: CSV文件的路径。
-//   - indexer: 索引接口，用于添加文档到索引中。
+//   - indexer: 用于添加文档到索引中。分布式模式下应传入 IndexServiceWorker 而不是它的 Indexer，否则写入不会记入变更日志，从副本同步不到。
 //   - totalWorkers: 分布式环境中的总worker数量。如果是单机模式，设为0。
 //   - workerIndex: 当前worker的索引，从0开始编号。单机模式下不使用此参数。
 //
 // 返回值: 无返回值
 // 注意事项: 如果使用分布式模式，每个worker只处理一部分数据。
-func BuildIndexFromFile(csvFile string, indexer indexer.Indexer, totalWorkers, workerIndex int) {
+func BuildIndexFromFile(csvFile string, indexer DocWriter, totalWorkers, workerIndex int) {
 	file, err := os.Open(csvFile)
 	if err != nil {
 		utils.Log.Printf("打开CSV文件 %v 失败，错误: %v", csvFile, err)
@@ -35,6 +48,22 @@ func BuildIndexFromFile(csvFile string, indexer indexer.Indexer, totalWorkers, w
 	location, _ := time.LoadLocation("Asia/Shanghai")
 	reader := csv.NewReader(file)
 	progress := 0
+	batch := make([]types.Document, 0, indexBatchSize)
+	// flush 将攒够的一批文档写入索引
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		n, errs := indexer.BatchAddDoc(batch)
+		for i, err := range errs {
+			if err != nil {
+				utils.Log.Printf("无法添加文档 %s, 错误: %v", batch[i].Id, err)
+			}
+		}
+		progress += n
+		utils.Log.Printf("索引进度: %d\n", progress)
+		batch = batch[:0]
+	}
 	for {
 		// 读取CSV文件的一行
 		record, err := reader.Read()
@@ -96,15 +125,18 @@ func BuildIndexFromFile(csvFile string, indexer indexer.Indexer, totalWorkers, w
 			}
 		}
 
-		// 将视频信息添加到索引中
-		AddVideo2Index(video, indexer)
-		progress++
-
-		// 每处理100条记录，输出进度
-		if progress%100 == 0 {
-			utils.Log.Printf("索引进度: %d\n", progress)
+		// 将视频信息加入当前批次，攒够一批后写入索引
+		doc, err := video2Document(video)
+		if err != nil {
+			utils.Log.Printf("序列化视频信息失败: %v", err)
+			continue
+		}
+		batch = append(batch, doc)
+		if len(batch) >= indexBatchSize {
+			flush()
 		}
 	}
+	flush()
 
 	utils.Log.Printf("索引构建完成，共添加了 %d 个文档", progress)
 }
@@ -115,6 +147,28 @@ func BuildIndexFromFile(csvFile string, indexer indexer.Indexer, totalWorkers, w
 // - video: 包含视频信息的BiliVideo对象。
 // - indexer: 实现了IIndexer接口的索引器实例。
 func AddVideo2Index(video *BiliVideo, indexer indexer.Indexer) {
+	doc, err := video2Document(video)
+	if err != nil {
+		utils.Log.Printf("序列化视频信息失败: %v", err)
+		return
+	}
+
+	// 将文档添加或更新到索引中
+	_, err = indexer.AddDoc(doc)
+	if err != nil {
+		utils.Log.Printf("无法添加文档, 错误: %v", err)
+	}
+}
+
+// video2Document 将视频信息转换为索引中的文档。
+//
+// 参数:
+//   - video: 包含视频信息的BiliVideo对象。
+//
+// 返回值:
+//   - types.Document: 转换后的文档。
+//   - error: 序列化视频信息失败时返回相应的错误。
+func video2Document(video *BiliVideo) (types.Document, error) {
 	// 构建Document对象，将视频ID赋值给文档ID
 	doc := types.Document{
 		Id: video.Id,
@@ -123,8 +177,7 @@ func AddVideo2Index(video *BiliVideo, indexer indexer.Indexer) {
 	// 将BiliVideo对象序列化为字节数组
 	docBytes, err := proto.Marshal(video)
 	if err != nil {
-		utils.Log.Printf("序列化视频信息失败: %v", err)
-		return
+		return doc, err
 	}
 	doc.Bytes = docBytes
 
@@ -143,14 +196,9 @@ func AddVideo2Index(video *BiliVideo, indexer indexer.Indexer) {
 			Word:  strings.ToLower(strings.TrimSpace(video.Author)),
 		})
 	}
-	doc.Keywords = keywords
+	doc.Keywords = IndexSynonyms.ExpandKeywords(keywords)
 
 	// 计算视频的特征位
 	doc.BitsFeature = GetClassBits(video.Keywords)
-
-	// 将文档添加或更新到索引中
-	_, err = indexer.AddDoc(doc)
-	if err != nil {
-		utils.Log.Printf("无法添加文档, 错误: %v", err)
-	}
+	return doc, nil
 }
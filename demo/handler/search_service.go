@@ -6,13 +6,18 @@ import (
 	"github.com/jmh000527/criker-search/demo"
 	"github.com/jmh000527/criker-search/demo/video_search"
 	"github.com/jmh000527/criker-search/demo/video_search/common"
+	"github.com/jmh000527/criker-search/index/synonym"
 	indexer "github.com/jmh000527/criker-search/index_service"
 	"github.com/jmh000527/criker-search/utils"
 	"net/http"
+	"strconv"
 	"strings"
 )
 
-var Indexer indexer.Indexer
+var (
+	Indexer  indexer.Indexer
+	Synonyms *synonym.Dictionary // 检索时使用的同义词词典，为 nil 时不展开
+)
 
 // cleanKeywords 接收一个字符串切片，并返回一个清理后的字符串切片。
 // 清理过程包括去除每个字符串的前后空白字符，将其转换为小写，并排除空字符串。
@@ -99,9 +104,10 @@ func SearchAll(ctx *gin.Context) {
 	}
 	// 构建搜索上下文
 	searchCtx := &common.VideoSearchContext{
-		Ctx:     context.Background(),
-		Request: &request,
-		Indexer: Indexer,
+		Ctx:      context.Background(),
+		Request:  &request,
+		Indexer:  Indexer,
+		Synonyms: Synonyms,
 	}
 	// 执行搜索
 	searcher := video_search.NewAllVideoSearcher()
@@ -141,9 +147,10 @@ func SearchByAuthor(ctx *gin.Context) {
 	}
 	// 构建搜索上下文
 	searchCtx := &common.VideoSearchContext{
-		Ctx:     context.WithValue(context.Background(), common.UN("user_name"), userName), // 将 userName 放到 context 中
-		Request: &request,
-		Indexer: Indexer,
+		Ctx:      context.WithValue(context.Background(), common.UN("user_name"), userName), // 将 userName 放到 context 中
+		Request:  &request,
+		Indexer:  Indexer,
+		Synonyms: Synonyms,
 	}
 	// 执行搜索
 	searcher := video_search.NewUpVideoSearcher()
@@ -152,3 +159,98 @@ func SearchByAuthor(ctx *gin.Context) {
 	utils.Log.Printf("返回 %d 个文档", len(videos))
 	ctx.JSON(http.StatusOK, videos)
 }
+
+// Stats 返回索引的统计信息。分布式模式下是整个集群汇总后的结果。
+//
+// 参数:
+//   - ctx: gin.Context 对象，包含请求上下文和相关信息。
+//
+// 返回值:
+//   - 无: 直接在 HTTP 响应中返回结果。
+func Stats(ctx *gin.Context) {
+	stats, err := Indexer.Stats()
+	if err != nil {
+		utils.Log.Printf("获取索引统计信息失败: %s", err)
+		ctx.String(http.StatusInternalServerError, "获取索引统计信息失败")
+		return
+	}
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// DidYouMean 处理拼写纠错的请求，通常在检索没有结果时调用。请求参数与 SearchAll 相同，
+// 关键词在 content 字段、作者在 author 字段的词典中查找最相近的词，词本身在词典中时不替换。
+//
+// 参数:
+//   - ctx: gin.Context 对象，包含请求上下文和相关信息。
+//
+// 返回值:
+//   - 无: 直接在 HTTP 响应中返回 demo.DidYouMeanResponse。
+func DidYouMean(ctx *gin.Context) {
+	var request demo.SearchRequest
+	if err := ctx.ShouldBindJSON(&request); err != nil {
+		utils.Log.Printf("绑定请求参数失败: %s", err)
+		ctx.String(http.StatusBadRequest, "无效的请求参数")
+		return
+	}
+	response := demo.DidYouMeanResponse{Keywords: cleanKeywords(request.Keywords)}
+	for i, word := range response.Keywords {
+		if correction := correct("content", word); correction != word {
+			response.Keywords[i] = correction
+			response.Corrected = true
+		}
+	}
+	if author := strings.TrimSpace(strings.ToLower(request.Author)); len(author) > 0 {
+		response.Author = correct("author", author)
+		response.Corrected = response.Corrected || response.Author != author
+	}
+	ctx.JSON(http.StatusOK, response)
+}
+
+// correct 返回字段 field 的词典中与 word 最相近的词，没有相近的词或查找失败时返回 word 本身
+func correct(field, word string) string {
+	corrections, err := Indexer.DidYouMean(field, word, 1)
+	if err != nil {
+		utils.Log.Printf("查找 %s 的纠错候选失败: %s", word, err)
+		return word
+	}
+	if len(corrections) == 0 {
+		return word
+	}
+	return corrections[0].Word
+}
+
+// Suggest 处理输入时自动补全的请求，返回以 prefix 开头、包含它的文档数量最多的词。
+// 请求参数 prefix 为用户已经输入的前缀；limit 为最多返回的候选数量，不传时使用默认值，不能为负数或超过 indexer.MaxSuggestLimit；
+// fields 为在哪些字段中补全，多个字段用逗号分隔，不传时在所有设置了自动补全的字段中补全。
+//
+// 参数:
+//   - ctx: gin.Context 对象，包含请求上下文和相关信息。
+//
+// 返回值:
+//   - 无: 直接在 HTTP 响应中返回候选词列表。
+func Suggest(ctx *gin.Context) {
+	prefix := strings.TrimSpace(strings.ToLower(ctx.Query("prefix")))
+	if len(prefix) == 0 {
+		ctx.JSON(http.StatusOK, []*indexer.Suggestion{})
+		return
+	}
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", "0"))
+	if err != nil || limit < 0 || limit > indexer.MaxSuggestLimit {
+		ctx.String(http.StatusBadRequest, "无效的 limit 参数，必须在 0 到 %d 之间", indexer.MaxSuggestLimit)
+		return
+	}
+	var fields []string
+	if len(ctx.Query("fields")) > 0 {
+		fields = cleanKeywords(strings.Split(ctx.Query("fields"), ","))
+	}
+	suggestions, err := Indexer.Suggest(prefix, fields, limit)
+	if err != nil {
+		utils.Log.Printf("获取补全候选失败: %s", err)
+		ctx.String(http.StatusInternalServerError, "获取补全候选失败")
+		return
+	}
+	if suggestions == nil {
+		suggestions = []*indexer.Suggestion{}
+	}
+	ctx.JSON(http.StatusOK, suggestions)
+}
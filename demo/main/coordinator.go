@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/coordinator"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/utils"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// CoordinatorMain 启动集群协调者，参与leader选举，当选后为index worker分配分片
+func CoordinatorMain() {
+	hub := service_hub.GetServiceHubProxy(etcdServers, 3, 100)
+	c, err := coordinator.NewCoordinator(etcdServers, hub, "127.0.0.1:"+strconv.Itoa(*port), coordinator.Config{
+		Service:   index_service.IndexService,
+		NumShards: *totalWorkers,
+		Replicas:  *replicas,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// 收到终止信号时退出选举，让其他coordinator尽快当选
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	c.Run(ctx)
+	c.Close()
+	hub.Close()
+	utils.Log.Printf("coordinator已退出")
+	os.Exit(0)
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/jmh000527/criker-search/demo"
 	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
 	"github.com/jmh000527/criker-search/utils"
 	"google.golang.org/grpc"
 	"net"
@@ -28,34 +29,77 @@ func GrpcIndexerInit() {
 		utils.Log.Printf("监听端口失败: %v", err)
 		panic(err)
 	}
-	server := grpc.NewServer()
-	service = new(index_service.IndexServiceWorker)
+	// 监听的是回环地址，默认也以回环地址注册
+	advertise := *advertiseAddr
+	if len(advertise) == 0 {
+		advertise = "127.0.0.1:" + strconv.Itoa(*port)
+	}
+	// 指定了主副本时作为从副本运行
+	role := service_hub.RolePrimary
+	if len(*primary) > 0 {
+		role = service_hub.RoleReplica
+	}
+	service = new(index_service.IndexServiceWorker).
+		WithAdvertiseAddr(advertise).
+		WithWeight(*weight).
+		WithShard(*workerIndex, role).
+		WithGeneration(*generation).
+		WithExpiryReaper(*reapInterval).
+		WithMaintenance(maintenanceOptions()).
+		WithCompletion(completionFields()...).
+		WithCodec(docCodec())
+	if *changeLog > 0 {
+		service.WithChangeLog(*changeLog)
+	}
 
 	// 初始化索引
-	err = service.Init(50000, dbType, *dbPath+"_part"+strconv.Itoa(*workerIndex))
+	err = service.Init(50000, *dbType, *dbPath+"_part"+strconv.Itoa(*workerIndex))
 	if err != nil {
 		utils.Log.Printf("初始化索引失败: %v", err)
 		panic(err)
 	}
-	// 是否重建索引
-	if *rebuildIndex {
+	// 是否重建索引。从副本的数据全部来自主副本：主副本的变更日志中还保留着全部变更时直接跟随即可，
+	// 否则需要先用主副本的备份恢复（Restore），因此从副本不从CSV文件重建索引
+	if *rebuildIndex && len(*primary) == 0 {
 		utils.Log.Printf("总工作节点数=%d, 当前工作节点索引=%d", *totalWorkers, *workerIndex)
-		// 重建索引
-		demo.BuildIndexFromFile(csvFile, service.Indexer, *totalWorkers, *workerIndex)
+		// 重建索引，经过 worker 写入，文档同时追加到变更日志，从副本可以同步到
+		demo.BuildIndexFromFile(csvFile, service, *totalWorkers, *workerIndex)
 	} else {
+		if *rebuildIndex {
+			utils.Log.Printf("从副本不从CSV文件重建索引，数据从主副本 %s 同步", *primary)
+		}
 		// 从正排索引文件加载
 		service.Indexer.LoadFromIndexFile()
 	}
-	// 注册服务实现
+	// 索引已加载完成，可以注册服务、处理请求
+	service.MarkReady()
+	// 从副本订阅主副本的变更
+	if len(*primary) > 0 {
+		if err := service.Follow(*primary); err != nil {
+			utils.Log.Printf("跟随主副本失败: %v", err)
+			panic(err)
+		}
+	}
+	// 注册服务实现，拦截器负责就绪检查和下线时等待在途请求
+	server := grpc.NewServer(service.ServerOptions()...)
 	index_service.RegisterIndexServiceServer(server, service)
 	// 启动服务
 	utils.Log.Printf("在端口 %d 启动 gRPC 服务器", *port)
-	// 向注册中心注册服务并周期性续期
-	err = service.RegisterService(etcdServers, *port)
+	// 向注册中心注册服务并周期性续期。worker 地址由文件维护时无需注册
+	if len(*hubFile) == 0 {
+		err = service.RegisterService(etcdServers, *port)
+	}
 	if err != nil {
 		utils.Log.Printf("注册服务失败: %v", err)
 		panic(err)
 	}
+	// 按 coordinator 的分片表切换分片和角色
+	if *shardMap && len(*hubFile) == 0 {
+		if err := service.WatchShardMap(etcdServers); err != nil {
+			utils.Log.Printf("监视分片表失败: %v", err)
+			panic(err)
+		}
+	}
 	// 启动服务
 	err = server.Serve(listener)
 	if err != nil {
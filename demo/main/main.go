@@ -2,30 +2,106 @@ package main
 
 import (
 	"flag"
+	"fmt"
+	"github.com/jmh000527/criker-search/demo"
 	"github.com/jmh000527/criker-search/demo/handler"
+	"github.com/jmh000527/criker-search/index/doc_codec"
 	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index/synonym"
+	"github.com/jmh000527/criker-search/index_service"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmh000527/criker-search/utils"
 )
 
 var (
-	mode         = flag.Int("mode", 1, "启动哪类服务。1-standalone web server, 2-grpc index server, 3-distributed web server")
-	rebuildIndex = flag.Bool("index", false, "server启动时是否需要重建索引")
-	port         = flag.Int("port", 0, "server的工作端口")
-	dbPath       = flag.String("dbPath", "", "正排索引数据的存放路径")
-	totalWorkers = flag.Int("totalWorkers", 0, "分布式环境中一共有几台index worker")
-	workerIndex  = flag.Int("workerIndex", 0, "本机是第几台index worker(从0开始编号)")
+	mode          = flag.Int("mode", 1, "启动哪类服务。1-standalone web server, 2-grpc index server, 3-distributed web server, 4-cluster coordinator")
+	rebuildIndex  = flag.Bool("index", false, "server启动时是否需要重建索引")
+	port          = flag.Int("port", 0, "server的工作端口")
+	dbPath        = flag.String("dbPath", "", "正排索引数据的存放路径")
+	totalWorkers  = flag.Int("totalWorkers", 0, "分布式环境中一共有几台index worker")
+	workerIndex   = flag.Int("workerIndex", 0, "本机是第几台index worker(从0开始编号)")
+	lbStrategy    = flag.String("lb", "round_robin", "分布式模式下web server使用的负载均衡策略: round_robin, random, weighted_round_robin, least_outstanding, p2c, consistent_hash")
+	weight        = flag.Int("weight", 1, "index worker注册到etcd的权重，供weighted_round_robin负载均衡使用")
+	generation    = flag.Int64("generation", 0, "index worker上索引的代数，每次重建索引后应递增")
+	advertiseAddr = flag.String("advertiseAddr", "", "index worker注册到服务中心的地址(host:port)，默认为127.0.0.1:port")
+	hubFile       = flag.String("hubFile", "", "分布式模式下从该JSON/YAML文件读取index worker的地址，不再依赖etcd")
+	changeLog     = flag.Int("changeLog", 0, "index worker变更日志保留的变更数量，0表示不开启。主副本开启后才能被从副本订阅，从副本必须开启")
+	primary       = flag.String("primary", "", "index worker作为从副本运行时，跟随的主副本地址(host:port)")
+	replicas      = flag.Int("replicas", 0, "coordinator为每个分片分配的从副本数量，分片数量由totalWorkers指定")
+	reapInterval  = flag.Duration("reapInterval", time.Minute, "删除过期文档的间隔，0表示不删除（过期的文档仍然检索不到）")
+	dbType        = flag.String("dbType", kv_db.BOLT, "正排索引使用哪种KV数据库: "+strings.Join(kv_db.Backends(), ", "))
+	codecName     = flag.String("codec", "", "正排索引中文档的编码方式: protobuf(默认), gob, zstd, snappy。已有数据不需要迁移即可读取")
+	compactEvery  = flag.Duration("compactInterval", 0, "检查是否需要整理正排索引的间隔，0表示不在后台整理（仍可用admin compact手动整理）")
+	compactRatio  = flag.Float64("compactThreshold", index_service.DefaultCompactThreshold, "可回收空间占正排索引的比例达到该值才整理")
+	quietHours    = flag.String("quietHours", "", "只在每天的这些小时内整理正排索引，格式为\"开始-结束\"（本地时间，可以跨零点，例如22-6），为空表示不限制")
+	synonymFile   = flag.String("synonyms", utils.RootPath+"demo/data/synonyms.txt", "同义词词典文件，修改后自动重新加载，为空表示不使用同义词")
+	synonymFields = flag.String("indexSynonymFields", "", "在写入索引时展开同义词的字段，多个字段用逗号分隔（例如content），需要配合-index=true重建索引。其余字段在检索时展开")
+	completions   = flag.String("completionFields", "content,author", "index worker和单机web server为哪些字段维护自动补全（/suggest），多个字段用逗号分隔，为空表示不提供自动补全")
+	shardMap      = flag.Bool("shardMap", false, "index worker和分布式web server是否按coordinator维护的分片表分配分片、选择主从，需要以mode=4启动coordinator")
 )
 
 var (
-	dbType      = kv_db.BOLT                                  // 正排索引使用哪种KV数据库
 	csvFile     = utils.RootPath + "demo/data/bili_video.csv" // 原始的数据文件，由它来创建索引
 	etcdServers = []string{"127.0.0.1:2379"}                  // etcd集群的地址
 )
 
+// docCodec 根据 -codec 参数获取文档的编码方式
+func docCodec() doc_codec.DocumentCodec {
+	codec, err := doc_codec.Get(*codecName)
+	if err != nil {
+		panic(err)
+	}
+	return codec
+}
+
+// maintenanceOptions 根据 -compactInterval、-compactThreshold 和 -quietHours 参数构造整理正排索引的选项
+func maintenanceOptions() index_service.MaintenanceOptions {
+	opts := index_service.MaintenanceOptions{Interval: *compactEvery, Threshold: *compactRatio}
+	if len(*quietHours) == 0 {
+		return opts
+	}
+	if _, err := fmt.Sscanf(*quietHours, "%d-%d", &opts.QuietStartHour, &opts.QuietEndHour); err != nil ||
+		opts.QuietStartHour < 0 || opts.QuietStartHour > 23 || opts.QuietEndHour < 0 || opts.QuietEndHour > 23 {
+		panic(fmt.Sprintf("无效的 -quietHours 参数: %s", *quietHours))
+	}
+	return opts
+}
+
+// completionFields 根据 -completionFields 参数获取需要自动补全的字段
+func completionFields() []string {
+	var fields []string
+	for _, field := range strings.Split(*completions, ",") {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// loadSynonyms 根据 -synonyms 和 -indexSynonymFields 参数加载同义词词典，供构建索引和检索使用
+func loadSynonyms() {
+	if len(*synonymFile) == 0 {
+		return
+	}
+	dict, err := synonym.LoadDictionary(*synonymFile, 10*time.Second)
+	if err != nil {
+		panic(err)
+	}
+	for _, field := range strings.Split(*synonymFields, ",") {
+		if field = strings.TrimSpace(field); len(field) > 0 {
+			// 写入时已经补充了同义词，检索时不需要再展开
+			dict.WithMode(field, synonym.IndexTime)
+		}
+	}
+	demo.IndexSynonyms = dict
+	handler.Synonyms = dict
+}
+
 // StartGin 启动 Gin Web 服务器
 func StartGin() {
 	// 创建默认的 Gin 引擎
@@ -54,6 +130,9 @@ func StartGin() {
 	// 设置 POST 请求路由
 	engine.POST("/search", handler.SearchAll)
 	engine.POST("/up_search", handler.SearchByAuthor)
+	engine.GET("/stats", handler.Stats)
+	engine.POST("/did_you_mean", handler.DidYouMean)
+	engine.GET("/suggest", handler.Suggest)
 	// 启动服务器，监听指定端口
 	engine.Run("127.0.0.1:" + strconv.Itoa(*port))
 }
@@ -61,6 +140,10 @@ func StartGin() {
 // main 程序入口函数
 func main() {
 	flag.Parse()
+	if *mode != 4 {
+		// coordinator 不写入文档也不处理检索，不需要同义词
+		loadSynonyms()
+	}
 
 	switch *mode {
 	case 1, 3:
@@ -71,10 +154,15 @@ func main() {
 	case 2:
 		// 2：以 gRPC 服务器的方式启动索引服务 IndexWorker
 		GrpcIndexerMain()
+	case 4:
+		// 4：以集群协调者的方式启动，多个协调者通过 etcd 选举出一个 leader，由 leader 维护分片表
+		CoordinatorMain()
 	}
 }
 
 // go run ./demo/main -mode=1 -index=true -port=5678 -dbPath=data/local_db/video_bolt
-// go run ./demo/main -mode=2 -index=true -port=5600 -dbPath=data/local_db/video_bolt -totalWorkers=2 -workerIndex=0
+// go run ./demo/main -mode=2 -index=true -port=5600 -dbPath=data/local_db/video_bolt -totalWorkers=2 -workerIndex=0 -changeLog=100000
 // go run ./demo/main -mode=2 -index=true -port=5601 -dbPath=data/local_db/video_bolt -totalWorkers=2 -workerIndex=1
-// go run ./demo/main -mode=3 -index=true -port=5678
+// go run ./demo/main -mode=2 -index=true -port=5610 -dbPath=data/local_db/video_bolt_replica -totalWorkers=2 -workerIndex=0 -changeLog=100000 -primary=127.0.0.1:5600
+// go run ./demo/main -mode=3 -index=true -port=5678 -lb=p2c
+// go run ./demo/main -mode=4 -port=5700 -totalWorkers=2 -replicas=1
@@ -2,9 +2,12 @@ package main
 
 import (
 	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/load_balancer"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/jmh000527/criker-search/demo"
 	"github.com/jmh000527/criker-search/demo/handler"
@@ -18,10 +21,10 @@ func WebServerInit(mode int) {
 	case 1:
 		// 模式 1：单机索引
 		// 创建一个新的索引器实例
-		standaloneIndexer := new(index_service.LocalIndexer)
+		standaloneIndexer := new(index_service.LocalIndexer).WithCodec(docCodec()).WithCompletion(completionFields()...)
 
 		// 初始化索引，参数为估计的文档数量，数据库类型，和数据库路径
-		if err := standaloneIndexer.Init(50000, dbType, *dbPath); err != nil {
+		if err := standaloneIndexer.Init(50000, *dbType, *dbPath); err != nil {
 			// 初始化失败，终止程序并报告错误
 			panic(err)
 		}
@@ -33,6 +36,8 @@ func WebServerInit(mode int) {
 			// 否则从正排索引文件加载索引
 			standaloneIndexer.LoadFromIndexFile()
 		}
+		standaloneIndexer.StartReaper(*reapInterval)
+		standaloneIndexer.StartMaintenance(maintenanceOptions())
 
 		// 将索引器实例分配给处理程序，以便处理请求时使用
 		handler.Indexer = standaloneIndexer
@@ -40,7 +45,27 @@ func WebServerInit(mode int) {
 	case 3:
 		// 模式 3：分布式索引
 		// 创建一个新的 Sentinel 实例作为分布式索引器
-		handler.Indexer = index_service.NewSentinel(etcdServers)
+		if len(*hubFile) == 0 {
+			sentinel := index_service.NewSentinel(etcdServers, *lbStrategy)
+			// 按 coordinator 的分片表选择主副本和从副本
+			if *shardMap {
+				if err := sentinel.WatchShardMap(etcdServers); err != nil {
+					panic(err)
+				}
+			}
+			handler.Indexer = sentinel
+			break
+		}
+		// 从文件读取 index worker 的地址，不依赖 etcd
+		hub, err := service_hub.NewFileServiceHub(*hubFile, time.Second)
+		if err != nil {
+			panic(err)
+		}
+		loadBalancer, err := load_balancer.NewLoadBalancer(*lbStrategy)
+		if err != nil {
+			panic(err)
+		}
+		handler.Indexer = index_service.NewSentinelWithHub(hub).WithLoadBalancer(loadBalancer)
 
 	default:
 		// 如果传入的模式无效，终止程序并报告错误
@@ -7,3 +7,10 @@ type SearchRequest struct {
 	ViewFrom int      // 视频播放量下限
 	ViewTo   int      // 视频播放量上限
 }
+
+// DidYouMeanResponse 拼写纠错的结果：把请求中的每个关键词和作者替换为词典中最相近的词
+type DidYouMeanResponse struct {
+	Author    string
+	Keywords  []string
+	Corrected bool // 是否有词被替换，为 false 时不需要提示
+}
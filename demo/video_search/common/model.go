@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"github.com/jmh000527/criker-search/demo"
+	"github.com/jmh000527/criker-search/index/synonym"
 	indexer "github.com/jmh000527/criker-search/index_service"
 )
 
@@ -12,6 +13,8 @@ type VideoSearchContext struct {
 	Indexer indexer.Indexer     // 索引服务，可能是本地的 Indexer，也可能是分布式的 Sentinel，提供索引操作的方法
 	Request *demo.SearchRequest // 搜索请求，包含了搜索的具体参数，如关键词、作者等
 	Videos  []*demo.BiliVideo   // 搜索结果，存储搜索得到的视频信息
+
+	Synonyms *synonym.Dictionary // 同义词词典，召回时用于展开查询中的关键词，为 nil 时不展开
 }
 
 // UN 表示用户名。
@@ -44,6 +44,8 @@ func (KeywordAuthorRecaller) Recall(ctx *common.VideoSearchContext) []*demo.Bili
 	}
 	// 构建或逻辑查询条件，满足指定类别
 	orFlags := []uint64{demo.GetClassBits(request.Classes)}
+	// 用同义词展开查询中的关键词
+	query = ctx.Synonyms.ExpandQuery(query)
 	// 执行查询，获取匹配的文档
 	docs := indexer.Search(query, 0, 0, orFlags)
 	// 创建一个用于存储匹配视频的切片
@@ -0,0 +1,224 @@
+package doc_codec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/golang/snappy"
+	"github.com/jmh000527/criker-search/types"
+	"github.com/klauspost/compress/zstd"
+	"sync"
+)
+
+// 存储在正排索引中的文档格式：marker(0x00) + 编码器标识(1字节) + 编码器产生的数据。
+// 早期版本直接存储 gob 编码的结果，gob 数据的第一个字节是消息长度，不会是 0x00，据此区分新旧格式。
+const marker byte = 0x00
+
+// 编码器标识，写入存储后不能修改
+const (
+	protobufId byte = 1
+	gobId      byte = 2
+	zstdId     byte = 3
+	snappyId   byte = 4
+)
+
+// 编码器的名称，用于配置
+const (
+	NameProtobuf = "protobuf"
+	NameGob      = "gob"
+	NameZstd     = "zstd"
+	NameSnappy   = "snappy"
+)
+
+// DocumentCodec 正排索引中文档的编码方式
+type DocumentCodec interface {
+	Name() string                                     // 编码器的名称
+	Marshal(doc *types.Document) ([]byte, error)      // 编码文档，不包含格式标记
+	Unmarshal(data []byte, doc *types.Document) error // 解码 Marshal 产生的数据
+	id() byte                                         // 写入存储的编码器标识
+}
+
+var (
+	Protobuf DocumentCodec = protobufCodec{} // protobuf 编码，默认的编码方式
+	Gob      DocumentCodec = gobCodec{}      // gob 编码，早期版本使用的编码方式
+	Zstd     DocumentCodec = &compressedCodec{name: NameZstd, codecId: zstdId, compress: zstdCompress, decompress: zstdDecompress}
+	Snappy   DocumentCodec = &compressedCodec{name: NameSnappy, codecId: snappyId, compress: snappyCompress, decompress: snappy.Decode}
+
+	codecs = map[byte]DocumentCodec{
+		protobufId: Protobuf,
+		gobId:      Gob,
+		zstdId:     Zstd,
+		snappyId:   Snappy,
+	}
+
+	// ErrUnknownCodec 存储的数据使用了未知的编码器，通常是数据由更新版本的程序写入
+	ErrUnknownCodec = errors.New("未知的文档编码器")
+)
+
+// Default 默认的编码方式
+var Default = Protobuf
+
+// Get 根据名称获取编码器，名称为空时返回默认的编码器
+//
+// 参数:
+//   - name: 编码器的名称，见 Name* 常量。
+//
+// 返回值:
+//   - DocumentCodec: 对应的编码器。
+//   - error: 名称不合法时返回错误。
+func Get(name string) (DocumentCodec, error) {
+	if len(name) == 0 {
+		return Default, nil
+	}
+	for _, codec := range codecs {
+		if codec.Name() == name {
+			return codec, nil
+		}
+	}
+	return nil, fmt.Errorf("不支持的文档编码器: %s", name)
+}
+
+// Encode 用 codec 编码文档，结果中带有格式标记，Decode 据此选择解码方式。
+// gob 编码器产生早期版本的格式（不带格式标记），使用 gob 写入的数据仍然可以被早期版本的程序读取，便于回滚。
+//
+// 参数:
+//   - codec: 编码器。
+//   - doc: 要编码的文档。
+//
+// 返回值:
+//   - []byte: 编码后的数据。
+//   - error: 编码失败时返回错误。
+func Encode(codec DocumentCodec, doc *types.Document) ([]byte, error) {
+	data, err := codec.Marshal(doc)
+	if err != nil || codec.id() == gobId {
+		return data, err
+	}
+	return append([]byte{marker, codec.id()}, data...), nil
+}
+
+// Decode 解码 Encode 产生的数据，根据格式标记选择编码器，没有格式标记的数据按 gob 解码
+//
+// 参数:
+//   - data: 存储中的数据。
+//   - doc: 解码的结果。
+//
+// 返回值:
+//   - error: 数据不完整、使用了未知的编码器或解码失败时返回错误。
+func Decode(data []byte, doc *types.Document) error {
+	codec, payload, err := detect(data)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(payload, doc)
+}
+
+// Detect 返回数据使用的编码器
+func Detect(data []byte) (DocumentCodec, error) {
+	codec, _, err := detect(data)
+	return codec, err
+}
+
+// detect 返回数据使用的编码器以及去掉格式标记之后的数据
+func detect(data []byte) (DocumentCodec, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errors.New("文档数据为空")
+	}
+	if data[0] != marker {
+		return Gob, data, nil
+	}
+	if len(data) < 2 {
+		return nil, nil, errors.New("文档数据不完整")
+	}
+	codec, exists := codecs[data[1]]
+	if !exists {
+		return nil, nil, fmt.Errorf("%w: %d", ErrUnknownCodec, data[1])
+	}
+	return codec, data[2:], nil
+}
+
+// protobufCodec types.Document 本身是 protobuf 消息，直接使用生成的编解码方法
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string { return NameProtobuf }
+func (protobufCodec) id() byte     { return protobufId }
+
+func (protobufCodec) Marshal(doc *types.Document) ([]byte, error) {
+	return doc.Marshal()
+}
+
+func (protobufCodec) Unmarshal(data []byte, doc *types.Document) error {
+	doc.Reset()
+	return doc.Unmarshal(data)
+}
+
+// gobCodec 早期版本使用的 gob 编码
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return NameGob }
+func (gobCodec) id() byte     { return gobId }
+
+func (gobCodec) Marshal(doc *types.Document) ([]byte, error) {
+	var value bytes.Buffer
+	if err := gob.NewEncoder(&value).Encode(doc); err != nil {
+		return nil, err
+	}
+	return value.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, doc *types.Document) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(doc)
+}
+
+// compressedCodec 先用 protobuf 编码再压缩，适合 Bytes 较大的文档
+type compressedCodec struct {
+	name       string
+	codecId    byte
+	compress   func(dst, src []byte) []byte
+	decompress func(dst, src []byte) ([]byte, error)
+}
+
+func (c *compressedCodec) Name() string { return c.name }
+func (c *compressedCodec) id() byte     { return c.codecId }
+
+func (c *compressedCodec) Marshal(doc *types.Document) ([]byte, error) {
+	data, err := doc.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	return c.compress(nil, data), nil
+}
+
+func (c *compressedCodec) Unmarshal(data []byte, doc *types.Document) error {
+	data, err := c.decompress(nil, data)
+	if err != nil {
+		return err
+	}
+	return Protobuf.Unmarshal(data, doc)
+}
+
+var (
+	zstdOnce    sync.Once
+	zstdEncoder *zstd.Encoder
+	zstdDecoder *zstd.Decoder
+)
+
+// initZstd 创建共享的 zstd 编码器和解码器，EncodeAll 和 DecodeAll 可以并发调用
+func initZstd() {
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+}
+
+func zstdCompress(dst, src []byte) []byte {
+	zstdOnce.Do(initZstd)
+	return zstdEncoder.EncodeAll(src, dst)
+}
+
+func zstdDecompress(dst, src []byte) ([]byte, error) {
+	zstdOnce.Do(initZstd)
+	return zstdDecoder.DecodeAll(src, dst)
+}
+
+func snappyCompress(dst, src []byte) []byte {
+	return snappy.Encode(dst, src)
+}
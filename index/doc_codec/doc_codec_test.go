@@ -0,0 +1,92 @@
+package doc_codec
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/types"
+)
+
+func testDoc(id string) *types.Document {
+	return &types.Document{
+		Id:          id,
+		IntId:       7,
+		BitsFeature: 0b101,
+		Keywords:    []*types.Keyword{{Field: "content", Word: "go"}, {Field: "author", Word: "a"}},
+		Bytes:       bytes.Repeat([]byte("video"), 100),
+		Version:     3,
+		ExpireAt:    1700000000,
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	doc := testDoc("doc")
+	for _, codec := range []DocumentCodec{Protobuf, Gob, Zstd, Snappy} {
+		data, err := Encode(codec, doc)
+		if err != nil {
+			t.Fatalf("%s 编码失败: %v", codec.Name(), err)
+		}
+		if detected, err := Detect(data); err != nil || detected != codec {
+			t.Fatalf("%s 编码的数据被识别为 %v，错误: %v", codec.Name(), detected, err)
+		}
+		var decoded types.Document
+		if err := Decode(data, &decoded); err != nil {
+			t.Fatalf("%s 解码失败: %v", codec.Name(), err)
+		}
+		if decoded.Id != doc.Id || decoded.IntId != doc.IntId || decoded.Version != doc.Version || decoded.ExpireAt != doc.ExpireAt ||
+			len(decoded.Keywords) != 2 || !bytes.Equal(decoded.Bytes, doc.Bytes) {
+			t.Fatalf("%s 解码的结果与原文档不一致: %+v", codec.Name(), decoded)
+		}
+	}
+
+	// gob 编码的数据与早期版本的格式相同，不带格式标记
+	legacy, _ := Gob.Marshal(doc)
+	if data, _ := Encode(Gob, doc); !bytes.Equal(data, legacy) {
+		t.Fatal("gob 编码的数据应与早期版本的格式相同")
+	}
+	if err := Decode([]byte{marker, 99, 1}, new(types.Document)); !errors.Is(err, ErrUnknownCodec) {
+		t.Fatalf("未知的编码器应返回 ErrUnknownCodec，实际为 %v", err)
+	}
+	if _, err := Get("lz4"); err == nil {
+		t.Fatal("不支持的编码器名称应返回错误")
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	db, err := kv_db.GetKvDB(kv_db.BOLT, filepath.Join(t.TempDir(), "migrate"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// 早期版本写入的 gob 数据
+	ids := []string{"doc0", "doc1", "doc2"}
+	for _, id := range ids {
+		value, _ := Encode(Gob, testDoc(id))
+		if err := db.Set([]byte(id), value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	n, err := Migrate(db, Zstd, 2, func(doc *types.Document) time.Time { return time.Time{} })
+	if err != nil || n != len(ids) {
+		t.Fatalf("应改写 %d 个文档，实际为 %d，错误: %v", len(ids), n, err)
+	}
+	for _, id := range ids {
+		value, err := db.Get([]byte(id))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var doc types.Document
+		if codec, _ := Detect(value); codec != Zstd || Decode(value, &doc) != nil || doc.Id != id {
+			t.Fatalf("文档 %s 应改写为 zstd 编码", id)
+		}
+	}
+	// 已经是目标编码的文档会被跳过
+	if n, err := Migrate(db, Zstd, 0, nil); err != nil || n != 0 {
+		t.Fatalf("重新执行不应改写文档，实际改写了 %d 个，错误: %v", n, err)
+	}
+}
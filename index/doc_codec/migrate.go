@@ -0,0 +1,90 @@
+package doc_codec
+
+import (
+	"fmt"
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/types"
+	"strings"
+	"time"
+)
+
+// DefaultMigrateBatchSize Migrate 默认每批改写的文档数量
+const DefaultMigrateBatchSize = 1000
+
+// Migrate 把正排索引中的全部文档改写为 codec 编码。已经是 codec 编码的文档会被跳过，中途失败后可以重新执行。
+// 先遍历出全部 key，再分批读取、改写，遍历期间不写数据库。调用方需保证迁移期间没有其他进程写入数据库。
+//
+// 参数:
+//   - db: 正排索引数据库。
+//   - codec: 目标编码方式。
+//   - batchSize: 每批改写的文档数量，<=0 时使用 DefaultMigrateBatchSize。
+//   - expireAt: 数据库支持过期（kv_db.ExpiringKeyValueDB）时，返回文档在数据库中的过期时间，零值表示不过期。
+//     改写会覆盖原有的过期时间，因此需要重新设置。为 nil 时改写后的文档不过期。
+//
+// 返回值:
+//   - int: 改写的文档数量。
+//   - error: 读写数据库或解码失败时返回错误。
+func Migrate(db kv_db.KeyValueDB, codec DocumentCodec, batchSize int, expireAt func(doc *types.Document) time.Time) (int, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultMigrateBatchSize
+	}
+	var keys [][]byte
+	if _, err := db.IterKey(func(k []byte) error {
+		keys = append(keys, append([]byte(nil), k...))
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for begin := 0; begin < len(keys); begin += batchSize {
+		end := begin + batchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+		values, err := db.BatchGet(keys[begin:end])
+		if err != nil {
+			return n, err
+		}
+		writeKeys := make([][]byte, 0, len(values))
+		writeValues := make([][]byte, 0, len(values))
+		expireAts := make([]time.Time, 0, len(values))
+		for _, value := range values {
+			if len(value) == 0 {
+				continue
+			}
+			if current, err := Detect(value); err == nil && current == codec {
+				continue
+			}
+			var doc types.Document
+			if err := Decode(value, &doc); err != nil {
+				return n, fmt.Errorf("解码文档失败: %w", err)
+			}
+			encoded, err := Encode(codec, &doc)
+			if err != nil {
+				return n, err
+			}
+			// BatchGet 不保证顺序，key 取自文档本身
+			writeKeys = append(writeKeys, []byte(strings.TrimSpace(doc.Id)))
+			writeValues = append(writeValues, encoded)
+			if expireAt != nil {
+				expireAts = append(expireAts, expireAt(&doc))
+			} else {
+				expireAts = append(expireAts, time.Time{})
+			}
+		}
+		if len(writeKeys) == 0 {
+			continue
+		}
+		if expiring, ok := db.(kv_db.ExpiringKeyValueDB); ok {
+			err = expiring.BatchSetWithExpiry(writeKeys, writeValues, expireAts)
+		} else {
+			err = db.BatchSet(writeKeys, writeValues)
+		}
+		if err != nil {
+			return n, err
+		}
+		n += len(writeKeys)
+	}
+	return n, nil
+}
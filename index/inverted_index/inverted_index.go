@@ -1,7 +1,9 @@
 package inverted_index
 
 import (
+	"github.com/jmh000527/criker-search/index/suggest"
 	"github.com/jmh000527/criker-search/types"
+	"math/bits"
 )
 
 // InvertedIndexer 定义了倒排索引器的接口，提供添加文档、删除文档以及根据查询条件搜索文档的功能。
@@ -9,9 +11,74 @@ type InvertedIndexer interface {
 	// Add 添加一个文档到倒排索引中。
 	Add(doc types.Document)
 
+	// BatchAdd 批量添加文档到倒排索引中。
+	BatchAdd(docs []types.Document)
+
 	// Delete 从倒排索引中删除与指定关键词和文档 ID 关联的文档。
 	Delete(keyword *types.Keyword, IntId uint64)
 
+	// Update 把文档从 oldDoc 更新为 newDoc，两者的 IntId 必须相同，只修改受影响的倒排链。
+	Update(oldDoc, newDoc types.Document)
+
 	// Search 根据给定的查询条件在倒排索引中查找匹配的文档，并返回业务侧的文档 ID 列表。
 	Search(q *types.TermQuery, onFlag uint64, offFlag uint64, orFlags []uint64) []string
+
+	// Stats 返回倒排索引的统计信息。
+	Stats() PostingStats
+
+	// Fields 返回字段目录：所有倒排链非空的字段及其keyword数量，按字段名排序。
+	Fields() []FieldStats
+
+	// Terms 遍历字段 field 下所有倒排链非空的keyword及其文档数量，fn 返回 false 时停止遍历。
+	Terms(field string, fn func(word string, docFreq int) bool)
+
+	// Complete 返回字段 field 中以 prefix 开头、文档数量最多的 limit 个keyword，用于自动补全。
+	Complete(field, prefix string, limit int) []suggest.Completion
+
+	// CompletionFields 返回设置了自动补全的字段。
+	CompletionFields() []string
+}
+
+// FilterByBits 检查特征位 bits 是否满足过滤条件：包含 onFlag 的所有位，不包含 offFlag 的任何位，
+// 并且与 orFlags 中的每个非零标志至少有一位相同。
+func FilterByBits(bits, onFlag, offFlag uint64, orFlags []uint64) bool {
+	// 检查 bits 是否包含 onFlag 中所有的位。
+	if bits&onFlag != onFlag {
+		return false
+	}
+	// 检查 bits 是否包含 offFlag 中的任何位。
+	if bits&offFlag != uint64(0) {
+		return false
+	}
+	// 检查 bits 是否匹配或标志列表中的所有标志中的至少一个。
+	for _, orFlag := range orFlags {
+		// 只要有一个 orFlag 的位在 bits 中存在，就符合条件。
+		if orFlag > 0 && bits&orFlag <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// PostingStats 倒排索引的统计信息
+type PostingStats struct {
+	KeywordCount int     // 倒排链非空的keyword数量
+	Histogram    []int64 // 倒排链长度的分布，第i个元素是长度在[2^i, 2^(i+1))之间的倒排链数量
+	MaxLength    int     // 最长的倒排链长度
+}
+
+// Observe 把一条长度为length的倒排链计入统计，长度为0的倒排链（其中的文档都已删除）不计入。
+func (s *PostingStats) Observe(length int) {
+	if length <= 0 {
+		return
+	}
+	s.KeywordCount++
+	bucket := bits.Len(uint(length)) - 1
+	for len(s.Histogram) <= bucket {
+		s.Histogram = append(s.Histogram, 0)
+	}
+	s.Histogram[bucket]++
+	if length > s.MaxLength {
+		s.MaxLength = length
+	}
 }
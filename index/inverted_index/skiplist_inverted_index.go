@@ -2,11 +2,15 @@ package inverted_index
 
 import (
 	"github.com/huandu/skiplist"
+	"github.com/jmh000527/criker-search/index/suggest"
 	"github.com/jmh000527/criker-search/types"
 	"github.com/jmh000527/criker-search/utils/concurrent_hash_map"
 	farmhash "github.com/leemcloughlin/gofarmhash"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 )
 
 // SkipListInvertedIndexer 表示一个使用跳表作为值的倒排索引。
@@ -14,12 +18,25 @@ import (
 type SkipListInvertedIndexer struct {
 	table *utils.ConcurrentHashMap // 使用分段锁保护的并发安全 map，用于存储倒排索引的数据
 	locks []sync.RWMutex           // 针对相同的 key 进行竞争的锁，以确保在修改倒排索引时的并发安全
+
+	fieldTerms map[string]int // 字段目录：每个字段上倒排链非空的keyword数量
+	fieldMu    sync.RWMutex   // 保护 fieldTerms
+
+	completions map[string]*suggest.Trie // 字段 -> 自动补全的前缀树，词的权重是包含它的文档数量。创建之后只读
 }
 
 // SkipListValue 跳表的key是Document IntId，跳表的value是SkipListValue类型
 type SkipListValue struct {
-	Id          string // 业务侧的ID
-	BitsFeature uint64 // 文件属性位图
+	Id          string  // 业务侧的ID
+	BitsFeature uint64  // 文件属性位图
+	ExpireAt    int64   // 文档的过期时间(Unix时间戳，单位秒)，0表示永不过期
+	Score       float64 // 检索结果中文档的得分，由多字段查询命中字段的权重累加而来。倒排链中存储的值为0
+}
+
+// FieldStats 字段目录中的一个字段
+type FieldStats struct {
+	Field     string // 字段名
+	TermCount int    // 该字段上倒排链非空的keyword数量
 }
 
 // NewSkipListInvertedIndexer 创建并返回一个新的 SkipListInvertedIndexer 实例。
@@ -35,7 +52,25 @@ func NewSkipListInvertedIndexer(docNumEstimate int) *SkipListInvertedIndexer {
 		// 创建一个分段锁保护的并发安全 map，用于存储倒排索引的数据。
 		table: utils.NewConcurrentHashMap(runtime.NumCPU(), docNumEstimate),
 		// 创建一个大小为 1000 的 RWMutex 数组，用于锁定倒排索引中的不同 key，以确保并发安全。
-		locks: make([]sync.RWMutex, 1000),
+		locks:      make([]sync.RWMutex, 1000),
+		fieldTerms: make(map[string]int),
+	}
+	return indexer
+}
+
+// WithCompletion 为字段 fields 维护自动补全的前缀树，需要在写入文档之前调用。
+//
+// 参数:
+//   - fields: 需要自动补全的字段，例如关键词和作者。
+//
+// 返回值:
+//   - *SkipListInvertedIndexer: 倒排索引本身，便于链式调用。
+func (indexer *SkipListInvertedIndexer) WithCompletion(fields ...string) *SkipListInvertedIndexer {
+	if len(fields) > 0 && indexer.completions == nil {
+		indexer.completions = make(map[string]*suggest.Trie, len(fields))
+	}
+	for _, field := range fields {
+		indexer.completions[field] = suggest.NewTrie()
 	}
 	return indexer
 }
@@ -45,29 +80,51 @@ func NewSkipListInvertedIndexer(docNumEstimate int) *SkipListInvertedIndexer {
 // 参数:
 //   - doc: 需要添加的文档，类型为 types.Document。
 func (indexer *SkipListInvertedIndexer) Add(doc types.Document) {
+	// 创建跳表中的值，包括文档的 ID 和位特征
+	skipListValue := SkipListValue{
+		Id:          doc.Id,
+		BitsFeature: doc.BitsFeature,
+		ExpireAt:    doc.ExpireAt,
+	}
 	for _, keyword := range doc.Keywords {
-		// 获取倒排索引的 key，通常是关键词的字符串表示
-		key := keyword.ToString()
-		// 获取与 key 关联的锁，用于确保并发操作的安全性
-		lock := indexer.getLock(key)
-		// 创建跳表中的值，包括文档的 ID 和位特征
-		skipListValue := SkipListValue{
-			Id:          doc.Id,
-			BitsFeature: doc.BitsFeature,
+		// 倒排索引的 key 是关键词的字符串表示，倒排链不存在时创建
+		indexer.set(keyword.ToString(), doc.IntId, skipListValue)
+	}
+}
+
+// BatchAdd 将一批 Document 添加到倒排索引中。
+// 与逐个调用 Add 不同，该方法先按倒排索引的 key 对文档分组，每个 key 的锁在一个批次内只获取一次。
+//
+// 参数:
+//   - docs: 需要添加的文档列表，类型为 []types.Document。
+func (indexer *SkipListInvertedIndexer) BatchAdd(docs []types.Document) {
+	// 按倒排索引的 key 对文档分组
+	groups := make(map[string][]*types.Document, len(docs))
+	for i := range docs {
+		for _, keyword := range docs[i].Keywords {
+			key := keyword.ToString()
+			groups[key] = append(groups[key], &docs[i])
 		}
+	}
 
+	for key, group := range groups {
+		lock := indexer.getLock(key)
 		lock.Lock()
+		var list *skiplist.SkipList
 		if value, exists := indexer.table.Get(key); exists {
-			// 如果倒排索引的 key 已存在，从表中获取对应的跳表，并将新文档的 ID 和位特征添加到跳表中
-			list := value.(*skiplist.SkipList)
-			list.Set(doc.IntId, skipListValue)
+			list = value.(*skiplist.SkipList)
 		} else {
-			// 如果倒排索引的 key 不存在，创建一个新的跳表，并将文档添加到该跳表中
-			list := skiplist.New(skiplist.Uint64)
-			list.Set(doc.IntId, skipListValue)
-			// 将新的跳表存入倒排索引表中，并发安全
+			// 如果倒排索引的 key 不存在，创建一个新的跳表并存入倒排索引表中
+			list = skiplist.New(skiplist.Uint64)
 			indexer.table.Set(key, list)
 		}
+		for _, doc := range group {
+			indexer.setInList(list, key, doc.IntId, SkipListValue{
+				Id:          doc.Id,
+				BitsFeature: doc.BitsFeature,
+				ExpireAt:    doc.ExpireAt,
+			})
+		}
 		lock.Unlock()
 	}
 }
@@ -87,13 +144,171 @@ func (indexer *SkipListInvertedIndexer) Delete(keyword *types.Keyword, IntId uin
 	// 如果倒排索引中存在该 key，获取对应的跳表并从中删除文档。
 	if value, exists := indexer.table.Get(key); exists {
 		list := value.(*skiplist.SkipList)
-		list.Remove(IntId)
+		if list.Remove(IntId) == nil {
+			return
+		}
+		indexer.addCompletion(key, -1)
+		// 倒排链被删空时，该 keyword 不再计入字段目录
+		if list.Len() == 0 {
+			indexer.countTerm(key, -1)
+		}
+	}
+}
+
+// Update 把文档从 oldDoc 更新为 newDoc，用于局部更新文档。文档的 IntId 不变，因此只需修改受影响的倒排链：
+// 删除的关键词从对应的倒排链中移除，新增的关键词加入对应的倒排链；
+// 位特征或过期时间发生变化时，保留的关键词对应的倒排链中的值也需要更新，否则检索时的过滤结果会不正确。
+//
+// 参数:
+//   - oldDoc: 更新前的文档。
+//   - newDoc: 更新后的文档，IntId 必须与 oldDoc 相同。
+func (indexer *SkipListInvertedIndexer) Update(oldDoc, newDoc types.Document) {
+	oldKeys := make(map[string]*types.Keyword, len(oldDoc.Keywords))
+	for _, keyword := range oldDoc.Keywords {
+		oldKeys[keyword.ToString()] = keyword
+	}
+	newKeys := make(map[string]struct{}, len(newDoc.Keywords))
+	value := SkipListValue{
+		Id:          newDoc.Id,
+		BitsFeature: newDoc.BitsFeature,
+		ExpireAt:    newDoc.ExpireAt,
+	}
+	for _, keyword := range newDoc.Keywords {
+		key := keyword.ToString()
+		newKeys[key] = struct{}{}
+		if _, exists := oldKeys[key]; exists && oldDoc.BitsFeature == newDoc.BitsFeature && oldDoc.ExpireAt == newDoc.ExpireAt {
+			// 关键词、位特征和过期时间都没有变化，不需要修改这条倒排链
+			continue
+		}
+		indexer.set(key, newDoc.IntId, value)
+	}
+	for key, keyword := range oldKeys {
+		if _, exists := newKeys[key]; !exists {
+			indexer.Delete(keyword, oldDoc.IntId)
+		}
+	}
+}
+
+// set 把文档写入 key 对应的倒排链，倒排链不存在时创建
+func (indexer *SkipListInvertedIndexer) set(key string, intId uint64, value SkipListValue) {
+	lock := indexer.getLock(key)
+	lock.Lock()
+	defer lock.Unlock()
+	if list, exists := indexer.table.Get(key); exists {
+		indexer.setInList(list.(*skiplist.SkipList), key, intId, value)
+		return
+	}
+	list := skiplist.New(skiplist.Uint64)
+	indexer.setInList(list, key, intId, value)
+	indexer.table.Set(key, list)
+}
+
+// setInList 把文档写入 key 对应的倒排链 list，调用方需持有 key 的锁。空的倒排链写入第一个文档时，该 keyword 计入字段目录；
+// 倒排链中新增文档时（而不是更新已有文档的值），该 keyword 在自动补全中的权重加1
+func (indexer *SkipListInvertedIndexer) setInList(list *skiplist.SkipList, key string, intId uint64, value SkipListValue) {
+	if list.Len() == 0 {
+		indexer.countTerm(key, 1)
+	}
+	if list.Get(intId) == nil {
+		indexer.addCompletion(key, 1)
+	}
+	list.Set(intId, value)
+}
+
+// addCompletion 把倒排索引的 key 在所属字段的前缀树中的权重加上 delta，字段不需要自动补全时什么都不做
+func (indexer *SkipListInvertedIndexer) addCompletion(key string, delta int64) {
+	if len(indexer.completions) == 0 {
+		return
+	}
+	field, word, _ := strings.Cut(key, "\001")
+	if trie := indexer.completions[field]; trie != nil {
+		trie.Add(word, delta)
+	}
+}
+
+// Complete 返回字段 field 中以 prefix 开头、包含它的文档数量最多的 limit 个keyword。
+//
+// 参数:
+//   - field: 字段名，没有通过 WithCompletion 设置自动补全的字段返回 nil。
+//   - prefix: 前缀。
+//   - limit: 最多返回的候选数量。
+//
+// 返回值:
+//   - []suggest.Completion: 按文档数量从多到少排序的候选词。
+func (indexer *SkipListInvertedIndexer) Complete(field, prefix string, limit int) []suggest.Completion {
+	if trie := indexer.completions[field]; trie != nil {
+		return trie.Complete(prefix, limit)
+	}
+	return nil
+}
+
+// CompletionFields 返回通过 WithCompletion 设置了自动补全的字段，按字段名排序
+func (indexer *SkipListInvertedIndexer) CompletionFields() []string {
+	fields := make([]string, 0, len(indexer.completions))
+	for field := range indexer.completions {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// countTerm 把倒排索引的 key 所属字段上的keyword数量加上 delta
+func (indexer *SkipListInvertedIndexer) countTerm(key string, delta int) {
+	field, _, _ := strings.Cut(key, "\001")
+	indexer.fieldMu.Lock()
+	defer indexer.fieldMu.Unlock()
+	if n := indexer.fieldTerms[field] + delta; n > 0 {
+		indexer.fieldTerms[field] = n
+	} else {
+		delete(indexer.fieldTerms, field)
+	}
+}
+
+// Fields 返回字段目录：所有倒排链非空的字段及其keyword数量，按字段名排序。
+//
+// 返回值:
+//   - []FieldStats: 字段目录。
+func (indexer *SkipListInvertedIndexer) Fields() []FieldStats {
+	indexer.fieldMu.RLock()
+	fields := make([]FieldStats, 0, len(indexer.fieldTerms))
+	for field, n := range indexer.fieldTerms {
+		fields = append(fields, FieldStats{Field: field, TermCount: n})
+	}
+	indexer.fieldMu.RUnlock()
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Field < fields[j].Field
+	})
+	return fields
+}
+
+// Terms 遍历字段 field 下所有倒排链非空的keyword，fn 返回 false 时停止遍历。遍历的顺序不确定。
+//
+// 参数:
+//   - field: 字段名。
+//   - fn: 回调函数，参数为keyword和包含它的文档数量。
+func (indexer *SkipListInvertedIndexer) Terms(field string, fn func(word string, docFreq int) bool) {
+	prefix := field + "\001"
+	iterator := indexer.table.CreateIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if entry.Value == nil || !strings.HasPrefix(entry.Key, prefix) {
+			continue
+		}
+		lock := indexer.getLock(entry.Key)
+		lock.RLock()
+		docFreq := entry.Value.(*skiplist.SkipList).Len()
+		lock.RUnlock()
+		if docFreq == 0 {
+			continue
+		}
+		if !fn(entry.Key[len(prefix):], docFreq) {
+			return
+		}
 	}
 }
 
 // Search 执行搜索查询并返回业务侧文档ID列表。
 // 该方法调用内部的 search 方法，获取匹配的文档 ID 和其 SkipListValue。
-// 然后将匹配的文档 ID 转换为业务侧 ID 并返回。
+// 然后将匹配的文档 ID 转换为业务侧 ID 并返回。查询中包含多字段查询时按得分从高到低排序，得分相同的保持 IntId 的顺序。
 //
 // 参数:
 //   - query: 查询条件，类型为 *types.TermQuery。
@@ -110,22 +325,51 @@ func (indexer *SkipListInvertedIndexer) Search(query *types.TermQuery, onFlag ui
 		return nil
 	}
 
-	// 创建一个切片，用于存储业务侧文档ID
-	arr := make([]string, 0, result.Len())
-
 	// 获取跳表的第一个节点
-	node := result.Front()
-	// 遍历匹配的结果，将文档ID添加到切片中
-	for node != nil {
+	values := make([]SkipListValue, 0, result.Len())
+	scored := false
+	// 遍历匹配的结果
+	for node := result.Front(); node != nil; node = node.Next() {
 		skipListValue := node.Value.(SkipListValue)
-		arr = append(arr, skipListValue.Id)
-		node = node.Next()
+		values = append(values, skipListValue)
+		scored = scored || skipListValue.Score != 0
+	}
+	if scored {
+		sort.SliceStable(values, func(i, j int) bool {
+			return values[i].Score > values[j].Score
+		})
 	}
 
+	// 创建一个切片，用于存储业务侧文档ID
+	arr := make([]string, 0, len(values))
+	for _, value := range values {
+		arr = append(arr, value.Id)
+	}
 	// 返回业务侧文档ID列表
 	return arr
 }
 
+// Stats 遍历倒排索引，统计keyword数量和倒排链长度的分布。
+// 统计期间索引可能仍在写入，结果是近似值。
+//
+// 返回值:
+//   - PostingStats: 倒排索引的统计信息。
+func (indexer *SkipListInvertedIndexer) Stats() PostingStats {
+	var stats PostingStats
+	iterator := indexer.table.CreateIterator()
+	for entry := iterator.Next(); entry != nil; entry = iterator.Next() {
+		if entry.Value == nil {
+			continue
+		}
+		lock := indexer.getLock(entry.Key)
+		lock.RLock()
+		length := entry.Value.(*skiplist.SkipList).Len()
+		lock.RUnlock()
+		stats.Observe(length)
+	}
+	return stats
+}
+
 // FilterByBits 根据 bits 特征进行过滤。
 // 该方法检查传入的 bits 是否符合指定的过滤条件。
 // - `onFlag`：所有 bits 必须完全匹配 `onFlag`。
@@ -141,22 +385,7 @@ func (indexer *SkipListInvertedIndexer) Search(query *types.TermQuery, onFlag ui
 // 返回值:
 //   - bool: 如果 bits 满足所有过滤条件，返回 true；否则返回 false。
 func (indexer *SkipListInvertedIndexer) FilterByBits(bits, onFlag, offFlag uint64, orFlags []uint64) bool {
-	// 检查 bits 是否包含 onFlag 中所有的位。
-	if bits&onFlag != onFlag {
-		return false
-	}
-	// 检查 bits 是否包含 offFlag 中的任何位。
-	if bits&offFlag != uint64(0) {
-		return false
-	}
-	// 检查 bits 是否匹配或标志列表中的所有标志中的至少一个。
-	for _, orFlag := range orFlags {
-		// 只要有一个 orFlag 的位在 bits 中存在，就符合条件。
-		if orFlag > 0 && bits&orFlag <= 0 {
-			return false
-		}
-	}
-	return true
+	return FilterByBits(bits, onFlag, offFlag, orFlags)
 }
 
 // search 执行 TermQuery 查询并返回匹配的跳表结果。
@@ -172,37 +401,29 @@ func (indexer *SkipListInvertedIndexer) FilterByBits(bits, onFlag, offFlag uint6
 // 返回值:
 //   - *skiplist.SkipList: 匹配的文档 ID 和其对应的 SkipListValue。
 func (indexer *SkipListInvertedIndexer) search(q *types.TermQuery, onFlag uint64, offFlag uint64, orFlags []uint64) *skiplist.SkipList {
+	return indexer.searchAt(q, onFlag, offFlag, orFlags, time.Now().Unix())
+}
+
+// searchAt 与 search 相同，已过期（过期时间不晚于 now）但尚未被删除的文档不会出现在结果中
+func (indexer *SkipListInvertedIndexer) searchAt(q *types.TermQuery, onFlag uint64, offFlag uint64, orFlags []uint64, now int64) *skiplist.SkipList {
 	// 处理叶子节点情况，即直接根据关键词查找。
 	if q.Keyword != nil {
-		// 获取关键词对应的跳表。
-		keyword := q.Keyword.ToString()
-		// 如果关键词存在，获取对应的跳表。
-		if value, exists := indexer.table.Get(keyword); exists {
-			list := value.(*skiplist.SkipList)
-			result := skiplist.New(skiplist.Uint64) // 存储查询结果的跳表
-
-			// 获取跳表的第一个节点
-			node := list.Front()
-			// 遍历跳表，查找符合条件的文档
-			for node != nil {
-				intId := node.Key().(uint64)
-				skipListValue := node.Value.(SkipListValue)
-				flag := skipListValue.BitsFeature
-				// 根据特征位标志过滤结果
-				if intId > 0 && indexer.FilterByBits(flag, onFlag, offFlag, orFlags) {
-					result.Set(intId, skipListValue)
-				}
-				node = node.Next()
-			}
-
-			return result
+		if len(q.Fields) == 0 {
+			return indexer.postings(q.Keyword.ToString(), 0, onFlag, offFlag, orFlags, now)
+		}
+		// 多字段查询，在每个字段上检索后求并集，文档的得分为命中字段的权重之和
+		results := make([]*skiplist.SkipList, 0, len(q.Fields))
+		for _, field := range q.Fields {
+			keyword := types.Keyword{Field: field.Field, Word: q.Keyword.Word}
+			results = append(results, indexer.postings(keyword.ToString(), field.Weight(), onFlag, offFlag, orFlags, now))
 		}
+		return UnionOfSkipList(results...)
 	} else if len(q.Must) > 0 {
 		// 处理 Must 查询条件，将所有 Must 查询的结果进行交集运算
 		results := make([]*skiplist.SkipList, 0, len(q.Must))
 		for _, query := range q.Must {
 			// 递归执行 Must 查询
-			results = append(results, indexer.search(query, onFlag, offFlag, orFlags))
+			results = append(results, indexer.searchAt(query, onFlag, offFlag, orFlags, now))
 		}
 		// 计算 Must 查询结果的交集
 		return IntersectionOfSkipLists(results...)
@@ -211,15 +432,40 @@ func (indexer *SkipListInvertedIndexer) search(q *types.TermQuery, onFlag uint64
 		results := make([]*skiplist.SkipList, 0, len(q.Should))
 		for _, query := range q.Should {
 			// 递归执行 Should 查询
-			results = append(results, indexer.search(query, onFlag, offFlag, orFlags))
+			results = append(results, indexer.searchAt(query, onFlag, offFlag, orFlags, now))
 		}
 		// 计算 Should 查询结果的并集
-		return IntersectionOfSkipLists(results...)
+		return UnionOfSkipList(results...)
 	}
 	// 如果查询条件为空，返回 nil
 	return nil
 }
 
+// postings 返回 key 对应的倒排链中符合位特征过滤条件、没有过期的文档，文档的得分为 score。key 不存在时返回 nil
+func (indexer *SkipListInvertedIndexer) postings(key string, score float64, onFlag uint64, offFlag uint64, orFlags []uint64, now int64) *skiplist.SkipList {
+	// 如果关键词存在，获取对应的跳表。
+	value, exists := indexer.table.Get(key)
+	if !exists {
+		return nil
+	}
+	list := value.(*skiplist.SkipList)
+	result := skiplist.New(skiplist.Uint64) // 存储查询结果的跳表
+
+	// 遍历跳表，查找符合条件的文档
+	for node := list.Front(); node != nil; node = node.Next() {
+		intId := node.Key().(uint64)
+		skipListValue := node.Value.(SkipListValue)
+		flag := skipListValue.BitsFeature
+		// 根据特征位标志过滤结果，并过滤掉已过期的文档
+		expired := skipListValue.ExpireAt > 0 && skipListValue.ExpireAt <= now
+		if intId > 0 && !expired && indexer.FilterByBits(flag, onFlag, offFlag, orFlags) {
+			skipListValue.Score = score
+			result.Set(intId, skipListValue)
+		}
+	}
+	return result
+}
+
 // getLock 获取与给定 key 关联的读写锁。
 // 使用哈希值来确定锁的索引，以确保相同的 key 总是使用相同的锁。
 // 这样可以在并发修改时确保对相同 key 的操作是线程安全的。
@@ -0,0 +1,101 @@
+package inverted_index
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index/suggest"
+	"github.com/jmh000527/criker-search/types"
+)
+
+func newTestIndexer() *SkipListInvertedIndexer {
+	indexer := NewSkipListInvertedIndexer(100)
+	indexer.BatchAdd([]types.Document{
+		{Id: "a", IntId: 1, Keywords: []*types.Keyword{{Field: "content", Word: "golang"}, {Field: "author", Word: "rob"}}},
+		{Id: "b", IntId: 2, Keywords: []*types.Keyword{{Field: "author", Word: "golang"}}},
+		{Id: "c", IntId: 3, Keywords: []*types.Keyword{{Field: "content", Word: "golang"}, {Field: "title", Word: "golang"}}},
+		{Id: "d", IntId: 4, Keywords: []*types.Keyword{{Field: "content", Word: "rust"}}},
+	})
+	return indexer
+}
+
+func TestShouldIsUnion(t *testing.T) {
+	indexer := newTestIndexer()
+	query := types.NewTermQuery("content", "golang").Or(types.NewTermQuery("content", "rust"))
+	if got := indexer.Search(query, 0, 0, nil); !reflect.DeepEqual(got, []string{"a", "c", "d"}) {
+		t.Fatalf("Should 应返回并集 [a c d]，实际为 %v", got)
+	}
+}
+
+func TestMultiFieldQuery(t *testing.T) {
+	indexer := newTestIndexer()
+	query := types.NewMultiFieldQuery("golang",
+		&types.FieldBoost{Field: "content"},
+		&types.FieldBoost{Field: "title", Boost: 1.5},
+		&types.FieldBoost{Field: "author", Boost: 2},
+	)
+	// c 命中 content 和 title，得分 2.5；b 命中 author，得分 2；a 命中 content，得分 1
+	if got := indexer.Search(query, 0, 0, nil); !reflect.DeepEqual(got, []string{"c", "b", "a"}) {
+		t.Fatalf("应按得分返回 [c b a]，实际为 %v", got)
+	}
+	// 与其它条件组合时得分继续累加
+	query = query.And(types.NewTermQuery("author", "rob"))
+	if got := indexer.Search(query, 0, 0, nil); !reflect.DeepEqual(got, []string{"a"}) {
+		t.Fatalf("应返回 [a]，实际为 %v", got)
+	}
+	// 只在指定的字段上检索
+	query = types.NewMultiFieldQuery("golang", &types.FieldBoost{Field: "author"})
+	if got := indexer.Search(query, 0, 0, nil); !reflect.DeepEqual(got, []string{"b"}) {
+		t.Fatalf("应返回 [b]，实际为 %v", got)
+	}
+}
+
+func TestFields(t *testing.T) {
+	indexer := newTestIndexer()
+	expect := []FieldStats{{"author", 2}, {"content", 2}, {"title", 1}}
+	if got := indexer.Fields(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("字段目录应为 %v，实际为 %v", expect, got)
+	}
+
+	// 倒排链被删空的keyword不再计入，重新写入后再次计入
+	indexer.Delete(&types.Keyword{Field: "title", Word: "golang"}, 3)
+	indexer.Delete(&types.Keyword{Field: "content", Word: "golang"}, 1)
+	expect = []FieldStats{{"author", 2}, {"content", 2}}
+	if got := indexer.Fields(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("删除之后字段目录应为 %v，实际为 %v", expect, got)
+	}
+	indexer.Add(types.Document{Id: "e", IntId: 5, Keywords: []*types.Keyword{{Field: "title", Word: "go"}}})
+	expect = []FieldStats{{"author", 2}, {"content", 2}, {"title", 1}}
+	if got := indexer.Fields(); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("写入之后字段目录应为 %v，实际为 %v", expect, got)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	indexer := NewSkipListInvertedIndexer(100).WithCompletion("content")
+	indexer.BatchAdd([]types.Document{
+		{Id: "a", IntId: 1, Keywords: []*types.Keyword{{Field: "content", Word: "golang"}, {Field: "author", Word: "gopher"}}},
+		{Id: "b", IntId: 2, Keywords: []*types.Keyword{{Field: "content", Word: "golang"}, {Field: "content", Word: "go"}}},
+	})
+	indexer.Add(types.Document{Id: "c", IntId: 3, Keywords: []*types.Keyword{{Field: "content", Word: "gopher"}}})
+	expect := []suggest.Completion{{Word: "golang", Weight: 2}, {Word: "go", Weight: 1}, {Word: "gopher", Weight: 1}}
+	if got := indexer.Complete("content", "go", 10); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	// 没有设置自动补全的字段
+	if got := indexer.Complete("author", "go", 10); got != nil {
+		t.Fatalf("author 字段不应补全，实际为 %v", got)
+	}
+
+	// 重复写入同一个文档不改变权重，删除和更新时权重随之变化
+	indexer.Add(types.Document{Id: "c", IntId: 3, Keywords: []*types.Keyword{{Field: "content", Word: "gopher"}}})
+	indexer.Delete(&types.Keyword{Field: "content", Word: "golang"}, 1)
+	indexer.Update(
+		types.Document{Id: "b", IntId: 2, Keywords: []*types.Keyword{{Field: "content", Word: "golang"}, {Field: "content", Word: "go"}}},
+		types.Document{Id: "b", IntId: 2, Keywords: []*types.Keyword{{Field: "content", Word: "gopher"}, {Field: "content", Word: "go"}}},
+	)
+	expect = []suggest.Completion{{Word: "gopher", Weight: 2}, {Word: "go", Weight: 1}}
+	if got := indexer.Complete("content", "go", 10); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("更新之后应返回 %v，实际为 %v", expect, got)
+	}
+}
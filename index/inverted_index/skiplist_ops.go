@@ -2,7 +2,7 @@ package inverted_index
 
 import "github.com/huandu/skiplist"
 
-// IntersectionOfSkipLists 多个SkipList求交集
+// IntersectionOfSkipLists 多个SkipList求交集。value为SkipListValue时，交集中文档的得分为各条链中得分之和
 func IntersectionOfSkipLists(lists ...*skiplist.SkipList) *skiplist.SkipList {
 	if len(lists) == 0 {
 		return nil
@@ -36,7 +36,11 @@ func IntersectionOfSkipLists(lists ...*skiplist.SkipList) *skiplist.SkipList {
 		// 所有node的值都一样大，则新诞生一个交集
 		if len(maxList) == len(curNodes) {
 			// 此时所有curNodes的key相同
-			result.Set(curNodes[0].Key(), curNodes[0].Value)
+			value := curNodes[0].Value
+			for _, node := range curNodes[1:] {
+				value = addScore(value, node.Value)
+			}
+			result.Set(curNodes[0].Key(), value)
 			// 所有node均需往后移
 			for i, node := range curNodes {
 				curNodes[i] = node.Next()
@@ -60,7 +64,7 @@ func IntersectionOfSkipLists(lists ...*skiplist.SkipList) *skiplist.SkipList {
 	}
 }
 
-// UnionOfSkipList 求多个SkipList的并集
+// UnionOfSkipList 求多个SkipList的并集。value为SkipListValue时，并集中文档的得分为各条链中得分之和
 func UnionOfSkipList(lists ...*skiplist.SkipList) *skiplist.SkipList {
 	if len(lists) == 0 {
 		return nil
@@ -81,9 +85,24 @@ func UnionOfSkipList(lists ...*skiplist.SkipList) *skiplist.SkipList {
 				result.Set(node.Key(), node.Value)
 				// 将当前节点的键添加到键集合中，标记为已添加
 				keySet[node.Key()] = struct{}{}
+			} else if elem := result.Get(node.Key()); elem != nil {
+				// 已经添加过的文档累加得分
+				elem.Value = addScore(elem.Value, node.Value)
 			}
 			node = node.Next()
 		}
 	}
 	return result
 }
+
+// addScore 两个value都是SkipListValue时，返回得分相加后的a，否则原样返回a
+func addScore(a, b any) any {
+	va, ok := a.(SkipListValue)
+	if !ok {
+		return a
+	}
+	if vb, ok := b.(SkipListValue); ok && vb.Score != 0 {
+		va.Score += vb.Score
+	}
+	return va
+}
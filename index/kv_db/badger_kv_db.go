@@ -2,11 +2,13 @@ package kv_db
 
 import (
 	"errors"
+	"fmt"
 	"github.com/dgraph-io/badger/v4"
 	"github.com/jmh000527/criker-search/utils"
+	"io"
 	"os"
 	"path"
-	"sync/atomic"
+	"time"
 )
 
 type Badger struct {
@@ -70,12 +72,50 @@ func (b *Badger) BatchSet(keys, values [][]byte) error {
 	return err
 }
 
-// Get 读取key对应的value，如果key不存在会返回error: Key not found
+// SetWithExpiry 写入<key, value>，到达expireAt之后key自动过期，expireAt为零值表示永不过期
+func (b *Badger) SetWithExpiry(k, v []byte, expireAt time.Time) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return txn.SetEntry(newEntry(k, v, expireAt))
+	})
+}
+
+// BatchSetWithExpiry 批量写入<key, value>并设置过期时间，多个写操作使用一个事务
+func (b *Badger) BatchSetWithExpiry(keys, values [][]byte, expireAts []time.Time) error {
+	if len(keys) != len(values) || len(keys) != len(expireAts) {
+		return errors.New("keys, values and expireAts do not match")
+	}
+	txn := b.db.NewTransaction(true)
+	for i, key := range keys {
+		entry := newEntry(key, values[i], expireAts[i])
+		if err := txn.SetEntry(entry); err != nil {
+			// 发生异常时就提交老事务，然后开一个新事务，重试set
+			_ = txn.Commit()
+			txn = b.db.NewTransaction(true)
+			_ = txn.SetEntry(newEntry(key, values[i], expireAts[i]))
+		}
+	}
+	return txn.Commit()
+}
+
+// newEntry 创建带过期时间的Entry，expireAt为零值时不过期
+func newEntry(k, v []byte, expireAt time.Time) *badger.Entry {
+	entry := badger.NewEntry(k, v)
+	if !expireAt.IsZero() {
+		entry.ExpiresAt = uint64(expireAt.Unix())
+	}
+	return entry
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
 func (b *Badger) Get(k []byte) ([]byte, error) {
 	var v []byte
 	// db.View相当于打开了一个读写事务:db.NewTransaction(true)。用db.Update的好处在于不用显式调用Txn.Discard()
 	err := b.db.View(func(txn *badger.Txn) error {
 		item, err := txn.Get(k)
+		if errors.Is(err, badger.ErrKeyNotFound) {
+			// 与 Bolt 保持一致，key 不存在时返回 NoDataError
+			return NoDataError
+		}
 		if err != nil {
 			return err
 		}
@@ -96,27 +136,28 @@ func (b *Badger) BatchGet(keys [][]byte) ([][]byte, error) {
 	txn := b.db.NewTransaction(false) //只读事务
 	values := make([][]byte, len(keys))
 	for i, key := range keys {
-		var item *badger.Item
-		item, err = txn.Get(key)
-		if err == nil {
+		item, e := txn.Get(key)
+		if e == nil {
 			// buffer := make([]byte, badgerOptions.ValueLogMaxEntries)
 			var v []byte
 			// v, err = item.ValueCopy(buffer)
-			err = item.Value(func(val []byte) error {
+			e = item.Value(func(val []byte) error {
 				v = val
 				return nil
 			})
-			if err == nil {
+			if e == nil {
 				values[i] = v
 			} else {
 				// 拷贝失败，把value设为空数组
 				values[i] = []byte{}
+				err = e
 			}
 		} else {
 			// 读取失败，把value设为空数组
 			values[i] = []byte{}
-			// 如果真的发生异常，则开一个新事务继续读后面的key
-			if !errors.Is(err, badger.ErrKeyNotFound) {
+			// key不存在不算错误。如果真的发生异常，则记下错误并开一个新事务继续读后面的key
+			if !errors.Is(e, badger.ErrKeyNotFound) {
+				err = e
 				txn.Discard()
 				txn = b.db.NewTransaction(false)
 			}
@@ -169,62 +210,271 @@ func (b *Badger) Has(k []byte) bool {
 	return exists
 }
 
-// IterDB 遍历数据库，返回数据的条数
+// IterDB 遍历数据库，返回数据的条数。读取value失败或fn返回错误的数据会被跳过，不计入条数
 func (b *Badger) IterDB(fn func(k, v []byte) error) (int64, error) {
 	var count int64
 	err := b.db.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		it := txn.NewIterator(opts)
-		defer it.Close()
-		for it.Rewind(); it.Valid(); it.Next() {
-			item := it.Item()
-			key := item.Key()
+		var err error
+		count, err = iterBadgerRange(txn, nil, nil, true, true, fn)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// IterKey 只遍历key。key是全部存在LSM tree上的，只需要读内存，所以很快
+func (b *Badger) IterKey(fn func(k []byte) error) (int64, error) {
+	var total int64
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		// 只需要读key，所以不读取value
+		total, err = iterBadgerRange(txn, nil, nil, false, true, func(k, v []byte) error {
+			return fn(k)
+		})
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, nil
+}
 
-			var v []byte
-			// var err error
-			// buffer := make([]byte, badgerOptions.ValueLogMaxEntries)
-			// v, err = item.ValueCopy(buffer)
+// IterRange 在一个只读事务中遍历[start, end)内的数据，返回数据的条数
+func (b *Badger) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	var count int64
+	err := b.db.View(func(txn *badger.Txn) error {
+		var err error
+		count, err = iterBadgerRange(txn, start, end, true, false, fn)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (b *Badger) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return b.IterRange(prefix, prefixEnd(prefix), fn)
+}
+
+// Snapshot 开启一个只读事务作为快照，Close时丢弃事务
+func (b *Badger) Snapshot() (Snapshot, error) {
+	return &badgerSnapshot{txn: b.db.NewTransaction(false)}, nil
+}
+
+// Update 在一个读写事务中执行fn，fn返回nil时提交事务。与其它事务写入了相同的key时提交失败，返回badger.ErrConflict
+func (b *Badger) Update(fn func(tx KVTxn) error) error {
+	return b.db.Update(func(txn *badger.Txn) error {
+		return fn(&badgerTxn{txn: txn, writable: true})
+	})
+}
+
+// View 在一个只读事务中执行fn
+func (b *Badger) View(fn func(tx KVTxn) error) error {
+	return b.db.View(func(txn *badger.Txn) error {
+		return fn(&badgerTxn{txn: txn})
+	})
+}
+
+// badgerTxn Badger事务中的读写操作，实现ExpiringKVTxn
+type badgerTxn struct {
+	txn      *badger.Txn
+	writable bool
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (t *badgerTxn) Get(k []byte) ([]byte, error) {
+	item, err := t.txn.Get(k)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, NoDataError
+	}
+	if err != nil {
+		return nil, err
+	}
+	return item.ValueCopy(nil)
+}
+
+// Has 判断某个key是否存在
+func (t *badgerTxn) Has(k []byte) bool {
+	_, err := t.txn.Get(k)
+	return err == nil
+}
+
+// Set 写入<key, value>
+func (t *badgerTxn) Set(k, v []byte) error {
+	return t.SetWithExpiry(k, v, time.Time{})
+}
+
+// SetWithExpiry 写入<key, value>，到达expireAt之后key自动过期，expireAt为零值表示永不过期
+func (t *badgerTxn) SetWithExpiry(k, v []byte, expireAt time.Time) error {
+	if !t.writable {
+		return ErrReadOnlyTxn
+	}
+	return t.txn.SetEntry(newEntry(k, v, expireAt))
+}
+
+// Delete 删除
+func (t *badgerTxn) Delete(k []byte) error {
+	if !t.writable {
+		return ErrReadOnlyTxn
+	}
+	return t.txn.Delete(k)
+}
+
+// iterBadgerRange 在txn中从start开始遍历，直到end或fn返回ErrStopIteration。
+// withValue为false时不读取value，传给fn的value为nil；skipErrors为true时跳过读取value失败和fn返回错误的数据，否则返回错误
+func iterBadgerRange(txn *badger.Txn, start, end []byte, withValue, skipErrors bool, fn func(k, v []byte) error) (int64, error) {
+	var count int64
+	opts := badger.DefaultIteratorOptions
+	opts.PrefetchValues = withValue
+	it := txn.NewIterator(opts)
+	defer it.Close()
+	if start != nil {
+		it.Seek(start)
+	} else {
+		it.Rewind()
+	}
+	for ; it.Valid(); it.Next() {
+		item := it.Item()
+		key := item.Key()
+		if !beforeEnd(key, end) {
+			break
+		}
+		var v []byte
+		if withValue {
 			err := item.Value(func(val []byte) error {
 				v = val
 				return nil
 			})
 			if err != nil {
-				continue
+				if skipErrors {
+					continue
+				}
+				return 0, err
 			}
-			if err := fn(key, v); err == nil {
-				atomic.AddInt64(&count, 1)
+		}
+		err := fn(key, v)
+		if errors.Is(err, ErrStopIteration) {
+			break
+		}
+		if err != nil {
+			if skipErrors {
+				continue
 			}
+			return 0, err
 		}
-		return nil
-	})
+		count++
+	}
+	return count, nil
+}
+
+// badgerSnapshot Badger的快照，即一个只读事务
+type badgerSnapshot struct {
+	txn *badger.Txn
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (s *badgerSnapshot) Get(k []byte) ([]byte, error) {
+	item, err := s.txn.Get(k)
+	if errors.Is(err, badger.ErrKeyNotFound) {
+		return nil, NoDataError
+	}
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return atomic.LoadInt64(&count), nil
+	return item.ValueCopy(nil)
 }
 
-// IterKey 只遍历key。key是全部存在LSM tree上的，只需要读内存，所以很快
-func (b *Badger) IterKey(fn func(k []byte) error) (int64, error) {
-	var total int64
-	err := b.db.View(func(txn *badger.Txn) error {
-		opts := badger.DefaultIteratorOptions
-		// 只需要读key，所以把PrefetchValues设为false
-		opts.PrefetchValues = false
-		it := txn.NewIterator(opts)
-		defer it.Close()
-		for it.Rewind(); it.Valid(); it.Next() {
-			item := it.Item()
-			k := item.Key()
-			if err := fn(k); err == nil {
-				atomic.AddInt64(&total, 1)
-			}
-		}
-		return nil
-	})
+// Has 判断某个key是否存在
+func (s *badgerSnapshot) Has(k []byte) bool {
+	_, err := s.txn.Get(k)
+	return err == nil
+}
+
+// IterDB 遍历快照中的全部数据，返回数据的条数
+func (s *badgerSnapshot) IterDB(fn func(k, v []byte) error) (int64, error) {
+	return iterBadgerRange(s.txn, nil, nil, true, false, fn)
+}
+
+// IterRange 遍历[start, end)内的数据，返回数据的条数
+func (s *badgerSnapshot) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterBadgerRange(s.txn, start, end, true, false, fn)
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (s *badgerSnapshot) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterBadgerRange(s.txn, prefix, prefixEnd(prefix), true, false, fn)
+}
+
+// Close 丢弃只读事务
+func (s *badgerSnapshot) Close() error {
+	s.txn.Discard()
+	return nil
+}
+
+// Backup 全量备份，备份的是调用时刻的快照，备份期间可以继续读写
+func (b *Badger) Backup(w io.Writer) error {
+	_, err := b.db.Backup(w, 0)
+	return err
+}
+
+// restoreMaxPendingWrites 恢复时最多同时有多少个未完成的写入
+const restoreMaxPendingWrites = 256
+
+// Restore 先把备份加载到临时目录中的新数据库，加载成功说明备份完整，再关闭数据库、用临时目录替换数据目录后重新打开。
+// 备份损坏时原有的数据不受影响。
+func (b *Badger) Restore(r io.Reader) error {
+	tmpPath := b.path + ".restore"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+	tmp := new(Badger).WithDataPath(tmpPath)
+	if err := tmp.Open(); err != nil {
+		return err
+	}
+	err := loadBadgerBackup(tmp.db, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
-		return 0, err
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("加载备份失败: %w", err)
+	}
+
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+	oldPath := b.path + ".old"
+	if err := os.RemoveAll(oldPath); err != nil {
+		return errors.Join(err, b.Open())
+	}
+	if err := os.Rename(b.path, oldPath); err != nil {
+		// 替换失败时重新打开原来的数据库
+		os.RemoveAll(tmpPath)
+		return errors.Join(err, b.Open())
+	}
+	if err := os.Rename(tmpPath, b.path); err != nil {
+		os.Rename(oldPath, b.path)
+		os.RemoveAll(tmpPath)
+		return errors.Join(err, b.Open())
 	}
-	return atomic.LoadInt64(&total), nil
+	if err := b.Open(); err != nil {
+		return err
+	}
+	return os.RemoveAll(oldPath)
+}
+
+// loadBadgerBackup 把备份加载到db中。badger 读到不合法的数据时可能 panic，这里转为错误返回
+func loadBadgerBackup(db *badger.DB, r io.Reader) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("备份数据不合法: %v", p)
+		}
+	}()
+	return db.Load(r, restoreMaxPendingWrites)
 }
 
 // Close 关闭数据库，把内存中的数据flush到磁盘，同时释放文件锁
@@ -238,17 +488,40 @@ func (b *Badger) WithDataPath(path string) *Badger {
 	return b
 }
 
+// CheckAndGC 回收value log中可回收比例达到一半的文件
 func (b *Badger) CheckAndGC() {
-	lsmSize1, vlogSize1 := b.db.Size()
+	result, err := b.Compact(0.5)
+	if err != nil {
+		utils.Log.Printf("badger GC failed: %v", err)
+	} else if result.AfterBytes < result.BeforeBytes {
+		utils.Log.Printf("badger before GC, %d bytes. after GC, %d bytes", result.BeforeBytes, result.AfterBytes)
+	} else {
+		utils.Log.Printf("collect zero garbage")
+	}
+}
+
+// Compact 对value log做垃圾回收：反复重写可回收比例（已删除、已覆盖、已过期的数据）达到threshold的value log文件，
+// 直到没有这样的文件为止。回收期间可以继续读写
+func (b *Badger) Compact(threshold float64) (CompactResult, error) {
+	before, err := DiskUsage(b.path)
+	if err != nil {
+		return CompactResult{}, err
+	}
+	result := CompactResult{BeforeBytes: before, AfterBytes: before}
 	for {
-		if err := b.db.RunValueLogGC(0.5); err == badger.ErrNoRewrite || err == badger.ErrRejected {
+		err := b.db.RunValueLogGC(threshold)
+		if errors.Is(err, badger.ErrNoRewrite) || errors.Is(err, badger.ErrRejected) {
 			break
 		}
+		if err != nil {
+			return result, err
+		}
+		result.Compacted = true
 	}
-	lsmSize2, vlogSize2 := b.db.Size()
-	if vlogSize2 < vlogSize1 {
-		utils.Log.Printf("badger before GC, LSM %d, vlog %d. after GC, LSM %d, vlog %d", lsmSize1, vlogSize1, lsmSize2, vlogSize2)
-	} else {
-		utils.Log.Printf("collect zero garbage")
+	if result.Compacted {
+		if result.AfterBytes, err = DiskUsage(b.path); err != nil {
+			return result, err
+		}
 	}
+	return result, nil
 }
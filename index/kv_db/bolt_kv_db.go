@@ -2,25 +2,35 @@ package kv_db
 
 import (
 	"errors"
+	"fmt"
 	bolt "go.etcd.io/bbolt"
-	"sync/atomic"
+	"io"
+	"os"
+	"sync"
 )
 
 var NoDataError = errors.New("no data found")
 
+// boltInitialMmapSize Bolt初始映射的大小，数据文件超过该大小之前持有快照不会阻塞写入
+const boltInitialMmapSize = 1 << 30
+
 // Bolt 存储结构
 type Bolt struct {
-	db     *bolt.DB // 数据库实例
-	path   string   // 本地存储目录
-	bucket []byte   // 表的名称
+	mu     sync.RWMutex // 读写数据时加读锁，替换数据库文件（Compact、Restore）和关闭时加写锁。遍历、事务的fn中不能再读写数据库，否则与等待中的Compact死锁
+	db     *bolt.DB     // 数据库实例
+	path   string       // 本地存储目录
+	bucket []byte       // 表的名称
 }
 
 // Open 初始化数据库
 func (b *Bolt) Open() error {
 	// 获取数据库文件的路径
 	dataDir := b.GetDbPath()
-	// 打开 BoltDB 数据库文件
-	db, err := bolt.Open(dataDir, 0600, bolt.DefaultOptions)
+	// 打开 BoltDB 数据库文件。数据文件需要扩大时要重新mmap，这要等所有只读事务结束，
+	// 预先映射足够大的空间，持有快照期间的写入就不会因此阻塞
+	options := *bolt.DefaultOptions
+	options.InitialMmapSize = boltInitialMmapSize
+	db, err := bolt.Open(dataDir, 0600, &options)
 	if err != nil {
 		return err
 	}
@@ -46,6 +56,8 @@ func (b *Bolt) GetDbPath() string {
 
 // Set 写入<key, value>
 func (b *Bolt) Set(k, v []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	err := b.db.Update(func(tx *bolt.Tx) error {
 		return tx.Bucket(b.bucket).Put(k, v)
 	})
@@ -58,6 +70,8 @@ func (b *Bolt) BatchSet(keys, values [][]byte) error {
 	if len(keys) != len(values) {
 		return errors.New("keys and values do not match")
 	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	// 开启 BoltDB 的批处理事务
 	err := b.db.Batch(func(tx *bolt.Tx) error {
 		for i, key := range keys {
@@ -75,6 +89,8 @@ func (b *Bolt) BatchSet(keys, values [][]byte) error {
 
 // Get 读取key对应的value
 func (b *Bolt) Get(k []byte) ([]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	var v []byte
 	err := b.db.View(func(tx *bolt.Tx) error {
 		v = tx.Bucket(b.bucket).Get(k)
@@ -88,6 +104,8 @@ func (b *Bolt) Get(k []byte) ([]byte, error) {
 
 // BatchGet 批量读取，注意不保证顺序
 func (b *Bolt) BatchGet(keys [][]byte) ([][]byte, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	values := make([][]byte, len(keys))
 	err := b.db.Batch(func(tx *bolt.Tx) error {
 		for i, key := range keys {
@@ -100,6 +118,8 @@ func (b *Bolt) BatchGet(keys [][]byte) ([][]byte, error) {
 
 // Delete 删除
 func (b *Bolt) Delete(k []byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	err := b.db.Update(func(tx *bolt.Tx) error {
 		return tx.Bucket(b.bucket).Delete(k)
 	})
@@ -108,6 +128,8 @@ func (b *Bolt) Delete(k []byte) error {
 
 // BatchDelete 批量删除
 func (b *Bolt) BatchDelete(keys [][]byte) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	err := b.db.Batch(func(tx *bolt.Tx) error {
 		for _, key := range keys {
 			if err := tx.Bucket(b.bucket).Delete(key); err != nil {
@@ -121,6 +143,8 @@ func (b *Bolt) BatchDelete(keys [][]byte) error {
 
 // Has 判断某个key是否存在
 func (b *Bolt) Has(k []byte) bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	var v []byte
 	err := b.db.View(func(tx *bolt.Tx) error {
 		v = tx.Bucket(b.bucket).Get(k)
@@ -134,49 +158,284 @@ func (b *Bolt) Has(k []byte) bool {
 
 // IterDB 遍历数据库，返回数据的条数
 func (b *Bolt) IterDB(fn func(k []byte, v []byte) error) (int64, error) {
+	return b.IterRange(nil, nil, fn)
+}
+
+// IterKey 遍历所有key，返回数据的条数
+func (b *Bolt) IterKey(fn func(k []byte) error) (int64, error) {
+	return b.IterRange(nil, nil, func(k, v []byte) error {
+		return fn(k)
+	})
+}
+
+// IterRange 在一个只读事务中遍历[start, end)内的数据，返回数据的条数
+func (b *Bolt) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 	var count int64
 	err := b.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(b.bucket)
-		// 迭代器模式
-		c := b.Cursor()
-		for k, v := c.First(); k != nil; k, v = c.Next() {
-			if err := fn(k, v); err != nil {
-				return err
-			} else {
-				atomic.AddInt64(&count, 1)
-			}
-		}
-		return nil
+		var err error
+		count, err = iterBoltRange(tx.Bucket(b.bucket), start, end, fn)
+		return err
 	})
 	if err != nil {
 		return 0, err
 	}
-	return atomic.LoadInt64(&count), nil
+	return count, nil
 }
 
-// IterKey 遍历所有key，返回数据的条数
-func (b *Bolt) IterKey(fn func(k []byte) error) (int64, error) {
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (b *Bolt) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return b.IterRange(prefix, prefixEnd(prefix), fn)
+}
+
+// Snapshot 开启一个只读事务作为快照，Close时结束事务
+func (b *Bolt) Snapshot() (Snapshot, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	tx, err := b.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &boltSnapshot{tx: tx, bucket: tx.Bucket(b.bucket)}, nil
+}
+
+// Update 在一个读写事务中执行fn，fn返回nil时提交事务。Bolt同一时刻只有一个读写事务
+func (b *Bolt) Update(fn func(tx KVTxn) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return fn(&boltTxn{bucket: tx.Bucket(b.bucket), writable: true})
+	})
+}
+
+// View 在一个只读事务中执行fn
+func (b *Bolt) View(fn func(tx KVTxn) error) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.View(func(tx *bolt.Tx) error {
+		return fn(&boltTxn{bucket: tx.Bucket(b.bucket)})
+	})
+}
+
+// boltTxn Bolt事务中的读写操作
+type boltTxn struct {
+	bucket   *bolt.Bucket
+	writable bool
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (t *boltTxn) Get(k []byte) ([]byte, error) {
+	v := t.bucket.Get(k)
+	if len(v) == 0 {
+		return nil, NoDataError
+	}
+	return v, nil
+}
+
+// Has 判断某个key是否存在
+func (t *boltTxn) Has(k []byte) bool {
+	return len(t.bucket.Get(k)) > 0
+}
+
+// Set 写入<key, value>
+func (t *boltTxn) Set(k, v []byte) error {
+	if !t.writable {
+		return ErrReadOnlyTxn
+	}
+	return t.bucket.Put(k, v)
+}
+
+// Delete 删除
+func (t *boltTxn) Delete(k []byte) error {
+	if !t.writable {
+		return ErrReadOnlyTxn
+	}
+	return t.bucket.Delete(k)
+}
+
+// iterBoltRange 用游标从start开始遍历bucket，直到end或fn返回错误
+func iterBoltRange(bucket *bolt.Bucket, start, end []byte, fn func(k, v []byte) error) (int64, error) {
 	var count int64
-	err := b.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket(b.bucket)
-		c := b.Cursor()
-		for k, _ := c.First(); k != nil; k, _ = c.Next() {
-			if err := fn(k); err != nil {
-				return err
-			} else {
-				atomic.AddInt64(&count, 1)
-			}
+	// 迭代器模式
+	c := bucket.Cursor()
+	k, v := c.First()
+	if start != nil {
+		k, v = c.Seek(start)
+	}
+	for ; k != nil && beforeEnd(k, end); k, v = c.Next() {
+		if err := fn(k, v); errors.Is(err, ErrStopIteration) {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// boltSnapshot Bolt的快照，即一个只读事务。读到的value在Close之前有效
+type boltSnapshot struct {
+	tx     *bolt.Tx
+	bucket *bolt.Bucket
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (s *boltSnapshot) Get(k []byte) ([]byte, error) {
+	v := s.bucket.Get(k)
+	if len(v) == 0 {
+		return nil, NoDataError
+	}
+	return v, nil
+}
+
+// Has 判断某个key是否存在
+func (s *boltSnapshot) Has(k []byte) bool {
+	return len(s.bucket.Get(k)) > 0
+}
+
+// IterDB 遍历快照中的全部数据，返回数据的条数
+func (s *boltSnapshot) IterDB(fn func(k, v []byte) error) (int64, error) {
+	return iterBoltRange(s.bucket, nil, nil, fn)
+}
+
+// IterRange 遍历[start, end)内的数据，返回数据的条数
+func (s *boltSnapshot) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterBoltRange(s.bucket, start, end, fn)
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (s *boltSnapshot) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterBoltRange(s.bucket, prefix, prefixEnd(prefix), fn)
+}
+
+// Close 结束只读事务
+func (s *boltSnapshot) Close() error {
+	return s.tx.Rollback()
+}
+
+// Backup 在一个只读事务中把整个数据库文件写入w，得到的备份本身就是一个Bolt数据库文件
+func (b *Bolt) Backup(w io.Writer) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// Restore 先把备份写入临时文件并校验，再关闭数据库、用临时文件替换数据库文件后重新打开
+func (b *Bolt) Restore(r io.Reader) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	tmpPath := b.path + ".restore"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	// 校验备份是合法的Bolt文件，且包含本数据库使用的表
+	if err := checkBoltFile(tmpPath, b.bucket); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := b.db.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, b.path); err != nil {
+		// 替换失败时重新打开原来的数据库
+		os.Remove(tmpPath)
+		if openErr := b.Open(); openErr != nil {
+			return openErr
+		}
+		return err
+	}
+	return b.Open()
+}
+
+// checkBoltFile 检查path是合法的Bolt文件，且包含名为bucket的表
+func checkBoltFile(path string, bucket []byte) error {
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("备份不是合法的Bolt文件: %v", err)
+	}
+	defer db.Close()
+	return db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(bucket) == nil {
+			return fmt.Errorf("备份中没有表 %s", bucket)
 		}
 		return nil
 	})
+}
+
+const (
+	boltCompactTxMaxSize    = 64 << 20 // Compact 拷贝数据时每个写事务的最大字节数
+	boltCompactMinFreeBytes = 1 << 20  // 空闲页不足该值时不整理，避免很小的数据文件因为固有的几个空闲页反复整理
+)
+
+// Compact 空闲页占数据文件的比例达到threshold时，把数据拷贝到一个新文件中并替换原来的文件，新文件不包含空闲页。
+// 拷贝和替换期间持有写锁，所有读写都要等待，应在访问量低的时段执行。
+func (b *Bolt) Compact(threshold float64) (CompactResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	info, err := os.Stat(b.path)
 	if err != nil {
-		return 0, err
+		return CompactResult{}, err
+	}
+	result := CompactResult{BeforeBytes: info.Size(), AfterBytes: info.Size()}
+	free := b.db.Stats().FreeAlloc
+	if free < boltCompactMinFreeBytes || float64(free)/float64(info.Size()) < threshold {
+		return result, nil
+	}
+
+	tmpPath := b.path + ".compact"
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return result, err
+	}
+	dst, err := bolt.Open(tmpPath, 0600, bolt.DefaultOptions)
+	if err != nil {
+		return result, err
+	}
+	err = bolt.Compact(dst, b.db, boltCompactTxMaxSize)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return result, err
+	}
+
+	if err := b.db.Close(); err != nil {
+		os.Remove(tmpPath)
+		return result, err
+	}
+	if err := os.Rename(tmpPath, b.path); err != nil {
+		// 替换失败时重新打开原来的数据库
+		os.Remove(tmpPath)
+		return result, errors.Join(err, b.Open())
+	}
+	if err := b.Open(); err != nil {
+		return result, err
+	}
+	if info, err := os.Stat(b.path); err == nil {
+		result.AfterBytes = info.Size()
 	}
-	return atomic.LoadInt64(&count), nil
+	result.Compacted = true
+	return result, nil
 }
 
 // Close 关闭数据库，把内存中的数据flush到磁盘，同时释放文件锁
 func (b *Bolt) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
 	return b.db.Close()
 }
 
@@ -1,60 +1,112 @@
 package kv_db
 
 import (
+	"fmt"
 	"github.com/jmh000527/criker-search/utils"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 )
 
+// Factory 按数据存储路径创建一个尚未打开的 KeyValueDB，GetKvDB 负责调用 Open
+type Factory func(path string) KeyValueDB
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[string]Factory{
+		BOLT: func(path string) KeyValueDB {
+			return new(Bolt).WithDataPath(path).WithBucket("radic")
+		},
+		BADGER: func(path string) KeyValueDB {
+			return new(Badger).WithDataPath(path)
+		},
+		PEBBLE: func(path string) KeyValueDB {
+			return new(Pebble).WithDataPath(path)
+		},
+		MEMORY: func(path string) KeyValueDB {
+			return new(Memory).WithDataPath(path)
+		},
+	}
+)
+
+// Register 以 name 注册一种 KeyValueDB，之后可以通过 GetKvDB(name, path) 创建。重复注册同一个名称会覆盖之前的实现。
+//
+// 参数:
+//   - name: 数据库类型的名称。
+//   - factory: 创建数据库实例的函数。
+func Register(name string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[name] = factory
+}
+
+// Backends 返回已注册的所有数据库类型的名称，按字典序排列
+func Backends() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // GetKvDB 使用工厂模式创建并返回一个具体的 KeyValueDB 实例。
 // 根据指定的数据库类型（dbType）和路径（path），创建并初始化一个 KeyValueDB 的实现。
-// 该工厂函数将创建所需的目录结构，并根据 dbType 返回相应的数据库实例。
+// 该工厂函数将创建所需的目录结构（内存数据库除外），并根据 dbType 返回相应的数据库实例。
 //
 // 参数:
-//   - dbType: 数据库类型，表示要创建的数据库的具体类型。
+//   - dbType: 数据库类型的名称，例如 BOLT、BADGER、PEBBLE、MEMORY，或通过 Register 注册的名称。
 //   - path: 数据库文件路径，指定数据库的数据存储位置。
 //
 // 返回值:
-//   - _interface.KeyValueDB: 创建的 KeyValueDB 实例接口。
-//   - error: 如果在创建目录或打开数据库时发生错误，返回相应的错误。
-func GetKvDB(dbType int, path string) (KeyValueDB, error) {
+//   - KeyValueDB: 创建的 KeyValueDB 实例接口。
+//   - error: 如果数据库类型未注册，或在创建目录、打开数据库时发生错误，返回相应的错误。
+func GetKvDB(dbType string, path string) (KeyValueDB, error) {
+	factoriesMu.RLock()
+	factory, exists := factories[dbType]
+	factoriesMu.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("不支持的数据库类型: %s，已注册的类型: %s", dbType, strings.Join(Backends(), ", "))
+	}
+
+	if dbType != MEMORY {
+		if err := prepareParentDir(path); err != nil {
+			return nil, err
+		}
+	}
+
+	// 创建具体 KVDB 实例的细节被隐藏在 Open() 方法中
+	db := factory(path)
+	err := db.Open()
+	return db, err
+}
+
+// prepareParentDir 确保 path 的父目录存在，父路径是普通文件时删除它
+func prepareParentDir(path string) error {
 	// 分割路径并确定父目录路径
 	paths := strings.Split(path, "/")
 	parentPath := strings.Join(paths[0:len(paths)-1], "/") // 父路径
+	if len(parentPath) == 0 {
+		return nil
+	}
 	stat, err := os.Stat(parentPath)
 
 	// 如果父路径不存在，则创建它
 	if os.IsNotExist(err) {
 		utils.Log.Printf("create dir: %s", parentPath)
-		if err := os.MkdirAll(parentPath, os.ModePerm); err != nil {
-			return nil, err
-		}
-	} else {
-		// 如果父路径存在
-		// 如果父路径是普通文件，则删除它
-		if stat.Mode().IsRegular() {
-			utils.Log.Printf("%s is a regular file, will delete it", parentPath)
-			if err := os.Remove(parentPath); err != nil {
-				return nil, err
-			}
-		}
-		// 重新创建目录
-		if err := os.MkdirAll(parentPath, os.ModePerm); err != nil {
-			return nil, err
-		}
+		return os.MkdirAll(parentPath, os.ModePerm)
 	}
-
-	// 根据 dbType 创建相应的 KeyValueDB 实例
-	var db KeyValueDB
-	switch dbType {
-	case BADGER:
-		db = new(Badger).WithDataPath(path)
-	default:
-		// 默认使用 Bolt 数据库，并设置相应的桶
-		db = new(Bolt).WithDataPath(path).WithBucket("radic")
+	// 如果父路径存在
+	// 如果父路径是普通文件，则删除它
+	if stat.Mode().IsRegular() {
+		utils.Log.Printf("%s is a regular file, will delete it", parentPath)
+		if err := os.Remove(parentPath); err != nil {
+			return err
+		}
 	}
-
-	// 创建具体 KVDB 实例的细节被隐藏在 Open() 方法中
-	err = db.Open()
-	return db, err
+	// 重新创建目录
+	return os.MkdirAll(parentPath, os.ModePerm)
 }
@@ -1,9 +1,21 @@
 package kv_db
 
-// 几种常见的基于LSM-tree算法实现的KV数据库
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// 内置的几种KV数据库的名称，GetKvDB 按名称选择，其它数据库可以通过 Register 注册。
+// Bolt 基于B+tree，Badger 和 Pebble 基于LSM-tree，Memory 只保存在内存中。
 const (
-	BOLT = iota
-	BADGER
+	BOLT   = "bolt"
+	BADGER = "badger"
+	PEBBLE = "pebble"
+	MEMORY = "memory"
 )
 
 // KeyValueDB k-v数据库接口
@@ -19,5 +31,101 @@ type KeyValueDB interface {
 	Has(k []byte) bool                                // 判断某个key是否存在
 	IterDB(fn func(k, v []byte) error) (int64, error) // 遍历数据库，返回数据的条数
 	IterKey(fn func(k []byte) error) (int64, error)   // 遍历所有key，返回数据的条数
+	Backup(w io.Writer) error                         // 在线备份，把数据库的一致快照写入w，备份期间可以继续读写
+	Restore(r io.Reader) error                        // 用Backup产生的备份替换数据库中的全部数据，调用方需保证恢复期间没有其他读写
 	Close() error                                     // 把内存中的数据flush到磁盘，同时释放文件锁
+	KeyRangeReader
+	Snapshot() (Snapshot, error)          // 获取数据库当前状态的只读视图，用完后必须调用Snapshot.Close
+	Update(fn func(tx KVTxn) error) error // 在一个读写事务中执行fn，fn返回nil时提交事务，否则丢弃事务中的全部写入
+	View(fn func(tx KVTxn) error) error   // 在一个只读事务中执行fn，事务中的写入返回ErrReadOnlyTxn
+}
+
+// KVTxn 事务中的读写操作，只能在Update或View的fn中使用。事务中的读取能看到本事务之前的写入，
+// 提交之前本事务的写入对其他读取不可见。读到的value在事务结束之前有效
+type KVTxn interface {
+	Get(k []byte) ([]byte, error) // 读取key对应的value，key不存在时返回NoDataError
+	Has(k []byte) bool            // 判断某个key是否存在
+	Set(k, v []byte) error        // 写入<key, value>
+	Delete(k []byte) error        // 删除
+}
+
+// ExpiringKVTxn 支持为key设置过期时间的事务（例如Badger的事务）
+type ExpiringKVTxn interface {
+	KVTxn
+	SetWithExpiry(k, v []byte, expireAt time.Time) error // 写入<key, value>，expireAt为零值表示永不过期
+}
+
+// ErrReadOnlyTxn 在View开启的只读事务中写入
+var ErrReadOnlyTxn = errors.New("read-only transaction")
+
+// KeyRangeReader 按key的字典序遍历一段数据。fn返回ErrStopIteration时停止遍历且不返回错误
+type KeyRangeReader interface {
+	IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) // 遍历[start, end)内的数据，start为nil表示从头开始，end为nil表示到最后，返回数据的条数
+	IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error)    // 遍历以prefix开头的数据，返回数据的条数
+}
+
+// Snapshot KV数据库在某一时刻的只读视图，不受之后的写入影响。
+// 持有快照期间数据库无法回收旧版本的数据，Bolt的数据文件超过1GB之后还会阻塞需要扩大文件的写入
+// （在持有快照的协程中写入会死锁），用完后应尽快Close。
+type Snapshot interface {
+	Get(k []byte) ([]byte, error)                     // 读取key对应的value，key不存在时返回NoDataError
+	Has(k []byte) bool                                // 判断某个key是否存在
+	IterDB(fn func(k, v []byte) error) (int64, error) // 遍历快照中的全部数据，返回数据的条数
+	KeyRangeReader
+	Close() error // 释放快照
+}
+
+// Compactor 能回收已删除、已覆盖的数据所占磁盘空间的KV数据库。Badger对value log做垃圾回收，Bolt把数据拷贝到新文件中
+type Compactor interface {
+	// Compact 可回收空间的比例（0到1之间）达到threshold时回收磁盘空间，没有达到时什么都不做
+	Compact(threshold float64) (CompactResult, error)
+}
+
+// CompactResult 一次Compact的结果
+type CompactResult struct {
+	BeforeBytes int64 // 回收之前占用的磁盘空间
+	AfterBytes  int64 // 回收之后占用的磁盘空间
+	Compacted   bool  // 是否达到阈值并执行了回收
+}
+
+// DiskUsage 统计path占用的磁盘空间。path可以是文件（Bolt）或目录（Badger、Pebble）
+func DiskUsage(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// ErrStopIteration 遍历函数返回该错误表示提前结束遍历，IterDB、IterKey、IterRange、IterPrefix 此时返回已遍历的条数和nil
+var ErrStopIteration = errors.New("stop iteration")
+
+// prefixEnd 返回比所有以prefix开头的key都大的最小key，作为遍历prefix时的结束位置。prefix为空或全是0xff时返回nil，表示遍历到最后
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte{}, prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+// beforeEnd 判断key是否在遍历的结束位置之前，end为nil表示没有结束位置
+func beforeEnd(k, end []byte) bool {
+	return end == nil || bytes.Compare(k, end) < 0
+}
+
+// ExpiringKeyValueDB 支持为key设置过期时间的KV数据库（例如Badger），过期的key由数据库自动删除，读取时也不会再返回
+type ExpiringKeyValueDB interface {
+	KeyValueDB
+	SetWithExpiry(k, v []byte, expireAt time.Time) error                   // 写入<key, value>，expireAt为零值表示永不过期
+	BatchSetWithExpiry(keys, values [][]byte, expireAts []time.Time) error // 批量写入，expireAts与keys一一对应
 }
@@ -0,0 +1,100 @@
+package kv_db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// kvStreamMagic 备份流的文件头，Memory 和 Pebble 的 Backup 产生这种格式
+var kvStreamMagic = []byte("CRIKER-KV-1\n")
+
+// kvStreamWriter 把<key, value>逐条写入备份流。
+// 格式为文件头之后依次写入每条记录（key的长度、key、value的长度、value，长度用uvarint编码），最后以长度为0的key结尾。
+type kvStreamWriter struct {
+	w   *bufio.Writer
+	buf [binary.MaxVarintLen64]byte
+}
+
+// newKVStreamWriter 创建备份流并写入文件头
+func newKVStreamWriter(w io.Writer) (*kvStreamWriter, error) {
+	writer := &kvStreamWriter{w: bufio.NewWriter(w)}
+	if _, err := writer.w.Write(kvStreamMagic); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+// Write 写入一条记录，key不能为空
+func (writer *kvStreamWriter) Write(k, v []byte) error {
+	if len(k) == 0 {
+		return errors.New("key不能为空")
+	}
+	if err := writer.writeBytes(k); err != nil {
+		return err
+	}
+	return writer.writeBytes(v)
+}
+
+// Close 写入结尾标记并flush，不关闭底层的io.Writer
+func (writer *kvStreamWriter) Close() error {
+	if err := writer.writeBytes(nil); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+func (writer *kvStreamWriter) writeBytes(b []byte) error {
+	n := binary.PutUvarint(writer.buf[:], uint64(len(b)))
+	if _, err := writer.w.Write(writer.buf[:n]); err != nil {
+		return err
+	}
+	_, err := writer.w.Write(b)
+	return err
+}
+
+// readKVStream 读取kvStreamWriter产生的备份流，对每条记录调用fn。
+// 文件头不对、数据被截断（没有读到结尾标记）时返回错误，调用方应丢弃已经读到的数据。
+func readKVStream(r io.Reader, fn func(k, v []byte) error) error {
+	reader := bufio.NewReader(r)
+	magic := make([]byte, len(kvStreamMagic))
+	if _, err := io.ReadFull(reader, magic); err != nil || !bytes.Equal(magic, kvStreamMagic) {
+		return errors.New("备份数据不合法: 文件头不匹配")
+	}
+	for {
+		k, err := readKVStreamBytes(reader)
+		if err != nil {
+			return err
+		}
+		if len(k) == 0 {
+			return nil
+		}
+		v, err := readKVStreamBytes(reader)
+		if err != nil {
+			return err
+		}
+		if err := fn(k, v); err != nil {
+			return err
+		}
+	}
+}
+
+// kvStreamMaxLen 备份流中单个key或value的最大长度，防止损坏的长度字段导致分配过多内存
+const kvStreamMaxLen = 1 << 30
+
+func readKVStreamBytes(reader *bufio.Reader) ([]byte, error) {
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, errors.Join(errors.New("备份数据被截断"), err)
+	}
+	if length > kvStreamMaxLen {
+		return nil, errors.New("备份数据不合法: 长度超出范围")
+	}
+	b := make([]byte, length)
+	if _, err := io.ReadFull(reader, b); err != nil {
+		return nil, errors.Join(errors.New("备份数据被截断"), err)
+	}
+	return b, nil
+}
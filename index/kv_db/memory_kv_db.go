@@ -0,0 +1,320 @@
+package kv_db
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Memory 只保存在内存中的KV数据库，进程退出后数据丢失，适合测试和不需要持久化的临时索引。
+// 读取返回的value是内部数据的拷贝，调用方可以随意修改。
+type Memory struct {
+	mu   sync.RWMutex
+	data map[string][]byte
+	path string // 只用于 GetDbPath，不会在磁盘上创建文件
+}
+
+// Open 初始化数据库，已打开的数据库再次调用会清空数据
+func (m *Memory) Open() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string][]byte)
+	return nil
+}
+
+// GetDbPath 获取数据库的路径
+func (m *Memory) GetDbPath() string {
+	return m.path
+}
+
+// Set 写入<key, value>
+func (m *Memory) Set(k, v []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[string(k)] = copyBytes(v)
+	return nil
+}
+
+// BatchSet 批量写入<key, value>
+func (m *Memory) BatchSet(keys, values [][]byte) error {
+	if len(keys) != len(values) {
+		return errors.New("keys and values do not match")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, key := range keys {
+		m.data[string(key)] = copyBytes(values[i])
+	}
+	return nil
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (m *Memory) Get(k []byte) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, exists := m.data[string(k)]
+	if !exists || len(v) == 0 {
+		return nil, NoDataError
+	}
+	return copyBytes(v), nil
+}
+
+// BatchGet 批量读取，返回的values与传入的keys顺序保持一致。如果key不存在则对应的value为nil
+func (m *Memory) BatchGet(keys [][]byte) ([][]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		if v, exists := m.data[string(key)]; exists {
+			values[i] = copyBytes(v)
+		}
+	}
+	return values, nil
+}
+
+// Delete 删除
+func (m *Memory) Delete(k []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, string(k))
+	return nil
+}
+
+// BatchDelete 批量删除
+func (m *Memory) BatchDelete(keys [][]byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, key := range keys {
+		delete(m.data, string(key))
+	}
+	return nil
+}
+
+// Has 判断某个key是否存在
+func (m *Memory) Has(k []byte) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	v, exists := m.data[string(k)]
+	return exists && len(v) > 0
+}
+
+// IterDB 按key的字典序遍历数据库，返回数据的条数。遍历的是调用时刻的快照，fn 中可以读写数据库
+func (m *Memory) IterDB(fn func(k, v []byte) error) (int64, error) {
+	return m.IterRange(nil, nil, fn)
+}
+
+// IterKey 按字典序遍历所有key，返回数据的条数
+func (m *Memory) IterKey(fn func(k []byte) error) (int64, error) {
+	return m.IterDB(func(k, v []byte) error {
+		return fn(k)
+	})
+}
+
+// IterRange 遍历[start, end)内的数据，返回数据的条数。遍历的是调用时刻的快照
+func (m *Memory) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	return m.sortedRange(start, end).IterDB(fn)
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (m *Memory) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return m.IterRange(prefix, prefixEnd(prefix), fn)
+}
+
+// Snapshot 拷贝全部数据作为快照
+func (m *Memory) Snapshot() (Snapshot, error) {
+	return m.sortedRange(nil, nil), nil
+}
+
+// Update 在一个读写事务中执行fn，fn返回nil时把事务中的写入应用到数据库。读写事务之间互斥
+func (m *Memory) Update(fn func(tx KVTxn) error) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tx := &memoryTxn{data: m.data, writes: make(map[string][]byte)}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	for k, v := range tx.writes {
+		if v == nil {
+			delete(m.data, k)
+		} else {
+			m.data[k] = v
+		}
+	}
+	return nil
+}
+
+// View 在一个只读事务中执行fn，期间其它写入等待
+func (m *Memory) View(fn func(tx KVTxn) error) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(&memoryTxn{data: m.data})
+}
+
+// memoryTxn Memory事务中的读写操作，writes为nil表示只读。提交之前写入暂存在writes中，value为nil表示删除
+type memoryTxn struct {
+	data   map[string][]byte
+	writes map[string][]byte
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (t *memoryTxn) Get(k []byte) ([]byte, error) {
+	v, exists := t.writes[string(k)]
+	if !exists {
+		v = t.data[string(k)]
+	}
+	if len(v) == 0 {
+		return nil, NoDataError
+	}
+	return copyBytes(v), nil
+}
+
+// Has 判断某个key是否存在
+func (t *memoryTxn) Has(k []byte) bool {
+	_, err := t.Get(k)
+	return err == nil
+}
+
+// Set 写入<key, value>
+func (t *memoryTxn) Set(k, v []byte) error {
+	if t.writes == nil {
+		return ErrReadOnlyTxn
+	}
+	t.writes[string(k)] = append([]byte{}, v...)
+	return nil
+}
+
+// Delete 删除
+func (t *memoryTxn) Delete(k []byte) error {
+	if t.writes == nil {
+		return ErrReadOnlyTxn
+	}
+	t.writes[string(k)] = nil
+	return nil
+}
+
+// sortedRange 返回[start, end)内的数据按key排序后的拷贝
+func (m *Memory) sortedRange(start, end []byte) *memorySnapshot {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	names := make([]string, 0, len(m.data))
+	for key := range m.data {
+		if (start == nil || key >= string(start)) && beforeEnd([]byte(key), end) {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	snapshot := &memorySnapshot{keys: make([][]byte, len(names)), values: make([][]byte, len(names))}
+	for i, name := range names {
+		snapshot.keys[i] = []byte(name)
+		snapshot.values[i] = copyBytes(m.data[name])
+	}
+	return snapshot
+}
+
+// memorySnapshot Memory的快照，按key排序的数据拷贝
+type memorySnapshot struct {
+	keys   [][]byte
+	values [][]byte
+}
+
+// search 返回第一个不小于k的key的下标
+func (s *memorySnapshot) search(k []byte) int {
+	return sort.Search(len(s.keys), func(i int) bool {
+		return bytes.Compare(s.keys[i], k) >= 0
+	})
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (s *memorySnapshot) Get(k []byte) ([]byte, error) {
+	if i := s.search(k); i < len(s.keys) && bytes.Equal(s.keys[i], k) && len(s.values[i]) > 0 {
+		return s.values[i], nil
+	}
+	return nil, NoDataError
+}
+
+// Has 判断某个key是否存在
+func (s *memorySnapshot) Has(k []byte) bool {
+	_, err := s.Get(k)
+	return err == nil
+}
+
+// IterDB 遍历快照中的全部数据，返回数据的条数
+func (s *memorySnapshot) IterDB(fn func(k, v []byte) error) (int64, error) {
+	return s.IterRange(nil, nil, fn)
+}
+
+// IterRange 遍历[start, end)内的数据，返回数据的条数
+func (s *memorySnapshot) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	var count int64
+	for i := s.search(start); i < len(s.keys) && beforeEnd(s.keys[i], end); i++ {
+		if err := fn(s.keys[i], s.values[i]); errors.Is(err, ErrStopIteration) {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (s *memorySnapshot) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return s.IterRange(prefix, prefixEnd(prefix), fn)
+}
+
+// Close 释放快照
+func (s *memorySnapshot) Close() error {
+	s.keys, s.values = nil, nil
+	return nil
+}
+
+// Backup 把调用时刻的全部数据写入w
+func (m *Memory) Backup(w io.Writer) error {
+	writer, err := newKVStreamWriter(w)
+	if err != nil {
+		return err
+	}
+	if _, err := m.IterDB(writer.Write); err != nil {
+		return err
+	}
+	return writer.Close()
+}
+
+// Restore 先完整读取备份，读取成功后再替换全部数据，备份损坏时原有的数据不受影响
+func (m *Memory) Restore(r io.Reader) error {
+	data := make(map[string][]byte)
+	if err := readKVStream(r, func(k, v []byte) error {
+		data[string(k)] = v
+		return nil
+	}); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = data
+	return nil
+}
+
+// Close 释放内存中的数据
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = nil
+	return nil
+}
+
+// WithDataPath 方法设置 Memory 结构的路径，只用于标识数据库。
+func (m *Memory) WithDataPath(path string) *Memory {
+	m.path = path
+	return m
+}
+
+// copyBytes 拷贝b，b为nil时返回nil
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append(make([]byte, 0, len(b)), b...)
+}
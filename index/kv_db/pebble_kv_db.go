@@ -0,0 +1,348 @@
+package kv_db
+
+import (
+	"errors"
+	"fmt"
+	"github.com/cockroachdb/pebble"
+	"io"
+	"os"
+	"path"
+)
+
+// Pebble 基于LSM-tree的KV数据库（CockroachDB使用的存储引擎），与Badger不同，value与key存放在一起
+type Pebble struct {
+	db   *pebble.DB
+	path string
+}
+
+// Open 初始化数据库
+func (p *Pebble) Open() error {
+	if err := os.MkdirAll(path.Dir(p.path), os.ModePerm); err != nil {
+		return err
+	}
+	db, err := pebble.Open(p.path, &pebble.Options{})
+	if err != nil {
+		return err
+	}
+	p.db = db
+	return nil
+}
+
+// GetDbPath 获取数据库文件的路径
+func (p *Pebble) GetDbPath() string {
+	return p.path
+}
+
+// Set 写入<key, value>
+func (p *Pebble) Set(k, v []byte) error {
+	return p.db.Set(k, v, pebble.Sync)
+}
+
+// BatchSet 批量写入<key, value>，多个写操作在一个batch中原子地提交
+func (p *Pebble) BatchSet(keys, values [][]byte) error {
+	if len(keys) != len(values) {
+		return errors.New("keys and values do not match")
+	}
+	batch := p.db.NewBatch()
+	defer batch.Close()
+	for i, key := range keys {
+		if err := batch.Set(key, values[i], nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (p *Pebble) Get(k []byte) ([]byte, error) {
+	return getPebble(p.db, k)
+}
+
+// BatchGet 批量读取，返回的values与传入的keys顺序保持一致。如果key不存在则对应的value为nil
+func (p *Pebble) BatchGet(keys [][]byte) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for i, key := range keys {
+		v, err := p.Get(key)
+		if err != nil && !errors.Is(err, NoDataError) {
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Delete 删除
+func (p *Pebble) Delete(k []byte) error {
+	return p.db.Delete(k, pebble.Sync)
+}
+
+// BatchDelete 批量删除
+func (p *Pebble) BatchDelete(keys [][]byte) error {
+	batch := p.db.NewBatch()
+	defer batch.Close()
+	for _, key := range keys {
+		if err := batch.Delete(key, nil); err != nil {
+			return err
+		}
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// Has 判断某个key是否存在
+func (p *Pebble) Has(k []byte) bool {
+	v, err := p.Get(k)
+	return err == nil && len(v) > 0
+}
+
+// IterDB 遍历数据库，返回数据的条数
+func (p *Pebble) IterDB(fn func(k, v []byte) error) (int64, error) {
+	return iterPebbleRange(p.db, nil, nil, fn)
+}
+
+// IterKey 遍历所有key，返回数据的条数
+func (p *Pebble) IterKey(fn func(k []byte) error) (int64, error) {
+	return p.IterDB(func(k, v []byte) error {
+		return fn(k)
+	})
+}
+
+// IterRange 遍历[start, end)内的数据，返回数据的条数
+func (p *Pebble) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterPebbleRange(p.db, start, end, fn)
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (p *Pebble) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterPebbleRange(p.db, prefix, prefixEnd(prefix), fn)
+}
+
+// Snapshot 获取Pebble的快照
+func (p *Pebble) Snapshot() (Snapshot, error) {
+	return &pebbleSnapshot{snapshot: p.db.NewSnapshot()}, nil
+}
+
+// Update 在一个batch中执行fn，fn返回nil时原子地提交batch。
+// Pebble没有冲突检测，事务中读到的是最新提交的数据加上本事务的写入，调用方需自行串行化对同一个key的读-改-写
+func (p *Pebble) Update(fn func(tx KVTxn) error) error {
+	batch := p.db.NewIndexedBatch()
+	defer batch.Close()
+	if err := fn(&pebbleTxn{reader: batch, batch: batch}); err != nil {
+		return err
+	}
+	return batch.Commit(pebble.Sync)
+}
+
+// View 在一个快照上执行fn
+func (p *Pebble) View(fn func(tx KVTxn) error) error {
+	snapshot := p.db.NewSnapshot()
+	defer snapshot.Close()
+	return fn(&pebbleTxn{reader: snapshot})
+}
+
+// pebbleTxn Pebble事务中的读写操作，batch为nil表示只读
+type pebbleTxn struct {
+	reader pebbleReader
+	batch  *pebble.Batch
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (t *pebbleTxn) Get(k []byte) ([]byte, error) {
+	return getPebble(t.reader, k)
+}
+
+// Has 判断某个key是否存在
+func (t *pebbleTxn) Has(k []byte) bool {
+	v, err := t.Get(k)
+	return err == nil && len(v) > 0
+}
+
+// Set 写入<key, value>
+func (t *pebbleTxn) Set(k, v []byte) error {
+	if t.batch == nil {
+		return ErrReadOnlyTxn
+	}
+	return t.batch.Set(k, v, nil)
+}
+
+// Delete 删除
+func (t *pebbleTxn) Delete(k []byte) error {
+	if t.batch == nil {
+		return ErrReadOnlyTxn
+	}
+	return t.batch.Delete(k, nil)
+}
+
+// pebbleReader *pebble.DB 和 *pebble.Snapshot 共有的读取方法
+type pebbleReader interface {
+	Get(key []byte) ([]byte, io.Closer, error)
+	NewIter(o *pebble.IterOptions) (*pebble.Iterator, error)
+}
+
+// getPebble 从reader中读取key对应的value的拷贝，如果key不存在会返回NoDataError
+func getPebble(reader pebbleReader, k []byte) ([]byte, error) {
+	v, closer, err := reader.Get(k)
+	if errors.Is(err, pebble.ErrNotFound) {
+		return nil, NoDataError
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+	// v只在closer关闭之前有效
+	return copyBytes(v), nil
+}
+
+// iterPebbleRange 遍历reader中[start, end)内的数据，直到fn返回错误
+func iterPebbleRange(reader pebbleReader, start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	iter, err := reader.NewIter(&pebble.IterOptions{LowerBound: start, UpperBound: end})
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for iter.First(); iter.Valid(); iter.Next() {
+		if err := fn(iter.Key(), iter.Value()); errors.Is(err, ErrStopIteration) {
+			break
+		} else if err != nil {
+			iter.Close()
+			return 0, err
+		}
+		count++
+	}
+	if err := iter.Close(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// pebbleSnapshot Pebble的快照
+type pebbleSnapshot struct {
+	snapshot *pebble.Snapshot
+}
+
+// Get 读取key对应的value，如果key不存在会返回NoDataError
+func (s *pebbleSnapshot) Get(k []byte) ([]byte, error) {
+	return getPebble(s.snapshot, k)
+}
+
+// Has 判断某个key是否存在
+func (s *pebbleSnapshot) Has(k []byte) bool {
+	v, err := s.Get(k)
+	return err == nil && len(v) > 0
+}
+
+// IterDB 遍历快照中的全部数据，返回数据的条数
+func (s *pebbleSnapshot) IterDB(fn func(k, v []byte) error) (int64, error) {
+	return iterPebbleRange(s.snapshot, nil, nil, fn)
+}
+
+// IterRange 遍历[start, end)内的数据，返回数据的条数
+func (s *pebbleSnapshot) IterRange(start, end []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterPebbleRange(s.snapshot, start, end, fn)
+}
+
+// IterPrefix 遍历以prefix开头的数据，返回数据的条数
+func (s *pebbleSnapshot) IterPrefix(prefix []byte, fn func(k, v []byte) error) (int64, error) {
+	return iterPebbleRange(s.snapshot, prefix, prefixEnd(prefix), fn)
+}
+
+// Close 释放快照
+func (s *pebbleSnapshot) Close() error {
+	return s.snapshot.Close()
+}
+
+// Backup 在快照上遍历全部数据写入w，备份期间可以继续读写
+func (p *Pebble) Backup(w io.Writer) error {
+	snapshot := p.db.NewSnapshot()
+	defer snapshot.Close()
+	iter, err := snapshot.NewIter(nil)
+	if err != nil {
+		return err
+	}
+	defer iter.Close()
+	writer, err := newKVStreamWriter(w)
+	if err != nil {
+		return err
+	}
+	for iter.First(); iter.Valid(); iter.Next() {
+		if err := writer.Write(iter.Key(), iter.Value()); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// pebbleRestoreBatchSize 恢复时每个batch写入的记录数
+const pebbleRestoreBatchSize = 1000
+
+// Restore 先把备份加载到临时目录中的新数据库，加载成功说明备份完整，再关闭数据库、用临时目录替换数据目录后重新打开。
+// 备份损坏时原有的数据不受影响。
+func (p *Pebble) Restore(r io.Reader) error {
+	tmpPath := p.path + ".restore"
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return err
+	}
+	tmp := new(Pebble).WithDataPath(tmpPath)
+	if err := tmp.Open(); err != nil {
+		return err
+	}
+	batch := tmp.db.NewBatch()
+	err := readKVStream(r, func(k, v []byte) error {
+		if err := batch.Set(k, v, nil); err != nil {
+			return err
+		}
+		// 分批提交，避免整个备份都缓存在一个batch中
+		if batch.Count() < pebbleRestoreBatchSize {
+			return nil
+		}
+		if err := batch.Commit(pebble.NoSync); err != nil {
+			return err
+		}
+		batch.Close()
+		batch = tmp.db.NewBatch()
+		return nil
+	})
+	if err == nil {
+		err = batch.Commit(pebble.Sync)
+	}
+	batch.Close()
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.RemoveAll(tmpPath)
+		return fmt.Errorf("加载备份失败: %w", err)
+	}
+
+	if err := p.db.Close(); err != nil {
+		return err
+	}
+	oldPath := p.path + ".old"
+	if err := os.RemoveAll(oldPath); err != nil {
+		return errors.Join(err, p.Open())
+	}
+	if err := os.Rename(p.path, oldPath); err != nil {
+		// 替换失败时重新打开原来的数据库
+		os.RemoveAll(tmpPath)
+		return errors.Join(err, p.Open())
+	}
+	if err := os.Rename(tmpPath, p.path); err != nil {
+		os.Rename(oldPath, p.path)
+		os.RemoveAll(tmpPath)
+		return errors.Join(err, p.Open())
+	}
+	if err := p.Open(); err != nil {
+		return err
+	}
+	return os.RemoveAll(oldPath)
+}
+
+// Close 关闭数据库，把内存中的数据flush到磁盘，同时释放文件锁
+func (p *Pebble) Close() error {
+	return p.db.Close()
+}
+
+// WithDataPath 方法设置 Pebble 结构的本地存储目录路径。
+func (p *Pebble) WithDataPath(path string) *Pebble {
+	p.path = path
+	return p
+}
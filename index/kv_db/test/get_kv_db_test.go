@@ -0,0 +1,35 @@
+package test
+
+import (
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"path/filepath"
+	"testing"
+)
+
+func TestGetKvDB(t *testing.T) {
+	if _, err := kv_db.GetKvDB("unknown", filepath.Join(t.TempDir(), "unknown")); err == nil {
+		t.Fatal("未注册的数据库类型应返回错误")
+	}
+
+	// 注册的数据库类型可以按名称创建，并通过同样的测试流
+	kv_db.Register("memory_copy", func(path string) kv_db.KeyValueDB {
+		return new(kv_db.Memory).WithDataPath(path)
+	})
+	for _, name := range []string{kv_db.BOLT, kv_db.BADGER, kv_db.PEBBLE, kv_db.MEMORY, "memory_copy"} {
+		found := false
+		for _, backend := range kv_db.Backends() {
+			found = found || backend == name
+		}
+		if !found {
+			t.Fatalf("%s 应已注册，实际为 %v", name, kv_db.Backends())
+		}
+	}
+	setup = func() {
+		var err error
+		db, err = kv_db.GetKvDB("memory_copy", filepath.Join(t.TempDir(), "memory_copy"))
+		if err != nil {
+			panic(err)
+		}
+	}
+	t.Run("registered_test", testPipeline)
+}
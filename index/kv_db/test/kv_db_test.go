@@ -1,10 +1,13 @@
 package test
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	_interface "github.com/jmh000527/criker-search/index/kv_db"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -161,6 +164,289 @@ func testIterDB(db _interface.KeyValueDB) error {
 	return nil
 }
 
+func testBackupRestore(db _interface.KeyValueDB) error {
+	k1 := []byte("k1")
+	v1 := []byte("v1")
+	k2 := []byte("k2")
+	v2 := []byte("v2")
+
+	if err := db.BatchDelete([][]byte{k1, k2}); err != nil {
+		return err
+	}
+	if err := db.Set(k1, v1); err != nil {
+		return err
+	}
+	var backup bytes.Buffer
+	if err := db.Backup(&backup); err != nil {
+		return err
+	}
+
+	// 备份之后的修改在恢复后应该消失
+	if err := db.Delete(k1); err != nil {
+		return err
+	}
+	if err := db.Set(k2, v2); err != nil {
+		return err
+	}
+	if err := db.Restore(&backup); err != nil {
+		return err
+	}
+	if v, err := db.Get(k1); err != nil || !bytes.Equal(v, v1) {
+		return fmt.Errorf("恢复后k1应为%s，实际为%s，错误: %v", v1, v, err)
+	}
+	if db.Has(k2) {
+		return errors.New("恢复后不应存在备份之后写入的k2")
+	}
+
+	// 损坏的备份恢复失败，原有的数据不受影响
+	if err := db.Restore(bytes.NewReader([]byte("corrupt backup"))); err == nil {
+		return errors.New("损坏的备份不应恢复成功")
+	}
+	if v, err := db.Get(k1); err != nil || !bytes.Equal(v, v1) {
+		return fmt.Errorf("恢复失败后k1应仍为%s，实际为%s，错误: %v", v1, v, err)
+	}
+	return db.Delete(k1)
+}
+
+func testExpiry(db _interface.KeyValueDB) error {
+	expiring, ok := db.(_interface.ExpiringKeyValueDB)
+	if !ok {
+		fmt.Println("不支持过期，跳过")
+		return nil
+	}
+	keys := [][]byte{[]byte("e1"), []byte("e2"), []byte("e3")}
+	values := [][]byte{[]byte("v1"), []byte("v2"), []byte("v3")}
+	if err := expiring.BatchSetWithExpiry(keys, values, []time.Time{{}, time.Now().Add(-time.Second), time.Now().Add(time.Hour)}); err != nil {
+		return err
+	}
+	if err := expiring.SetWithExpiry([]byte("e4"), []byte("v4"), time.Now().Add(-time.Second)); err != nil {
+		return err
+	}
+	for _, key := range [][]byte{[]byte("e2"), []byte("e4")} {
+		if _, err := db.Get(key); !errors.Is(err, _interface.NoDataError) {
+			return fmt.Errorf("已过期的%s应读取不到，实际错误为 %v", key, err)
+		}
+	}
+	for i, key := range [][]byte{keys[0], keys[2]} {
+		v, err := db.Get(key)
+		if err != nil || !bytes.Equal(v, values[i*2]) {
+			return fmt.Errorf("未过期的%s应能读取到，实际为 %s，错误为 %v", key, v, err)
+		}
+	}
+	return db.BatchDelete(keys)
+}
+
+// collectKeys 把遍历到的key拼成一个字符串，便于比较
+func collectKeys(iter func(fn func(k, v []byte) error) (int64, error)) (string, error) {
+	var keys []string
+	count, err := iter(func(k, v []byte) error {
+		keys = append(keys, string(k))
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if int(count) != len(keys) {
+		return "", fmt.Errorf("返回的条数%d与遍历到的%d条不一致", count, len(keys))
+	}
+	return strings.Join(keys, ","), nil
+}
+
+func testRangeAndSnapshot(db _interface.KeyValueDB) error {
+	keys := [][]byte{[]byte("r/a"), []byte("r/b"), []byte("r/c"), []byte("s/a"), {'r', 0xff}}
+	values := [][]byte{[]byte("1"), []byte("2"), []byte("3"), []byte("4"), []byte("5")}
+	if err := db.BatchSet(keys, values); err != nil {
+		return err
+	}
+	defer db.BatchDelete(keys)
+
+	expects := []struct {
+		name   string
+		iter   func(fn func(k, v []byte) error) (int64, error)
+		expect string
+	}{
+		{"IterRange", func(fn func(k, v []byte) error) (int64, error) {
+			return db.IterRange([]byte("r/b"), []byte("s/a"), fn)
+		}, "r/b,r/c,r\xff"},
+		{"IterRange到最后", func(fn func(k, v []byte) error) (int64, error) {
+			return db.IterRange([]byte("r\xff"), nil, fn)
+		}, "r\xff,s/a"},
+		{"IterPrefix", func(fn func(k, v []byte) error) (int64, error) {
+			return db.IterPrefix([]byte("r/"), fn)
+		}, "r/a,r/b,r/c"},
+		{"IterPrefix结尾为0xff", func(fn func(k, v []byte) error) (int64, error) {
+			return db.IterPrefix([]byte{'r', 0xff}, fn)
+		}, "r\xff"},
+	}
+	for _, e := range expects {
+		got, err := collectKeys(e.iter)
+		if err != nil {
+			return fmt.Errorf("%s: %v", e.name, err)
+		}
+		if got != e.expect {
+			return fmt.Errorf("%s应遍历到%q，实际为%q", e.name, e.expect, got)
+		}
+	}
+
+	// 遍历函数返回 ErrStopIteration 时提前结束，不返回错误
+	count, err := db.IterPrefix([]byte("r/"), func(k, v []byte) error {
+		if string(k) == "r/b" {
+			return _interface.ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil || count != 1 {
+		return fmt.Errorf("提前结束时应遍历 1 条，实际为 %d，错误: %v", count, err)
+	}
+
+	// 快照不受之后的写入影响
+	snapshot, err := db.Snapshot()
+	if err != nil {
+		return err
+	}
+	defer snapshot.Close()
+	if err := db.Set([]byte("r/a"), []byte("changed")); err != nil {
+		return err
+	}
+	if err := db.Set([]byte("r/d"), []byte("6")); err != nil {
+		return err
+	}
+	defer db.Delete([]byte("r/d"))
+	if v, err := snapshot.Get([]byte("r/a")); err != nil || string(v) != "1" {
+		return fmt.Errorf("快照中r/a应为1，实际为%s，错误: %v", v, err)
+	}
+	if snapshot.Has([]byte("r/d")) {
+		return errors.New("快照中不应存在之后写入的r/d")
+	}
+	if _, err := snapshot.Get([]byte("r/d")); !errors.Is(err, _interface.NoDataError) {
+		return fmt.Errorf("快照中读取r/d应返回NoDataError，实际为%v", err)
+	}
+	got, err := collectKeys(func(fn func(k, v []byte) error) (int64, error) {
+		return snapshot.IterPrefix([]byte("r/"), fn)
+	})
+	if err != nil || got != "r/a,r/b,r/c" {
+		return fmt.Errorf("快照中应遍历到r/a,r/b,r/c，实际为%q，错误: %v", got, err)
+	}
+	if got, err := collectKeys(func(fn func(k, v []byte) error) (int64, error) {
+		return db.IterPrefix([]byte("r/"), fn)
+	}); err != nil || got != "r/a,r/b,r/c,r/d" {
+		return fmt.Errorf("数据库中应遍历到r/a,r/b,r/c,r/d，实际为%q，错误: %v", got, err)
+	}
+	return nil
+}
+
+func testTxn(db _interface.KeyValueDB) error {
+	k1, k2 := []byte("t1"), []byte("t2")
+	if err := db.Set(k2, []byte("old")); err != nil {
+		return err
+	}
+	defer db.BatchDelete([][]byte{k1, k2})
+
+	// fn 返回错误时事务中的写入全部丢弃
+	errAbort := errors.New("abort")
+	err := db.Update(func(tx _interface.KVTxn) error {
+		if err := tx.Set(k1, []byte("v1")); err != nil {
+			return err
+		}
+		if err := tx.Delete(k2); err != nil {
+			return err
+		}
+		// 事务中能读到本事务之前的写入
+		if v, err := tx.Get(k1); err != nil || string(v) != "v1" {
+			return fmt.Errorf("事务中t1应为v1，实际为%s，错误: %v", v, err)
+		}
+		if tx.Has(k2) {
+			return errors.New("事务中t2应已被删除")
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		return fmt.Errorf("Update应返回fn的错误，实际为%v", err)
+	}
+	if db.Has(k1) {
+		return errors.New("回滚的事务不应写入t1")
+	}
+	if v, err := db.Get(k2); err != nil || string(v) != "old" {
+		return fmt.Errorf("回滚的事务不应删除t2，实际为%s，错误: %v", v, err)
+	}
+
+	// fn 返回 nil 时事务中的写入全部生效
+	if err := db.Update(func(tx _interface.KVTxn) error {
+		if err := tx.Set(k1, []byte("v1")); err != nil {
+			return err
+		}
+		return tx.Delete(k2)
+	}); err != nil {
+		return err
+	}
+	if v, err := db.Get(k1); err != nil || string(v) != "v1" {
+		return fmt.Errorf("提交后t1应为v1，实际为%s，错误: %v", v, err)
+	}
+	if db.Has(k2) {
+		return errors.New("提交后t2应已被删除")
+	}
+
+	// 只读事务可以读取，不能写入
+	return db.View(func(tx _interface.KVTxn) error {
+		if v, err := tx.Get(k1); err != nil || string(v) != "v1" {
+			return fmt.Errorf("只读事务中t1应为v1，实际为%s，错误: %v", v, err)
+		}
+		if _, err := tx.Get(k2); !errors.Is(err, _interface.NoDataError) {
+			return fmt.Errorf("只读事务中读取t2应返回NoDataError，实际为%v", err)
+		}
+		if err := tx.Set(k2, []byte("v2")); !errors.Is(err, _interface.ErrReadOnlyTxn) {
+			return fmt.Errorf("只读事务中写入应返回ErrReadOnlyTxn，实际为%v", err)
+		}
+		return nil
+	})
+}
+
+func testCompact(db _interface.KeyValueDB) error {
+	compactor, ok := db.(_interface.Compactor)
+	if !ok {
+		fmt.Println("不支持Compact，跳过")
+		return nil
+	}
+	// 写入一批较大的value后删除大部分，留出可回收的空间
+	const total, kept = 2000, 10
+	keys := make([][]byte, total)
+	values := make([][]byte, total)
+	for i := range keys {
+		keys[i] = []byte(fmt.Sprintf("c/%04d", i))
+		values[i] = bytes.Repeat([]byte{byte(i)}, 1024)
+	}
+	if err := db.BatchSet(keys, values); err != nil {
+		return err
+	}
+	defer db.BatchDelete(keys[:kept])
+	if err := db.BatchDelete(keys[kept:]); err != nil {
+		return err
+	}
+
+	result, err := compactor.Compact(0.01)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Compact: %+v\n", result)
+	if result.Compacted && result.AfterBytes > result.BeforeBytes {
+		return fmt.Errorf("回收之后占用的空间%d不应大于回收之前的%d", result.AfterBytes, result.BeforeBytes)
+	}
+	// 回收之后数据保持不变，数据库可以继续读写
+	for i := 0; i < total; i++ {
+		v, err := db.Get(keys[i])
+		if i < kept && (err != nil || !bytes.Equal(v, values[i])) {
+			return fmt.Errorf("回收之后%s的value不正确，错误: %v", keys[i], err)
+		}
+		if i >= kept && !errors.Is(err, _interface.NoDataError) {
+			return fmt.Errorf("回收之后已删除的%s不应再读到，错误: %v", keys[i], err)
+		}
+	}
+	if err := db.Set([]byte("c/new"), []byte("v")); err != nil {
+		return err
+	}
+	return db.Delete([]byte("c/new"))
+}
+
 func testPipeline(t *testing.T) { //整个测试流
 	defer teardown()
 	setup()
@@ -188,4 +474,39 @@ func testPipeline(t *testing.T) { //整个测试流
 		t.Fail()
 	}
 	fmt.Println()
+
+	err = testBackupRestore(db)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	fmt.Println()
+
+	err = testExpiry(db)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	fmt.Println()
+
+	err = testRangeAndSnapshot(db)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	fmt.Println()
+
+	err = testTxn(db)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	fmt.Println()
+
+	err = testCompact(db)
+	if err != nil {
+		fmt.Println(err)
+		t.Fail()
+	}
+	fmt.Println()
 }
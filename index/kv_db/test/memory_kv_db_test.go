@@ -0,0 +1,20 @@
+package test
+
+import (
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/utils"
+	"testing"
+)
+
+func TestMemory(t *testing.T) {
+	setup = func() {
+		var err error
+		db, err = kv_db.GetKvDB(kv_db.MEMORY, utils.RootPath+"data/memory_db")
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 子测试
+	t.Run("memory_test", testPipeline)
+}
@@ -0,0 +1,20 @@
+package test
+
+import (
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/utils"
+	"testing"
+)
+
+func TestPebble(t *testing.T) {
+	setup = func() {
+		var err error
+		db, err = kv_db.GetKvDB(kv_db.PEBBLE, utils.RootPath+"data/pebble_db")
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// 子测试
+	t.Run("pebble_test", testPipeline)
+}
@@ -0,0 +1,191 @@
+package suggest
+
+import (
+	"container/heap"
+	"sync"
+)
+
+// Completion 补全的候选词
+type Completion struct {
+	Word   string // 候选词
+	Weight int64  // 候选词的权重，例如包含它的文档数量
+}
+
+// Trie 带权重的前缀树，用于输入时的自动补全，并发安全。
+// 每个节点记录子树中的最大权重，补全时按最大权重优先展开节点，只访问与前 limit 个结果有关的节点，
+// 前缀很短、子树很大时也能很快返回。
+type Trie struct {
+	mu   sync.RWMutex
+	root *trieNode
+	size int // 权重大于0的词的数量
+}
+
+type trieNode struct {
+	children  map[rune]*trieNode
+	weight    int64 // 以该节点结尾的词的权重，0表示没有以该节点结尾的词
+	maxWeight int64 // 子树（包括该节点）中的最大权重
+}
+
+// NewTrie 创建一个空的前缀树
+func NewTrie() *Trie {
+	return &Trie{root: new(trieNode)}
+}
+
+// Add 把词 word 的权重加上 delta，delta 可以为负数。权重不大于0的词从前缀树中删除。
+//
+// 参数:
+//   - word: 词，不能为空。
+//   - delta: 权重的增量。
+func (t *Trie) Add(word string, delta int64) {
+	if len(word) == 0 || delta == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 记录从根节点到词尾节点的路径，更新权重之后从下往上重新计算子树的最大权重
+	path := []*trieNode{t.root}
+	runes := []rune(word)
+	node := t.root
+	for _, r := range runes {
+		child, ok := node.children[r]
+		if !ok {
+			if delta < 0 {
+				return // 词不存在，不需要删除
+			}
+			child = new(trieNode)
+			if node.children == nil {
+				node.children = make(map[rune]*trieNode)
+			}
+			node.children[r] = child
+		}
+		node = child
+		path = append(path, node)
+	}
+
+	before := node.weight
+	node.weight += delta
+	if node.weight < 0 {
+		node.weight = 0
+	}
+	if before == 0 && node.weight > 0 {
+		t.size++
+	} else if before > 0 && node.weight == 0 {
+		t.size--
+	}
+
+	for i := len(path) - 1; i >= 0; i-- {
+		node := path[i]
+		node.maxWeight = node.weight
+		for _, child := range node.children {
+			if child.maxWeight > node.maxWeight {
+				node.maxWeight = child.maxWeight
+			}
+		}
+		// 子树中已经没有词的节点从父节点上摘除
+		if i > 0 && node.maxWeight == 0 {
+			delete(path[i-1].children, runes[i-1])
+		}
+	}
+}
+
+// Weight 返回词 word 的权重，词不存在时返回0
+func (t *Trie) Weight(word string) int64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if node := t.find(word); node != nil {
+		return node.weight
+	}
+	return 0
+}
+
+// Len 返回前缀树中词的数量
+func (t *Trie) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.size
+}
+
+// Complete 返回以 prefix 开头的权重最大的 limit 个词（包括 prefix 本身），按权重从大到小、词的字典序排序。
+//
+// 参数:
+//   - prefix: 前缀，为空时返回整个前缀树中权重最大的词。
+//   - limit: 最多返回的候选数量，必须大于0。
+//
+// 返回值:
+//   - []Completion: 排好序的候选词。
+func (t *Trie) Complete(prefix string, limit int) []Completion {
+	if limit <= 0 {
+		return nil
+	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	start := t.find(prefix)
+	if start == nil || start.maxWeight == 0 {
+		return nil
+	}
+
+	// 优先队列中既有待展开的节点（按子树最大权重排序），也有已经确定的词（按词的权重排序）。
+	// 一个词出队时，队列中其他节点子树的最大权重都不超过它，因此它一定是剩余的词中权重最大的。
+	queue := &completionQueue{{node: start, word: prefix, weight: start.maxWeight}}
+	var completions []Completion // 不按 limit 预先分配，limit 可能远大于实际的候选数量
+	for queue.Len() > 0 && len(completions) < limit {
+		item := heap.Pop(queue).(completionItem)
+		if item.node == nil {
+			completions = append(completions, Completion{Word: item.word, Weight: item.weight})
+			continue
+		}
+		if item.node.weight > 0 {
+			heap.Push(queue, completionItem{word: item.word, weight: item.node.weight})
+		}
+		for r, child := range item.node.children {
+			heap.Push(queue, completionItem{node: child, word: item.word + string(r), weight: child.maxWeight})
+		}
+	}
+	return completions
+}
+
+// find 返回前缀 prefix 对应的节点，不存在时返回 nil，调用方需持有读锁
+func (t *Trie) find(prefix string) *trieNode {
+	node := t.root
+	for _, r := range prefix {
+		if node = node.children[r]; node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// completionItem 补全时优先队列中的元素：node 不为 nil 时是待展开的节点，为 nil 时是已经确定的词
+type completionItem struct {
+	node   *trieNode
+	word   string
+	weight int64
+}
+
+// completionQueue 按权重从大到小排序的优先队列。权重相同时按词（节点的前缀）的字典序出队，
+// 子树中可能有字典序更小的词的节点会先展开，因此权重相同的词也按字典序返回
+type completionQueue []completionItem
+
+func (q completionQueue) Len() int { return len(q) }
+
+func (q completionQueue) Less(i, j int) bool {
+	if q[i].weight != q[j].weight {
+		return q[i].weight > q[j].weight
+	}
+	if q[i].word != q[j].word {
+		return q[i].word < q[j].word
+	}
+	return q[i].node == nil
+}
+
+func (q completionQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *completionQueue) Push(x any) { *q = append(*q, x.(completionItem)) }
+
+func (q *completionQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
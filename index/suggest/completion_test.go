@@ -0,0 +1,64 @@
+package suggest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestTrieComplete(t *testing.T) {
+	trie := NewTrie()
+	for word, weight := range map[string]int64{"go": 5, "golang": 10, "gopher": 3, "goland": 3, "google": 7, "rust": 8, "教程": 2, "教材": 4} {
+		trie.Add(word, weight)
+	}
+	if trie.Len() != 8 {
+		t.Fatalf("应有 8 个词，实际为 %d", trie.Len())
+	}
+
+	got := trie.Complete("go", 4)
+	expect := []Completion{{"golang", 10}, {"google", 7}, {"go", 5}, {"goland", 3}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	// limit 远大于候选数量时只返回已有的候选，不按 limit 分配内存
+	if got := trie.Complete("go", math.MaxInt32); len(got) != 5 {
+		t.Fatalf("应返回 5 个候选，实际为 %v", got)
+	}
+	got = trie.Complete("教", 10)
+	expect = []Completion{{"教材", 4}, {"教程", 2}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	if got := trie.Complete("java", 10); len(got) != 0 {
+		t.Fatalf("没有以 java 开头的词，实际为 %v", got)
+	}
+}
+
+func TestTrieAdd(t *testing.T) {
+	trie := NewTrie()
+	trie.Add("golang", 2)
+	trie.Add("go", 1)
+	trie.Add("golang", 3)
+	if w := trie.Weight("golang"); w != 5 {
+		t.Fatalf("权重应累加为 5，实际为 %d", w)
+	}
+
+	// 权重减到0的词被删除，子树的最大权重随之更新
+	trie.Add("golang", -5)
+	if w := trie.Weight("golang"); w != 0 || trie.Len() != 1 {
+		t.Fatalf("golang 应被删除，实际权重为 %d，词的数量为 %d", w, trie.Len())
+	}
+	if got, expect := trie.Complete("g", 10), []Completion{{"go", 1}}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	if got := trie.Complete("gol", 10); len(got) != 0 {
+		t.Fatalf("删除之后不应再补全 golang，实际为 %v", got)
+	}
+
+	// 删除不存在的词不影响前缀树
+	trie.Add("gopher", -1)
+	trie.Add("go", -1)
+	if trie.Len() != 0 || len(trie.Complete("", 10)) != 0 {
+		t.Fatalf("前缀树应为空，实际为 %v", trie.Complete("", 10))
+	}
+}
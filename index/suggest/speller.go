@@ -0,0 +1,132 @@
+package suggest
+
+import (
+	"sort"
+	"unicode/utf8"
+)
+
+// TermSource 提供某个字段下的词典及每个词的文档数量，倒排索引实现了该接口
+type TermSource interface {
+	// Terms 遍历字段 field 下的所有词，fn 返回 false 时停止遍历
+	Terms(field string, fn func(word string, docFreq int) bool)
+}
+
+// Candidate 纠错的候选词
+type Candidate struct {
+	Word     string // 候选词
+	DocFreq  int    // 包含候选词的文档数量
+	Distance int    // 候选词与原词的编辑距离，为0表示原词就在词典中
+}
+
+// MaxDistance 返回纠错时允许的默认最大编辑距离：4个字符以内的词只允许1次编辑，更长的词允许2次。
+func MaxDistance(word string) int {
+	if utf8.RuneCountInString(word) <= 4 {
+		return 1
+	}
+	return 2
+}
+
+// Correct 在词典中查找与 word 编辑距离不超过 maxDistance 的词，作为 "您是不是要找" 的候选。
+// 候选按编辑距离从小到大、文档数量从多到少、词的字典序排序。word 本身在词典中时也会作为距离为0的候选返回，
+// 调用方据此判断原词是否需要纠正。
+//
+// 参数:
+//   - source: 词典。
+//   - field: 在哪个字段的词典中查找。
+//   - word: 待纠正的词。
+//   - maxDistance: 允许的最大编辑距离，<=0 时使用 MaxDistance(word)。
+//   - limit: 最多返回的候选数量，<=0 表示不限制。
+//
+// 返回值:
+//   - []Candidate: 排好序的候选词。
+func Correct(source TermSource, field, word string, maxDistance, limit int) []Candidate {
+	if source == nil || len(word) == 0 {
+		return nil
+	}
+	if maxDistance <= 0 {
+		maxDistance = MaxDistance(word)
+	}
+	target := []rune(word)
+	var candidates []Candidate
+	source.Terms(field, func(term string, docFreq int) bool {
+		// 长度之差已经超过最大编辑距离的词不用计算
+		diff := utf8.RuneCountInString(term) - len(target)
+		if diff > maxDistance || -diff > maxDistance {
+			return true
+		}
+		if distance := distance([]rune(term), target, maxDistance); distance <= maxDistance {
+			candidates = append(candidates, Candidate{Word: term, DocFreq: docFreq, Distance: distance})
+		}
+		return true
+	})
+	SortCandidates(candidates)
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates
+}
+
+// SortCandidates 按编辑距离从小到大、文档数量从多到少、词的字典序对候选词排序
+func SortCandidates(candidates []Candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.DocFreq != b.DocFreq {
+			return a.DocFreq > b.DocFreq
+		}
+		return a.Word < b.Word
+	})
+}
+
+// Distance 计算两个词之间的编辑距离（按字符计算，相邻两个字符交换算1次编辑）
+func Distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	return distance(ra, rb, len(ra)+len(rb))
+}
+
+// distance 计算 a 和 b 的 Damerau-Levenshtein 编辑距离（限制版本，每个子串最多编辑一次）。
+// 某一行的最小值已经超过 max 时提前返回 max+1。
+func distance(a, b []rune, max int) int {
+	// prev2、prev、curr 分别是动态规划表的第 i-2、i-1、i 行
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && prev2[j-2]+1 < d {
+				d = prev2[j-2] + 1
+			}
+			curr[j] = d
+			if d < rowMin {
+				rowMin = d
+			}
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		prev2, prev, curr = prev, curr, prev2
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
@@ -0,0 +1,59 @@
+package suggest
+
+import (
+	"reflect"
+	"testing"
+)
+
+// mapSource 用 map 模拟倒排索引的词典
+type mapSource map[string]map[string]int
+
+func (s mapSource) Terms(field string, fn func(word string, docFreq int) bool) {
+	for word, docFreq := range s[field] {
+		if !fn(word, docFreq) {
+			return
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	for _, c := range []struct {
+		a, b   string
+		expect int
+	}{
+		{"golang", "golang", 0},
+		{"golnag", "golang", 1}, // 相邻字符交换
+		{"golan", "golang", 1},
+		{"gopher", "golang", 4},
+		{"", "go", 2},
+		{"教承", "教程", 1},
+	} {
+		if got := Distance(c.a, c.b); got != c.expect {
+			t.Errorf("Distance(%q, %q) 应为 %d，实际为 %d", c.a, c.b, c.expect, got)
+		}
+	}
+}
+
+func TestCorrect(t *testing.T) {
+	source := mapSource{
+		"content": {"golang": 10, "golf": 3, "gold": 5, "goland": 2, "rust": 8},
+		"author":  {"golang": 1},
+	}
+	got := Correct(source, "content", "golnag", 0, 0)
+	expect := []Candidate{{"golang", 10, 1}, {"goland", 2, 2}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+
+	// 原词在词典中时作为距离为0的候选排在最前面
+	got = Correct(source, "content", "golf", 0, 2)
+	expect = []Candidate{{"golf", 3, 0}, {"gold", 5, 1}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+
+	// 只在指定的字段中查找
+	if got := Correct(source, "author", "rusty", 0, 0); len(got) != 0 {
+		t.Fatalf("author 字段中没有相近的词，实际为 %v", got)
+	}
+}
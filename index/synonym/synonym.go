@@ -0,0 +1,372 @@
+package synonym
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/jmh000527/criker-search/types"
+	"github.com/jmh000527/criker-search/utils"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AllFields 词典文件中对所有字段生效的段，WithMode 中表示未单独配置的字段
+const AllFields = "*"
+
+// Mode 同义词在哪个阶段展开，可以按位组合
+type Mode int
+
+const (
+	QueryTime Mode = 1 << iota // 检索时把关键词展开为同义词的 Should
+	IndexTime                  // 写入时给文档补充同义词关键词，检索时不需要再展开
+)
+
+// Dictionary 同义词词典，并发安全。方法在 nil 上调用时什么都不做，调用方不需要判断是否配置了词典。
+//
+// 词典文件每行是一组互为同义词的词，用逗号分隔；"#"开头的行是注释。"[字段名]"开始一个只对该字段生效的段，
+// "[*]"（也是文件开头的默认段）对所有字段生效。同一个词在字段段和"[*]"段中的同义词合并使用，例如:
+//
+//	go, golang, go语言
+//	[author]
+//	老番茄, 番茄
+//
+// 词典不做大小写转换，词的写法需要与索引中的关键词一致。自动重新加载可能读到写了一半的文件，
+// 修改词典文件时应先写入临时文件，再重命名为词典文件。
+type Dictionary struct {
+	mu     sync.RWMutex
+	groups map[string]map[string][]string // 字段 -> 词 -> 同义词（包含词本身）
+	modes  map[string]Mode                // 字段 -> 展开的阶段，没有配置的字段使用 AllFields 的配置
+
+	path     string    // 词典文件的路径，为空表示不是从文件加载的
+	modTime  time.Time // 最近一次加载时文件的修改时间
+	size     int64     // 最近一次加载时文件的大小
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDictionary 创建一个空的同义词词典，所有字段都在检索时展开。
+func NewDictionary() *Dictionary {
+	return &Dictionary{
+		groups: make(map[string]map[string][]string),
+		modes:  map[string]Mode{AllFields: QueryTime},
+		stop:   make(chan struct{}),
+	}
+}
+
+// LoadDictionary 从文件加载同义词词典，并在后台定期检查文件是否变化，变化后自动重新加载。
+//
+// 参数:
+//   - path: 词典文件的路径。
+//   - reloadInterval: 检查文件变化的间隔，<=0 时不自动重新加载。
+//
+// 返回值:
+//   - *Dictionary: 加载好的词典，所有字段都在检索时展开。
+//   - error: 首次加载文件失败时返回错误。
+func LoadDictionary(path string, reloadInterval time.Duration) (*Dictionary, error) {
+	dict := NewDictionary()
+	dict.path = path
+	if err := dict.Reload(); err != nil {
+		return nil, err
+	}
+	if reloadInterval > 0 {
+		go dict.watch(reloadInterval)
+	}
+	return dict, nil
+}
+
+// WithMode 设置字段上同义词展开的阶段，field 为 AllFields 时设置没有单独配置的字段。mode 为0表示该字段不展开。
+func (dict *Dictionary) WithMode(field string, mode Mode) *Dictionary {
+	dict.mu.Lock()
+	defer dict.mu.Unlock()
+	dict.modes[field] = mode
+	return dict
+}
+
+// Add 添加一组互为同义词的词，只对 field 生效，field 为 AllFields 时对所有字段生效。
+// 从文件加载的词典重新加载后，通过 Add 添加的词会被文件的内容替换。
+func (dict *Dictionary) Add(field string, words ...string) {
+	dict.mu.Lock()
+	defer dict.mu.Unlock()
+	addGroup(dict.groups, field, words)
+}
+
+// Reload 重新读取词典文件，读取或解析失败时继续使用原来的词典。
+//
+// 返回值:
+//   - error: 词典不是从文件加载的，或者读取、解析文件失败时返回错误。
+func (dict *Dictionary) Reload() error {
+	if len(dict.path) == 0 {
+		return errors.New("同义词词典不是从文件加载的")
+	}
+	info, err := os.Stat(dict.path)
+	if err != nil {
+		return fmt.Errorf("读取同义词词典 %s 失败: %v", dict.path, err)
+	}
+	file, err := os.Open(dict.path)
+	if err != nil {
+		return fmt.Errorf("读取同义词词典 %s 失败: %v", dict.path, err)
+	}
+	defer file.Close()
+	groups, err := parse(file)
+	if err != nil {
+		return fmt.Errorf("解析同义词词典 %s 失败: %v", dict.path, err)
+	}
+
+	dict.mu.Lock()
+	dict.groups = groups
+	dict.modTime = info.ModTime()
+	dict.size = info.Size()
+	dict.mu.Unlock()
+	utils.Log.Printf("从文件 %s 加载同义词词典，共 %d 个字段段", dict.path, len(groups))
+	return nil
+}
+
+// watch 定期检查文件的修改时间和大小，变化时重新加载
+func (dict *Dictionary) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-dict.stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(dict.path)
+			if err != nil {
+				utils.Log.Printf("读取同义词词典 %s 失败: %v", dict.path, err)
+				continue
+			}
+			dict.mu.RLock()
+			changed := !info.ModTime().Equal(dict.modTime) || info.Size() != dict.size
+			dict.mu.RUnlock()
+			if changed {
+				if err := dict.Reload(); err != nil {
+					utils.Log.Printf("重新加载同义词词典失败，继续使用旧的词典: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// Close 停止自动重新加载
+func (dict *Dictionary) Close() {
+	if dict == nil {
+		return
+	}
+	dict.stopOnce.Do(func() { close(dict.stop) })
+}
+
+// parse 解析词典文件的内容
+func parse(r io.Reader) (map[string]map[string][]string, error) {
+	groups := make(map[string]map[string][]string)
+	field := AllFields
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") || len(line) < 3 {
+				return nil, fmt.Errorf("第 %d 行的字段段格式不正确: %s", lineNo, line)
+			}
+			field = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		addGroup(groups, field, strings.FieldsFunc(line, func(r rune) bool {
+			return r == ',' || r == '，'
+		}))
+	}
+	return groups, scanner.Err()
+}
+
+// addGroup 把一组同义词加入 field 的词典，一个词出现在多组中时合并这些组
+func addGroup(groups map[string]map[string][]string, field string, words []string) {
+	group := make([]string, 0, len(words))
+	for _, word := range words {
+		if word = strings.TrimSpace(word); len(word) > 0 {
+			group = appendUnique(group, word)
+		}
+	}
+	if len(group) < 2 {
+		return
+	}
+	fieldGroups := groups[field]
+	if fieldGroups == nil {
+		fieldGroups = make(map[string][]string)
+		groups[field] = fieldGroups
+	}
+	for _, word := range group {
+		merged := fieldGroups[word]
+		for _, synonym := range group {
+			merged = appendUnique(merged, synonym)
+		}
+		fieldGroups[word] = merged
+	}
+}
+
+// appendUnique word 不在 words 中时追加到末尾
+func appendUnique(words []string, word string) []string {
+	for _, w := range words {
+		if w == word {
+			return words
+		}
+	}
+	return append(words, word)
+}
+
+// mode 返回字段上同义词展开的阶段，调用方需持有读锁
+func (dict *Dictionary) mode(field string) Mode {
+	if mode, exists := dict.modes[field]; exists {
+		return mode
+	}
+	return dict.modes[AllFields]
+}
+
+// synonyms 返回 field 上 word 的同义词，第一个是 word 本身；没有同义词时返回 nil。调用方需持有读锁
+func (dict *Dictionary) synonyms(field, word string) []string {
+	var result []string
+	for _, group := range [][]string{dict.groups[field][word], dict.groups[AllFields][word]} {
+		for _, synonym := range group {
+			if len(result) == 0 {
+				result = append(result, word)
+			}
+			result = appendUnique(result, synonym)
+		}
+	}
+	return result
+}
+
+// Synonyms 返回 field 上 word 的同义词，与展开的阶段无关。
+//
+// 返回值:
+//   - []string: 同义词列表，第一个是 word 本身；没有同义词时返回 nil。
+func (dict *Dictionary) Synonyms(field, word string) []string {
+	if dict == nil {
+		return nil
+	}
+	dict.mu.RLock()
+	defer dict.mu.RUnlock()
+	return dict.synonyms(field, word)
+}
+
+// ExpandQuery 把查询中在检索时展开的字段上的关键词替换为其同义词组成的 Should，不修改传入的查询。
+// 多字段查询按字段分别展开，每个同义词保留原字段的权重。
+//
+// 参数:
+//   - query: 原始的查询。
+//
+// 返回值:
+//   - *types.TermQuery: 展开后的查询；没有需要展开的关键词时返回 query 本身。
+func (dict *Dictionary) ExpandQuery(query *types.TermQuery) *types.TermQuery {
+	if dict == nil || query == nil {
+		return query
+	}
+	dict.mu.RLock()
+	defer dict.mu.RUnlock()
+	return dict.expandQuery(query)
+}
+
+// expandQuery 递归展开查询，调用方需持有读锁
+func (dict *Dictionary) expandQuery(query *types.TermQuery) *types.TermQuery {
+	switch {
+	case query.Keyword != nil && len(query.Fields) == 0:
+		words := dict.expandable(query.Keyword.Field, query.Keyword.Word)
+		if words == nil {
+			return query
+		}
+		should := make([]*types.TermQuery, 0, len(words))
+		for _, word := range words {
+			should = append(should, types.NewTermQuery(query.Keyword.Field, word))
+		}
+		return &types.TermQuery{Should: should}
+	case query.Keyword != nil:
+		var should []*types.TermQuery
+		expanded := false
+		for _, field := range query.Fields {
+			words := dict.expandable(field.Field, query.Keyword.Word)
+			if words == nil {
+				words = []string{query.Keyword.Word}
+			} else {
+				expanded = true
+			}
+			for _, word := range words {
+				should = append(should, types.NewMultiFieldQuery(word, field))
+			}
+		}
+		if !expanded {
+			return query
+		}
+		return &types.TermQuery{Should: should}
+	case len(query.Must) > 0:
+		if must, changed := dict.expandAll(query.Must); changed {
+			return &types.TermQuery{Must: must}
+		}
+	case len(query.Should) > 0:
+		if should, changed := dict.expandAll(query.Should); changed {
+			return &types.TermQuery{Should: should}
+		}
+	}
+	return query
+}
+
+// expandAll 展开一组子查询，返回展开后的子查询以及是否有子查询被展开
+func (dict *Dictionary) expandAll(queries []*types.TermQuery) ([]*types.TermQuery, bool) {
+	expanded := make([]*types.TermQuery, len(queries))
+	changed := false
+	for i, query := range queries {
+		expanded[i] = dict.expandQuery(query)
+		changed = changed || expanded[i] != query
+	}
+	return expanded, changed
+}
+
+// expandable 字段在检索时展开且 word 有同义词时返回同义词，否则返回 nil。调用方需持有读锁
+func (dict *Dictionary) expandable(field, word string) []string {
+	if dict.mode(field)&QueryTime == 0 {
+		return nil
+	}
+	return dict.synonyms(field, word)
+}
+
+// ExpandKeywords 给写入时展开的字段上的关键词补充同义词关键词，用于构建文档的 Keywords。不修改传入的切片。
+//
+// 参数:
+//   - keywords: 文档原始的关键词。
+//
+// 返回值:
+//   - []*types.Keyword: 补充同义词并去重之后的关键词；没有需要补充的关键词时返回 keywords 本身。
+func (dict *Dictionary) ExpandKeywords(keywords []*types.Keyword) []*types.Keyword {
+	if dict == nil {
+		return keywords
+	}
+	dict.mu.RLock()
+	defer dict.mu.RUnlock()
+	var expanded []*types.Keyword
+	seen := make(map[string]struct{}, len(keywords))
+	for _, keyword := range keywords {
+		seen[keyword.ToString()] = struct{}{}
+	}
+	for _, keyword := range keywords {
+		if dict.mode(keyword.Field)&IndexTime == 0 {
+			continue
+		}
+		for _, word := range dict.synonyms(keyword.Field, keyword.Word) {
+			synonym := &types.Keyword{Field: keyword.Field, Word: word}
+			if _, exists := seen[synonym.ToString()]; exists {
+				continue
+			}
+			seen[synonym.ToString()] = struct{}{}
+			if expanded == nil {
+				expanded = append(make([]*types.Keyword, 0, 2*len(keywords)), keywords...)
+			}
+			expanded = append(expanded, synonym)
+		}
+	}
+	if expanded == nil {
+		return keywords
+	}
+	return expanded
+}
@@ -0,0 +1,137 @@
+package synonym
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/types"
+)
+
+const testDict = `
+# 编程语言
+go, golang, go语言
+golang，gopher
+
+[author]
+老番茄, 番茄
+`
+
+func TestParse(t *testing.T) {
+	groups, err := parse(strings.NewReader(testDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// golang 出现在两组中，两组合并；go 只与第一组同义
+	if got := groups[AllFields]["golang"]; !reflect.DeepEqual(got, []string{"go", "golang", "go语言", "gopher"}) {
+		t.Fatalf("golang 的同义词不正确: %v", got)
+	}
+	if got := groups[AllFields]["go"]; !reflect.DeepEqual(got, []string{"go", "golang", "go语言"}) {
+		t.Fatalf("go 的同义词不正确: %v", got)
+	}
+	if got := groups["author"]["番茄"]; !reflect.DeepEqual(got, []string{"老番茄", "番茄"}) {
+		t.Fatalf("author 段的同义词不正确: %v", got)
+	}
+	if _, err := parse(strings.NewReader("[author\na, b")); err == nil {
+		t.Fatal("字段段格式不正确时应返回错误")
+	}
+}
+
+func TestExpandQuery(t *testing.T) {
+	dict := NewDictionary().WithMode("author", 0)
+	dict.Add(AllFields, "go", "golang")
+	dict.Add("content", "go", "go语言")
+
+	query := types.NewTermQuery("content", "go").And(types.NewTermQuery("author", "go"))
+	expect := "((content\001go|content\001go语言|content\001golang)&author\001go)"
+	if got := dict.ExpandQuery(query).ToString(); got != expect {
+		t.Fatalf("展开后应为 %q，实际为 %q", expect, got)
+	}
+	// 不修改传入的查询
+	if got := query.ToString(); got != "(content\001go&author\001go)" {
+		t.Fatalf("原查询被修改: %q", got)
+	}
+	// 没有同义词时返回原查询
+	if other := types.NewTermQuery("content", "rust"); dict.ExpandQuery(other) != other {
+		t.Fatal("没有同义词时应返回原查询")
+	}
+
+	// 多字段查询按字段展开，保留字段的权重
+	query = types.NewMultiFieldQuery("golang", &types.FieldBoost{Field: "content"}, &types.FieldBoost{Field: "author", Boost: 2})
+	expect = "((content\001golang)|(content\001go)|(author\001golang^2))"
+	if got := dict.ExpandQuery(query).ToString(); got != expect {
+		t.Fatalf("展开后应为 %q，实际为 %q", expect, got)
+	}
+
+	// nil 词典不展开
+	var empty *Dictionary
+	if empty.ExpandQuery(query) != query {
+		t.Fatal("nil 词典应返回原查询")
+	}
+}
+
+func TestExpandKeywords(t *testing.T) {
+	dict := NewDictionary().WithMode("content", IndexTime|QueryTime)
+	dict.Add(AllFields, "go", "golang")
+	keywords := []*types.Keyword{{Field: "content", Word: "go"}, {Field: "content", Word: "golang"}, {Field: "author", Word: "go"}}
+	got := dict.ExpandKeywords(keywords)
+	if len(got) != len(keywords) {
+		t.Fatalf("同义词已经存在时不应重复添加，实际为 %v", got)
+	}
+	got = dict.ExpandKeywords(keywords[:1])
+	expect := []*types.Keyword{{Field: "content", Word: "go"}, {Field: "content", Word: "golang"}}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应补充为 %v，实际为 %v", expect, got)
+	}
+	// author 没有配置为写入时展开
+	if got := dict.ExpandKeywords(keywords[2:]); len(got) != 1 {
+		t.Fatalf("author 不应在写入时展开，实际为 %v", got)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	if err := os.WriteFile(path, []byte("go, golang\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadDictionary(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dict.Close()
+	if got := dict.Synonyms("content", "go"); !reflect.DeepEqual(got, []string{"go", "golang"}) {
+		t.Fatalf("go 的同义词不正确: %v", got)
+	}
+
+	// 文件变化后自动重新加载
+	if err := os.WriteFile(path, []byte("go, golang, go语言\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for len(dict.Synonyms("content", "go")) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("没有重新加载词典: %v", dict.Synonyms("content", "go"))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	dict.Close()
+
+	// 解析失败时继续使用旧的词典
+	dict, err = LoadDictionary(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("[content\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := dict.Reload(); err == nil {
+		t.Fatal("解析失败时应返回错误")
+	}
+	if got := dict.Synonyms("content", "go"); len(got) != 3 {
+		t.Fatalf("解析失败时应保留旧的词典，实际为 %v", got)
+	}
+}
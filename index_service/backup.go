@@ -0,0 +1,152 @@
+package index_service
+
+import (
+	"bufio"
+	"errors"
+	"github.com/jmh000527/criker-search/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// backupChunkSize Backup 每个分片的大小
+const backupChunkSize = 1 << 20
+
+// chunkWriter 把写入的数据作为 BackupChunk 发送出去
+type chunkWriter struct {
+	stream IndexService_BackupServer
+}
+
+func (w chunkWriter) Write(p []byte) (int, error) {
+	if err := w.stream.Send(&BackupChunk{Data: p}); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Backup 在线备份正排索引，备份期间 worker 继续处理读写请求。
+// 第一个分片只包含备份开始时变更日志的序号，之后是备份数据。
+// 序号在备份开始之前读取，快照中可能已经包含序号之后的部分变更；由于变更是幂等的（覆盖写入或删除），从副本从该序号开始重放不会出错。
+//
+// 参数:
+//   - request: 备份请求。
+//   - stream: 发送备份数据的流。
+//
+// 返回值:
+//   - error: 备份或发送失败时返回错误。
+func (w *IndexServiceWorker) Backup(request *BackupRequest, stream IndexService_BackupServer) error {
+	seq := w.ChangeLogSeq()
+	if err := stream.Send(&BackupChunk{ChangeLogSeq: seq}); err != nil {
+		return err
+	}
+	writer := bufio.NewWriterSize(chunkWriter{stream: stream}, backupChunkSize)
+	if err := w.Indexer.Backup(writer); err != nil {
+		utils.Log.Printf("备份索引失败: %v", err)
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	utils.Log.Printf("备份索引完成，变更日志序号为 %d", seq)
+	return nil
+}
+
+// Restore 用备份替换本 worker 的索引。恢复期间拒绝其他请求；正在跟随主副本时先暂停，恢复完成后从备份对应的序号继续同步，
+// 因此可以用主副本的备份为新的从副本准备数据。
+//
+// 参数:
+//   - stream: 接收备份数据的流，第一个分片中的 ChangeLogSeq 是备份对应的变更日志序号。
+//
+// 返回值:
+//   - error: 等待在途请求超时返回 Unavailable，恢复失败时返回相应的错误。
+func (w *IndexServiceWorker) Restore(stream IndexService_RestoreServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	// 暂停跟随主副本，避免恢复期间继续应用变更。恢复期间分片表的变化等恢复结束后再应用
+	w.followMu.Lock()
+	defer w.followMu.Unlock()
+	primary := w.primaryAddr()
+	w.pauseFollowing()
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+
+	// 拒绝新的请求，并等待除本请求之外的在途请求完成。恢复结束后重新接受请求，除非索引与变更日志已经不一致
+	w.ready.Store(false)
+	failClosed := false
+	defer func() {
+		if !failClosed {
+			w.ready.Store(true)
+		}
+	}()
+	if !w.waitInflight(1, w.effectiveDrainTimeout()) {
+		w.resumeFollowing(primary)
+		return status.Error(codes.Unavailable, "等待在途请求完成超时，无法恢复索引")
+	}
+
+	reader, writer := io.Pipe()
+	go func() {
+		// 把流中的数据分片转为 io.Reader 交给 KeyValueDB.Restore
+		if _, err := writer.Write(first.Data); err != nil {
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				writer.Close()
+				return
+			}
+			if err != nil {
+				writer.CloseWithError(err)
+				return
+			}
+			if _, err := writer.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+	n, err := w.Indexer.Restore(reader)
+	// 读取出错时让发送数据的协程退出
+	reader.CloseWithError(errors.New("恢复已结束"))
+	if err != nil {
+		utils.Log.Printf("从备份恢复索引失败: %v", err)
+		w.resumeFollowing(primary)
+		return err
+	}
+	if w.changeLog != nil {
+		if err := w.changeLog.reset(first.ChangeLogSeq); err != nil {
+			// 索引已经替换为备份，变更日志却仍停留在旧的序号，继续跟随主副本或接受请求都会使数据不一致，
+			// 保持未就绪且不再跟随主副本，需要重新恢复或重启
+			utils.Log.Printf("重置变更日志失败，worker 保持未就绪: %v", err)
+			failClosed = true
+			return err
+		}
+	}
+	utils.Log.Printf("从备份恢复了 %d 个文档，变更日志序号为 %d", n, first.ChangeLogSeq)
+	w.resumeFollowing(primary)
+	return stream.SendAndClose(&AffectedCount{Count: int32(n)})
+}
+
+// effectiveDrainTimeout 等待在途请求完成的最长时间
+func (w *IndexServiceWorker) effectiveDrainTimeout() time.Duration {
+	if w.drainTimeout <= 0 {
+		return DefaultDrainTimeout
+	}
+	return w.drainTimeout
+}
+
+// waitInflight 等待在途请求数降到 n 以下（含 n），超时返回 false
+func (w *IndexServiceWorker) waitInflight(n int64, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for atomic.LoadInt64(&w.inflight) > n {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
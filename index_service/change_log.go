@@ -0,0 +1,238 @@
+package index_service
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/jmh000527/criker-search/utils"
+	bolt "go.etcd.io/bbolt"
+	"sync"
+)
+
+const (
+	DefaultChangeLogRetain = 100000 // 变更日志默认保留最近多少条变更
+
+	changeLogPruneInterval = 1000 // 每追加多少条变更清理一次过期的变更
+)
+
+var (
+	changeLogBucket = []byte("changes")
+	changeLogMeta   = []byte("meta")
+	baseSeqKey      = []byte("base_seq") // reset 之后日志从哪个序号之后开始，日志为空时据此恢复最大序号
+
+	// errChangeLogTruncated 订阅的起始序号之后的部分变更已被清理，从副本无法增量同步，只能从备份或原始数据重建
+	errChangeLogTruncated = errors.New("变更日志已被截断")
+)
+
+// changeLog 持久化的文档变更日志。
+// 主副本按写入顺序为每个 AddDoc/DeleteDoc 分配递增的序号并追加到日志中，从副本据此增量同步；
+// 从副本也把应用过的变更按原序号追加到自己的日志中，重启后从最大序号处继续同步，被提升为主副本后还可以继续为其他从副本提供变更。
+// 日志保存在一个独立的 Bolt 文件中，key 为大端编码的序号，保证按序号有序遍历。
+type changeLog struct {
+	db     *bolt.DB
+	retain uint64 // 保留最近多少条变更
+
+	mu      sync.Mutex
+	lastSeq uint64        // 已追加的最大序号
+	notify  chan struct{} // 每次追加后关闭并替换，用于唤醒等待新变更的订阅者
+	done    chan struct{} // shutdown 时关闭
+}
+
+// openChangeLog 打开或创建变更日志。
+//
+// 参数:
+//   - path: 日志文件的路径。
+//   - retain: 保留最近多少条变更，<=0 时使用 DefaultChangeLogRetain。
+//
+// 返回值:
+//   - *changeLog: 打开的变更日志。
+//   - error: 打开文件失败时返回错误。
+func openChangeLog(path string, retain int) (*changeLog, error) {
+	if retain <= 0 {
+		retain = DefaultChangeLogRetain
+	}
+	db, err := bolt.Open(path, 0600, bolt.DefaultOptions)
+	if err != nil {
+		return nil, err
+	}
+	log := &changeLog{
+		db:     db,
+		retain: uint64(retain),
+		notify: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists(changeLogBucket)
+		if err != nil {
+			return err
+		}
+		meta, err := tx.CreateBucketIfNotExists(changeLogMeta)
+		if err != nil {
+			return err
+		}
+		if v := meta.Get(baseSeqKey); v != nil {
+			log.lastSeq = binary.BigEndian.Uint64(v)
+		}
+		if k, _ := bucket.Cursor().Last(); k != nil && binary.BigEndian.Uint64(k) > log.lastSeq {
+			log.lastSeq = binary.BigEndian.Uint64(k)
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	utils.Log.Printf("打开变更日志 %s，最大序号为 %d", path, log.lastSeq)
+	return log, nil
+}
+
+// LastSeq 已追加的最大序号，日志为空时返回0
+func (log *changeLog) LastSeq() uint64 {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return log.lastSeq
+}
+
+// append 为变更分配下一个序号并追加到日志中（主副本使用）
+func (log *changeLog) append(event *ChangeEvent) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	event.Seq = log.lastSeq + 1
+	return log.put(event)
+}
+
+// appendAt 按主副本分配的序号追加变更（从副本使用），序号必须大于已追加的最大序号
+func (log *changeLog) appendAt(event *ChangeEvent) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	if event.Seq <= log.lastSeq {
+		return fmt.Errorf("变更的序号 %d 不大于已追加的最大序号 %d", event.Seq, log.lastSeq)
+	}
+	return log.put(event)
+}
+
+// put 写入一条变更，唤醒所有订阅者，必要时清理过期的变更。调用方需持有 mu。
+func (log *changeLog) put(event *ChangeEvent) error {
+	value, err := event.Marshal()
+	if err != nil {
+		return err
+	}
+	err = log.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(changeLogBucket)
+		if err := bucket.Put(seqKey(event.Seq), value); err != nil {
+			return err
+		}
+		if event.Seq%changeLogPruneInterval == 0 && event.Seq > log.retain {
+			// 删除序号不大于 event.Seq-retain 的变更
+			c := bucket.Cursor()
+			for k, _ := c.First(); k != nil && binary.BigEndian.Uint64(k) <= event.Seq-log.retain; k, _ = c.Next() {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.lastSeq = event.Seq
+	close(log.notify)
+	log.notify = make(chan struct{})
+	return nil
+}
+
+// reset 清空日志，之后从 seq+1 开始追加。用备份恢复索引后调用，seq 是备份对应的序号。
+func (log *changeLog) reset(seq uint64) error {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	err := log.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(changeLogBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(changeLogBucket); err != nil {
+			return err
+		}
+		return tx.Bucket(changeLogMeta).Put(baseSeqKey, seqKey(seq))
+	})
+	if err != nil {
+		return err
+	}
+	log.lastSeq = seq
+	return nil
+}
+
+// wait 返回一个在下一次追加变更后关闭的 channel。应在读取变更之前获取，避免错过读取之后、等待之前追加的变更。
+func (log *changeLog) wait() <-chan struct{} {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return log.notify
+}
+
+// read 按序号顺序读取序号大于 fromSeq 的所有变更，逐条回调 fn，fn 返回错误时停止读取并返回该错误。
+//
+// 参数:
+//   - fromSeq: 已经读取过的最大序号。
+//   - fn: 处理每条变更的回调函数。
+//
+// 返回值:
+//   - error: fromSeq 之后的变更已被清理时返回 errChangeLogTruncated。
+func (log *changeLog) read(fromSeq uint64, fn func(event *ChangeEvent) error) error {
+	// 在只读事务中解码变更，回调在事务之外执行，避免发送缓慢时长时间占用事务
+	const batch = 1000
+	lastSeq := log.LastSeq()
+	for {
+		events := make([]*ChangeEvent, 0, batch)
+		err := log.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(changeLogBucket).Cursor()
+			k, _ := c.First()
+			if (k != nil && binary.BigEndian.Uint64(k) > fromSeq+1) || (k == nil && fromSeq < lastSeq) {
+				return errChangeLogTruncated
+			}
+			for k, v := c.Seek(seqKey(fromSeq + 1)); k != nil && len(events) < batch; k, v = c.Next() {
+				event := new(ChangeEvent)
+				if err := event.Unmarshal(v); err != nil {
+					return err
+				}
+				events = append(events, event)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			if err := fn(event); err != nil {
+				return err
+			}
+			fromSeq = event.Seq
+		}
+		if len(events) < batch {
+			return nil
+		}
+	}
+}
+
+// shutdown 通知所有订阅者退出，之后不再有新的订阅
+func (log *changeLog) shutdown() {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	select {
+	case <-log.done:
+	default:
+		close(log.done)
+	}
+}
+
+// Close 关闭日志文件
+func (log *changeLog) Close() error {
+	log.shutdown()
+	return log.db.Close()
+}
+
+// seqKey 把序号编码为大端字节序，使 Bolt 中的 key 顺序与序号顺序一致
+func seqKey(seq uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key
+}
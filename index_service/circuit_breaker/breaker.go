@@ -0,0 +1,248 @@
+package circuit_breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// State 熔断器的状态
+type State int32
+
+const (
+	Closed   State = iota // 关闭：请求正常放行
+	Open                  // 打开：节点被熔断，请求不再发往该节点
+	HalfOpen              // 半开：熔断时间已过，放行少量试探请求，全部成功才恢复为关闭
+)
+
+// String 返回状态的字符串表示
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config 熔断和异常节点驱逐的配置
+type Config struct {
+	FailureThreshold   int           // 连续失败多少次后熔断
+	OpenTimeout        time.Duration // 熔断多久之后进入半开状态
+	HalfOpenMaxCalls   int           // 半开状态下连续成功多少次后恢复为关闭，同时也是半开状态下最多同时在途的试探请求数
+	WindowSize         int           // 错误率统计窗口，即最近多少次请求
+	MinRequests        int           // 窗口内至少有多少次请求才计算错误率和延迟
+	ErrorRateThreshold float64       // 窗口内错误率达到该值时驱逐节点
+	LatencyFactor      float64       // 节点的平均延迟超过所有节点延迟中位数的多少倍时驱逐节点
+	MinLatency         time.Duration // 平均延迟低于该值的节点不会因为慢而被驱逐
+	EwmaDecay          float64       // 延迟指数加权移动平均的衰减系数，越大越看重最近的请求
+	MaxEjectionPercent float64       // 最多同时驱逐多少比例的节点，避免全部节点都被驱逐
+}
+
+// DefaultConfig 返回默认的熔断配置
+func DefaultConfig() Config {
+	return Config{
+		FailureThreshold:   5,
+		OpenTimeout:        10 * time.Second,
+		HalfOpenMaxCalls:   3,
+		WindowSize:         100,
+		MinRequests:        20,
+		ErrorRateThreshold: 0.5,
+		LatencyFactor:      3,
+		MinLatency:         50 * time.Millisecond,
+		EwmaDecay:          0.1,
+		MaxEjectionPercent: 0.5,
+	}
+}
+
+// Breaker 单个节点的熔断器，同时统计该节点最近的错误率和延迟。
+type Breaker struct {
+	config Config
+	mu     sync.Mutex
+
+	state               State
+	openedAt            time.Time // 最近一次熔断的时间
+	consecutiveFailures int       // 连续失败次数
+	halfOpenSuccesses   int       // 半开状态下的连续成功次数
+	halfOpenInFlight    int       // 半开状态下在途的试探请求数
+
+	window   []bool        // 最近 WindowSize 次请求是否失败，环形数组
+	next     int           // 环形数组的下一个写入位置
+	failures int           // 窗口内的失败次数
+	ewma     time.Duration // 成功请求延迟的指数加权移动平均
+	samples  int           // 参与 ewma 计算的请求数
+}
+
+// NewBreaker 创建一个处于关闭状态的熔断器
+func NewBreaker(config Config) *Breaker {
+	return &Breaker{
+		config: config,
+		window: make([]bool, 0, config.WindowSize),
+	}
+}
+
+// State 返回熔断器当前的状态。熔断时间已过的打开状态会被视为半开。
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	return b.state
+}
+
+// Available 判断当前是否可以向该节点发送请求。半开状态下在途的试探请求达到 HalfOpenMaxCalls 时不再放行。
+func (b *Breaker) Available() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	switch b.state {
+	case Open:
+		return false
+	case HalfOpen:
+		return b.halfOpenInFlight < b.config.HalfOpenMaxCalls
+	default:
+		return true
+	}
+}
+
+// begin 在向节点发出请求之前调用，半开状态下占用一个试探名额，直到 record 或 release 归还。
+// 试探名额已用完时返回 false，调用方不应再发出请求。
+func (b *Breaker) begin() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	switch b.state {
+	case Open:
+		return false
+	case HalfOpen:
+		if b.halfOpenInFlight >= b.config.HalfOpenMaxCalls {
+			return false
+		}
+		b.halfOpenInFlight++
+	}
+	return true
+}
+
+// release 归还 begin 占用的试探名额，用于结果不参与统计的请求
+func (b *Breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+	b.releaseProbe()
+}
+
+// Latency 返回成功请求延迟的指数加权移动平均，以及参与统计的请求数
+func (b *Breaker) Latency() (time.Duration, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ewma, b.samples
+}
+
+// Trip 熔断该节点
+func (b *Breaker) Trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trip()
+}
+
+// record 记录一次请求的结果，返回是否应当熔断该节点。半开状态下的结果会直接改变状态。
+func (b *Breaker) record(latency time.Duration, failed bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refresh()
+
+	switch b.state {
+	case Open:
+		// 熔断期间仍在途的请求，结果不再统计
+		return false
+	case HalfOpen:
+		b.releaseProbe()
+		if failed {
+			// 试探失败，重新熔断
+			b.trip()
+			return false
+		}
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.config.HalfOpenMaxCalls {
+			// 试探全部成功，恢复为关闭状态并清空统计
+			b.reset()
+		}
+		b.observeLatency(latency)
+		return false
+	}
+
+	// 关闭状态：更新窗口统计
+	if len(b.window) < b.config.WindowSize {
+		b.window = append(b.window, failed)
+	} else {
+		if b.window[b.next] {
+			b.failures--
+		}
+		b.window[b.next] = failed
+		b.next = (b.next + 1) % b.config.WindowSize
+	}
+	if failed {
+		b.failures++
+		b.consecutiveFailures++
+	} else {
+		b.consecutiveFailures = 0
+		b.observeLatency(latency)
+	}
+
+	if b.consecutiveFailures >= b.config.FailureThreshold {
+		return true
+	}
+	if len(b.window) >= b.config.MinRequests && float64(b.failures)/float64(len(b.window)) >= b.config.ErrorRateThreshold {
+		return true
+	}
+	return false
+}
+
+// observeLatency 更新延迟的指数加权移动平均
+func (b *Breaker) observeLatency(latency time.Duration) {
+	if b.samples == 0 {
+		b.ewma = latency
+	} else {
+		b.ewma = time.Duration(b.config.EwmaDecay*float64(latency) + (1-b.config.EwmaDecay)*float64(b.ewma))
+	}
+	b.samples++
+}
+
+// releaseProbe 半开状态下归还一个试探名额。调用方需持有锁。
+func (b *Breaker) releaseProbe() {
+	if b.state == HalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+}
+
+// refresh 熔断时间已过时，把打开状态切换为半开状态。调用方需持有锁。
+func (b *Breaker) refresh() {
+	if b.state == Open && time.Since(b.openedAt) >= b.config.OpenTimeout {
+		b.state = HalfOpen
+		b.halfOpenSuccesses = 0
+		b.halfOpenInFlight = 0
+	}
+}
+
+// trip 熔断节点。调用方需持有锁。
+func (b *Breaker) trip() {
+	b.state = Open
+	b.openedAt = time.Now()
+	b.halfOpenSuccesses = 0
+	b.halfOpenInFlight = 0
+}
+
+// reset 恢复为关闭状态并清空统计。调用方需持有锁。
+func (b *Breaker) reset() {
+	b.state = Closed
+	b.consecutiveFailures = 0
+	b.halfOpenSuccesses = 0
+	b.halfOpenInFlight = 0
+	b.window = b.window[:0]
+	b.next = 0
+	b.failures = 0
+	b.ewma = 0
+	b.samples = 0
+}
@@ -0,0 +1,101 @@
+package circuit_breaker
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBreaker(t *testing.T) {
+	config := DefaultConfig()
+	config.OpenTimeout = 50 * time.Millisecond
+	tracker := NewHealthTracker(config)
+	endpoint := "127.0.0.1:5000"
+
+	// 连续失败达到阈值后熔断
+	for i := 0; i < config.FailureThreshold; i++ {
+		tracker.Report(endpoint, time.Millisecond, errors.New("connection refused"))
+	}
+	if tracker.State(endpoint) != Open {
+		t.Fatalf("连续失败 %d 次后应处于打开状态，实际为 %s", config.FailureThreshold, tracker.State(endpoint))
+	}
+	if tracker.IsAvailable(endpoint) {
+		t.Fatal("被熔断的节点不应可用")
+	}
+
+	// 所有节点都被熔断时，Filter 原样返回
+	if len(tracker.Filter([]string{endpoint})) != 1 {
+		t.Fatal("所有节点都被熔断时 Filter 应返回全部节点")
+	}
+
+	// 熔断时间过后进入半开状态，试探成功后恢复
+	time.Sleep(config.OpenTimeout)
+	if tracker.State(endpoint) != HalfOpen {
+		t.Fatalf("熔断时间过后应处于半开状态，实际为 %s", tracker.State(endpoint))
+	}
+	for i := 0; i < config.HalfOpenMaxCalls; i++ {
+		tracker.Report(endpoint, time.Millisecond, nil)
+	}
+	if tracker.State(endpoint) != Closed {
+		t.Fatalf("试探成功后应处于关闭状态，实际为 %s", tracker.State(endpoint))
+	}
+}
+
+func TestLatencyOutlier(t *testing.T) {
+	config := DefaultConfig()
+	tracker := NewHealthTracker(config)
+	fast := []string{"127.0.0.1:5000", "127.0.0.1:5001", "127.0.0.1:5002"}
+	slow := "127.0.0.1:5003"
+
+	for i := 0; i < config.MinRequests; i++ {
+		for _, endpoint := range fast {
+			tracker.Report(endpoint, 60*time.Millisecond, nil)
+		}
+		tracker.Report(slow, time.Second, nil)
+	}
+	if tracker.IsAvailable(slow) {
+		t.Fatal("延迟明显高于其他节点的节点应被驱逐")
+	}
+	available := tracker.Filter(append(fast, slow))
+	if len(available) != len(fast) {
+		t.Fatalf("Filter 应剔除被驱逐的节点，实际返回 %v", available)
+	}
+}
+
+func TestHalfOpenProbeBudget(t *testing.T) {
+	config := DefaultConfig()
+	config.OpenTimeout = 50 * time.Millisecond
+	tracker := NewHealthTracker(config)
+	endpoint := "127.0.0.1:5000"
+	for i := 0; i < config.FailureThreshold; i++ {
+		tracker.Report(endpoint, time.Millisecond, errors.New("connection refused"))
+	}
+	if tracker.Begin(endpoint) {
+		t.Fatal("被熔断的节点不应放行请求")
+	}
+
+	// 半开状态下最多同时放行 HalfOpenMaxCalls 个试探请求
+	time.Sleep(config.OpenTimeout)
+	for i := 0; i < config.HalfOpenMaxCalls; i++ {
+		if !tracker.Begin(endpoint) {
+			t.Fatalf("第 %d 个试探请求应被放行", i+1)
+		}
+	}
+	if tracker.Begin(endpoint) || tracker.IsAvailable(endpoint) {
+		t.Fatal("试探名额用完后不应再放行请求")
+	}
+	// 业务错误不参与统计，但会归还试探名额
+	tracker.Report(endpoint, time.Millisecond, status.Error(codes.NotFound, "not found"))
+	if !tracker.IsAvailable(endpoint) || !tracker.Begin(endpoint) {
+		t.Fatal("归还试探名额后应放行请求")
+	}
+	for i := 0; i < config.HalfOpenMaxCalls; i++ {
+		tracker.Report(endpoint, time.Millisecond, nil)
+	}
+	if tracker.State(endpoint) != Closed || !tracker.Begin(endpoint) {
+		t.Fatalf("试探成功后应处于关闭状态，实际为 %s", tracker.State(endpoint))
+	}
+}
@@ -0,0 +1,180 @@
+package circuit_breaker
+
+import (
+	"github.com/jmh000527/criker-search/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HealthTracker 按 endpoint 跟踪各个节点的健康状况。
+// 每个 endpoint 对应一个熔断器：连续失败或错误率过高的节点会被熔断，
+// 平均延迟明显高于其他节点的节点会作为异常节点被驱逐，被驱逐的节点在熔断时间过后以半开状态接受试探请求。
+type HealthTracker struct {
+	config   Config
+	breakers sync.Map // endpoint -> *Breaker
+}
+
+// NewHealthTracker 创建一个 HealthTracker
+//
+// 参数:
+//   - config: 熔断和异常节点驱逐的配置。
+//
+// 返回值:
+//   - *HealthTracker: 新创建的 HealthTracker 实例。
+func NewHealthTracker(config Config) *HealthTracker {
+	return &HealthTracker{config: config}
+}
+
+// IsFailure 判断一次调用的错误是否说明节点不健康。
+// 调用方主动取消、参数错误等业务错误不计入节点的失败次数。
+func IsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		// 非 gRPC 错误（例如建立连接失败），视为节点不健康
+		return true
+	}
+	switch s.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Internal, codes.DataLoss:
+		return true
+	default:
+		return false
+	}
+}
+
+// Report 记录一次对 endpoint 的调用结果。
+//
+// 参数:
+//   - endpoint: 被调用的节点地址。
+//   - latency: 本次调用的耗时。
+//   - err: 本次调用返回的错误，nil 表示成功。
+func (t *HealthTracker) Report(endpoint string, latency time.Duration, err error) {
+	b := t.breaker(endpoint)
+	failed := IsFailure(err)
+	if err != nil && !failed {
+		// 主动取消、业务错误等既不说明节点不健康，其耗时也不代表节点的处理能力，不参与统计
+		b.release()
+		return
+	}
+	if b.record(latency, failed) {
+		t.eject(endpoint, b, "连续失败或错误率过高")
+		return
+	}
+	if !failed {
+		t.checkLatencyOutlier(endpoint, b)
+	}
+}
+
+// Begin 在向 endpoint 发出请求之前调用，节点处于半开状态时占用一个试探名额，每次 Begin 都应对应一次 Report。
+//
+// 参数:
+//   - endpoint: 被调用的节点地址。
+//
+// 返回值:
+//   - bool: 节点被熔断或半开状态下的试探名额已用完时返回 false。
+func (t *HealthTracker) Begin(endpoint string) bool {
+	v, exists := t.breakers.Load(endpoint)
+	if !exists {
+		return true
+	}
+	return v.(*Breaker).begin()
+}
+
+// IsAvailable 判断 endpoint 当前是否可以接收请求
+func (t *HealthTracker) IsAvailable(endpoint string) bool {
+	v, exists := t.breakers.Load(endpoint)
+	if !exists {
+		return true
+	}
+	return v.(*Breaker).Available()
+}
+
+// State 返回 endpoint 的熔断器状态
+func (t *HealthTracker) State(endpoint string) State {
+	v, exists := t.breakers.Load(endpoint)
+	if !exists {
+		return Closed
+	}
+	return v.(*Breaker).State()
+}
+
+// Filter 从 endpoints 中剔除已被熔断的节点。
+// 如果所有节点都被熔断，则原样返回 endpoints，宁可把请求发往可能不健康的节点，也不要完全不可用。
+func (t *HealthTracker) Filter(endpoints []string) []string {
+	available := make([]string, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		if t.IsAvailable(endpoint) {
+			available = append(available, endpoint)
+		}
+	}
+	if len(available) == 0 {
+		return endpoints
+	}
+	return available
+}
+
+// Forget 删除 endpoint 的健康状况，用于节点下线之后
+func (t *HealthTracker) Forget(endpoint string) {
+	t.breakers.Delete(endpoint)
+}
+
+// breaker 获取 endpoint 对应的熔断器，不存在则创建
+func (t *HealthTracker) breaker(endpoint string) *Breaker {
+	if v, exists := t.breakers.Load(endpoint); exists {
+		return v.(*Breaker)
+	}
+	v, _ := t.breakers.LoadOrStore(endpoint, NewBreaker(t.config))
+	return v.(*Breaker)
+}
+
+// checkLatencyOutlier 如果 endpoint 的平均延迟明显高于所有节点延迟的中位数，则驱逐该节点
+func (t *HealthTracker) checkLatencyOutlier(endpoint string, b *Breaker) {
+	latency, samples := b.Latency()
+	if samples < t.config.MinRequests || latency < t.config.MinLatency {
+		return
+	}
+	latencies := make([]time.Duration, 0)
+	t.breakers.Range(func(key, value any) bool {
+		l, n := value.(*Breaker).Latency()
+		if n >= t.config.MinRequests {
+			latencies = append(latencies, l)
+		}
+		return true
+	})
+	// 至少要有 3 个节点可供比较，中位数才有意义
+	if len(latencies) < 3 {
+		return
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	median := latencies[len(latencies)/2]
+	if float64(latency) > t.config.LatencyFactor*float64(median) {
+		t.eject(endpoint, b, "延迟过高")
+	}
+}
+
+// eject 在不超过最大驱逐比例的前提下熔断 endpoint
+func (t *HealthTracker) eject(endpoint string, b *Breaker, reason string) {
+	total, ejected := 0, 0
+	t.breakers.Range(func(key, value any) bool {
+		total++
+		if value.(*Breaker).State() == Open {
+			ejected++
+		}
+		return true
+	})
+	limit := int(t.config.MaxEjectionPercent * float64(total))
+	if limit < 1 {
+		limit = 1
+	}
+	if ejected >= limit {
+		utils.Log.Printf("节点 %s %s，但已驱逐 %d/%d 个节点，达到上限，暂不驱逐", endpoint, reason, ejected, total)
+		return
+	}
+	b.Trip()
+	utils.Log.Printf("节点 %s %s，熔断 %v", endpoint, reason, t.config.OpenTimeout)
+}
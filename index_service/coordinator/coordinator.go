@@ -0,0 +1,310 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/utils"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	electionPrefix = service_hub.ServiceRootPath + "/coordinator/election"  // 选举使用的key前缀
+	ShardMapKey    = service_hub.ServiceRootPath + "/coordinator/shard_map" // 分片表保存的位置
+
+	DefaultCheckInterval = 3 * time.Second // 默认每隔多久检查一次worker的变化
+	DefaultSessionTTL    = 5               // 默认的选举会话TTL，单位：秒。leader宕机后最多经过这么久会选出新的leader
+)
+
+// ErrNotLeader 当前coordinator不是leader，写分片表时leader已经变更
+var ErrNotLeader = errors.New("coordinator不是leader")
+
+// Config coordinator的配置
+type Config struct {
+	Service       string        // 需要分配分片的服务名称
+	NumShards     int           // 分片数量
+	Replicas      int           // 每个分片除主副本外的从副本数量
+	CheckInterval time.Duration // 每隔多久检查一次worker的变化
+	SessionTTL    int           // 选举会话的TTL，单位：秒
+}
+
+// Coordinator 集群协调者。多个coordinator通过etcd选举出一个leader，
+// 由leader维护分片表：为新注册的worker分配分片，worker下线时提升从副本并重新分配。
+type Coordinator struct {
+	id     string                 // 本coordinator的标识，参与选举时写入etcd
+	client *etcdv3.Client         // etcd客户端，用于选举和读写分片表
+	hub    service_hub.ServiceHub // 用于发现存活的worker
+	config Config
+
+	leader   atomic.Bool // 当前是否是leader
+	mu       sync.RWMutex
+	shardMap *ShardMap // leader维护的分片表，非leader时为最近一次读到的分片表
+}
+
+// NewCoordinator Coordinator的构造函数。
+//
+// 参数:
+//   - etcdServers: etcd服务器的地址列表，用于选举和保存分片表。
+//   - hub: 服务发现，用于获取存活的worker。
+//   - id: 本coordinator的标识，通常使用本机地址。
+//   - config: 配置，CheckInterval和SessionTTL为0时使用默认值。
+//
+// 返回值:
+//   - *Coordinator: 创建的Coordinator。
+//   - error: 连接etcd失败或配置不合法时返回错误。
+func NewCoordinator(etcdServers []string, hub service_hub.ServiceHub, id string, config Config) (*Coordinator, error) {
+	if config.NumShards <= 0 {
+		return nil, errors.New("分片数量必须大于0")
+	}
+	if config.Replicas < 0 {
+		return nil, errors.New("副本数量不能小于0")
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = DefaultCheckInterval
+	}
+	if config.SessionTTL <= 0 {
+		config.SessionTTL = DefaultSessionTTL
+	}
+	client, err := etcdv3.New(etcdv3.Config{
+		Endpoints:   etcdServers,
+		DialTimeout: 3 * time.Second,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Coordinator{
+		id:       id,
+		client:   client,
+		hub:      hub,
+		config:   config,
+		shardMap: NewShardMap(config.NumShards),
+	}, nil
+}
+
+// IsLeader 当前coordinator是否是leader
+func (c *Coordinator) IsLeader() bool {
+	return c.leader.Load()
+}
+
+// ShardMap 返回分片表的副本
+func (c *Coordinator) ShardMap() ShardMap {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.shardMap.clone()
+}
+
+// Run 参与选举，当选后维护分片表，直到ctx被取消。会话过期（例如与etcd断开太久）后失去leader身份，重新参与选举。
+//
+// 参数:
+//   - ctx: 取消时退出选举并返回。
+//
+// 返回值:
+//   - error: ctx被取消时返回nil。
+func (c *Coordinator) Run(ctx context.Context) error {
+	for ctx.Err() == nil {
+		if err := c.campaign(ctx); err != nil && ctx.Err() == nil {
+			utils.Log.Printf("coordinator %s 选举失败，%v 后重试: %v", c.id, c.config.CheckInterval, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(c.config.CheckInterval):
+			}
+		}
+	}
+	return nil
+}
+
+// Close 关闭etcd客户端。应先取消传给Run的ctx，Run退出时会主动放弃leader身份。
+func (c *Coordinator) Close() error {
+	return c.client.Close()
+}
+
+// campaign 创建一个选举会话并参与选举，当选后维护分片表，直到会话过期或ctx被取消
+func (c *Coordinator) campaign(ctx context.Context) error {
+	session, err := concurrency.NewSession(c.client, concurrency.WithTTL(c.config.SessionTTL), concurrency.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	election := concurrency.NewElection(session, electionPrefix)
+	// 阻塞直到当选，或ctx被取消
+	if err := election.Campaign(ctx, c.id); err != nil {
+		return err
+	}
+	c.leader.Store(true)
+	defer c.leader.Store(false)
+	utils.Log.Printf("coordinator %s 当选为leader", c.id)
+
+	defer func() {
+		// 主动放弃leader身份，其他coordinator无需等待会话过期就能当选
+		resignCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		if err := election.Resign(resignCtx); err != nil {
+			utils.Log.Printf("coordinator %s 放弃leader身份失败: %v", c.id, err)
+		}
+	}()
+
+	if err := c.loadShardMap(ctx); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(c.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.reconcile(ctx, election); err != nil {
+			if errors.Is(err, ErrNotLeader) {
+				return err
+			}
+			utils.Log.Printf("coordinator %s 更新分片表失败: %v", c.id, err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-session.Done():
+			utils.Log.Printf("coordinator %s 的选举会话已过期，失去leader身份", c.id)
+			return errors.New("选举会话已过期")
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadShardMap 当选后从etcd读取上一任leader保存的分片表，etcd中没有分片表时从空表开始
+func (c *Coordinator) loadShardMap(ctx context.Context) error {
+	shardMap, err := LoadShardMap(ctx, c.client)
+	if err != nil {
+		return err
+	}
+	if shardMap == nil {
+		shardMap = NewShardMap(c.config.NumShards)
+	} else if len(shardMap.Shards) != c.config.NumShards {
+		// 暂不支持在线调整分片数量，分片数量以etcd中保存的为准
+		utils.Log.Printf("etcd中的分片表有 %d 个分片，与配置的 %d 个不一致，以etcd中的为准", len(shardMap.Shards), c.config.NumShards)
+	}
+	c.mu.Lock()
+	c.shardMap = shardMap
+	c.mu.Unlock()
+	return nil
+}
+
+// reconcile 根据存活的worker调整分片表，有变化时写入etcd。
+// 写入时要求选举key仍然存在，防止已经失去leader身份的coordinator覆盖新leader的分片表。
+func (c *Coordinator) reconcile(ctx context.Context, election *concurrency.Election) error {
+	alive := c.hub.GetServiceInstances(c.config.Service)
+	if alive == nil {
+		// 服务发现失败（例如被限流）与没有worker无法区分，此时不调整分片表，避免误判所有worker下线
+		return nil
+	}
+
+	c.mu.RLock()
+	next := c.shardMap.clone()
+	c.mu.RUnlock()
+	if !next.Rebalance(alive, c.config.Replicas) {
+		return nil
+	}
+
+	value, err := json.Marshal(next)
+	if err != nil {
+		return err
+	}
+	leaderKey := election.Key()
+	response, err := c.client.Txn(ctx).
+		If(etcdv3.Compare(etcdv3.CreateRevision(leaderKey), "=", election.Rev())).
+		Then(etcdv3.OpPut(ShardMapKey, string(value))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !response.Succeeded {
+		return ErrNotLeader
+	}
+
+	c.mu.Lock()
+	c.shardMap = &next
+	c.mu.Unlock()
+	utils.Log.Printf("分片表已更新到epoch %d: %+v", next.Epoch, next.Shards)
+	return nil
+}
+
+// LoadShardMap 从etcd读取分片表，worker和Sentinel可以用它获取分片分配。
+//
+// 参数:
+//   - ctx: 控制读取超时。
+//   - client: etcd客户端。
+//
+// 返回值:
+//   - *ShardMap: 分片表，etcd中尚无分片表时返回nil。
+//   - error: 读取或解析失败时返回错误。
+func LoadShardMap(ctx context.Context, client *etcdv3.Client) (*ShardMap, error) {
+	response, err := client.Get(ctx, ShardMapKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Kvs) == 0 {
+		return nil, nil
+	}
+	shardMap := new(ShardMap)
+	if err := json.Unmarshal(response.Kvs[0].Value, shardMap); err != nil {
+		return nil, err
+	}
+	return shardMap, nil
+}
+
+// WatchShardMap 读取etcd中当前的分片表并持续监视它的变化，每次读到新的分片表时调用apply，直到ctx被取消。
+// 监视中断（例如与etcd断开）后会重新读取分片表并继续监视，apply收到的分片表的Epoch可能重复，但不会倒退。
+// worker和Sentinel通过它获取coordinator分配的分片。
+//
+// 参数:
+//   - ctx: 取消时停止监视并返回。
+//   - client: etcd客户端。
+//   - apply: 处理新的分片表，在调用WatchShardMap的协程中依次调用。
+func WatchShardMap(ctx context.Context, client *etcdv3.Client, apply func(shardMap *ShardMap)) {
+	var epoch int64 = -1
+	// update 只把比上一次更新的分片表交给apply
+	update := func(value []byte) {
+		shardMap := new(ShardMap)
+		if err := json.Unmarshal(value, shardMap); err != nil {
+			utils.Log.Printf("解析分片表失败: %v", err)
+			return
+		}
+		if shardMap.Epoch <= epoch {
+			return
+		}
+		epoch = shardMap.Epoch
+		apply(shardMap)
+	}
+
+	for ctx.Err() == nil {
+		response, err := client.Get(ctx, ShardMapKey)
+		if err != nil {
+			if ctx.Err() == nil {
+				utils.Log.Printf("读取分片表失败，%v 后重试: %v", DefaultCheckInterval, err)
+			}
+		} else {
+			if len(response.Kvs) > 0 {
+				update(response.Kvs[0].Value)
+			}
+			// 从读取时的revision之后开始监视，不会遗漏读取之后的修改
+			watchCtx, cancel := context.WithCancel(ctx)
+			for watchResponse := range client.Watch(watchCtx, ShardMapKey, etcdv3.WithRev(response.Header.Revision+1)) {
+				if err := watchResponse.Err(); err != nil {
+					utils.Log.Printf("监视分片表中断: %v", err)
+					break
+				}
+				for _, event := range watchResponse.Events {
+					if event.Type == etcdv3.EventTypePut {
+						update(event.Kv.Value)
+					}
+				}
+			}
+			cancel()
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(DefaultCheckInterval):
+		}
+	}
+}
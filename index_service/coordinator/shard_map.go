@@ -0,0 +1,180 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"sort"
+)
+
+// ShardAssignment 一个分片的副本分配
+type ShardAssignment struct {
+	ShardId  int      `json:"shard_id"`
+	Primary  string   `json:"primary"`  // 主副本所在的worker，为空表示该分片暂无可用的主副本
+	Replicas []string `json:"replicas"` // 从副本所在的worker
+}
+
+// ShardMap 分片表，记录每个分片由哪些worker负责。由coordinator的leader维护，保存在etcd中。
+type ShardMap struct {
+	Epoch  int64             `json:"epoch"`  // 每次修改后递增，worker和Sentinel据此判断分片表是否有更新
+	Shards []ShardAssignment `json:"shards"` // 按分片编号排列
+}
+
+// NewShardMap 创建一个包含numShards个空分片的分片表
+func NewShardMap(numShards int) *ShardMap {
+	m := &ShardMap{Shards: make([]ShardAssignment, numShards)}
+	for i := range m.Shards {
+		m.Shards[i].ShardId = i
+	}
+	return m
+}
+
+// ShardOf 返回endpoint负责的分片编号，以及它是否是主副本。endpoint未被分配时返回-1
+func (m *ShardMap) ShardOf(endpoint string) (int, bool) {
+	for _, shard := range m.Shards {
+		if shard.Primary == endpoint {
+			return shard.ShardId, true
+		}
+		for _, replica := range shard.Replicas {
+			if replica == endpoint {
+				return shard.ShardId, false
+			}
+		}
+	}
+	return -1, false
+}
+
+// Rebalance 根据当前存活的worker调整分片表，返回分片表是否发生变化。
+//   - 下线的worker从分片中移除；主副本下线时，提升该分片的第一个从副本为主副本。
+//   - 未分配的worker如果已经发布了分片和角色（例如通过WithShard配置，或者coordinator重启前已被分配），
+//     说明它上面已经有该分片的数据，只分配回该分片：声明为主副本且分片没有主副本时作为主副本，否则作为从副本；
+//     分片没有主副本时，声明为从副本的worker也会被提升为主副本。该分片已满员时作为备用，不会被分配到其他分片。
+//   - 其余未分配的worker优先分配给没有主副本的分片（编号小的优先），其次分配给从副本最少且不足replicas个的分片。
+//   - 所有分片都已满员时，多余的worker作为备用，等待其他worker下线后再分配。
+//
+// 参数:
+//   - alive: 当前存活的worker及其发布的元数据。
+//   - replicas: 每个分片除主副本外的从副本数量。
+//
+// 返回值:
+//   - bool: 分片表发生变化时返回true，此时Epoch已递增。
+func (m *ShardMap) Rebalance(alive []service_hub.ServiceInstance, replicas int) bool {
+	before, _ := json.Marshal(m.Shards)
+
+	aliveSet := make(map[string]struct{}, len(alive))
+	for _, instance := range alive {
+		aliveSet[instance.Endpoint] = struct{}{}
+	}
+	isAlive := func(endpoint string) bool {
+		_, exists := aliveSet[endpoint]
+		return exists
+	}
+
+	// 移除下线的worker，必要时提升从副本
+	assigned := make(map[string]struct{}, len(alive))
+	for i := range m.Shards {
+		shard := &m.Shards[i]
+		if !isAlive(shard.Primary) {
+			shard.Primary = ""
+		}
+		kept := make([]string, 0, len(shard.Replicas))
+		for _, replica := range shard.Replicas {
+			if isAlive(replica) {
+				kept = append(kept, replica)
+			}
+		}
+		shard.Replicas = kept
+		if shard.Primary == "" && len(shard.Replicas) > 0 {
+			shard.Primary, shard.Replicas = shard.Replicas[0], shard.Replicas[1:]
+		}
+		if shard.Primary != "" {
+			assigned[shard.Primary] = struct{}{}
+		}
+		for _, replica := range shard.Replicas {
+			assigned[replica] = struct{}{}
+		}
+	}
+
+	// 分配新的worker。已声明为主副本的worker优先，其次是已声明为从副本的worker，排序保证各个coordinator的分配结果一致
+	unassigned := make([]service_hub.ServiceInstance, 0)
+	for _, instance := range alive {
+		if _, exists := assigned[instance.Endpoint]; !exists {
+			unassigned = append(unassigned, instance)
+		}
+	}
+	sort.Slice(unassigned, func(i, j int) bool {
+		ri, rj := roleRank(unassigned[i].Role), roleRank(unassigned[j].Role)
+		if ri != rj {
+			return ri < rj
+		}
+		return unassigned[i].Endpoint < unassigned[j].Endpoint
+	})
+	for _, instance := range unassigned {
+		var shard *ShardAssignment
+		if len(instance.Role) > 0 {
+			// 已有该分片的数据，只能分配回该分片
+			if instance.ShardId < 0 || instance.ShardId >= len(m.Shards) {
+				continue
+			}
+			shard = &m.Shards[instance.ShardId]
+			if shard.Primary != "" && len(shard.Replicas) >= replicas {
+				continue
+			}
+		} else if shard = m.pickShard(replicas); shard == nil {
+			continue
+		}
+		if shard.Primary == "" {
+			shard.Primary = instance.Endpoint
+		} else {
+			shard.Replicas = append(shard.Replicas, instance.Endpoint)
+		}
+	}
+
+	after, _ := json.Marshal(m.Shards)
+	if string(before) == string(after) {
+		return false
+	}
+	m.Epoch++
+	return true
+}
+
+// roleRank 分配未分配的worker时的优先级，数值越小越先分配
+func roleRank(role string) int {
+	switch role {
+	case service_hub.RolePrimary:
+		return 0
+	case service_hub.RoleReplica:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// pickShard 选择下一个worker应分配到的分片，所有分片都已满员时返回nil
+func (m *ShardMap) pickShard(replicas int) *ShardAssignment {
+	for i := range m.Shards {
+		if m.Shards[i].Primary == "" {
+			return &m.Shards[i]
+		}
+	}
+	var best *ShardAssignment
+	for i := range m.Shards {
+		shard := &m.Shards[i]
+		if len(shard.Replicas) >= replicas {
+			continue
+		}
+		if best == nil || len(shard.Replicas) < len(best.Replicas) {
+			best = shard
+		}
+	}
+	return best
+}
+
+// clone 深拷贝分片表
+func (m *ShardMap) clone() ShardMap {
+	shards := make([]ShardAssignment, len(m.Shards))
+	for i, shard := range m.Shards {
+		shards[i] = shard
+		shards[i].Replicas = append([]string(nil), shard.Replicas...)
+	}
+	return ShardMap{Epoch: m.Epoch, Shards: shards}
+}
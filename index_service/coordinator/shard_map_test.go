@@ -0,0 +1,106 @@
+package coordinator
+
+import (
+	"testing"
+
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+)
+
+// instances 构造没有发布分片和角色的worker
+func instances(endpoints ...string) []service_hub.ServiceInstance {
+	result := make([]service_hub.ServiceInstance, len(endpoints))
+	for i, endpoint := range endpoints {
+		result[i].Endpoint = endpoint
+	}
+	return result
+}
+
+// instance 构造发布了分片和角色的worker
+func instance(endpoint string, shardId int, role string) service_hub.ServiceInstance {
+	return service_hub.ServiceInstance{Endpoint: endpoint, ServiceMeta: service_hub.ServiceMeta{ShardId: shardId, Role: role}}
+}
+
+func TestRebalance(t *testing.T) {
+	m := NewShardMap(2)
+
+	// 新worker优先分配为没有主副本的分片的主副本，其余作为从副本
+	if !m.Rebalance(instances("w3", "w1", "w2", "w0"), 1) {
+		t.Fatal("分配新worker后分片表应发生变化")
+	}
+	if m.Epoch != 1 {
+		t.Fatalf("epoch应为1，实际为%d", m.Epoch)
+	}
+	if m.Shards[0].Primary != "w0" || m.Shards[1].Primary != "w1" {
+		t.Fatalf("主副本分配不正确: %+v", m.Shards)
+	}
+	if len(m.Shards[0].Replicas) != 1 || m.Shards[0].Replicas[0] != "w2" ||
+		len(m.Shards[1].Replicas) != 1 || m.Shards[1].Replicas[0] != "w3" {
+		t.Fatalf("从副本分配不正确: %+v", m.Shards)
+	}
+
+	// 没有变化时epoch不变
+	if m.Rebalance(instances("w0", "w1", "w2", "w3"), 1) || m.Epoch != 1 {
+		t.Fatalf("worker没有变化时分片表不应变化，epoch为%d", m.Epoch)
+	}
+
+	// 分片都已满员，多余的worker作为备用
+	m.Rebalance(instances("w0", "w1", "w2", "w3", "w4"), 1)
+	if shardId, _ := m.ShardOf("w4"); shardId != -1 {
+		t.Fatalf("分片已满员时不应分配w4，实际分配到分片%d", shardId)
+	}
+
+	// 主副本下线，提升从副本，并由备用worker补充从副本
+	if !m.Rebalance(instances("w1", "w2", "w3", "w4"), 1) {
+		t.Fatal("worker下线后分片表应发生变化")
+	}
+	if shardId, primary := m.ShardOf("w2"); shardId != 0 || !primary {
+		t.Fatalf("w2应被提升为分片0的主副本，实际为分片%d，主副本%t", shardId, primary)
+	}
+	if shardId, primary := m.ShardOf("w4"); shardId != 0 || primary {
+		t.Fatalf("w4应成为分片0的从副本，实际为分片%d，主副本%t", shardId, primary)
+	}
+
+	// 分片的所有副本都下线后，该分片没有主副本
+	m.Rebalance(instances("w1", "w3"), 1)
+	if m.Shards[0].Primary != "" || len(m.Shards[0].Replicas) != 0 {
+		t.Fatalf("分片0的副本应全部移除: %+v", m.Shards[0])
+	}
+}
+
+func TestRebalancePublishedShard(t *testing.T) {
+	m := NewShardMap(2)
+	alive := []service_hub.ServiceInstance{
+		{Endpoint: "w0"},
+		instance("w1", 1, service_hub.RoleReplica),
+		instance("w2", 1, service_hub.RolePrimary),
+		instance("w3", 1, service_hub.RoleReplica),
+		instance("w4", 5, service_hub.RolePrimary),
+	}
+	m.Rebalance(alive, 1)
+
+	// 已发布分片和角色的worker分配回原来的分片
+	if shardId, primary := m.ShardOf("w2"); shardId != 1 || !primary {
+		t.Fatalf("w2应是分片1的主副本，实际为分片%d，主副本%t", shardId, primary)
+	}
+	if shardId, primary := m.ShardOf("w1"); shardId != 1 || primary {
+		t.Fatalf("w1应是分片1的从副本，实际为分片%d，主副本%t", shardId, primary)
+	}
+	// 原分片已满员或分片编号不合法的worker作为备用，不会被分配到其他分片
+	if shardId, _ := m.ShardOf("w3"); shardId != -1 {
+		t.Fatalf("分片1已满员，w3应作为备用，实际分配到分片%d", shardId)
+	}
+	if shardId, _ := m.ShardOf("w4"); shardId != -1 {
+		t.Fatalf("w4发布的分片不存在，应作为备用，实际分配到分片%d", shardId)
+	}
+	// 未发布角色的worker自由分配
+	if shardId, primary := m.ShardOf("w0"); shardId != 0 || !primary {
+		t.Fatalf("w0应是分片0的主副本，实际为分片%d，主副本%t", shardId, primary)
+	}
+
+	// 分片没有主副本时，声明为从副本的worker被提升为主副本
+	m = NewShardMap(1)
+	m.Rebalance([]service_hub.ServiceInstance{{Endpoint: "w0"}, instance("w1", 0, service_hub.RoleReplica)}, 1)
+	if shardId, primary := m.ShardOf("w1"); shardId != 0 || !primary {
+		t.Fatalf("w1应被提升为分片0的主副本，实际为分片%d，主副本%t", shardId, primary)
+	}
+}
@@ -0,0 +1,193 @@
+package endpoint_selector
+
+import (
+	"github.com/jmh000527/criker-search/index_service/circuit_breaker"
+	"github.com/jmh000527/criker-search/index_service/load_balancer"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"sync"
+	"time"
+)
+
+// EndpointSelector 从服务发现得到的endpoint中选择请求的目标：先剔除已被熔断的endpoint，再按负载均衡策略选择一个。
+// 调用方（如Sentinel）持有EndpointSelector，上报每次调用的结果；ServiceHub只负责服务注册与发现，即提供endpoint列表和元数据。
+type EndpointSelector struct {
+	loadBalancer load_balancer.LoadBalancer     // 负载均衡策略的接口，支持多种负载均衡实现
+	health       *circuit_breaker.HealthTracker // 各个endpoint的健康状况，被熔断的endpoint不参与负载均衡
+
+	mu      sync.Mutex
+	weights map[string]int // 已同步给负载均衡的权重，endpoint -> 权重，只有权重变化时才推送给负载均衡
+}
+
+// NewEndpointSelector 创建一个使用默认熔断配置的EndpointSelector。
+//
+// 参数:
+//   - loadBalancer: 负载均衡策略，可通过load_balancer.NewLoadBalancer按名称创建，为nil时使用Round-Robin。
+//
+// 返回值:
+//   - *EndpointSelector: 新创建的EndpointSelector。
+func NewEndpointSelector(loadBalancer load_balancer.LoadBalancer) *EndpointSelector {
+	if loadBalancer == nil {
+		loadBalancer = &load_balancer.RoundRobin{}
+	}
+	return &EndpointSelector{
+		loadBalancer: loadBalancer,
+		health:       circuit_breaker.NewHealthTracker(circuit_breaker.DefaultConfig()),
+		weights:      make(map[string]int),
+	}
+}
+
+// SelectEndpoint 剔除已被熔断的endpoint后，按负载均衡策略从endpoints中选择一个。
+//
+// 参数:
+//   - endpoints: 候选的服务端点。
+//
+// 返回值:
+//   - string: 选择的服务端点地址，endpoints为空时返回空字符串。
+func (s *EndpointSelector) SelectEndpoint(endpoints []string) string {
+	return s.loadBalancer.Take(s.health.Filter(endpoints))
+}
+
+// SelectEndpointByKey 从给定的endpoints中按key选择一个，用于只能发往部分endpoint的请求，例如写请求只能发给各分片的主副本。
+// 负载均衡不支持按key选择时与SelectEndpoint相同。
+// 按key选择时不剔除已被熔断的endpoint：同一个key必须总是落到同一个endpoint，
+// 否则熔断期间的写入会落到其他节点，留下同一文档的多个副本。被熔断的endpoint由BeginRequest拒绝，写入直接失败。
+//
+// 参数:
+//   - endpoints: 候选的服务端点。
+//   - key: 路由使用的key，例如文档ID。
+//
+// 返回值:
+//   - string: 选择的服务端点地址，endpoints为空时返回空字符串。
+func (s *EndpointSelector) SelectEndpointByKey(endpoints []string, key string) string {
+	if keyed, ok := s.loadBalancer.(load_balancer.KeyedLoadBalancer); ok {
+		return keyed.TakeByKey(endpoints, key)
+	}
+	return s.SelectEndpoint(endpoints)
+}
+
+// KeyedRouting 负载均衡策略是否支持按key选择，即相同的key总是落到同一个endpoint
+func (s *EndpointSelector) KeyedRouting() bool {
+	_, ok := s.loadBalancer.(load_balancer.KeyedLoadBalancer)
+	return ok
+}
+
+// SetWeight 把endpoint发布的权重同步给加权负载均衡，权重与上次同步的相同时什么也不做。
+//
+// 参数:
+//   - endpoint: 服务端点地址。
+//   - weight: 权重，<=0 表示使用默认权重。
+func (s *EndpointSelector) SetWeight(endpoint string, weight int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.setWeight(endpoint, weight)
+}
+
+// setWeight 同SetWeight，调用方需持有锁
+func (s *EndpointSelector) setWeight(endpoint string, weight int) {
+	if old, exists := s.weights[endpoint]; exists && old == weight {
+		return
+	}
+	s.weights[endpoint] = weight
+	if weighted, ok := s.loadBalancer.(load_balancer.WeightedLoadBalancer); ok {
+		weighted.SetWeight(endpoint, weight)
+	}
+}
+
+// Forget 删除endpoint的健康状况和权重，用于节点下线之后。
+//
+// 参数:
+//   - endpoint: 已下线的服务端点地址。
+func (s *EndpointSelector) Forget(endpoint string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.forget(endpoint)
+}
+
+// forget 同Forget，调用方需持有锁
+func (s *EndpointSelector) forget(endpoint string) {
+	s.health.Forget(endpoint)
+	if _, exists := s.weights[endpoint]; !exists {
+		return
+	}
+	delete(s.weights, endpoint)
+	if weighted, ok := s.loadBalancer.(load_balancer.WeightedLoadBalancer); ok {
+		weighted.SetWeight(endpoint, 0)
+	}
+}
+
+// Sync 用服务发现得到的全部实例同步权重：权重变化的推送给负载均衡，不在instances中的endpoint视为已下线，删除其健康状况和权重。
+// 权重和实例都没有变化时只做比较，不会触碰负载均衡。
+// instances为空时不做任何事，避免服务发现暂时失败（返回nil）时丢掉所有endpoint的健康状况。
+//
+// 参数:
+//   - instances: 服务的全部实例及其元数据。
+func (s *EndpointSelector) Sync(instances []service_hub.ServiceInstance) {
+	if len(instances) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	before, known := len(s.weights), 0 // known: 之前已经同步过的实例数量
+	for _, instance := range instances {
+		if _, exists := s.weights[instance.Endpoint]; exists {
+			known++
+		}
+		s.setWeight(instance.Endpoint, instance.Weight)
+	}
+	if known == before {
+		return // 之前同步过的endpoint都还在
+	}
+	alive := make(map[string]struct{}, len(instances))
+	for _, instance := range instances {
+		alive[instance.Endpoint] = struct{}{}
+	}
+	for endpoint := range s.weights {
+		if _, exists := alive[endpoint]; !exists {
+			s.forget(endpoint)
+		}
+	}
+}
+
+// BeginRequest 在向endpoint发出请求之前调用，供需要统计在途请求的负载均衡使用。
+// endpoint处于半开状态时占用一个试探名额，名额用完后拒绝请求。每次返回true的BeginRequest都应对应一次ReportResult。
+//
+// 参数:
+//   - endpoint: 被调用的服务端点地址。
+//
+// 返回值:
+//   - bool: endpoint被熔断或半开状态下的试探名额已用完时返回false，调用方不应再发出请求。
+func (s *EndpointSelector) BeginRequest(endpoint string) bool {
+	if !s.health.Begin(endpoint) {
+		return false
+	}
+	if feedback, ok := s.loadBalancer.(load_balancer.FeedbackLoadBalancer); ok {
+		feedback.Begin(endpoint)
+	}
+	return true
+}
+
+// ReportResult 上报一次对endpoint的调用结果。
+// 连续失败、错误率过高或延迟明显高于其他节点的endpoint会被熔断，一段时间内不再被选中。
+// 调用结果同时会反馈给需要统计延迟和在途请求的负载均衡。
+//
+// 参数:
+//   - endpoint: 被调用的服务端点地址。
+//   - latency: 本次调用的耗时。
+//   - err: 本次调用返回的错误，nil表示成功。
+func (s *EndpointSelector) ReportResult(endpoint string, latency time.Duration, err error) {
+	s.health.Report(endpoint, latency, err)
+	if feedback, ok := s.loadBalancer.(load_balancer.FeedbackLoadBalancer); ok {
+		feedback.Done(endpoint, latency, err)
+	}
+}
+
+// IsAvailable 判断endpoint当前是否未被熔断。
+//
+// 参数:
+//   - endpoint: 服务端点地址。
+//
+// 返回值:
+//   - bool: endpoint可以接收请求时返回true。
+func (s *EndpointSelector) IsAvailable(endpoint string) bool {
+	return s.health.IsAvailable(endpoint)
+}
@@ -0,0 +1,115 @@
+package endpoint_selector
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index_service/load_balancer"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+)
+
+var endpoints = []string{"127.0.0.1:5000", "127.0.0.1:5001"}
+
+// countingWeighted 记录每次 SetWeight 调用的加权负载均衡
+type countingWeighted struct {
+	*load_balancer.WeightedRoundRobin
+	calls []string
+}
+
+func (b *countingWeighted) SetWeight(endpoint string, weight int) {
+	b.calls = append(b.calls, endpoint)
+	b.WeightedRoundRobin.SetWeight(endpoint, weight)
+}
+
+func TestSelectEndpointByKey(t *testing.T) {
+	selector := NewEndpointSelector(load_balancer.NewConsistentHash(load_balancer.DefaultVirtualNodes))
+	if !selector.KeyedRouting() {
+		t.Fatal("一致性哈希应支持按key选择")
+	}
+
+	// 一致性哈希下相同的key总是选中相同的endpoint
+	first := selector.SelectEndpointByKey(endpoints, "doc1")
+	for i := 0; i < 10; i++ {
+		if endpoint := selector.SelectEndpointByKey(endpoints, "doc1"); endpoint != first {
+			t.Fatalf("相同的key应选中相同的endpoint，%s != %s", endpoint, first)
+		}
+	}
+
+	// 按key选择时不跳过被熔断的endpoint，由BeginRequest拒绝请求
+	for i := 0; i < 10; i++ {
+		selector.ReportResult(first, time.Millisecond, errors.New("connection refused"))
+	}
+	if selector.IsAvailable(first) {
+		t.Fatalf("%s 应已被熔断", first)
+	}
+	if endpoint := selector.SelectEndpointByKey(endpoints, "doc1"); endpoint != first {
+		t.Fatalf("熔断期间相同的key仍应选中 %s，实际为 %s", first, endpoint)
+	}
+	if selector.BeginRequest(first) {
+		t.Fatal("被熔断的endpoint不应放行请求")
+	}
+	// 不按key选择时跳过被熔断的endpoint
+	for i := 0; i < 10; i++ {
+		if endpoint := selector.SelectEndpoint(endpoints); endpoint == first {
+			t.Fatalf("不应选中被熔断的 %s", first)
+		}
+	}
+
+	// 下线之后不再记得熔断状态
+	selector.Forget(first)
+	if !selector.IsAvailable(first) {
+		t.Fatalf("%s 下线之后不应再处于熔断状态", first)
+	}
+}
+
+func TestSync(t *testing.T) {
+	lb := &countingWeighted{WeightedRoundRobin: load_balancer.NewWeightedRoundRobin()}
+	selector := NewEndpointSelector(lb)
+	instances := []service_hub.ServiceInstance{
+		{Endpoint: endpoints[0], ServiceMeta: service_hub.ServiceMeta{Weight: 3}},
+		{Endpoint: endpoints[1]},
+	}
+
+	// worker 发布的权重同步给加权负载均衡
+	selector.Sync(instances)
+	counts := make(map[string]int)
+	for i := 0; i < 40; i++ {
+		counts[selector.SelectEndpoint(endpoints)]++
+	}
+	if counts[endpoints[0]] != 30 || counts[endpoints[1]] != 10 {
+		t.Fatalf("加权负载均衡的分配比例不正确: %v", counts)
+	}
+
+	// 权重没有变化时不触碰负载均衡，只推送变化的权重
+	lb.calls = nil
+	selector.Sync(instances)
+	if len(lb.calls) != 0 {
+		t.Fatalf("权重没有变化时不应调用 SetWeight，实际调用了 %v", lb.calls)
+	}
+	instances[1].Weight = 2
+	selector.Sync(instances)
+	if !reflect.DeepEqual(lb.calls, []string{endpoints[1]}) {
+		t.Fatalf("应只推送 %s 的权重，实际为 %v", endpoints[1], lb.calls)
+	}
+
+	// 服务发现暂时失败时保留之前的状态
+	for i := 0; i < 10; i++ {
+		selector.ReportResult(endpoints[0], time.Millisecond, errors.New("connection refused"))
+	}
+	lb.calls = nil
+	selector.Sync(nil)
+	if len(lb.calls) != 0 || selector.IsAvailable(endpoints[0]) {
+		t.Fatalf("实例为空时不应改变任何状态，SetWeight 调用: %v", lb.calls)
+	}
+
+	// 已下线的 endpoint 删除权重和熔断状态
+	selector.Sync(instances[1:])
+	if !reflect.DeepEqual(lb.calls, []string{endpoints[0]}) {
+		t.Fatalf("应删除 %s 的权重，实际调用了 %v", endpoints[0], lb.calls)
+	}
+	if !selector.IsAvailable(endpoints[0]) {
+		t.Fatalf("%s 下线之后不应再处于熔断状态", endpoints[0])
+	}
+}
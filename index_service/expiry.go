@@ -0,0 +1,223 @@
+package index_service
+
+import (
+	"container/heap"
+	kvDb "github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/types"
+	"github.com/jmh000527/criker-search/utils"
+	"sync"
+	"time"
+)
+
+// forwardExpiryGrace 正排索引支持过期（ExpiringKeyValueDB）时，文档在正排索引中比过期时间多保留的时长。
+// 后台任务需要从正排索引中读出文档的关键词才能把它从倒排索引中删除，因此数据库自动删除要晚于后台任务。
+// 进程停止期间过期的文档由数据库删除，重启后重建倒排索引时自然不会再包含它们。
+// 没有启动后台任务时不设置数据库过期，否则正排记录被数据库删除后，倒排索引和文档计数中仍会残留这些文档。
+const forwardExpiryGrace = time.Hour
+
+// expiryEntry 一个带过期时间的文档
+type expiryEntry struct {
+	expireAt int64  // 过期时间(Unix时间戳，单位秒)
+	docId    string // 业务侧文档ID
+	index    int    // 在堆中的下标，由 expiryHeap 维护
+}
+
+// expiryHeap 按过期时间排序的小根堆，实现 heap.Interface
+type expiryHeap []*expiryEntry
+
+func (h expiryHeap) Len() int           { return len(h) }
+func (h expiryHeap) Less(i, j int) bool { return h[i].expireAt < h[j].expireAt }
+func (h expiryHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *expiryHeap) Push(x any) {
+	entry := x.(*expiryEntry)
+	entry.index = len(*h)
+	*h = append(*h, entry)
+}
+func (h *expiryHeap) Pop() any {
+	old := *h
+	entry := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return entry
+}
+
+// expiry LocalIndexer 的文档过期状态。
+// 每个文档在堆中至多一项，重新写入时更新该项的过期时间；文档被覆盖或删除时不从堆中移除，
+// 而是在到期时重新读取文档，只有文档当前的过期时间确实已到才删除它。
+type expiry struct {
+	expiryMu       sync.Mutex
+	expiries       expiryHeap
+	tracked        map[string]*expiryEntry // 业务侧文档ID到堆中对应项的映射
+	stopReaper     chan struct{}           // 关闭后后台任务退出，未启动时为 nil
+	reaperDone     chan struct{}           // 后台任务退出后关闭
+	reaperInterval time.Duration           // 后台任务检查过期文档的间隔，暂停后按原来的间隔重新启动
+}
+
+// StartReaper 启动后台任务，每隔 interval 删除一次已过期的文档。重复调用不会启动多个任务。
+// 从副本上同样需要启动：过期删除不写入变更日志，每个副本按文档的过期时间各自删除。
+//
+// 参数:
+//   - interval: 检查过期文档的间隔。
+func (indexer *LocalIndexer) StartReaper(interval time.Duration) {
+	indexer.expiryMu.Lock()
+	defer indexer.expiryMu.Unlock()
+	if indexer.stopReaper != nil || interval <= 0 {
+		return
+	}
+	indexer.stopReaper = make(chan struct{})
+	indexer.reaperDone = make(chan struct{})
+	indexer.reaperInterval = interval
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				indexer.ReapExpired()
+			case <-stop:
+				return
+			}
+		}
+	}(indexer.stopReaper, indexer.reaperDone)
+}
+
+// ReapExpired 从正排索引和倒排索引中删除所有已过期的文档。
+//
+// 返回值:
+//   - int: 删除的文档数量。
+func (indexer *LocalIndexer) ReapExpired() int {
+	now := time.Now().Unix()
+	indexer.expiryMu.Lock()
+	var docIds []string
+	for len(indexer.expiries) > 0 && indexer.expiries[0].expireAt <= now {
+		entry := heap.Pop(&indexer.expiries).(*expiryEntry)
+		delete(indexer.tracked, entry.docId)
+		docIds = append(docIds, entry.docId)
+	}
+	indexer.expiryMu.Unlock()
+	if len(docIds) == 0 {
+		return 0
+	}
+
+	// 到期之后文档可能被重新写入过，只删除当前确实已过期的文档
+	deleted, err := indexer.batchDeleteWhere(docIds, func(doc *types.Document) bool {
+		return doc.ExpireAt > 0 && doc.ExpireAt <= now
+	})
+	if err != nil {
+		utils.Log.Printf("删除过期文档失败: %v", err)
+		// 放回堆中，下次再试
+		indexer.expiryMu.Lock()
+		for _, docId := range docIds {
+			indexer.pushExpiry(docId, now)
+		}
+		indexer.expiryMu.Unlock()
+		return 0
+	}
+	if len(deleted) > 0 {
+		utils.Log.Printf("删除了 %d 个过期文档", len(deleted))
+	}
+	return len(deleted)
+}
+
+// trackExpiry 记录带过期时间的文档，到期后由 ReapExpired 删除
+func (indexer *LocalIndexer) trackExpiry(docs ...types.Document) {
+	indexer.expiryMu.Lock()
+	defer indexer.expiryMu.Unlock()
+	for i := range docs {
+		if docs[i].ExpireAt > 0 {
+			indexer.pushExpiry(docs[i].Id, docs[i].ExpireAt)
+		}
+	}
+}
+
+// pushExpiry 把文档加入堆中，文档已在堆中时只更新它的过期时间。调用方需持有 expiryMu
+func (indexer *LocalIndexer) pushExpiry(docId string, expireAt int64) {
+	if entry, exists := indexer.tracked[docId]; exists {
+		entry.expireAt = expireAt
+		heap.Fix(&indexer.expiries, entry.index)
+		return
+	}
+	if indexer.tracked == nil {
+		indexer.tracked = make(map[string]*expiryEntry)
+	}
+	entry := &expiryEntry{expireAt: expireAt, docId: docId}
+	heap.Push(&indexer.expiries, entry)
+	indexer.tracked[docId] = entry
+}
+
+// resetExpiry 清空记录的过期文档，重建倒排索引之前调用
+func (indexer *LocalIndexer) resetExpiry() {
+	indexer.expiryMu.Lock()
+	defer indexer.expiryMu.Unlock()
+	indexer.expiries = nil
+	indexer.tracked = nil
+}
+
+// reaping 是否启动了删除过期文档的后台任务
+func (indexer *LocalIndexer) reaping() bool {
+	indexer.expiryMu.Lock()
+	defer indexer.expiryMu.Unlock()
+	return indexer.stopReaper != nil
+}
+
+// closeReaper 停止后台任务并等待其退出，返回后台任务的间隔，未启动时返回0。用返回值调用 StartReaper 即可恢复后台任务
+func (indexer *LocalIndexer) closeReaper() time.Duration {
+	indexer.expiryMu.Lock()
+	stop, done, interval := indexer.stopReaper, indexer.reaperDone, indexer.reaperInterval
+	indexer.stopReaper = nil
+	indexer.expiryMu.Unlock()
+	if stop == nil {
+		return 0
+	}
+	close(stop)
+	<-done
+	return interval
+}
+
+// setDoc 把编码后的文档写入正排索引，正排索引支持过期且启动了后台任务时让数据库在过期时间之后自动删除文档
+func (indexer *LocalIndexer) setDoc(key, value []byte, expireAt int64) error {
+	if db, ok := indexer.forwardIndex.(kvDb.ExpiringKeyValueDB); ok && expireAt > 0 && indexer.reaping() {
+		return db.SetWithExpiry(key, value, forwardExpireTime(expireAt))
+	}
+	return indexer.forwardIndex.Set(key, value)
+}
+
+// setDocIn 在正排索引的事务中写入编码后的文档，与 setDoc 相同，事务支持过期且启动了后台任务时设置数据库过期
+func (indexer *LocalIndexer) setDocIn(tx kvDb.KVTxn, key, value []byte, expireAt int64) error {
+	if etx, ok := tx.(kvDb.ExpiringKVTxn); ok && expireAt > 0 && indexer.reaping() {
+		return etx.SetWithExpiry(key, value, forwardExpireTime(expireAt))
+	}
+	return tx.Set(key, value)
+}
+
+// batchSetDocs 批量把编码后的文档写入正排索引，expireAts 与 keys 一一对应
+func (indexer *LocalIndexer) batchSetDocs(keys, values [][]byte, expireAts []int64) error {
+	db, ok := indexer.forwardIndex.(kvDb.ExpiringKeyValueDB)
+	if !ok || !indexer.reaping() {
+		return indexer.forwardIndex.BatchSet(keys, values)
+	}
+	times := make([]time.Time, len(expireAts))
+	for i, expireAt := range expireAts {
+		if expireAt > 0 {
+			times[i] = forwardExpireTime(expireAt)
+		}
+	}
+	return db.BatchSetWithExpiry(keys, values, times)
+}
+
+// ForwardExpireTime 文档在正排索引中的过期时间，文档不过期时返回零值。改写正排索引的工具（例如 doc_codec.Migrate）需要据此重新设置过期时间。
+func ForwardExpireTime(doc *types.Document) time.Time {
+	if doc.ExpireAt <= 0 {
+		return time.Time{}
+	}
+	return forwardExpireTime(doc.ExpireAt)
+}
+
+// forwardExpireTime 文档在正排索引中的过期时间
+func forwardExpireTime(expireAt int64) time.Time {
+	return time.Unix(expireAt, 0).Add(forwardExpiryGrace)
+}
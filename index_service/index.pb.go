@@ -5,6 +5,7 @@ package index_service
 
 import (
 	context "context"
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	proto "github.com/gogo/protobuf/proto"
 	types "github.com/jmh000527/criker-search/types"
@@ -27,6 +28,31 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type ChangeOp int32
+
+const (
+	ChangeOp_ADD    ChangeOp = 0
+	ChangeOp_DELETE ChangeOp = 1
+)
+
+var ChangeOp_name = map[int32]string{
+	0: "ADD",
+	1: "DELETE",
+}
+
+var ChangeOp_value = map[string]int32{
+	"ADD":    0,
+	"DELETE": 1,
+}
+
+func (x ChangeOp) String() string {
+	return proto.EnumName(ChangeOp_name, int32(x))
+}
+
+func (ChangeOp) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{0}
+}
+
 type DocId struct {
 	DocId string `protobuf:"bytes,1,opt,name=DocId,proto3" json:"DocId,omitempty"`
 }
@@ -116,10 +142,12 @@ func (m *AffectedCount) GetCount() int32 {
 }
 
 type SearchRequest struct {
-	Query   *types.TermQuery `protobuf:"bytes,1,opt,name=Query,proto3" json:"Query,omitempty"`
-	OnFlag  uint64           `protobuf:"varint,2,opt,name=OnFlag,proto3" json:"OnFlag,omitempty"`
-	OffFlag uint64           `protobuf:"varint,3,opt,name=OffFlag,proto3" json:"OffFlag,omitempty"`
-	OrFlags []uint64         `protobuf:"varint,4,rep,packed,name=OrFlags,proto3" json:"OrFlags,omitempty"`
+	Query     *types.TermQuery `protobuf:"bytes,1,opt,name=Query,proto3" json:"Query,omitempty"`
+	OnFlag    uint64           `protobuf:"varint,2,opt,name=OnFlag,proto3" json:"OnFlag,omitempty"`
+	OffFlag   uint64           `protobuf:"varint,3,opt,name=OffFlag,proto3" json:"OffFlag,omitempty"`
+	OrFlags   []uint64         `protobuf:"varint,4,rep,packed,name=OrFlags,proto3" json:"OrFlags,omitempty"`
+	ChunkSize int32            `protobuf:"varint,5,opt,name=ChunkSize,proto3" json:"ChunkSize,omitempty"`
+	Limit     int32            `protobuf:"varint,6,opt,name=Limit,proto3" json:"Limit,omitempty"`
 }
 
 func (m *SearchRequest) Reset()         { *m = SearchRequest{} }
@@ -183,6 +211,20 @@ func (m *SearchRequest) GetOrFlags() []uint64 {
 	return nil
 }
 
+func (m *SearchRequest) GetChunkSize() int32 {
+	if m != nil {
+		return m.ChunkSize
+	}
+	return 0
+}
+
+func (m *SearchRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
 type SearchResult struct {
 	Results []*types.Document `protobuf:"bytes,1,rep,name=Results,proto3" json:"Results,omitempty"`
 }
@@ -263,506 +305,6586 @@ func (m *CountRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_CountRequest proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*DocId)(nil), "index_service.DocId")
-	proto.RegisterType((*AffectedCount)(nil), "index_service.AffectedCount")
-	proto.RegisterType((*SearchRequest)(nil), "index_service.SearchRequest")
-	proto.RegisterType((*SearchResult)(nil), "index_service.SearchResult")
-	proto.RegisterType((*CountRequest)(nil), "index_service.CountRequest")
+type DocStatus struct {
+	DocId string `protobuf:"bytes,1,opt,name=DocId,proto3" json:"DocId,omitempty"`
+	Ok    bool   `protobuf:"varint,2,opt,name=Ok,proto3" json:"Ok,omitempty"`
+	Error string `protobuf:"bytes,3,opt,name=Error,proto3" json:"Error,omitempty"`
 }
 
-func init() { proto.RegisterFile("index.proto", fileDescriptor_f750e0f7889345b5) }
-
-var fileDescriptor_f750e0f7889345b5 = []byte{
-	// 370 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x92, 0x4f, 0x6b, 0xea, 0x40,
-	0x14, 0xc5, 0x1d, 0xff, 0x44, 0x1c, 0xf5, 0xbd, 0xc7, 0xe0, 0x2b, 0x21, 0xb5, 0x21, 0x04, 0x5a,
-	0xec, 0x26, 0x82, 0x5d, 0x94, 0xae, 0x8a, 0x1a, 0x0a, 0xae, 0xa4, 0xb1, 0x7b, 0xb1, 0xc9, 0x4d,
-	0x2b, 0x68, 0xa2, 0x93, 0x49, 0xa9, 0xeb, 0x7e, 0x81, 0xf6, 0x5b, 0x75, 0xe9, 0xb2, 0xcb, 0xa2,
-	0x5f, 0xa4, 0xe4, 0x4e, 0x04, 0x0d, 0xb4, 0xdd, 0xdd, 0x73, 0x7f, 0x67, 0xf0, 0xdc, 0x63, 0x68,
-	0x75, 0x1a, 0x78, 0xf0, 0x6c, 0x2d, 0x78, 0x28, 0x42, 0x56, 0x47, 0x31, 0x8e, 0x80, 0x3f, 0x4d,
-	0x5d, 0xd0, 0xfe, 0x8b, 0xd5, 0x02, 0xa2, 0x36, 0xb2, 0xb6, 0x17, 0xba, 0xd2, 0xa5, 0x35, 0xf7,
-	0xd7, 0x02, 0xf8, 0x7c, 0xbc, 0x8c, 0x81, 0xaf, 0x24, 0x35, 0x4f, 0x68, 0xc9, 0x0e, 0xdd, 0x81,
-	0xc7, 0x1a, 0xe9, 0xa0, 0x12, 0x83, 0xb4, 0x2a, 0x8e, 0x14, 0xe6, 0x29, 0xad, 0x77, 0x7d, 0x1f,
-	0x5c, 0x01, 0x5e, 0x3f, 0x8c, 0x03, 0x91, 0xd8, 0x70, 0x40, 0x5b, 0xc9, 0x91, 0xc2, 0x7c, 0x21,
-	0xb4, 0x3e, 0x82, 0x09, 0x77, 0x1f, 0x1d, 0x58, 0xc6, 0x10, 0x09, 0x76, 0x46, 0x4b, 0xb7, 0xc9,
-	0xcf, 0xa0, 0xaf, 0xda, 0xf9, 0x67, 0x61, 0x0a, 0xeb, 0x0e, 0xf8, 0x1c, 0xf7, 0x8e, 0xc4, 0xec,
-	0x88, 0x2a, 0xc3, 0xe0, 0x66, 0x36, 0x79, 0x50, 0xf3, 0x06, 0x69, 0x15, 0x9d, 0x54, 0x31, 0x95,
-	0x96, 0x87, 0xbe, 0x8f, 0xa0, 0x80, 0x60, 0x27, 0x91, 0xf0, 0x64, 0x8a, 0xd4, 0xa2, 0x51, 0x40,
-	0x22, 0xa5, 0x79, 0x45, 0x6b, 0xbb, 0x10, 0x51, 0x3c, 0x13, 0xec, 0x9c, 0x96, 0xe5, 0x14, 0xa9,
-	0xc4, 0x28, 0xb4, 0xaa, 0x9d, 0xbf, 0x69, 0x0a, 0x3b, 0x74, 0xe3, 0x39, 0x04, 0xc2, 0xd9, 0x71,
-	0xf3, 0x0f, 0xad, 0xe1, 0x25, 0x69, 0xfc, 0xce, 0x5b, 0x9e, 0xd6, 0x06, 0x49, 0xbb, 0x23, 0x59,
-	0x2e, 0xbb, 0xa6, 0x15, 0x1b, 0x66, 0x20, 0xc0, 0x0e, 0x5d, 0xd6, 0xb0, 0x0e, 0x9a, 0xb7, 0xb0,
-	0x2b, 0xad, 0x99, 0xd9, 0x1e, 0x16, 0x77, 0x49, 0x95, 0xae, 0xe7, 0x25, 0xaf, 0xb3, 0x29, 0x7e,
-	0x79, 0xd8, 0xa7, 0x8a, 0xbc, 0x8a, 0x65, 0x7d, 0x07, 0x8d, 0x6b, 0xc7, 0xdf, 0x50, 0xac, 0xa2,
-	0x97, 0xfe, 0x6d, 0x2c, 0xeb, 0xda, 0xbf, 0xfa, 0xe7, 0x20, 0x3d, 0xf5, 0x7d, 0xa3, 0x93, 0xf5,
-	0x46, 0x27, 0x9f, 0x1b, 0x9d, 0xbc, 0x6e, 0xf5, 0xdc, 0x7a, 0xab, 0xe7, 0x3e, 0xb6, 0x7a, 0xee,
-	0x5e, 0xc1, 0x6f, 0xe9, 0xe2, 0x2b, 0x00, 0x00, 0xff, 0xff, 0xef, 0xf3, 0x26, 0xfa, 0x9e, 0x02,
-	0x00, 0x00,
+func (m *DocStatus) Reset()         { *m = DocStatus{} }
+func (m *DocStatus) String() string { return proto.CompactTextString(m) }
+func (*DocStatus) ProtoMessage()    {}
+func (*DocStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{5}
+}
+func (m *DocStatus) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DocStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DocStatus.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DocStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DocStatus.Merge(m, src)
+}
+func (m *DocStatus) XXX_Size() int {
+	return m.Size()
+}
+func (m *DocStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_DocStatus.DiscardUnknown(m)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
-
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+var xxx_messageInfo_DocStatus proto.InternalMessageInfo
 
-// IndexServiceClient is the client API for IndexService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type IndexServiceClient interface {
-	DeleteDoc(ctx context.Context, in *DocId, opts ...grpc.CallOption) (*AffectedCount, error)
-	AddDoc(ctx context.Context, in *types.Document, opts ...grpc.CallOption) (*AffectedCount, error)
-	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResult, error)
-	Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*AffectedCount, error)
+func (m *DocStatus) GetDocId() string {
+	if m != nil {
+		return m.DocId
+	}
+	return ""
 }
 
-type indexServiceClient struct {
-	cc *grpc.ClientConn
+func (m *DocStatus) GetOk() bool {
+	if m != nil {
+		return m.Ok
+	}
+	return false
 }
 
-func NewIndexServiceClient(cc *grpc.ClientConn) IndexServiceClient {
-	return &indexServiceClient{cc}
+func (m *DocStatus) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
 }
 
-func (c *indexServiceClient) DeleteDoc(ctx context.Context, in *DocId, opts ...grpc.CallOption) (*AffectedCount, error) {
-	out := new(AffectedCount)
-	err := c.cc.Invoke(ctx, "/index_service.IndexService/DeleteDoc", in, out, opts...)
-	if err != nil {
-		return nil, err
-	}
-	return out, nil
+type BulkAddResult struct {
+	Count    int32        `protobuf:"varint,1,opt,name=Count,proto3" json:"Count,omitempty"`
+	Statuses []*DocStatus `protobuf:"bytes,2,rep,name=Statuses,proto3" json:"Statuses,omitempty"`
 }
 
-func (c *indexServiceClient) AddDoc(ctx context.Context, in *types.Document, opts ...grpc.CallOption) (*AffectedCount, error) {
-	out := new(AffectedCount)
-	err := c.cc.Invoke(ctx, "/index_service.IndexService/AddDoc", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *BulkAddResult) Reset()         { *m = BulkAddResult{} }
+func (m *BulkAddResult) String() string { return proto.CompactTextString(m) }
+func (*BulkAddResult) ProtoMessage()    {}
+func (*BulkAddResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{6}
+}
+func (m *BulkAddResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BulkAddResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BulkAddResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return out, nil
+}
+func (m *BulkAddResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BulkAddResult.Merge(m, src)
+}
+func (m *BulkAddResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *BulkAddResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_BulkAddResult.DiscardUnknown(m)
 }
 
-func (c *indexServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResult, error) {
-	out := new(SearchResult)
-	err := c.cc.Invoke(ctx, "/index_service.IndexService/Search", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_BulkAddResult proto.InternalMessageInfo
+
+func (m *BulkAddResult) GetCount() int32 {
+	if m != nil {
+		return m.Count
 	}
-	return out, nil
+	return 0
 }
 
-func (c *indexServiceClient) Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*AffectedCount, error) {
-	out := new(AffectedCount)
-	err := c.cc.Invoke(ctx, "/index_service.IndexService/Count", in, out, opts...)
-	if err != nil {
-		return nil, err
+func (m *BulkAddResult) GetStatuses() []*DocStatus {
+	if m != nil {
+		return m.Statuses
 	}
-	return out, nil
+	return nil
 }
 
-// IndexServiceServer is the server API for IndexService service.
-type IndexServiceServer interface {
-	DeleteDoc(context.Context, *DocId) (*AffectedCount, error)
-	AddDoc(context.Context, *types.Document) (*AffectedCount, error)
-	Search(context.Context, *SearchRequest) (*SearchResult, error)
-	Count(context.Context, *CountRequest) (*AffectedCount, error)
+type StatsRequest struct {
 }
 
-// UnimplementedIndexServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedIndexServiceServer struct {
+func (m *StatsRequest) Reset()         { *m = StatsRequest{} }
+func (m *StatsRequest) String() string { return proto.CompactTextString(m) }
+func (*StatsRequest) ProtoMessage()    {}
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{7}
 }
-
-func (*UnimplementedIndexServiceServer) DeleteDoc(ctx context.Context, req *DocId) (*AffectedCount, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method DeleteDoc not implemented")
+func (m *StatsRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
 }
-func (*UnimplementedIndexServiceServer) AddDoc(ctx context.Context, req *types.Document) (*AffectedCount, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method AddDoc not implemented")
+func (m *StatsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_StatsRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
 }
-func (*UnimplementedIndexServiceServer) Search(ctx context.Context, req *SearchRequest) (*SearchResult, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+func (m *StatsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StatsRequest.Merge(m, src)
 }
-func (*UnimplementedIndexServiceServer) Count(ctx context.Context, req *CountRequest) (*AffectedCount, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Count not implemented")
+func (m *StatsRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *StatsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_StatsRequest.DiscardUnknown(m)
 }
 
-func RegisterIndexServiceServer(s *grpc.Server, srv IndexServiceServer) {
-	s.RegisterService(&_IndexService_serviceDesc, srv)
+var xxx_messageInfo_StatsRequest proto.InternalMessageInfo
+
+type IndexStats struct {
+	DocCount             int64         `protobuf:"varint,1,opt,name=DocCount,proto3" json:"DocCount,omitempty"`
+	KeywordCount         int64         `protobuf:"varint,2,opt,name=KeywordCount,proto3" json:"KeywordCount,omitempty"`
+	PostingListHistogram []int64       `protobuf:"varint,3,rep,packed,name=PostingListHistogram,proto3" json:"PostingListHistogram,omitempty"`
+	MaxPostingListLength int64         `protobuf:"varint,4,opt,name=MaxPostingListLength,proto3" json:"MaxPostingListLength,omitempty"`
+	ForwardIndexBytes    int64         `protobuf:"varint,5,opt,name=ForwardIndexBytes,proto3" json:"ForwardIndexBytes,omitempty"`
+	MemoryBytes          uint64        `protobuf:"varint,6,opt,name=MemoryBytes,proto3" json:"MemoryBytes,omitempty"`
+	Workers              int32         `protobuf:"varint,7,opt,name=Workers,proto3" json:"Workers,omitempty"`
+	CompactRuns          int64         `protobuf:"varint,8,opt,name=CompactRuns,proto3" json:"CompactRuns,omitempty"`
+	CompactErrors        int64         `protobuf:"varint,9,opt,name=CompactErrors,proto3" json:"CompactErrors,omitempty"`
+	ReclaimedBytes       int64         `protobuf:"varint,10,opt,name=ReclaimedBytes,proto3" json:"ReclaimedBytes,omitempty"`
+	LastCompactAt        int64         `protobuf:"varint,11,opt,name=LastCompactAt,proto3" json:"LastCompactAt,omitempty"`
+	Fields               []*FieldTerms `protobuf:"bytes,12,rep,name=Fields,proto3" json:"Fields,omitempty"`
 }
 
-func _IndexService_DeleteDoc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DocId)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *IndexStats) Reset()         { *m = IndexStats{} }
+func (m *IndexStats) String() string { return proto.CompactTextString(m) }
+func (*IndexStats) ProtoMessage()    {}
+func (*IndexStats) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{8}
+}
+func (m *IndexStats) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *IndexStats) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_IndexStats.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	if interceptor == nil {
-		return srv.(IndexServiceServer).DeleteDoc(ctx, in)
+}
+func (m *IndexStats) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_IndexStats.Merge(m, src)
+}
+func (m *IndexStats) XXX_Size() int {
+	return m.Size()
+}
+func (m *IndexStats) XXX_DiscardUnknown() {
+	xxx_messageInfo_IndexStats.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_IndexStats proto.InternalMessageInfo
+
+func (m *IndexStats) GetDocCount() int64 {
+	if m != nil {
+		return m.DocCount
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/index_service.IndexService/DeleteDoc",
+	return 0
+}
+
+func (m *IndexStats) GetKeywordCount() int64 {
+	if m != nil {
+		return m.KeywordCount
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(IndexServiceServer).DeleteDoc(ctx, req.(*DocId))
+	return 0
+}
+
+func (m *IndexStats) GetPostingListHistogram() []int64 {
+	if m != nil {
+		return m.PostingListHistogram
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _IndexService_AddDoc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(types.Document)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *IndexStats) GetMaxPostingListLength() int64 {
+	if m != nil {
+		return m.MaxPostingListLength
 	}
-	if interceptor == nil {
-		return srv.(IndexServiceServer).AddDoc(ctx, in)
+	return 0
+}
+
+func (m *IndexStats) GetForwardIndexBytes() int64 {
+	if m != nil {
+		return m.ForwardIndexBytes
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/index_service.IndexService/AddDoc",
+	return 0
+}
+
+func (m *IndexStats) GetMemoryBytes() uint64 {
+	if m != nil {
+		return m.MemoryBytes
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(IndexServiceServer).AddDoc(ctx, req.(*types.Document))
+	return 0
+}
+
+func (m *IndexStats) GetWorkers() int32 {
+	if m != nil {
+		return m.Workers
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _IndexService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(SearchRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *IndexStats) GetCompactRuns() int64 {
+	if m != nil {
+		return m.CompactRuns
 	}
-	if interceptor == nil {
-		return srv.(IndexServiceServer).Search(ctx, in)
+	return 0
+}
+
+func (m *IndexStats) GetCompactErrors() int64 {
+	if m != nil {
+		return m.CompactErrors
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/index_service.IndexService/Search",
+	return 0
+}
+
+func (m *IndexStats) GetReclaimedBytes() int64 {
+	if m != nil {
+		return m.ReclaimedBytes
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(IndexServiceServer).Search(ctx, req.(*SearchRequest))
+	return 0
+}
+
+func (m *IndexStats) GetLastCompactAt() int64 {
+	if m != nil {
+		return m.LastCompactAt
 	}
-	return interceptor(ctx, in, info, handler)
+	return 0
 }
 
-func _IndexService_Count_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CountRequest)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *IndexStats) GetFields() []*FieldTerms {
+	if m != nil {
+		return m.Fields
 	}
-	if interceptor == nil {
-		return srv.(IndexServiceServer).Count(ctx, in)
-	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/index_service.IndexService/Count",
-	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(IndexServiceServer).Count(ctx, req.(*CountRequest))
-	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-var _IndexService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "index_service.IndexService",
-	HandlerType: (*IndexServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "DeleteDoc",
-			Handler:    _IndexService_DeleteDoc_Handler,
-		},
-		{
-			MethodName: "AddDoc",
-			Handler:    _IndexService_AddDoc_Handler,
-		},
-		{
-			MethodName: "Search",
-			Handler:    _IndexService_Search_Handler,
-		},
-		{
-			MethodName: "Count",
-			Handler:    _IndexService_Count_Handler,
-		},
-	},
-	Streams:  []grpc.StreamDesc{},
-	Metadata: "index.proto",
+type FieldTerms struct {
+	Field     string `protobuf:"bytes,1,opt,name=Field,proto3" json:"Field,omitempty"`
+	TermCount int64  `protobuf:"varint,2,opt,name=TermCount,proto3" json:"TermCount,omitempty"`
 }
 
-func (m *DocId) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *FieldTerms) Reset()         { *m = FieldTerms{} }
+func (m *FieldTerms) String() string { return proto.CompactTextString(m) }
+func (*FieldTerms) ProtoMessage()    {}
+func (*FieldTerms) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{9}
+}
+func (m *FieldTerms) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FieldTerms) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FieldTerms.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return dAtA[:n], nil
 }
-
-func (m *DocId) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (m *FieldTerms) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldTerms.Merge(m, src)
+}
+func (m *FieldTerms) XXX_Size() int {
+	return m.Size()
+}
+func (m *FieldTerms) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldTerms.DiscardUnknown(m)
 }
 
-func (m *DocId) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if len(m.DocId) > 0 {
-		i -= len(m.DocId)
-		copy(dAtA[i:], m.DocId)
-		i = encodeVarintIndex(dAtA, i, uint64(len(m.DocId)))
-		i--
-		dAtA[i] = 0xa
+var xxx_messageInfo_FieldTerms proto.InternalMessageInfo
+
+func (m *FieldTerms) GetField() string {
+	if m != nil {
+		return m.Field
 	}
-	return len(dAtA) - i, nil
+	return ""
 }
 
-func (m *AffectedCount) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *FieldTerms) GetTermCount() int64 {
+	if m != nil {
+		return m.TermCount
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *AffectedCount) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type ChangeEvent struct {
+	Seq   uint64          `protobuf:"varint,1,opt,name=Seq,proto3" json:"Seq,omitempty"`
+	Op    ChangeOp        `protobuf:"varint,2,opt,name=Op,proto3,enum=index_service.ChangeOp" json:"Op,omitempty"`
+	Doc   *types.Document `protobuf:"bytes,3,opt,name=Doc,proto3" json:"Doc,omitempty"`
+	DocId string          `protobuf:"bytes,4,opt,name=DocId,proto3" json:"DocId,omitempty"`
 }
 
-func (m *AffectedCount) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if m.Count != 0 {
-		i = encodeVarintIndex(dAtA, i, uint64(m.Count))
-		i--
-		dAtA[i] = 0x8
+func (m *ChangeEvent) Reset()         { *m = ChangeEvent{} }
+func (m *ChangeEvent) String() string { return proto.CompactTextString(m) }
+func (*ChangeEvent) ProtoMessage()    {}
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{10}
+}
+func (m *ChangeEvent) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *ChangeEvent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_ChangeEvent.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	return len(dAtA) - i, nil
+}
+func (m *ChangeEvent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChangeEvent.Merge(m, src)
+}
+func (m *ChangeEvent) XXX_Size() int {
+	return m.Size()
+}
+func (m *ChangeEvent) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChangeEvent.DiscardUnknown(m)
 }
 
-func (m *SearchRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_ChangeEvent proto.InternalMessageInfo
+
+func (m *ChangeEvent) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *SearchRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+func (m *ChangeEvent) GetOp() ChangeOp {
+	if m != nil {
+		return m.Op
+	}
+	return ChangeOp_ADD
 }
 
-func (m *SearchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if len(m.OrFlags) > 0 {
-		dAtA2 := make([]byte, len(m.OrFlags)*10)
-		var j1 int
-		for _, num := range m.OrFlags {
-			for num >= 1<<7 {
-				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
-				num >>= 7
-				j1++
-			}
-			dAtA2[j1] = uint8(num)
-			j1++
-		}
-		i -= j1
-		copy(dAtA[i:], dAtA2[:j1])
-		i = encodeVarintIndex(dAtA, i, uint64(j1))
-		i--
-		dAtA[i] = 0x22
-	}
-	if m.OffFlag != 0 {
-		i = encodeVarintIndex(dAtA, i, uint64(m.OffFlag))
-		i--
-		dAtA[i] = 0x18
-	}
-	if m.OnFlag != 0 {
-		i = encodeVarintIndex(dAtA, i, uint64(m.OnFlag))
-		i--
-		dAtA[i] = 0x10
-	}
-	if m.Query != nil {
-		{
-			size, err := m.Query.MarshalToSizedBuffer(dAtA[:i])
-			if err != nil {
-				return 0, err
-			}
-			i -= size
-			i = encodeVarintIndex(dAtA, i, uint64(size))
-		}
-		i--
-		dAtA[i] = 0xa
+func (m *ChangeEvent) GetDoc() *types.Document {
+	if m != nil {
+		return m.Doc
 	}
-	return len(dAtA) - i, nil
+	return nil
 }
 
-func (m *SearchResult) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+func (m *ChangeEvent) GetDocId() string {
+	if m != nil {
+		return m.DocId
 	}
-	return dAtA[:n], nil
+	return ""
 }
 
-func (m *SearchResult) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type SubscribeRequest struct {
+	FromSeq uint64 `protobuf:"varint,1,opt,name=FromSeq,proto3" json:"FromSeq,omitempty"`
 }
 
-func (m *SearchResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	if len(m.Results) > 0 {
-		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
-			{
-				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
-				if err != nil {
-					return 0, err
-				}
-				i -= size
-				i = encodeVarintIndex(dAtA, i, uint64(size))
-			}
-			i--
-			dAtA[i] = 0xa
+func (m *SubscribeRequest) Reset()         { *m = SubscribeRequest{} }
+func (m *SubscribeRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeRequest) ProtoMessage()    {}
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{11}
+}
+func (m *SubscribeRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SubscribeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SubscribeRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
 		}
+		return b[:n], nil
 	}
-	return len(dAtA) - i, nil
+}
+func (m *SubscribeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SubscribeRequest.Merge(m, src)
+}
+func (m *SubscribeRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SubscribeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SubscribeRequest.DiscardUnknown(m)
 }
 
-func (m *CountRequest) Marshal() (dAtA []byte, err error) {
-	size := m.Size()
-	dAtA = make([]byte, size)
-	n, err := m.MarshalToSizedBuffer(dAtA[:size])
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_SubscribeRequest proto.InternalMessageInfo
+
+func (m *SubscribeRequest) GetFromSeq() uint64 {
+	if m != nil {
+		return m.FromSeq
 	}
-	return dAtA[:n], nil
+	return 0
 }
 
-func (m *CountRequest) MarshalTo(dAtA []byte) (int, error) {
-	size := m.Size()
-	return m.MarshalToSizedBuffer(dAtA[:size])
+type BackupRequest struct {
 }
 
-func (m *CountRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
-	i := len(dAtA)
-	_ = i
-	var l int
-	_ = l
-	return len(dAtA) - i, nil
+func (m *BackupRequest) Reset()         { *m = BackupRequest{} }
+func (m *BackupRequest) String() string { return proto.CompactTextString(m) }
+func (*BackupRequest) ProtoMessage()    {}
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{12}
 }
-
-func encodeVarintIndex(dAtA []byte, offset int, v uint64) int {
-	offset -= sovIndex(v)
-	base := offset
-	for v >= 1<<7 {
-		dAtA[offset] = uint8(v&0x7f | 0x80)
-		v >>= 7
-		offset++
+func (m *BackupRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BackupRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
 	}
-	dAtA[offset] = uint8(v)
-	return base
+}
+func (m *BackupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupRequest.Merge(m, src)
+}
+func (m *BackupRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BackupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupRequest proto.InternalMessageInfo
+
+type BackupChunk struct {
+	Data         []byte `protobuf:"bytes,1,opt,name=Data,proto3" json:"Data,omitempty"`
+	ChangeLogSeq uint64 `protobuf:"varint,2,opt,name=ChangeLogSeq,proto3" json:"ChangeLogSeq,omitempty"`
+}
+
+func (m *BackupChunk) Reset()         { *m = BackupChunk{} }
+func (m *BackupChunk) String() string { return proto.CompactTextString(m) }
+func (*BackupChunk) ProtoMessage()    {}
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{13}
+}
+func (m *BackupChunk) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BackupChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BackupChunk.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BackupChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupChunk.Merge(m, src)
+}
+func (m *BackupChunk) XXX_Size() int {
+	return m.Size()
+}
+func (m *BackupChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupChunk proto.InternalMessageInfo
+
+func (m *BackupChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *BackupChunk) GetChangeLogSeq() uint64 {
+	if m != nil {
+		return m.ChangeLogSeq
+	}
+	return 0
+}
+
+type AddDocRequest struct {
+	Doc             *types.Document `protobuf:"bytes,1,opt,name=Doc,proto3" json:"Doc,omitempty"`
+	IfVersion       int64           `protobuf:"varint,2,opt,name=IfVersion,proto3" json:"IfVersion,omitempty"`
+	ExternalVersion bool            `protobuf:"varint,3,opt,name=ExternalVersion,proto3" json:"ExternalVersion,omitempty"`
+}
+
+func (m *AddDocRequest) Reset()         { *m = AddDocRequest{} }
+func (m *AddDocRequest) String() string { return proto.CompactTextString(m) }
+func (*AddDocRequest) ProtoMessage()    {}
+func (*AddDocRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{14}
+}
+func (m *AddDocRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *AddDocRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_AddDocRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *AddDocRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AddDocRequest.Merge(m, src)
+}
+func (m *AddDocRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *AddDocRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AddDocRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AddDocRequest proto.InternalMessageInfo
+
+func (m *AddDocRequest) GetDoc() *types.Document {
+	if m != nil {
+		return m.Doc
+	}
+	return nil
+}
+
+func (m *AddDocRequest) GetIfVersion() int64 {
+	if m != nil {
+		return m.IfVersion
+	}
+	return 0
+}
+
+func (m *AddDocRequest) GetExternalVersion() bool {
+	if m != nil {
+		return m.ExternalVersion
+	}
+	return false
+}
+
+type DocVersion struct {
+	Version int64 `protobuf:"varint,1,opt,name=Version,proto3" json:"Version,omitempty"`
+}
+
+func (m *DocVersion) Reset()         { *m = DocVersion{} }
+func (m *DocVersion) String() string { return proto.CompactTextString(m) }
+func (*DocVersion) ProtoMessage()    {}
+func (*DocVersion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{15}
+}
+func (m *DocVersion) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DocVersion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DocVersion.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DocVersion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DocVersion.Merge(m, src)
+}
+func (m *DocVersion) XXX_Size() int {
+	return m.Size()
+}
+func (m *DocVersion) XXX_DiscardUnknown() {
+	xxx_messageInfo_DocVersion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DocVersion proto.InternalMessageInfo
+
+func (m *DocVersion) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+type DocPatch struct {
+	DocId          string           `protobuf:"bytes,1,opt,name=DocId,proto3" json:"DocId,omitempty"`
+	AddKeywords    []*types.Keyword `protobuf:"bytes,2,rep,name=AddKeywords,proto3" json:"AddKeywords,omitempty"`
+	RemoveKeywords []*types.Keyword `protobuf:"bytes,3,rep,name=RemoveKeywords,proto3" json:"RemoveKeywords,omitempty"`
+	SetBits        uint64           `protobuf:"varint,4,opt,name=SetBits,proto3" json:"SetBits,omitempty"`
+	ClearBits      uint64           `protobuf:"varint,5,opt,name=ClearBits,proto3" json:"ClearBits,omitempty"`
+	Bytes          []byte           `protobuf:"bytes,6,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	ReplaceBytes   bool             `protobuf:"varint,7,opt,name=ReplaceBytes,proto3" json:"ReplaceBytes,omitempty"`
+	IfVersion      int64            `protobuf:"varint,8,opt,name=IfVersion,proto3" json:"IfVersion,omitempty"`
+}
+
+func (m *DocPatch) Reset()         { *m = DocPatch{} }
+func (m *DocPatch) String() string { return proto.CompactTextString(m) }
+func (*DocPatch) ProtoMessage()    {}
+func (*DocPatch) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{16}
+}
+func (m *DocPatch) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DocPatch) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DocPatch.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DocPatch) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DocPatch.Merge(m, src)
+}
+func (m *DocPatch) XXX_Size() int {
+	return m.Size()
+}
+func (m *DocPatch) XXX_DiscardUnknown() {
+	xxx_messageInfo_DocPatch.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DocPatch proto.InternalMessageInfo
+
+func (m *DocPatch) GetDocId() string {
+	if m != nil {
+		return m.DocId
+	}
+	return ""
+}
+
+func (m *DocPatch) GetAddKeywords() []*types.Keyword {
+	if m != nil {
+		return m.AddKeywords
+	}
+	return nil
+}
+
+func (m *DocPatch) GetRemoveKeywords() []*types.Keyword {
+	if m != nil {
+		return m.RemoveKeywords
+	}
+	return nil
+}
+
+func (m *DocPatch) GetSetBits() uint64 {
+	if m != nil {
+		return m.SetBits
+	}
+	return 0
+}
+
+func (m *DocPatch) GetClearBits() uint64 {
+	if m != nil {
+		return m.ClearBits
+	}
+	return 0
+}
+
+func (m *DocPatch) GetBytes() []byte {
+	if m != nil {
+		return m.Bytes
+	}
+	return nil
+}
+
+func (m *DocPatch) GetReplaceBytes() bool {
+	if m != nil {
+		return m.ReplaceBytes
+	}
+	return false
+}
+
+func (m *DocPatch) GetIfVersion() int64 {
+	if m != nil {
+		return m.IfVersion
+	}
+	return 0
+}
+
+type BatchDeleteRequest struct {
+	DocIds []string `protobuf:"bytes,1,rep,name=DocIds,proto3" json:"DocIds,omitempty"`
+}
+
+func (m *BatchDeleteRequest) Reset()         { *m = BatchDeleteRequest{} }
+func (m *BatchDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*BatchDeleteRequest) ProtoMessage()    {}
+func (*BatchDeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{17}
+}
+func (m *BatchDeleteRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *BatchDeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_BatchDeleteRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *BatchDeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BatchDeleteRequest.Merge(m, src)
+}
+func (m *BatchDeleteRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *BatchDeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BatchDeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BatchDeleteRequest proto.InternalMessageInfo
+
+func (m *BatchDeleteRequest) GetDocIds() []string {
+	if m != nil {
+		return m.DocIds
+	}
+	return nil
+}
+
+type DeleteByQueryRequest struct {
+	Query   *types.TermQuery `protobuf:"bytes,1,opt,name=Query,proto3" json:"Query,omitempty"`
+	OnFlag  uint64           `protobuf:"varint,2,opt,name=OnFlag,proto3" json:"OnFlag,omitempty"`
+	OffFlag uint64           `protobuf:"varint,3,opt,name=OffFlag,proto3" json:"OffFlag,omitempty"`
+	OrFlags []uint64         `protobuf:"varint,4,rep,packed,name=OrFlags,proto3" json:"OrFlags,omitempty"`
+}
+
+func (m *DeleteByQueryRequest) Reset()         { *m = DeleteByQueryRequest{} }
+func (m *DeleteByQueryRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteByQueryRequest) ProtoMessage()    {}
+func (*DeleteByQueryRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{18}
+}
+func (m *DeleteByQueryRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DeleteByQueryRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DeleteByQueryRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DeleteByQueryRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteByQueryRequest.Merge(m, src)
+}
+func (m *DeleteByQueryRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DeleteByQueryRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteByQueryRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteByQueryRequest proto.InternalMessageInfo
+
+func (m *DeleteByQueryRequest) GetQuery() *types.TermQuery {
+	if m != nil {
+		return m.Query
+	}
+	return nil
+}
+
+func (m *DeleteByQueryRequest) GetOnFlag() uint64 {
+	if m != nil {
+		return m.OnFlag
+	}
+	return 0
+}
+
+func (m *DeleteByQueryRequest) GetOffFlag() uint64 {
+	if m != nil {
+		return m.OffFlag
+	}
+	return 0
+}
+
+func (m *DeleteByQueryRequest) GetOrFlags() []uint64 {
+	if m != nil {
+		return m.OrFlags
+	}
+	return nil
+}
+
+type CompactRequest struct {
+	Threshold float64 `protobuf:"fixed64,1,opt,name=Threshold,proto3" json:"Threshold,omitempty"`
+}
+
+func (m *CompactRequest) Reset()         { *m = CompactRequest{} }
+func (m *CompactRequest) String() string { return proto.CompactTextString(m) }
+func (*CompactRequest) ProtoMessage()    {}
+func (*CompactRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{19}
+}
+func (m *CompactRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CompactRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CompactRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CompactRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactRequest.Merge(m, src)
+}
+func (m *CompactRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CompactRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactRequest proto.InternalMessageInfo
+
+func (m *CompactRequest) GetThreshold() float64 {
+	if m != nil {
+		return m.Threshold
+	}
+	return 0
+}
+
+type CompactResult struct {
+	BeforeBytes int64 `protobuf:"varint,1,opt,name=BeforeBytes,proto3" json:"BeforeBytes,omitempty"`
+	AfterBytes  int64 `protobuf:"varint,2,opt,name=AfterBytes,proto3" json:"AfterBytes,omitempty"`
+	Compacted   bool  `protobuf:"varint,3,opt,name=Compacted,proto3" json:"Compacted,omitempty"`
+}
+
+func (m *CompactResult) Reset()         { *m = CompactResult{} }
+func (m *CompactResult) String() string { return proto.CompactTextString(m) }
+func (*CompactResult) ProtoMessage()    {}
+func (*CompactResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{20}
+}
+func (m *CompactResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CompactResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CompactResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CompactResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CompactResult.Merge(m, src)
+}
+func (m *CompactResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *CompactResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_CompactResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CompactResult proto.InternalMessageInfo
+
+func (m *CompactResult) GetBeforeBytes() int64 {
+	if m != nil {
+		return m.BeforeBytes
+	}
+	return 0
+}
+
+func (m *CompactResult) GetAfterBytes() int64 {
+	if m != nil {
+		return m.AfterBytes
+	}
+	return 0
+}
+
+func (m *CompactResult) GetCompacted() bool {
+	if m != nil {
+		return m.Compacted
+	}
+	return false
+}
+
+type DidYouMeanRequest struct {
+	Field string `protobuf:"bytes,1,opt,name=Field,proto3" json:"Field,omitempty"`
+	Word  string `protobuf:"bytes,2,opt,name=Word,proto3" json:"Word,omitempty"`
+	Limit int32  `protobuf:"varint,3,opt,name=Limit,proto3" json:"Limit,omitempty"`
+}
+
+func (m *DidYouMeanRequest) Reset()         { *m = DidYouMeanRequest{} }
+func (m *DidYouMeanRequest) String() string { return proto.CompactTextString(m) }
+func (*DidYouMeanRequest) ProtoMessage()    {}
+func (*DidYouMeanRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{21}
+}
+func (m *DidYouMeanRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DidYouMeanRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DidYouMeanRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DidYouMeanRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DidYouMeanRequest.Merge(m, src)
+}
+func (m *DidYouMeanRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *DidYouMeanRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DidYouMeanRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DidYouMeanRequest proto.InternalMessageInfo
+
+func (m *DidYouMeanRequest) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *DidYouMeanRequest) GetWord() string {
+	if m != nil {
+		return m.Word
+	}
+	return ""
+}
+
+func (m *DidYouMeanRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type Correction struct {
+	Word     string `protobuf:"bytes,1,opt,name=Word,proto3" json:"Word,omitempty"`
+	DocFreq  int64  `protobuf:"varint,2,opt,name=DocFreq,proto3" json:"DocFreq,omitempty"`
+	Distance int32  `protobuf:"varint,3,opt,name=Distance,proto3" json:"Distance,omitempty"`
+}
+
+func (m *Correction) Reset()         { *m = Correction{} }
+func (m *Correction) String() string { return proto.CompactTextString(m) }
+func (*Correction) ProtoMessage()    {}
+func (*Correction) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{22}
+}
+func (m *Correction) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Correction) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Correction.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Correction) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Correction.Merge(m, src)
+}
+func (m *Correction) XXX_Size() int {
+	return m.Size()
+}
+func (m *Correction) XXX_DiscardUnknown() {
+	xxx_messageInfo_Correction.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Correction proto.InternalMessageInfo
+
+func (m *Correction) GetWord() string {
+	if m != nil {
+		return m.Word
+	}
+	return ""
+}
+
+func (m *Correction) GetDocFreq() int64 {
+	if m != nil {
+		return m.DocFreq
+	}
+	return 0
+}
+
+func (m *Correction) GetDistance() int32 {
+	if m != nil {
+		return m.Distance
+	}
+	return 0
+}
+
+type DidYouMeanResult struct {
+	Corrections []*Correction `protobuf:"bytes,1,rep,name=Corrections,proto3" json:"Corrections,omitempty"`
+}
+
+func (m *DidYouMeanResult) Reset()         { *m = DidYouMeanResult{} }
+func (m *DidYouMeanResult) String() string { return proto.CompactTextString(m) }
+func (*DidYouMeanResult) ProtoMessage()    {}
+func (*DidYouMeanResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{23}
+}
+func (m *DidYouMeanResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *DidYouMeanResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_DidYouMeanResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *DidYouMeanResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DidYouMeanResult.Merge(m, src)
+}
+func (m *DidYouMeanResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *DidYouMeanResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_DidYouMeanResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DidYouMeanResult proto.InternalMessageInfo
+
+func (m *DidYouMeanResult) GetCorrections() []*Correction {
+	if m != nil {
+		return m.Corrections
+	}
+	return nil
+}
+
+type SuggestRequest struct {
+	Prefix string   `protobuf:"bytes,1,opt,name=Prefix,proto3" json:"Prefix,omitempty"`
+	Fields []string `protobuf:"bytes,2,rep,name=Fields,proto3" json:"Fields,omitempty"`
+	Limit  int32    `protobuf:"varint,3,opt,name=Limit,proto3" json:"Limit,omitempty"`
+}
+
+func (m *SuggestRequest) Reset()         { *m = SuggestRequest{} }
+func (m *SuggestRequest) String() string { return proto.CompactTextString(m) }
+func (*SuggestRequest) ProtoMessage()    {}
+func (*SuggestRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{24}
+}
+func (m *SuggestRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SuggestRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SuggestRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SuggestRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SuggestRequest.Merge(m, src)
+}
+func (m *SuggestRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *SuggestRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_SuggestRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SuggestRequest proto.InternalMessageInfo
+
+func (m *SuggestRequest) GetPrefix() string {
+	if m != nil {
+		return m.Prefix
+	}
+	return ""
+}
+
+func (m *SuggestRequest) GetFields() []string {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+func (m *SuggestRequest) GetLimit() int32 {
+	if m != nil {
+		return m.Limit
+	}
+	return 0
+}
+
+type Suggestion struct {
+	Field  string `protobuf:"bytes,1,opt,name=Field,proto3" json:"Field,omitempty"`
+	Word   string `protobuf:"bytes,2,opt,name=Word,proto3" json:"Word,omitempty"`
+	Weight int64  `protobuf:"varint,3,opt,name=Weight,proto3" json:"Weight,omitempty"`
+}
+
+func (m *Suggestion) Reset()         { *m = Suggestion{} }
+func (m *Suggestion) String() string { return proto.CompactTextString(m) }
+func (*Suggestion) ProtoMessage()    {}
+func (*Suggestion) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{25}
+}
+func (m *Suggestion) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Suggestion) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Suggestion.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Suggestion) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Suggestion.Merge(m, src)
+}
+func (m *Suggestion) XXX_Size() int {
+	return m.Size()
+}
+func (m *Suggestion) XXX_DiscardUnknown() {
+	xxx_messageInfo_Suggestion.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Suggestion proto.InternalMessageInfo
+
+func (m *Suggestion) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *Suggestion) GetWord() string {
+	if m != nil {
+		return m.Word
+	}
+	return ""
+}
+
+func (m *Suggestion) GetWeight() int64 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+type SuggestResult struct {
+	Suggestions []*Suggestion `protobuf:"bytes,1,rep,name=Suggestions,proto3" json:"Suggestions,omitempty"`
+}
+
+func (m *SuggestResult) Reset()         { *m = SuggestResult{} }
+func (m *SuggestResult) String() string { return proto.CompactTextString(m) }
+func (*SuggestResult) ProtoMessage()    {}
+func (*SuggestResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f750e0f7889345b5, []int{26}
+}
+func (m *SuggestResult) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *SuggestResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_SuggestResult.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *SuggestResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SuggestResult.Merge(m, src)
+}
+func (m *SuggestResult) XXX_Size() int {
+	return m.Size()
+}
+func (m *SuggestResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_SuggestResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SuggestResult proto.InternalMessageInfo
+
+func (m *SuggestResult) GetSuggestions() []*Suggestion {
+	if m != nil {
+		return m.Suggestions
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("index_service.ChangeOp", ChangeOp_name, ChangeOp_value)
+	proto.RegisterType((*DocId)(nil), "index_service.DocId")
+	proto.RegisterType((*AffectedCount)(nil), "index_service.AffectedCount")
+	proto.RegisterType((*SearchRequest)(nil), "index_service.SearchRequest")
+	proto.RegisterType((*SearchResult)(nil), "index_service.SearchResult")
+	proto.RegisterType((*CountRequest)(nil), "index_service.CountRequest")
+	proto.RegisterType((*DocStatus)(nil), "index_service.DocStatus")
+	proto.RegisterType((*BulkAddResult)(nil), "index_service.BulkAddResult")
+	proto.RegisterType((*StatsRequest)(nil), "index_service.StatsRequest")
+	proto.RegisterType((*IndexStats)(nil), "index_service.IndexStats")
+	proto.RegisterType((*FieldTerms)(nil), "index_service.FieldTerms")
+	proto.RegisterType((*ChangeEvent)(nil), "index_service.ChangeEvent")
+	proto.RegisterType((*SubscribeRequest)(nil), "index_service.SubscribeRequest")
+	proto.RegisterType((*BackupRequest)(nil), "index_service.BackupRequest")
+	proto.RegisterType((*BackupChunk)(nil), "index_service.BackupChunk")
+	proto.RegisterType((*AddDocRequest)(nil), "index_service.AddDocRequest")
+	proto.RegisterType((*DocVersion)(nil), "index_service.DocVersion")
+	proto.RegisterType((*DocPatch)(nil), "index_service.DocPatch")
+	proto.RegisterType((*BatchDeleteRequest)(nil), "index_service.BatchDeleteRequest")
+	proto.RegisterType((*DeleteByQueryRequest)(nil), "index_service.DeleteByQueryRequest")
+	proto.RegisterType((*CompactRequest)(nil), "index_service.CompactRequest")
+	proto.RegisterType((*CompactResult)(nil), "index_service.CompactResult")
+	proto.RegisterType((*DidYouMeanRequest)(nil), "index_service.DidYouMeanRequest")
+	proto.RegisterType((*Correction)(nil), "index_service.Correction")
+	proto.RegisterType((*DidYouMeanResult)(nil), "index_service.DidYouMeanResult")
+	proto.RegisterType((*SuggestRequest)(nil), "index_service.SuggestRequest")
+	proto.RegisterType((*Suggestion)(nil), "index_service.Suggestion")
+	proto.RegisterType((*SuggestResult)(nil), "index_service.SuggestResult")
+}
+
+func init() { proto.RegisterFile("index.proto", fileDescriptor_f750e0f7889345b5) }
+
+var fileDescriptor_f750e0f7889345b5 = []byte{
+	// 1466 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x57, 0xcd, 0x72, 0x1b, 0xc5,
+	0x16, 0xf6, 0x68, 0x64, 0xc9, 0x3a, 0xb2, 0x1c, 0xa7, 0x2b, 0x37, 0x99, 0xab, 0x38, 0x8e, 0x32,
+	0xf7, 0x12, 0x0c, 0x95, 0x72, 0x8c, 0xa1, 0xa0, 0x80, 0x45, 0xb0, 0x3c, 0x32, 0x98, 0x28, 0x51,
+	0x68, 0x85, 0xb8, 0x28, 0x16, 0xa9, 0xc9, 0x4c, 0x4b, 0x9a, 0xb2, 0xa4, 0x56, 0x7a, 0x5a, 0x89,
+	0x9d, 0x1d, 0x5b, 0x56, 0xac, 0x79, 0x11, 0x5e, 0x21, 0xcb, 0x2c, 0x59, 0x52, 0xc9, 0x82, 0xd7,
+	0xa0, 0xfa, 0xf4, 0x8c, 0xe6, 0x47, 0xb2, 0x4d, 0x15, 0x0b, 0x76, 0x73, 0x7e, 0xfb, 0xeb, 0xd3,
+	0xe7, 0x6f, 0xa0, 0x1a, 0x8c, 0x7d, 0x76, 0xb2, 0x3d, 0x11, 0x5c, 0x72, 0x52, 0x43, 0xe2, 0x69,
+	0xc8, 0xc4, 0x8b, 0xc0, 0x63, 0xf5, 0xff, 0xc8, 0xd3, 0x09, 0x0b, 0xef, 0xa2, 0xec, 0xae, 0xcf,
+	0x3d, 0xad, 0x55, 0xdf, 0x48, 0xb3, 0x25, 0x13, 0xa3, 0xa7, 0xcf, 0xa7, 0x4c, 0x9c, 0x6a, 0xa9,
+	0x7d, 0x03, 0x96, 0x1d, 0xee, 0x1d, 0xfa, 0xe4, 0x4a, 0xf4, 0x61, 0x19, 0x0d, 0x63, 0xab, 0x42,
+	0x35, 0x61, 0xbf, 0x07, 0xb5, 0xbd, 0x5e, 0x8f, 0x79, 0x92, 0xf9, 0xfb, 0x7c, 0x3a, 0x96, 0x4a,
+	0x0d, 0x3f, 0x50, 0x6d, 0x99, 0x6a, 0xc2, 0xfe, 0xcd, 0x80, 0x5a, 0x97, 0xb9, 0xc2, 0x1b, 0x50,
+	0xf6, 0x7c, 0xca, 0x42, 0x49, 0x6e, 0xc3, 0xf2, 0x77, 0xea, 0x18, 0xd4, 0xab, 0xee, 0xae, 0x6f,
+	0x23, 0x8a, 0xed, 0xc7, 0x4c, 0x8c, 0x90, 0x4f, 0xb5, 0x98, 0x5c, 0x85, 0x52, 0x67, 0x7c, 0x30,
+	0x74, 0xfb, 0x56, 0xa1, 0x61, 0x6c, 0x15, 0x69, 0x44, 0x11, 0x0b, 0xca, 0x9d, 0x5e, 0x0f, 0x05,
+	0x26, 0x0a, 0x62, 0x12, 0x25, 0x42, 0x7d, 0x85, 0x56, 0xb1, 0x61, 0xa2, 0x44, 0x93, 0x64, 0x03,
+	0x2a, 0xfb, 0x83, 0xe9, 0xf8, 0xb8, 0x1b, 0xbc, 0x62, 0xd6, 0x32, 0xe2, 0x4b, 0x18, 0x0a, 0x79,
+	0x3b, 0x18, 0x05, 0xd2, 0x2a, 0x69, 0xe4, 0x48, 0xd8, 0x9f, 0xc3, 0x6a, 0x0c, 0x3c, 0x9c, 0x0e,
+	0x25, 0xf9, 0x00, 0xca, 0xfa, 0x2b, 0xb4, 0x8c, 0x86, 0xb9, 0x55, 0xdd, 0xbd, 0x14, 0x21, 0x77,
+	0xb8, 0x37, 0x1d, 0xb1, 0xb1, 0xa4, 0xb1, 0xdc, 0x5e, 0x83, 0x55, 0xbc, 0x7d, 0x74, 0x65, 0xfb,
+	0x6b, 0xa8, 0x38, 0xdc, 0xeb, 0x4a, 0x57, 0x4e, 0xc3, 0xc5, 0xe1, 0x24, 0x6b, 0x50, 0xe8, 0x1c,
+	0xe3, 0x4d, 0x57, 0x68, 0xa1, 0x73, 0xac, 0xb4, 0x5a, 0x42, 0x70, 0x81, 0x77, 0xac, 0x50, 0x4d,
+	0xd8, 0x3f, 0x42, 0xad, 0x39, 0x1d, 0x1e, 0xef, 0xf9, 0x7e, 0x04, 0x6a, 0x61, 0xd0, 0xc9, 0x27,
+	0xb0, 0xa2, 0x0f, 0x63, 0xa1, 0x55, 0x40, 0xac, 0xd6, 0x76, 0x26, 0x23, 0xb6, 0x67, 0x70, 0xe8,
+	0x4c, 0x53, 0xa1, 0x56, 0xdf, 0x61, 0x8c, 0xfa, 0x4f, 0x13, 0xe0, 0x50, 0x59, 0x21, 0x97, 0xd4,
+	0x61, 0xc5, 0xe1, 0x5e, 0x72, 0x9a, 0x49, 0x67, 0x34, 0xb1, 0x61, 0xf5, 0x3e, 0x3b, 0x7d, 0xc9,
+	0x85, 0xce, 0x05, 0xbc, 0x87, 0x49, 0x33, 0x3c, 0xb2, 0x0b, 0x57, 0x1e, 0xf1, 0x50, 0x06, 0xe3,
+	0x7e, 0x3b, 0x08, 0xe5, 0x37, 0x41, 0x28, 0x79, 0x5f, 0xb8, 0x23, 0xcb, 0x6c, 0x98, 0x5b, 0x26,
+	0x5d, 0x28, 0x53, 0x36, 0x0f, 0xdc, 0x93, 0x94, 0xa8, 0xcd, 0xc6, 0x7d, 0x39, 0xb0, 0x8a, 0xe8,
+	0x7f, 0xa1, 0x8c, 0xdc, 0x81, 0xcb, 0x07, 0x5c, 0xbc, 0x74, 0x85, 0x8f, 0xe0, 0x9b, 0xa7, 0x92,
+	0x85, 0xf8, 0xe6, 0x26, 0x9d, 0x17, 0x90, 0x06, 0x54, 0x1f, 0xb0, 0x11, 0x17, 0xa7, 0x5a, 0xaf,
+	0x84, 0x19, 0x95, 0x66, 0xa9, 0xac, 0x3a, 0xe2, 0xe2, 0x98, 0x89, 0xd0, 0x2a, 0x63, 0x90, 0x63,
+	0x52, 0xd9, 0xee, 0xf3, 0xd1, 0xc4, 0xf5, 0x24, 0x9d, 0x8e, 0x43, 0x6b, 0x05, 0xcf, 0x48, 0xb3,
+	0xc8, 0xff, 0xa1, 0x16, 0x91, 0xf8, 0x7e, 0xa1, 0x55, 0x41, 0x9d, 0x2c, 0x93, 0xdc, 0x86, 0x35,
+	0xca, 0xbc, 0xa1, 0x1b, 0x8c, 0x98, 0xaf, 0x61, 0x00, 0xaa, 0xe5, 0xb8, 0xca, 0x5b, 0xdb, 0x0d,
+	0x65, 0x64, 0xbc, 0x27, 0xad, 0xaa, 0xf6, 0x96, 0x61, 0x92, 0x8f, 0xa0, 0x74, 0x10, 0xb0, 0xa1,
+	0x1f, 0x5a, 0xab, 0xf8, 0xf4, 0xff, 0xcd, 0x3d, 0x3d, 0x0a, 0x55, 0xb5, 0x85, 0x34, 0x52, 0xb4,
+	0xbf, 0x02, 0x48, 0xb8, 0x2a, 0xa7, 0x90, 0x8a, 0x13, 0x14, 0x09, 0x55, 0x42, 0x4a, 0x9c, 0x7e,
+	0xdf, 0x84, 0x61, 0xff, 0x64, 0x40, 0x75, 0x7f, 0xe0, 0x8e, 0xfb, 0xac, 0xf5, 0x82, 0x8d, 0x25,
+	0x59, 0x07, 0xb3, 0xcb, 0x9e, 0xa3, 0x87, 0x22, 0x55, 0x9f, 0xe4, 0x7d, 0x28, 0x74, 0x26, 0x68,
+	0xb8, 0xb6, 0x7b, 0x2d, 0x07, 0x49, 0x5b, 0x76, 0x26, 0xb4, 0xd0, 0x99, 0x90, 0x5b, 0x60, 0x3a,
+	0xdc, 0xc3, 0xbc, 0x5f, 0x50, 0x63, 0x4a, 0x96, 0x94, 0x50, 0x31, 0xdd, 0x91, 0xee, 0xc0, 0x7a,
+	0x77, 0xfa, 0x2c, 0xf4, 0x44, 0xf0, 0x8c, 0xc5, 0xcd, 0xc6, 0x82, 0xf2, 0x81, 0xe0, 0xa3, 0x04,
+	0x4b, 0x4c, 0xda, 0x97, 0xa0, 0xd6, 0x74, 0xbd, 0xe3, 0xe9, 0x24, 0x4e, 0xf7, 0x16, 0x54, 0x35,
+	0x03, 0x1b, 0x03, 0x21, 0x50, 0x74, 0x5c, 0xe9, 0xa2, 0xd9, 0x2a, 0xc5, 0x6f, 0x95, 0xe6, 0x1a,
+	0x6a, 0x9b, 0xf7, 0x95, 0x4b, 0xdd, 0x98, 0x32, 0x3c, 0xfb, 0x15, 0xd4, 0xf6, 0x7c, 0xdf, 0xe1,
+	0x5e, 0x0c, 0x21, 0xba, 0x8f, 0x71, 0xce, 0x7d, 0x36, 0xa0, 0x72, 0xd8, 0x7b, 0xc2, 0x44, 0x18,
+	0xf0, 0x71, 0x1c, 0xdb, 0x19, 0x83, 0x6c, 0xc1, 0xa5, 0xd6, 0x89, 0x64, 0x62, 0xec, 0x0e, 0x63,
+	0x1d, 0x13, 0xfb, 0x44, 0x9e, 0x6d, 0xdf, 0x06, 0x70, 0xb8, 0x17, 0xdb, 0x59, 0x50, 0x8e, 0xf5,
+	0x75, 0xbd, 0xc6, 0xa4, 0xfd, 0x6b, 0x01, 0x6b, 0xf9, 0x91, 0x2b, 0xbd, 0xc1, 0x19, 0xfd, 0x68,
+	0x07, 0xaa, 0x7b, 0xbe, 0x1f, 0x15, 0x70, 0xdc, 0x45, 0xd6, 0x22, 0xf4, 0x11, 0x9b, 0xa6, 0x55,
+	0xc8, 0xa7, 0x2a, 0x8b, 0x47, 0xfc, 0x05, 0x9b, 0x19, 0x99, 0x0b, 0x8d, 0x72, 0x5a, 0x0a, 0x66,
+	0x97, 0xc9, 0x66, 0x20, 0x43, 0x7c, 0xce, 0x22, 0x8d, 0x49, 0xec, 0xda, 0x43, 0xe6, 0x0a, 0x94,
+	0x2d, 0xa3, 0x2c, 0x61, 0x28, 0xdc, 0x49, 0xcd, 0xae, 0x52, 0x4d, 0xa8, 0x27, 0xa2, 0x6c, 0x32,
+	0x74, 0x3d, 0xa6, 0x85, 0x65, 0x8c, 0x54, 0x86, 0x97, 0x0d, 0xf7, 0x4a, 0x2e, 0xdc, 0xf6, 0x1d,
+	0x20, 0x4d, 0x15, 0x18, 0x87, 0x0d, 0x99, 0x9c, 0x25, 0xd2, 0x55, 0x28, 0x61, 0x60, 0x74, 0xf3,
+	0xaf, 0xd0, 0x88, 0xb2, 0x7f, 0x36, 0xe0, 0x8a, 0xd6, 0x6c, 0x9e, 0xea, 0xf1, 0xf5, 0xef, 0x8d,
+	0x39, 0x7b, 0x1b, 0xd6, 0xe2, 0xee, 0x13, 0xa1, 0x50, 0x55, 0x3b, 0x10, 0x2c, 0x1c, 0xf0, 0xa8,
+	0x9e, 0x0d, 0x9a, 0x30, 0x6c, 0x3e, 0x6b, 0x4f, 0xd1, 0x38, 0x69, 0x40, 0xb5, 0xc9, 0x7a, 0x5c,
+	0x44, 0xc1, 0xd3, 0x69, 0x93, 0x66, 0x91, 0x4d, 0x80, 0xbd, 0x9e, 0x64, 0x42, 0x2b, 0xe8, 0x5c,
+	0x4d, 0x71, 0xf0, 0xcd, 0xb4, 0x4b, 0xe6, 0x47, 0x69, 0x9a, 0x30, 0xec, 0x2e, 0x5c, 0x76, 0x02,
+	0xff, 0x07, 0x3e, 0x7d, 0xc0, 0xdc, 0x71, 0x8c, 0x71, 0x71, 0xbf, 0x21, 0x50, 0x3c, 0xe2, 0xc2,
+	0xc7, 0x23, 0x2a, 0x14, 0xbf, 0x93, 0x41, 0x6d, 0xa6, 0x07, 0xf5, 0x13, 0x80, 0x7d, 0x2e, 0x04,
+	0xf3, 0xa4, 0xca, 0xfa, 0xd8, 0xce, 0x48, 0xd9, 0x59, 0x50, 0x76, 0xb8, 0x77, 0x20, 0xa2, 0x92,
+	0x35, 0x69, 0x4c, 0xe2, 0x50, 0x0b, 0x42, 0xe9, 0x8e, 0x3d, 0x16, 0x39, 0x9d, 0xd1, 0x76, 0x07,
+	0xd6, 0xd3, 0x60, 0x31, 0x40, 0x5f, 0x42, 0x35, 0x39, 0x2b, 0x5e, 0x04, 0xf2, 0x1d, 0x36, 0xd1,
+	0xa0, 0x69, 0x6d, 0xfb, 0x09, 0xac, 0x75, 0xa7, 0xfd, 0x3e, 0x0b, 0x65, 0x2a, 0xab, 0x1e, 0x09,
+	0xd6, 0x0b, 0x4e, 0x22, 0xb8, 0x11, 0xa5, 0xf8, 0x51, 0x0f, 0x2f, 0xe8, 0x6c, 0xd3, 0xd4, 0x19,
+	0x01, 0x78, 0x08, 0x10, 0xf9, 0x55, 0x01, 0xf8, 0xfb, 0xe1, 0xbc, 0x0a, 0xa5, 0x23, 0x16, 0xf4,
+	0x07, 0xda, 0x9d, 0x49, 0x23, 0xca, 0x6e, 0x43, 0x6d, 0x86, 0x33, 0xbe, 0x75, 0x72, 0xc0, 0x59,
+	0xb7, 0x4e, 0x34, 0x68, 0x5a, 0xfb, 0xc3, 0x9b, 0xb0, 0x12, 0xf7, 0x77, 0x52, 0x06, 0x73, 0xcf,
+	0x71, 0xd6, 0x97, 0x08, 0x40, 0xc9, 0x69, 0xb5, 0x5b, 0x8f, 0x5b, 0xeb, 0xc6, 0xee, 0xeb, 0x0a,
+	0xac, 0xea, 0x3d, 0x43, 0x7b, 0x22, 0xf7, 0xa0, 0xa2, 0x4b, 0x0a, 0x7b, 0xfd, 0xfc, 0xe6, 0x72,
+	0xe8, 0xd7, 0x37, 0x72, 0xdc, 0xec, 0x2a, 0xfa, 0x19, 0x94, 0x74, 0x0f, 0x26, 0xf9, 0x7e, 0x7b,
+	0x81, 0xe1, 0x3e, 0x94, 0xf4, 0xce, 0x47, 0xf2, 0x7a, 0x99, 0x1d, 0xb6, 0x7e, 0xfd, 0x0c, 0x29,
+	0x46, 0xab, 0x19, 0xed, 0x64, 0xe4, 0xfa, 0x5c, 0x5e, 0x24, 0x3b, 0xe1, 0x05, 0x40, 0xbe, 0x80,
+	0x72, 0xb4, 0xe8, 0x5d, 0x7c, 0x85, 0xcc, 0x46, 0xb8, 0x65, 0x90, 0xfb, 0xf1, 0xe2, 0xda, 0x95,
+	0x82, 0xb9, 0xa3, 0x7f, 0x70, 0x95, 0x1d, 0x83, 0xdc, 0x83, 0x65, 0xbd, 0xfe, 0xcd, 0xe9, 0xa5,
+	0x56, 0xc5, 0x7a, 0x3e, 0x17, 0x52, 0x6b, 0xe3, 0xb7, 0x50, 0x99, 0x4d, 0x65, 0x72, 0x73, 0x2e,
+	0x67, 0xb2, 0xf3, 0xba, 0x5e, 0x5f, 0xb8, 0x19, 0xe0, 0x4e, 0xb1, 0x63, 0x90, 0x36, 0x5c, 0xd6,
+	0xef, 0x7a, 0x14, 0xc8, 0x41, 0x67, 0x82, 0xf9, 0x35, 0x77, 0xbd, 0xcc, 0xf4, 0x9d, 0x43, 0x96,
+	0x9a, 0x8f, 0xf7, 0xa0, 0xf2, 0xfd, 0xc4, 0x77, 0x75, 0x9a, 0x5d, 0x9b, 0xd7, 0xc3, 0xf1, 0x78,
+	0x9e, 0x83, 0x87, 0x50, 0x4d, 0x4d, 0x0a, 0x72, 0x2b, 0xff, 0x2e, 0x73, 0x53, 0xe4, 0x82, 0x47,
+	0xa7, 0x50, 0xcb, 0x8c, 0x12, 0xf2, 0xbf, 0xfc, 0xd9, 0x0b, 0x06, 0xcd, 0x05, 0x3e, 0x1d, 0x28,
+	0xe9, 0xad, 0x66, 0x2e, 0x4e, 0x99, 0xed, 0xa7, 0x5e, 0x5f, 0x28, 0xc5, 0x55, 0x68, 0xc7, 0x20,
+	0x2d, 0xfc, 0xf7, 0x91, 0x5c, 0x30, 0x72, 0x8e, 0xe2, 0xf9, 0x50, 0xb6, 0x0c, 0x72, 0x00, 0xe5,
+	0x68, 0x16, 0x90, 0x1b, 0x73, 0xb5, 0x91, 0x9e, 0x5b, 0xf5, 0x8d, 0xb3, 0xc4, 0x58, 0x61, 0x1d,
+	0x80, 0xa4, 0x33, 0x93, 0x46, 0x3e, 0x4a, 0xf9, 0x09, 0x53, 0xbf, 0x79, 0x8e, 0x06, 0x3a, 0x3c,
+	0x80, 0x72, 0xd4, 0xb2, 0xe6, 0x80, 0x65, 0x3b, 0x76, 0x7d, 0xe3, 0x2c, 0xb1, 0xf2, 0xd3, 0xb4,
+	0x5e, 0xbf, 0xdd, 0x34, 0xde, 0xbc, 0xdd, 0x34, 0xfe, 0x78, 0xbb, 0x69, 0xfc, 0xf2, 0x6e, 0x73,
+	0xe9, 0xcd, 0xbb, 0xcd, 0xa5, 0xdf, 0xdf, 0x6d, 0x2e, 0x3d, 0x2b, 0xe1, 0x4f, 0xf5, 0xc7, 0x7f,
+	0x0d, 0x00, 0xbd, 0xfe, 0xa3, 0x41, 0xa7, 0x0f, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// IndexServiceClient is the client API for IndexService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type IndexServiceClient interface {
+	DeleteDoc(ctx context.Context, in *DocId, opts ...grpc.CallOption) (*AffectedCount, error)
+	AddDoc(ctx context.Context, in *types.Document, opts ...grpc.CallOption) (*AffectedCount, error)
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResult, error)
+	Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*AffectedCount, error)
+	BulkAdd(ctx context.Context, opts ...grpc.CallOption) (IndexService_BulkAddClient, error)
+	SearchStream(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (IndexService_SearchStreamClient, error)
+	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*IndexStats, error)
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (IndexService_SubscribeClient, error)
+	AddDocWithOptions(ctx context.Context, in *AddDocRequest, opts ...grpc.CallOption) (*DocVersion, error)
+	UpdateDoc(ctx context.Context, in *DocPatch, opts ...grpc.CallOption) (*DocVersion, error)
+	BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*AffectedCount, error)
+	DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, opts ...grpc.CallOption) (*AffectedCount, error)
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (IndexService_BackupClient, error)
+	Restore(ctx context.Context, opts ...grpc.CallOption) (IndexService_RestoreClient, error)
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResult, error)
+	DidYouMean(ctx context.Context, in *DidYouMeanRequest, opts ...grpc.CallOption) (*DidYouMeanResult, error)
+	Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResult, error)
+}
+
+type indexServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIndexServiceClient(cc *grpc.ClientConn) IndexServiceClient {
+	return &indexServiceClient{cc}
+}
+
+func (c *indexServiceClient) DeleteDoc(ctx context.Context, in *DocId, opts ...grpc.CallOption) (*AffectedCount, error) {
+	out := new(AffectedCount)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/DeleteDoc", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) AddDoc(ctx context.Context, in *types.Document, opts ...grpc.CallOption) (*AffectedCount, error) {
+	out := new(AffectedCount)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/AddDoc", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResult, error) {
+	out := new(SearchResult)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/Search", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) Count(ctx context.Context, in *CountRequest, opts ...grpc.CallOption) (*AffectedCount, error) {
+	out := new(AffectedCount)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/Count", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) BulkAdd(ctx context.Context, opts ...grpc.CallOption) (IndexService_BulkAddClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexService_serviceDesc.Streams[0], "/index_service.IndexService/BulkAdd", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexServiceBulkAddClient{stream}
+	return x, nil
+}
+
+type IndexService_BulkAddClient interface {
+	Send(*types.Document) error
+	CloseAndRecv() (*BulkAddResult, error)
+	grpc.ClientStream
+}
+
+type indexServiceBulkAddClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexServiceBulkAddClient) Send(m *types.Document) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *indexServiceBulkAddClient) CloseAndRecv() (*BulkAddResult, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(BulkAddResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexServiceClient) SearchStream(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (IndexService_SearchStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexService_serviceDesc.Streams[1], "/index_service.IndexService/SearchStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexServiceSearchStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexService_SearchStreamClient interface {
+	Recv() (*SearchResult, error)
+	grpc.ClientStream
+}
+
+type indexServiceSearchStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexServiceSearchStreamClient) Recv() (*SearchResult, error) {
+	m := new(SearchResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexServiceClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*IndexStats, error) {
+	out := new(IndexStats)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/Stats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (IndexService_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexService_serviceDesc.Streams[2], "/index_service.IndexService/Subscribe", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexServiceSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexService_SubscribeClient interface {
+	Recv() (*ChangeEvent, error)
+	grpc.ClientStream
+}
+
+type indexServiceSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexServiceSubscribeClient) Recv() (*ChangeEvent, error) {
+	m := new(ChangeEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexServiceClient) AddDocWithOptions(ctx context.Context, in *AddDocRequest, opts ...grpc.CallOption) (*DocVersion, error) {
+	out := new(DocVersion)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/AddDocWithOptions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) UpdateDoc(ctx context.Context, in *DocPatch, opts ...grpc.CallOption) (*DocVersion, error) {
+	out := new(DocVersion)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/UpdateDoc", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) BatchDelete(ctx context.Context, in *BatchDeleteRequest, opts ...grpc.CallOption) (*AffectedCount, error) {
+	out := new(AffectedCount)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/BatchDelete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) DeleteByQuery(ctx context.Context, in *DeleteByQueryRequest, opts ...grpc.CallOption) (*AffectedCount, error) {
+	out := new(AffectedCount)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/DeleteByQuery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (IndexService_BackupClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexService_serviceDesc.Streams[3], "/index_service.IndexService/Backup", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexServiceBackupClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type IndexService_BackupClient interface {
+	Recv() (*BackupChunk, error)
+	grpc.ClientStream
+}
+
+type indexServiceBackupClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexServiceBackupClient) Recv() (*BackupChunk, error) {
+	m := new(BackupChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexServiceClient) Restore(ctx context.Context, opts ...grpc.CallOption) (IndexService_RestoreClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_IndexService_serviceDesc.Streams[4], "/index_service.IndexService/Restore", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &indexServiceRestoreClient{stream}
+	return x, nil
+}
+
+type IndexService_RestoreClient interface {
+	Send(*BackupChunk) error
+	CloseAndRecv() (*AffectedCount, error)
+	grpc.ClientStream
+}
+
+type indexServiceRestoreClient struct {
+	grpc.ClientStream
+}
+
+func (x *indexServiceRestoreClient) Send(m *BackupChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *indexServiceRestoreClient) CloseAndRecv() (*AffectedCount, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(AffectedCount)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *indexServiceClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResult, error) {
+	out := new(CompactResult)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/Compact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) DidYouMean(ctx context.Context, in *DidYouMeanRequest, opts ...grpc.CallOption) (*DidYouMeanResult, error) {
+	out := new(DidYouMeanResult)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/DidYouMean", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *indexServiceClient) Suggest(ctx context.Context, in *SuggestRequest, opts ...grpc.CallOption) (*SuggestResult, error) {
+	out := new(SuggestResult)
+	err := c.cc.Invoke(ctx, "/index_service.IndexService/Suggest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IndexServiceServer is the server API for IndexService service.
+type IndexServiceServer interface {
+	DeleteDoc(context.Context, *DocId) (*AffectedCount, error)
+	AddDoc(context.Context, *types.Document) (*AffectedCount, error)
+	Search(context.Context, *SearchRequest) (*SearchResult, error)
+	Count(context.Context, *CountRequest) (*AffectedCount, error)
+	BulkAdd(IndexService_BulkAddServer) error
+	SearchStream(*SearchRequest, IndexService_SearchStreamServer) error
+	Stats(context.Context, *StatsRequest) (*IndexStats, error)
+	Subscribe(*SubscribeRequest, IndexService_SubscribeServer) error
+	AddDocWithOptions(context.Context, *AddDocRequest) (*DocVersion, error)
+	UpdateDoc(context.Context, *DocPatch) (*DocVersion, error)
+	BatchDelete(context.Context, *BatchDeleteRequest) (*AffectedCount, error)
+	DeleteByQuery(context.Context, *DeleteByQueryRequest) (*AffectedCount, error)
+	Backup(*BackupRequest, IndexService_BackupServer) error
+	Restore(IndexService_RestoreServer) error
+	Compact(context.Context, *CompactRequest) (*CompactResult, error)
+	DidYouMean(context.Context, *DidYouMeanRequest) (*DidYouMeanResult, error)
+	Suggest(context.Context, *SuggestRequest) (*SuggestResult, error)
+}
+
+// UnimplementedIndexServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedIndexServiceServer struct {
+}
+
+func (*UnimplementedIndexServiceServer) DeleteDoc(ctx context.Context, req *DocId) (*AffectedCount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDoc not implemented")
+}
+func (*UnimplementedIndexServiceServer) AddDoc(ctx context.Context, req *types.Document) (*AffectedCount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddDoc not implemented")
+}
+func (*UnimplementedIndexServiceServer) Search(ctx context.Context, req *SearchRequest) (*SearchResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (*UnimplementedIndexServiceServer) Count(ctx context.Context, req *CountRequest) (*AffectedCount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Count not implemented")
+}
+func (*UnimplementedIndexServiceServer) BulkAdd(srv IndexService_BulkAddServer) error {
+	return status.Errorf(codes.Unimplemented, "method BulkAdd not implemented")
+}
+func (*UnimplementedIndexServiceServer) SearchStream(req *SearchRequest, srv IndexService_SearchStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SearchStream not implemented")
+}
+func (*UnimplementedIndexServiceServer) Stats(ctx context.Context, req *StatsRequest) (*IndexStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
+func (*UnimplementedIndexServiceServer) Subscribe(req *SubscribeRequest, srv IndexService_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (*UnimplementedIndexServiceServer) AddDocWithOptions(ctx context.Context, req *AddDocRequest) (*DocVersion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddDocWithOptions not implemented")
+}
+func (*UnimplementedIndexServiceServer) UpdateDoc(ctx context.Context, req *DocPatch) (*DocVersion, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDoc not implemented")
+}
+func (*UnimplementedIndexServiceServer) BatchDelete(ctx context.Context, req *BatchDeleteRequest) (*AffectedCount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDelete not implemented")
+}
+func (*UnimplementedIndexServiceServer) DeleteByQuery(ctx context.Context, req *DeleteByQueryRequest) (*AffectedCount, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteByQuery not implemented")
+}
+func (*UnimplementedIndexServiceServer) Backup(req *BackupRequest, srv IndexService_BackupServer) error {
+	return status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (*UnimplementedIndexServiceServer) Restore(srv IndexService_RestoreServer) error {
+	return status.Errorf(codes.Unimplemented, "method Restore not implemented")
+}
+func (*UnimplementedIndexServiceServer) Compact(ctx context.Context, req *CompactRequest) (*CompactResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
+func (*UnimplementedIndexServiceServer) DidYouMean(ctx context.Context, req *DidYouMeanRequest) (*DidYouMeanResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DidYouMean not implemented")
+}
+func (*UnimplementedIndexServiceServer) Suggest(ctx context.Context, req *SuggestRequest) (*SuggestResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Suggest not implemented")
+}
+
+func RegisterIndexServiceServer(s *grpc.Server, srv IndexServiceServer) {
+	s.RegisterService(&_IndexService_serviceDesc, srv)
+}
+
+func _IndexService_DeleteDoc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DocId)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).DeleteDoc(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/DeleteDoc",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).DeleteDoc(ctx, req.(*DocId))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_AddDoc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(types.Document)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).AddDoc(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/AddDoc",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).AddDoc(ctx, req.(*types.Document))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/Search",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_Count_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CountRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).Count(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/Count",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).Count(ctx, req.(*CountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_BulkAdd_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IndexServiceServer).BulkAdd(&indexServiceBulkAddServer{stream})
+}
+
+type IndexService_BulkAddServer interface {
+	SendAndClose(*BulkAddResult) error
+	Recv() (*types.Document, error)
+	grpc.ServerStream
+}
+
+type indexServiceBulkAddServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexServiceBulkAddServer) SendAndClose(m *BulkAddResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *indexServiceBulkAddServer) Recv() (*types.Document, error) {
+	m := new(types.Document)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _IndexService_SearchStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SearchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexServiceServer).SearchStream(m, &indexServiceSearchStreamServer{stream})
+}
+
+type IndexService_SearchStreamServer interface {
+	Send(*SearchResult) error
+	grpc.ServerStream
+}
+
+type indexServiceSearchStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexServiceSearchStreamServer) Send(m *SearchResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IndexService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/Stats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).Stats(ctx, req.(*StatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexServiceServer).Subscribe(m, &indexServiceSubscribeServer{stream})
+}
+
+type IndexService_SubscribeServer interface {
+	Send(*ChangeEvent) error
+	grpc.ServerStream
+}
+
+type indexServiceSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexServiceSubscribeServer) Send(m *ChangeEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IndexService_AddDocWithOptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddDocRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).AddDocWithOptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/AddDocWithOptions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).AddDocWithOptions(ctx, req.(*AddDocRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_UpdateDoc_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DocPatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).UpdateDoc(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/UpdateDoc",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).UpdateDoc(ctx, req.(*DocPatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_BatchDelete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).BatchDelete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/BatchDelete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).BatchDelete(ctx, req.(*BatchDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_DeleteByQuery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteByQueryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).DeleteByQuery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/DeleteByQuery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).DeleteByQuery(ctx, req.(*DeleteByQueryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(IndexServiceServer).Backup(m, &indexServiceBackupServer{stream})
+}
+
+type IndexService_BackupServer interface {
+	Send(*BackupChunk) error
+	grpc.ServerStream
+}
+
+type indexServiceBackupServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexServiceBackupServer) Send(m *BackupChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _IndexService_Restore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IndexServiceServer).Restore(&indexServiceRestoreServer{stream})
+}
+
+type IndexService_RestoreServer interface {
+	SendAndClose(*AffectedCount) error
+	Recv() (*BackupChunk, error)
+	grpc.ServerStream
+}
+
+type indexServiceRestoreServer struct {
+	grpc.ServerStream
+}
+
+func (x *indexServiceRestoreServer) SendAndClose(m *AffectedCount) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *indexServiceRestoreServer) Recv() (*BackupChunk, error) {
+	m := new(BackupChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _IndexService_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/Compact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).Compact(ctx, req.(*CompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_DidYouMean_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DidYouMeanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).DidYouMean(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/DidYouMean",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).DidYouMean(ctx, req.(*DidYouMeanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _IndexService_Suggest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SuggestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IndexServiceServer).Suggest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/index_service.IndexService/Suggest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IndexServiceServer).Suggest(ctx, req.(*SuggestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _IndexService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "index_service.IndexService",
+	HandlerType: (*IndexServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "DeleteDoc",
+			Handler:    _IndexService_DeleteDoc_Handler,
+		},
+		{
+			MethodName: "AddDoc",
+			Handler:    _IndexService_AddDoc_Handler,
+		},
+		{
+			MethodName: "Search",
+			Handler:    _IndexService_Search_Handler,
+		},
+		{
+			MethodName: "Count",
+			Handler:    _IndexService_Count_Handler,
+		},
+		{
+			MethodName: "Stats",
+			Handler:    _IndexService_Stats_Handler,
+		},
+		{
+			MethodName: "AddDocWithOptions",
+			Handler:    _IndexService_AddDocWithOptions_Handler,
+		},
+		{
+			MethodName: "UpdateDoc",
+			Handler:    _IndexService_UpdateDoc_Handler,
+		},
+		{
+			MethodName: "BatchDelete",
+			Handler:    _IndexService_BatchDelete_Handler,
+		},
+		{
+			MethodName: "DeleteByQuery",
+			Handler:    _IndexService_DeleteByQuery_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _IndexService_Compact_Handler,
+		},
+		{
+			MethodName: "DidYouMean",
+			Handler:    _IndexService_DidYouMean_Handler,
+		},
+		{
+			MethodName: "Suggest",
+			Handler:    _IndexService_Suggest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "BulkAdd",
+			Handler:       _IndexService_BulkAdd_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SearchStream",
+			Handler:       _IndexService_SearchStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Subscribe",
+			Handler:       _IndexService_Subscribe_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Backup",
+			Handler:       _IndexService_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Restore",
+			Handler:       _IndexService_Restore_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "index.proto",
+}
+
+func (m *DocId) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DocId) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DocId) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.DocId) > 0 {
+		i -= len(m.DocId)
+		copy(dAtA[i:], m.DocId)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.DocId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AffectedCount) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AffectedCount) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AffectedCount) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Count != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Count))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SearchRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SearchRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SearchRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.ChunkSize != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.ChunkSize))
+		i--
+		dAtA[i] = 0x28
+	}
+	if len(m.OrFlags) > 0 {
+		dAtA2 := make([]byte, len(m.OrFlags)*10)
+		var j1 int
+		for _, num := range m.OrFlags {
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		i -= j1
+		copy(dAtA[i:], dAtA2[:j1])
+		i = encodeVarintIndex(dAtA, i, uint64(j1))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.OffFlag != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.OffFlag))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.OnFlag != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.OnFlag))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Query != nil {
+		{
+			size, err := m.Query.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintIndex(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SearchResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SearchResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SearchResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for iNdEx := len(m.Results) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Results[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CountRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CountRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CountRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *DocStatus) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DocStatus) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DocStatus) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Error) > 0 {
+		i -= len(m.Error)
+		copy(dAtA[i:], m.Error)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Error)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Ok {
+		i--
+		if m.Ok {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.DocId) > 0 {
+		i -= len(m.DocId)
+		copy(dAtA[i:], m.DocId)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.DocId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *BulkAddResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BulkAddResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BulkAddResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Statuses) > 0 {
+		for iNdEx := len(m.Statuses) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Statuses[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if m.Count != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Count))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *StatsRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *StatsRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *StatsRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *IndexStats) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *IndexStats) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *IndexStats) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Fields) > 0 {
+		for iNdEx := len(m.Fields) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Fields[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x62
+		}
+	}
+	if m.LastCompactAt != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.LastCompactAt))
+		i--
+		dAtA[i] = 0x58
+	}
+	if m.ReclaimedBytes != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.ReclaimedBytes))
+		i--
+		dAtA[i] = 0x50
+	}
+	if m.CompactErrors != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.CompactErrors))
+		i--
+		dAtA[i] = 0x48
+	}
+	if m.CompactRuns != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.CompactRuns))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.Workers != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Workers))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.MemoryBytes != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.MemoryBytes))
+		i--
+		dAtA[i] = 0x30
+	}
+	if m.ForwardIndexBytes != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.ForwardIndexBytes))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.MaxPostingListLength != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.MaxPostingListLength))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.PostingListHistogram) > 0 {
+		dAtA5 := make([]byte, len(m.PostingListHistogram)*10)
+		var j4 int
+		for _, num1 := range m.PostingListHistogram {
+			num := uint64(num1)
+			for num >= 1<<7 {
+				dAtA5[j4] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j4++
+			}
+			dAtA5[j4] = uint8(num)
+			j4++
+		}
+		i -= j4
+		copy(dAtA[i:], dAtA5[:j4])
+		i = encodeVarintIndex(dAtA, i, uint64(j4))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.KeywordCount != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.KeywordCount))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.DocCount != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.DocCount))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *FieldTerms) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FieldTerms) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FieldTerms) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.TermCount != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.TermCount))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Field) > 0 {
+		i -= len(m.Field)
+		copy(dAtA[i:], m.Field)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Field)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *ChangeEvent) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *ChangeEvent) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *ChangeEvent) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.DocId) > 0 {
+		i -= len(m.DocId)
+		copy(dAtA[i:], m.DocId)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.DocId)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Doc != nil {
+		{
+			size, err := m.Doc.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintIndex(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.Op != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Op))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Seq != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Seq))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SubscribeRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SubscribeRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SubscribeRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.FromSeq != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.FromSeq))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *BackupRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BackupRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BackupRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *BackupChunk) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BackupChunk) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BackupChunk) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.ChangeLogSeq != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.ChangeLogSeq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Data) > 0 {
+		i -= len(m.Data)
+		copy(dAtA[i:], m.Data)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Data)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *AddDocRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *AddDocRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *AddDocRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.ExternalVersion {
+		i--
+		if m.ExternalVersion {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.IfVersion != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.IfVersion))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Doc != nil {
+		{
+			size, err := m.Doc.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintIndex(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DocVersion) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DocVersion) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DocVersion) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Version != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DocPatch) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DocPatch) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DocPatch) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.IfVersion != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.IfVersion))
+		i--
+		dAtA[i] = 0x40
+	}
+	if m.ReplaceBytes {
+		i--
+		if m.ReplaceBytes {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x38
+	}
+	if len(m.Bytes) > 0 {
+		i -= len(m.Bytes)
+		copy(dAtA[i:], m.Bytes)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Bytes)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if m.ClearBits != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.ClearBits))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.SetBits != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.SetBits))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.RemoveKeywords) > 0 {
+		for iNdEx := len(m.RemoveKeywords) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.RemoveKeywords[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if len(m.AddKeywords) > 0 {
+		for iNdEx := len(m.AddKeywords) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.AddKeywords[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.DocId) > 0 {
+		i -= len(m.DocId)
+		copy(dAtA[i:], m.DocId)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.DocId)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *BatchDeleteRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *BatchDeleteRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *BatchDeleteRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.DocIds) > 0 {
+		for iNdEx := len(m.DocIds) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.DocIds[iNdEx])
+			copy(dAtA[i:], m.DocIds[iNdEx])
+			i = encodeVarintIndex(dAtA, i, uint64(len(m.DocIds[iNdEx])))
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DeleteByQueryRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DeleteByQueryRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DeleteByQueryRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.OrFlags) > 0 {
+		dAtA9 := make([]byte, len(m.OrFlags)*10)
+		var j8 int
+		for _, num := range m.OrFlags {
+			for num >= 1<<7 {
+				dAtA9[j8] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j8++
+			}
+			dAtA9[j8] = uint8(num)
+			j8++
+		}
+		i -= j8
+		copy(dAtA[i:], dAtA9[:j8])
+		i = encodeVarintIndex(dAtA, i, uint64(j8))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.OffFlag != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.OffFlag))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.OnFlag != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.OnFlag))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.Query != nil {
+		{
+			size, err := m.Query.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintIndex(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CompactRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CompactRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CompactRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Threshold != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.Threshold))))
+		i--
+		dAtA[i] = 0x9
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CompactResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CompactResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CompactResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Compacted {
+		i--
+		if m.Compacted {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.AfterBytes != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.AfterBytes))
+		i--
+		dAtA[i] = 0x10
+	}
+	if m.BeforeBytes != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.BeforeBytes))
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DidYouMeanRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DidYouMeanRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DidYouMeanRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Word) > 0 {
+		i -= len(m.Word)
+		copy(dAtA[i:], m.Word)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Word)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Field) > 0 {
+		i -= len(m.Field)
+		copy(dAtA[i:], m.Field)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Field)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Correction) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Correction) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Correction) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Distance != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Distance))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.DocFreq != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.DocFreq))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.Word) > 0 {
+		i -= len(m.Word)
+		copy(dAtA[i:], m.Word)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Word)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *DidYouMeanResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *DidYouMeanResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *DidYouMeanResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Corrections) > 0 {
+		for iNdEx := len(m.Corrections) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Corrections[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SuggestRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SuggestRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SuggestRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Limit != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Limit))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Fields) > 0 {
+		for iNdEx := len(m.Fields) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.Fields[iNdEx])
+			copy(dAtA[i:], m.Fields[iNdEx])
+			i = encodeVarintIndex(dAtA, i, uint64(len(m.Fields[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Prefix) > 0 {
+		i -= len(m.Prefix)
+		copy(dAtA[i:], m.Prefix)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Prefix)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Suggestion) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Suggestion) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Suggestion) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Weight != 0 {
+		i = encodeVarintIndex(dAtA, i, uint64(m.Weight))
+		i--
+		dAtA[i] = 0x18
+	}
+	if len(m.Word) > 0 {
+		i -= len(m.Word)
+		copy(dAtA[i:], m.Word)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Word)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if len(m.Field) > 0 {
+		i -= len(m.Field)
+		copy(dAtA[i:], m.Field)
+		i = encodeVarintIndex(dAtA, i, uint64(len(m.Field)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *SuggestResult) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *SuggestResult) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *SuggestResult) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Suggestions) > 0 {
+		for iNdEx := len(m.Suggestions) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Suggestions[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintIndex(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0xa
+		}
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintIndex(dAtA []byte, offset int, v uint64) int {
+	offset -= sovIndex(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
 }
 func (m *DocId) Size() (n int) {
 	if m == nil {
 		return 0
 	}
-	var l int
-	_ = l
-	l = len(m.DocId)
-	if l > 0 {
-		n += 1 + l + sovIndex(uint64(l))
+	var l int
+	_ = l
+	l = len(m.DocId)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	return n
+}
+
+func (m *AffectedCount) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Count != 0 {
+		n += 1 + sovIndex(uint64(m.Count))
+	}
+	return n
+}
+
+func (m *SearchRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.OnFlag != 0 {
+		n += 1 + sovIndex(uint64(m.OnFlag))
+	}
+	if m.OffFlag != 0 {
+		n += 1 + sovIndex(uint64(m.OffFlag))
+	}
+	if len(m.OrFlags) > 0 {
+		l = 0
+		for _, e := range m.OrFlags {
+			l += sovIndex(uint64(e))
+		}
+		n += 1 + sovIndex(uint64(l)) + l
+	}
+	if m.ChunkSize != 0 {
+		n += 1 + sovIndex(uint64(m.ChunkSize))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovIndex(uint64(m.Limit))
+	}
+	return n
+}
+
+func (m *SearchResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Results) > 0 {
+		for _, e := range m.Results {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *CountRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *DocStatus) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.DocId)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.Ok {
+		n += 2
+	}
+	l = len(m.Error)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	return n
+}
+
+func (m *BulkAddResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Count != 0 {
+		n += 1 + sovIndex(uint64(m.Count))
+	}
+	if len(m.Statuses) > 0 {
+		for _, e := range m.Statuses {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *StatsRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *IndexStats) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.DocCount != 0 {
+		n += 1 + sovIndex(uint64(m.DocCount))
+	}
+	if m.KeywordCount != 0 {
+		n += 1 + sovIndex(uint64(m.KeywordCount))
+	}
+	if len(m.PostingListHistogram) > 0 {
+		l = 0
+		for _, e := range m.PostingListHistogram {
+			l += sovIndex(uint64(e))
+		}
+		n += 1 + sovIndex(uint64(l)) + l
+	}
+	if m.MaxPostingListLength != 0 {
+		n += 1 + sovIndex(uint64(m.MaxPostingListLength))
+	}
+	if m.ForwardIndexBytes != 0 {
+		n += 1 + sovIndex(uint64(m.ForwardIndexBytes))
+	}
+	if m.MemoryBytes != 0 {
+		n += 1 + sovIndex(uint64(m.MemoryBytes))
+	}
+	if m.Workers != 0 {
+		n += 1 + sovIndex(uint64(m.Workers))
+	}
+	if m.CompactRuns != 0 {
+		n += 1 + sovIndex(uint64(m.CompactRuns))
+	}
+	if m.CompactErrors != 0 {
+		n += 1 + sovIndex(uint64(m.CompactErrors))
+	}
+	if m.ReclaimedBytes != 0 {
+		n += 1 + sovIndex(uint64(m.ReclaimedBytes))
+	}
+	if m.LastCompactAt != 0 {
+		n += 1 + sovIndex(uint64(m.LastCompactAt))
+	}
+	if len(m.Fields) > 0 {
+		for _, e := range m.Fields {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *FieldTerms) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.TermCount != 0 {
+		n += 1 + sovIndex(uint64(m.TermCount))
+	}
+	return n
+}
+
+func (m *ChangeEvent) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Seq != 0 {
+		n += 1 + sovIndex(uint64(m.Seq))
+	}
+	if m.Op != 0 {
+		n += 1 + sovIndex(uint64(m.Op))
+	}
+	if m.Doc != nil {
+		l = m.Doc.Size()
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	l = len(m.DocId)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	return n
+}
+
+func (m *SubscribeRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.FromSeq != 0 {
+		n += 1 + sovIndex(uint64(m.FromSeq))
+	}
+	return n
+}
+
+func (m *BackupRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *BackupChunk) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Data)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.ChangeLogSeq != 0 {
+		n += 1 + sovIndex(uint64(m.ChangeLogSeq))
+	}
+	return n
+}
+
+func (m *AddDocRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Doc != nil {
+		l = m.Doc.Size()
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.IfVersion != 0 {
+		n += 1 + sovIndex(uint64(m.IfVersion))
+	}
+	if m.ExternalVersion {
+		n += 2
+	}
+	return n
+}
+
+func (m *DocVersion) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Version != 0 {
+		n += 1 + sovIndex(uint64(m.Version))
+	}
+	return n
+}
+
+func (m *DocPatch) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.DocId)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if len(m.AddKeywords) > 0 {
+		for _, e := range m.AddKeywords {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	if len(m.RemoveKeywords) > 0 {
+		for _, e := range m.RemoveKeywords {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	if m.SetBits != 0 {
+		n += 1 + sovIndex(uint64(m.SetBits))
+	}
+	if m.ClearBits != 0 {
+		n += 1 + sovIndex(uint64(m.ClearBits))
+	}
+	l = len(m.Bytes)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.ReplaceBytes {
+		n += 2
+	}
+	if m.IfVersion != 0 {
+		n += 1 + sovIndex(uint64(m.IfVersion))
+	}
+	return n
+}
+
+func (m *BatchDeleteRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.DocIds) > 0 {
+		for _, s := range m.DocIds {
+			l = len(s)
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *DeleteByQueryRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Query != nil {
+		l = m.Query.Size()
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.OnFlag != 0 {
+		n += 1 + sovIndex(uint64(m.OnFlag))
+	}
+	if m.OffFlag != 0 {
+		n += 1 + sovIndex(uint64(m.OffFlag))
+	}
+	if len(m.OrFlags) > 0 {
+		l = 0
+		for _, e := range m.OrFlags {
+			l += sovIndex(uint64(e))
+		}
+		n += 1 + sovIndex(uint64(l)) + l
+	}
+	return n
+}
+
+func (m *CompactRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Threshold != 0 {
+		n += 9
+	}
+	return n
+}
+
+func (m *CompactResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.BeforeBytes != 0 {
+		n += 1 + sovIndex(uint64(m.BeforeBytes))
+	}
+	if m.AfterBytes != 0 {
+		n += 1 + sovIndex(uint64(m.AfterBytes))
+	}
+	if m.Compacted {
+		n += 2
+	}
+	return n
+}
+
+func (m *DidYouMeanRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	l = len(m.Word)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.Limit != 0 {
+		n += 1 + sovIndex(uint64(m.Limit))
+	}
+	return n
+}
+
+func (m *Correction) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Word)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.DocFreq != 0 {
+		n += 1 + sovIndex(uint64(m.DocFreq))
+	}
+	if m.Distance != 0 {
+		n += 1 + sovIndex(uint64(m.Distance))
+	}
+	return n
+}
+
+func (m *DidYouMeanResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Corrections) > 0 {
+		for _, e := range m.Corrections {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func (m *SuggestRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Prefix)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if len(m.Fields) > 0 {
+		for _, s := range m.Fields {
+			l = len(s)
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	if m.Limit != 0 {
+		n += 1 + sovIndex(uint64(m.Limit))
+	}
+	return n
+}
+
+func (m *Suggestion) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	l = len(m.Word)
+	if l > 0 {
+		n += 1 + l + sovIndex(uint64(l))
+	}
+	if m.Weight != 0 {
+		n += 1 + sovIndex(uint64(m.Weight))
+	}
+	return n
+}
+
+func (m *SuggestResult) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if len(m.Suggestions) > 0 {
+		for _, e := range m.Suggestions {
+			l = e.Size()
+			n += 1 + l + sovIndex(uint64(l))
+		}
+	}
+	return n
+}
+
+func sovIndex(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozIndex(x uint64) (n int) {
+	return sovIndex(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (m *DocId) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DocId: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DocId: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DocId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AffectedCount) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AffectedCount: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AffectedCount: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &types.TermQuery{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OnFlag", wireType)
+			}
+			m.OnFlag = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OnFlag |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OffFlag", wireType)
+			}
+			m.OffFlag = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OffFlag |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.OrFlags = append(m.OrFlags, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthIndex
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthIndex
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.OrFlags) == 0 {
+					m.OrFlags = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowIndex
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.OrFlags = append(m.OrFlags, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field OrFlags", wireType)
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChunkSize", wireType)
+			}
+			m.ChunkSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChunkSize |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SearchResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SearchResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SearchResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Results = append(m.Results, &types.Document{})
+			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CountRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CountRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CountRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DocStatus) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DocStatus: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DocStatus: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DocId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ok", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Ok = bool(v != 0)
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Error", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Error = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BulkAddResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BulkAddResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BulkAddResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			}
+			m.Count = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Count |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Statuses", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Statuses = append(m.Statuses, &DocStatus{})
+			if err := m.Statuses[len(m.Statuses)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *StatsRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: StatsRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: StatsRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *IndexStats) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: IndexStats: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: IndexStats: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocCount", wireType)
+			}
+			m.DocCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DocCount |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeywordCount", wireType)
+			}
+			m.KeywordCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.KeywordCount |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType == 0 {
+				var v int64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= int64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.PostingListHistogram = append(m.PostingListHistogram, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthIndex
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthIndex
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.PostingListHistogram) == 0 {
+					m.PostingListHistogram = make([]int64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v int64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowIndex
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= int64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.PostingListHistogram = append(m.PostingListHistogram, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field PostingListHistogram", wireType)
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MaxPostingListLength", wireType)
+			}
+			m.MaxPostingListLength = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MaxPostingListLength |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ForwardIndexBytes", wireType)
+			}
+			m.ForwardIndexBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ForwardIndexBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryBytes", wireType)
+			}
+			m.MemoryBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Workers", wireType)
+			}
+			m.Workers = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Workers |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CompactRuns", wireType)
+			}
+			m.CompactRuns = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CompactRuns |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 9:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CompactErrors", wireType)
+			}
+			m.CompactErrors = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CompactErrors |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 10:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReclaimedBytes", wireType)
+			}
+			m.ReclaimedBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ReclaimedBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 11:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field LastCompactAt", wireType)
+			}
+			m.LastCompactAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.LastCompactAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 12:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fields", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Fields = append(m.Fields, &FieldTerms{})
+			if err := m.Fields[len(m.Fields)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *FieldTerms) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FieldTerms: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FieldTerms: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TermCount", wireType)
+			}
+			m.TermCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.TermCount |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *ChangeEvent) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: ChangeEvent: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: ChangeEvent: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Seq", wireType)
+			}
+			m.Seq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Seq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Op", wireType)
+			}
+			m.Op = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Op |= ChangeOp(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Doc", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Doc == nil {
+				m.Doc = &types.Document{}
+			}
+			if err := m.Doc.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DocId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *SubscribeRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: SubscribeRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: SubscribeRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FromSeq", wireType)
+			}
+			m.FromSeq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.FromSeq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BackupRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BackupRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BackupRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BackupChunk) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BackupChunk: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BackupChunk: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Data", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Data = append(m.Data[:0], dAtA[iNdEx:postIndex]...)
+			if m.Data == nil {
+				m.Data = []byte{}
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ChangeLogSeq", wireType)
+			}
+			m.ChangeLogSeq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ChangeLogSeq |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *AddDocRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: AddDocRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: AddDocRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Doc", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Doc == nil {
+				m.Doc = &types.Document{}
+			}
+			if err := m.Doc.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IfVersion", wireType)
+			}
+			m.IfVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.IfVersion |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExternalVersion", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ExternalVersion = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DocVersion) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DocVersion: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DocVersion: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DocPatch) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DocPatch: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DocPatch: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocId", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DocId = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AddKeywords", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AddKeywords = append(m.AddKeywords, &types.Keyword{})
+			if err := m.AddKeywords[len(m.AddKeywords)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RemoveKeywords", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.RemoveKeywords = append(m.RemoveKeywords, &types.Keyword{})
+			if err := m.RemoveKeywords[len(m.RemoveKeywords)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SetBits", wireType)
+			}
+			m.SetBits = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SetBits |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ClearBits", wireType)
+			}
+			m.ClearBits = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ClearBits |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Bytes", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Bytes = append(m.Bytes[:0], dAtA[iNdEx:postIndex]...)
+			if m.Bytes == nil {
+				m.Bytes = []byte{}
+			}
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ReplaceBytes", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.ReplaceBytes = bool(v != 0)
+		case 8:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field IfVersion", wireType)
+			}
+			m.IfVersion = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.IfVersion |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *BatchDeleteRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: BatchDeleteRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: BatchDeleteRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocIds", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.DocIds = append(m.DocIds, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *DeleteByQueryRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DeleteByQueryRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DeleteByQueryRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Query == nil {
+				m.Query = &types.TermQuery{}
+			}
+			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OnFlag", wireType)
+			}
+			m.OnFlag = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OnFlag |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OffFlag", wireType)
+			}
+			m.OffFlag = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.OffFlag |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType == 0 {
+				var v uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.OrFlags = append(m.OrFlags, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowIndex
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthIndex
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthIndex
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				var count int
+				for _, integer := range dAtA[iNdEx:postIndex] {
+					if integer < 128 {
+						count++
+					}
+				}
+				elementCount = count
+				if elementCount != 0 && len(m.OrFlags) == 0 {
+					m.OrFlags = make([]uint64, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowIndex
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.OrFlags = append(m.OrFlags, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field OrFlags", wireType)
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CompactRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CompactRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CompactRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Threshold", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.Threshold = float64(math.Float64frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CompactResult) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CompactResult: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CompactResult: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BeforeBytes", wireType)
+			}
+			m.BeforeBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.BeforeBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AfterBytes", wireType)
+			}
+			m.AfterBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.AfterBytes |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Compacted", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Compacted = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
 	}
-	return n
-}
 
-func (m *AffectedCount) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Count != 0 {
-		n += 1 + sovIndex(uint64(m.Count))
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	return n
+	return nil
 }
-
-func (m *SearchRequest) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if m.Query != nil {
-		l = m.Query.Size()
-		n += 1 + l + sovIndex(uint64(l))
-	}
-	if m.OnFlag != 0 {
-		n += 1 + sovIndex(uint64(m.OnFlag))
-	}
-	if m.OffFlag != 0 {
-		n += 1 + sovIndex(uint64(m.OffFlag))
-	}
-	if len(m.OrFlags) > 0 {
-		l = 0
-		for _, e := range m.OrFlags {
-			l += sovIndex(uint64(e))
+func (m *DidYouMeanRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowIndex
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
 		}
-		n += 1 + sovIndex(uint64(l)) + l
-	}
-	return n
-}
-
-func (m *SearchResult) Size() (n int) {
-	if m == nil {
-		return 0
-	}
-	var l int
-	_ = l
-	if len(m.Results) > 0 {
-		for _, e := range m.Results {
-			l = e.Size()
-			n += 1 + l + sovIndex(uint64(l))
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: DidYouMeanRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: DidYouMeanRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Word", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Word = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
+			}
+			m.Limit = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Limit |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipIndex(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
 		}
 	}
-	return n
-}
 
-func (m *CountRequest) Size() (n int) {
-	if m == nil {
-		return 0
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
-	var l int
-	_ = l
-	return n
-}
-
-func sovIndex(x uint64) (n int) {
-	return (math_bits.Len64(x|1) + 6) / 7
-}
-func sozIndex(x uint64) (n int) {
-	return sovIndex(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+	return nil
 }
-func (m *DocId) Unmarshal(dAtA []byte) error {
+func (m *Correction) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -785,17 +6907,68 @@ func (m *DocId) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: DocId: wiretype end group for non-group")
+			return fmt.Errorf("proto: Correction: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: DocId: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Correction: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field DocId", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Word", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Word = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DocFreq", wireType)
+			}
+			m.DocFreq = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.DocFreq |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			var stringLen uint64
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Distance", wireType)
+			}
+			m.Distance = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIndex
@@ -805,24 +6978,11 @@ func (m *DocId) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				m.Distance |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
-				return ErrInvalidLengthIndex
-			}
-			postIndex := iNdEx + intStringLen
-			if postIndex < 0 {
-				return ErrInvalidLengthIndex
-			}
-			if postIndex > l {
-				return io.ErrUnexpectedEOF
-			}
-			m.DocId = string(dAtA[iNdEx:postIndex])
-			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIndex(dAtA[iNdEx:])
@@ -844,7 +7004,7 @@ func (m *DocId) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *AffectedCount) Unmarshal(dAtA []byte) error {
+func (m *DidYouMeanResult) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -867,17 +7027,17 @@ func (m *AffectedCount) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: AffectedCount: wiretype end group for non-group")
+			return fmt.Errorf("proto: DidYouMeanResult: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: AffectedCount: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: DidYouMeanResult: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Count", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Corrections", wireType)
 			}
-			m.Count = 0
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIndex
@@ -887,11 +7047,26 @@ func (m *AffectedCount) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.Count |= int32(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Corrections = append(m.Corrections, &Correction{})
+			if err := m.Corrections[len(m.Corrections)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIndex(dAtA[iNdEx:])
@@ -913,7 +7088,7 @@ func (m *AffectedCount) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchRequest) Unmarshal(dAtA []byte) error {
+func (m *SuggestRequest) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -936,17 +7111,17 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SuggestRequest: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SuggestRequest: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Query", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Prefix", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIndex
@@ -956,33 +7131,29 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthIndex
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthIndex
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Query == nil {
-				m.Query = &types.TermQuery{}
-			}
-			if err := m.Query.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Prefix = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
-			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field OnFlag", wireType)
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fields", wireType)
 			}
-			m.OnFlag = 0
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIndex
@@ -992,16 +7163,29 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.OnFlag |= uint64(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Fields = append(m.Fields, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
 		case 3:
 			if wireType != 0 {
-				return fmt.Errorf("proto: wrong wireType = %d for field OffFlag", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Limit", wireType)
 			}
-			m.OffFlag = 0
+			m.Limit = 0
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIndex
@@ -1011,87 +7195,11 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				m.OffFlag |= uint64(b&0x7F) << shift
+				m.Limit |= int32(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-		case 4:
-			if wireType == 0 {
-				var v uint64
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowIndex
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					v |= uint64(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				m.OrFlags = append(m.OrFlags, v)
-			} else if wireType == 2 {
-				var packedLen int
-				for shift := uint(0); ; shift += 7 {
-					if shift >= 64 {
-						return ErrIntOverflowIndex
-					}
-					if iNdEx >= l {
-						return io.ErrUnexpectedEOF
-					}
-					b := dAtA[iNdEx]
-					iNdEx++
-					packedLen |= int(b&0x7F) << shift
-					if b < 0x80 {
-						break
-					}
-				}
-				if packedLen < 0 {
-					return ErrInvalidLengthIndex
-				}
-				postIndex := iNdEx + packedLen
-				if postIndex < 0 {
-					return ErrInvalidLengthIndex
-				}
-				if postIndex > l {
-					return io.ErrUnexpectedEOF
-				}
-				var elementCount int
-				var count int
-				for _, integer := range dAtA[iNdEx:postIndex] {
-					if integer < 128 {
-						count++
-					}
-				}
-				elementCount = count
-				if elementCount != 0 && len(m.OrFlags) == 0 {
-					m.OrFlags = make([]uint64, 0, elementCount)
-				}
-				for iNdEx < postIndex {
-					var v uint64
-					for shift := uint(0); ; shift += 7 {
-						if shift >= 64 {
-							return ErrIntOverflowIndex
-						}
-						if iNdEx >= l {
-							return io.ErrUnexpectedEOF
-						}
-						b := dAtA[iNdEx]
-						iNdEx++
-						v |= uint64(b&0x7F) << shift
-						if b < 0x80 {
-							break
-						}
-					}
-					m.OrFlags = append(m.OrFlags, v)
-				}
-			} else {
-				return fmt.Errorf("proto: wrong wireType = %d for field OrFlags", wireType)
-			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIndex(dAtA[iNdEx:])
@@ -1113,7 +7221,7 @@ func (m *SearchRequest) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *SearchResult) Unmarshal(dAtA []byte) error {
+func (m *Suggestion) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1136,17 +7244,17 @@ func (m *SearchResult) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: SearchResult: wiretype end group for non-group")
+			return fmt.Errorf("proto: Suggestion: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: SearchResult: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Suggestion: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Results", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowIndex
@@ -1156,26 +7264,75 @@ func (m *SearchResult) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthIndex
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthIndex
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Results = append(m.Results, &types.Document{})
-			if err := m.Results[len(m.Results)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			m.Field = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Word", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
 			}
+			m.Word = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Weight", wireType)
+			}
+			m.Weight = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Weight |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIndex(dAtA[iNdEx:])
@@ -1197,7 +7354,7 @@ func (m *SearchResult) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *CountRequest) Unmarshal(dAtA []byte) error {
+func (m *SuggestResult) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -1220,12 +7377,46 @@ func (m *CountRequest) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: CountRequest: wiretype end group for non-group")
+			return fmt.Errorf("proto: SuggestResult: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: CountRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: SuggestResult: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Suggestions", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowIndex
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthIndex
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthIndex
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Suggestions = append(m.Suggestions, &Suggestion{})
+			if err := m.Suggestions[len(m.Suggestions)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipIndex(dAtA[iNdEx:])
@@ -2,16 +2,29 @@ package index_service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/jmh000527/criker-search/index/doc_codec"
 	"github.com/jmh000527/criker-search/index_service/service_hub"
 	"github.com/jmh000527/criker-search/types"
 	"github.com/jmh000527/criker-search/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"io"
 	"strconv"
+	"sync"
 	"time"
 )
 
 const (
-	IndexService = "index_service"
+	IndexService         = "index_service"
+	DefaultBulkBatchSize = 500 // BulkAdd 默认每攒够多少个文档写一次索引
+	DefaultSearchChunk   = 100 // SearchStream 默认每个分片包含的文档数
+
+	DefaultDrainTimeout = 10 * time.Second // 下线时等待在途请求完成的默认最长时间
+
+	heartbeatFrequency  int64 = 3                // 服务续约的心跳频率（租约的TTL），单位：秒
+	metaRefreshInterval       = 30 * time.Second // 刷新注册元数据（如文档数量）的间隔
 )
 
 // IndexServiceWorker 代表一个gRPC服务器，负责处理索引相关的服务请求。
@@ -20,6 +33,23 @@ type IndexServiceWorker struct {
 	Indexer  *LocalIndexer          // 正排索引和倒排索引的组合，用于处理文档的索引和搜索
 	hub      service_hub.ServiceHub // 服务注册和发现相关的配置，负责服务的注册、注销和发现
 	selfAddr string                 // 当前服务实例的地址，用于注册到服务中心和服务发现
+
+	advertiseAddr string        // 注册到服务中心的地址，为空时使用本机IP和服务端口
+	drainTimeout  time.Duration // 下线时等待在途请求完成的最长时间，<=0 时使用 DefaultDrainTimeout
+	lifecycle                   // 注册续约、就绪状态和在途请求统计
+	replication                 // 变更日志和主从复制
+	sharding                    // 分片和副本角色，可以按 coordinator 的分片表切换
+
+	bulkBatchSize int   // BulkAdd 每批写入索引的文档数量，<=0 时使用 DefaultBulkBatchSize
+	weight        int   // 注册到etcd的权重，供加权负载均衡使用，<=0 时使用默认权重
+	generation    int64 // 索引的代数，每次重建索引后递增
+
+	reapInterval time.Duration           // 删除过期文档的间隔，<=0 表示不删除
+	codec        doc_codec.DocumentCodec // 写入正排索引时文档的编码方式，为 nil 时使用 doc_codec.Default
+
+	maintenanceOpts MaintenanceOptions // 后台整理正排索引的选项，Interval<=0 表示不在后台整理
+
+	completionFields []string // 需要自动补全的字段，为空时 Suggest 不返回结果
 }
 
 // Init 初始化索引服务。
@@ -27,16 +57,85 @@ type IndexServiceWorker struct {
 //
 // 参数:
 //   - DocNumEstimate: 预计文档数量，用于初始化倒排索引。
-//   - dbtype: 数据库类型，决定使用哪种数据库存储索引数据。
+//   - dbtype: 数据库类型的名称，决定使用哪种数据库存储索引数据。
 //   - DataDir: 数据目录，数据库文件存放的路径。
 //
 // 返回值:
 //   - error: 如果初始化过程中发生错误，则返回相应的错误。
-func (w *IndexServiceWorker) Init(DocNumEstimate int, dbtype int, DataDir string) error {
+func (w *IndexServiceWorker) Init(DocNumEstimate int, dbtype string, DataDir string) error {
 	// 创建一个新的Indexer实例
-	w.Indexer = new(LocalIndexer)
+	w.Indexer = new(LocalIndexer).WithCodec(w.codec).WithCompletion(w.completionFields...)
 	// 初始化Indexer实例，并传递文档数量估计、数据库类型和数据目录
-	return w.Indexer.Init(DocNumEstimate, dbtype, DataDir)
+	if err := w.Indexer.Init(DocNumEstimate, dbtype, DataDir); err != nil {
+		return err
+	}
+	// 开启了变更日志时打开变更日志
+	if err := w.openChangeLog(DataDir); err != nil {
+		w.Indexer.Close()
+		return err
+	}
+	w.Indexer.StartReaper(w.reapInterval)
+	w.Indexer.StartMaintenance(w.maintenanceOpts)
+	return nil
+}
+
+// WithBulkBatchSize 设置 BulkAdd 每批写入索引的文档数量。
+// 批次越大写入吞吐越高，但单批占用的内存和单次写事务也越大。
+func (w *IndexServiceWorker) WithBulkBatchSize(batchSize int) *IndexServiceWorker {
+	w.bulkBatchSize = batchSize
+	return w
+}
+
+// WithWeight 设置注册到etcd的权重。
+// 使用加权轮询负载均衡时，Sentinel 按权重比例把写请求分配给各个 worker，机器配置更高的 worker 可以设置更大的权重。
+func (w *IndexServiceWorker) WithWeight(weight int) *IndexServiceWorker {
+	w.weight = weight
+	return w
+}
+
+// WithGeneration 设置索引的代数，随服务注册一起发布。每次重建索引后应递增，运维据此判断各个节点上的索引是否为最新。
+func (w *IndexServiceWorker) WithGeneration(generation int64) *IndexServiceWorker {
+	w.generation = generation
+	return w
+}
+
+// WithCodec 设置写入正排索引时文档的编码方式，需要在 Init 之前调用。
+func (w *IndexServiceWorker) WithCodec(codec doc_codec.DocumentCodec) *IndexServiceWorker {
+	w.codec = codec
+	return w
+}
+
+// WithExpiryReaper 设置删除过期文档的间隔，需要在 Init 之前调用。
+// 不设置时过期的文档仍然检索不到，但会一直占用索引空间。
+func (w *IndexServiceWorker) WithExpiryReaper(interval time.Duration) *IndexServiceWorker {
+	w.reapInterval = interval
+	return w
+}
+
+// WithMaintenance 设置后台整理正排索引的选项，需要在 Init 之前调用。
+// 不设置时已删除和被覆盖的文档占用的磁盘空间不会自动回收，可以通过 Compact 接口手动整理。
+func (w *IndexServiceWorker) WithMaintenance(opts MaintenanceOptions) *IndexServiceWorker {
+	w.maintenanceOpts = opts
+	return w
+}
+
+// WithCompletion 设置需要自动补全的字段，需要在 Init 之前调用。
+func (w *IndexServiceWorker) WithCompletion(fields ...string) *IndexServiceWorker {
+	w.completionFields = fields
+	return w
+}
+
+// serviceMeta 构造注册到服务中心的元数据
+func (w *IndexServiceWorker) serviceMeta() service_hub.ServiceMeta {
+	shardId, role := w.shard()
+	return service_hub.ServiceMeta{
+		Version:    utils.Version,
+		ShardId:    shardId,
+		Role:       role,
+		Weight:     w.weight,
+		DocCount:   w.Indexer.Count(),
+		Generation: w.generation,
+	}
 }
 
 // RegisterService 注册服务到etcd。如果提供了etcdServers，则创建EtcdServiceHub并注册服务。
@@ -50,78 +149,179 @@ func (w *IndexServiceWorker) Init(DocNumEstimate int, dbtype int, DataDir string
 //   - error: 如果传入的端口号无效或服务注册过程中发生错误，则返回相应的错误。
 func (w *IndexServiceWorker) RegisterService(etcdServers []string, servicePort int) error {
 	// 检查是否需要注册服务到etcd
-	if len(etcdServers) > 0 {
-		// 验证服务端口号是否合法
-		if servicePort <= 1024 {
-			return fmt.Errorf("无效的服务端口号 %d，服务端口必须大于1024", servicePort)
-		}
+	if len(etcdServers) == 0 {
+		return nil
+	}
+	// 获取EtcdServiceHub实例（单例模式）
+	return w.RegisterServiceWithHub(service_hub.GetServiceHub(etcdServers, heartbeatFrequency), servicePort)
+}
 
-		// 获取本地IP地址
-		localIP, err := utils.GetLocalIP()
-		if err != nil {
-			return fmt.Errorf("获取本地IP地址失败: %v", err)
-		}
+// RegisterServiceWithHub 把服务注册到指定的ServiceHub，并启动一个协程持续续约、定期刷新元数据，直到 Close。
+// 通过传入不同的ServiceHub实现，worker可以不依赖etcd集群运行，例如测试时使用MemoryServiceHub。
+// 只有索引加载完成（调用过 LoadFromIndexFile 或 MarkReady）之后才能注册，避免 Sentinel 把请求发到还没有数据的 worker 上。
+//
+// 参数:
+//   - hub: 服务注册中心。
+//   - servicePort: 服务端口号。必须大于1024。
+//
+// 返回值:
+//   - error: 如果索引尚未就绪、传入的端口号无效或服务注册过程中发生错误，则返回相应的错误。
+func (w *IndexServiceWorker) RegisterServiceWithHub(hub service_hub.ServiceHub, servicePort int) error {
+	if !w.ready.Load() {
+		return errors.New("索引尚未加载完成，不能注册服务")
+	}
+	// 验证服务端口号是否合法
+	if servicePort <= 1024 {
+		return fmt.Errorf("无效的服务端口号 %d，服务端口必须大于1024", servicePort)
+	}
+	w.selfAddr = w.advertiseAddress(servicePort)
+	w.metaChanged = make(chan struct{}, 1)
 
-		// 单机模式下，将本地IP写死为127.0.0.1
-		localIP = "127.0.0.1"
-		w.selfAddr = localIP + ":" + strconv.Itoa(servicePort)
+	// 注册服务，初始时租约ID为0，版本、分片、权重、文档数量等元数据随注册一起发布
+	meta := w.serviceMeta()
+	leaseID, err := hub.RegisterServiceWithMeta(IndexService, w.selfAddr, 0, meta)
+	if err != nil {
+		return fmt.Errorf("服务注册失败: %v", err)
+	}
 
-		// 设置心跳频率
-		var heartbeatFrequency int64 = 3
+	// 设置hub
+	w.hub = hub
 
-		// 获取EtcdServiceHub实例（单例模式）
-		hub := service_hub.GetServiceHub(etcdServers, heartbeatFrequency)
+	// 启动一个协程，持续续约服务租约并定期刷新元数据，Close 时停止
+	ctx, cancel := context.WithCancel(context.Background())
+	w.stopRegistration = cancel
+	w.registrationDone = make(chan struct{})
+	go w.keepRegistered(ctx, hub, leaseID, meta)
+	return nil
+}
 
-		// 注册服务到etcd，初始时租约ID为0
-		leaseID, err := hub.RegisterService(IndexService, w.selfAddr, 0)
-		if err != nil {
-			return fmt.Errorf("服务注册失败: %v", err)
-		}
+// advertiseAddress 计算注册到服务中心的地址。
+// 优先使用 WithAdvertiseAddr 配置的地址，否则使用本机IP和服务端口；获取本机IP失败（例如只有回环网卡）时使用127.0.0.1。
+func (w *IndexServiceWorker) advertiseAddress(servicePort int) string {
+	if len(w.advertiseAddr) > 0 {
+		return w.advertiseAddr
+	}
+	localIP, err := utils.GetLocalIP()
+	if err != nil {
+		utils.Log.Printf("获取本地IP地址失败，使用127.0.0.1: %v", err)
+		localIP = "127.0.0.1"
+	}
+	return localIP + ":" + strconv.Itoa(servicePort)
+}
 
-		// 设置hub
-		w.hub = hub
+// keepRegistered 持续为租约续约，租约丢失时重新注册；同时定期刷新元数据。ctx 被取消后返回。
+// meta 是注册时发布的元数据。
+func (w *IndexServiceWorker) keepRegistered(ctx context.Context, hub service_hub.ServiceHub, leaseID int64, meta service_hub.ServiceMeta) {
+	defer close(w.registrationDone)
+	var leaseMu sync.Mutex // 保护 leaseID，续约协程和刷新元数据的协程都会访问
+	currentLease := func() int64 {
+		leaseMu.Lock()
+		defer leaseMu.Unlock()
+		return leaseID
+	}
 
-		// 启动一个协程，定期续约服务租约
-		go func() {
-			for {
-				_, err := hub.RegisterService(IndexService, w.selfAddr, leaseID)
-				if err != nil {
-					utils.Log.Printf("续约服务租约失败，租约ID: %v, 错误: %v", leaseID, err)
+	// 文档数量等元数据会变化，为了减少对服务中心的写入，间隔较长时间才刷新一次
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(metaRefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-w.metaChanged:
+				// 分片或角色变化需要立即发布
+			}
+			if latest := w.serviceMeta(); latest != meta {
+				if err := hub.UpdateServiceMeta(IndexService, w.selfAddr, currentLease(), latest); err != nil {
+					utils.Log.Printf("更新服务元数据失败: %v", err)
+				} else {
+					meta = latest
 				}
-				// 心跳间隔时间稍短于最大超时时间
-				time.Sleep(time.Duration(heartbeatFrequency)*time.Second - 100*time.Millisecond)
 			}
-		}()
+		}
+	}()
+
+	for {
+		// 阻塞续约，直到 ctx 被取消或租约丢失
+		if err := hub.KeepAlive(ctx, currentLease()); err == nil || ctx.Err() != nil {
+			break
+		}
+		// 租约丢失，重新注册直到成功
+		for ctx.Err() == nil {
+			newLeaseID, err := hub.RegisterServiceWithMeta(IndexService, w.selfAddr, 0, w.serviceMeta())
+			if err == nil {
+				leaseMu.Lock()
+				leaseID = newLeaseID
+				leaseMu.Unlock()
+				utils.Log.Printf("租约丢失后重新注册服务成功，租约ID: %v", newLeaseID)
+				break
+			}
+			utils.Log.Printf("重新注册服务失败，%d 秒后重试: %v", heartbeatFrequency, err)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Duration(heartbeatFrequency) * time.Second):
+			}
+		}
 	}
-	return nil
+	wg.Wait()
 }
 
 // LoadFromIndexFile 从索引文件中加载数据。在系统重启后，可以通过此方法从持久化的索引文件中恢复数据。
+// 加载完成后 worker 进入就绪状态，可以注册服务并处理请求。
 //
 // 返回值:
 //   - int: 加载成功的文档数量。如果加载过程中发生错误，则返回0。
 func (w *IndexServiceWorker) LoadFromIndexFile() int {
-	return w.Indexer.LoadFromIndexFile()
+	n := w.Indexer.LoadFromIndexFile()
+	w.MarkReady()
+	return n
 }
 
-// Close 关闭索引服务。如果服务在etcd中注册过，则需要注销服务；否则只需要关闭索引。
+// Close 优雅下线：先停止续约并从服务中心注销，使 Sentinel 不再发来新的请求；
+// 再停止跟随主副本、结束从副本的订阅，拒绝新的请求，等待在途请求完成（最多 drainTimeout）；最后关闭索引和变更日志。
 //
 // 返回值:
 //   - error: 如果在注销服务或关闭索引过程中发生错误，则返回相应的错误。
 func (w *IndexServiceWorker) Close() error {
+	var unregisterErr error
 	// 检查是否需要注销服务
 	if w.hub != nil {
+		// 停止续约
+		w.stopRegistration()
+		<-w.registrationDone
 		// 注销服务
-		err := w.hub.UnregisterService(IndexService, w.selfAddr)
-		if err != nil {
-			utils.Log.Printf("注销服务失败，服务地址: %v, 错误: %v", w.selfAddr, err)
-			return err
+		unregisterErr = w.hub.UnregisterService(IndexService, w.selfAddr)
+		if unregisterErr != nil {
+			utils.Log.Printf("注销服务失败，服务地址: %v, 错误: %v", w.selfAddr, unregisterErr)
+		} else {
+			utils.Log.Printf("注销服务成功，服务地址: %v", w.selfAddr)
 		}
-		utils.Log.Printf("注销服务成功，服务地址: %v", w.selfAddr)
+	}
+
+	// 停止监视分片表和复制，订阅流是长期存在的请求，不结束的话 drain 只能等到超时
+	w.closeSharding()
+	w.closeReplication()
+
+	// 等待在途请求完成
+	drainTimeout := w.effectiveDrainTimeout()
+	if !w.drain(drainTimeout) {
+		utils.Log.Printf("等待在途请求完成超时(%v)，强制关闭索引", drainTimeout)
 	}
 
 	// 关闭索引
-	return w.Indexer.Close()
+	if err := w.Indexer.Close(); err != nil {
+		return err
+	}
+	if w.changeLog != nil {
+		if err := w.changeLog.Close(); err != nil {
+			return err
+		}
+	}
+	return unregisterErr
 }
 
 // DeleteDoc 从索引中删除文档。根据提供的文档ID删除对应的文档。
@@ -134,10 +334,56 @@ func (w *IndexServiceWorker) Close() error {
 //   - *AffectedCount: 删除操作影响的文档数量。
 //   - error: 如果删除操作中发生错误，则返回相应的错误。
 func (w *IndexServiceWorker) DeleteDoc(ctx context.Context, docId *DocId) (*AffectedCount, error) {
-	// 调用Indexer的DeleteDoc方法删除文档，并返回影响的文档数量
+	// 删除文档并追加到变更日志，返回影响的文档数量
+	n, err := w.deleteDoc(docId.DocId)
 	return &AffectedCount{
-		Count: int32(w.Indexer.DeleteDoc(docId.DocId)),
-	}, nil
+		Count: int32(n),
+	}, err
+}
+
+// BatchDelete 批量删除文档。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 包含要删除的文档ID列表。
+//
+// 返回值:
+//   - *AffectedCount: 实际删除的文档数量，不存在的文档不计入。
+//   - error: 如果删除操作中发生错误，则返回相应的错误。
+func (w *IndexServiceWorker) BatchDelete(ctx context.Context, request *BatchDeleteRequest) (*AffectedCount, error) {
+	n, err := w.batchDelete(request.DocIds)
+	return &AffectedCount{
+		Count: int32(n),
+	}, err
+}
+
+// DeleteByQuery 删除所有符合检索条件的文档。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 检索条件，与 Search 的检索条件含义相同。
+//
+// 返回值:
+//   - *AffectedCount: 实际删除的文档数量。
+//   - error: 如果删除操作中发生错误，则返回相应的错误。
+func (w *IndexServiceWorker) DeleteByQuery(ctx context.Context, request *DeleteByQueryRequest) (*AffectedCount, error) {
+	n, err := w.deleteByQuery(request)
+	return &AffectedCount{
+		Count: int32(n),
+	}, err
+}
+
+// BatchAddDoc 在 worker 进程内批量写入文档，例如从原始数据重建索引。
+// 与直接写 Indexer 不同，写入成功的文档会追加到变更日志，从副本可以同步到。
+//
+// 参数:
+//   - docs: 要写入的文档。
+//
+// 返回值:
+//   - int: 写入成功的文档数量。
+//   - []error: 与 docs 一一对应的错误，nil 表示写入成功。本 worker 是从副本时全部返回 FailedPrecondition。
+func (w *IndexServiceWorker) BatchAddDoc(docs []types.Document) (int, []error) {
+	return w.batchAddDoc(docs)
 }
 
 // AddDoc 向索引中添加文档。如果文档已经存在，会先删除旧文档再添加新文档。
@@ -150,11 +396,58 @@ func (w *IndexServiceWorker) DeleteDoc(ctx context.Context, docId *DocId) (*Affe
 //   - *AffectedCount: 添加操作影响的文档数量。
 //   - error: 如果添加操作中发生错误，则返回相应的错误。
 func (w *IndexServiceWorker) AddDoc(ctx context.Context, doc *types.Document) (*AffectedCount, error) {
-	// 调用Indexer的AddDoc方法添加文档，并返回影响的文档数量
-	n, err := w.Indexer.AddDoc(*doc)
+	// 添加文档并追加到变更日志，返回影响的文档数量
+	if _, err := w.addDoc(*doc, WriteOptions{}); err != nil {
+		return &AffectedCount{Count: 0}, err
+	}
 	return &AffectedCount{
-		Count: int32(n),
-	}, err
+		Count: 1,
+	}, nil
+}
+
+// AddDocWithOptions 带版本检查地向索引中添加文档。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 要添加的文档和版本检查的条件。
+//
+// 返回值:
+//   - *DocVersion: 写入后文档的版本号。
+//   - error: 版本冲突时返回 Aborted，调用方可以重新读取文档后重试；其他错误原样返回。
+func (w *IndexServiceWorker) AddDocWithOptions(ctx context.Context, request *AddDocRequest) (*DocVersion, error) {
+	if request.Doc == nil {
+		return nil, status.Error(codes.InvalidArgument, "文档不能为空")
+	}
+	version, err := w.addDoc(*request.Doc, WriteOptions{IfVersion: request.IfVersion, ExternalVersion: request.ExternalVersion})
+	if errors.Is(err, ErrVersionConflict) {
+		return nil, status.Error(codes.Aborted, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &DocVersion{Version: version}, nil
+}
+
+// UpdateDoc 局部更新文档，只修改补丁中指定的关键词、位特征和 Bytes。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - patch: 要对文档做的修改。
+//
+// 返回值:
+//   - *DocVersion: 更新后文档的版本号。
+//   - error: 文档不存在时返回 NotFound，版本冲突时返回 Aborted，其他错误原样返回。
+func (w *IndexServiceWorker) UpdateDoc(ctx context.Context, patch *DocPatch) (*DocVersion, error) {
+	version, err := w.updateDoc(patch)
+	switch {
+	case errors.Is(err, ErrDocNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, ErrVersionConflict):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case err != nil:
+		return nil, err
+	}
+	return &DocVersion{Version: version}, nil
 }
 
 // Search 执行检索操作，返回符合查询条件的文档列表。
@@ -168,7 +461,14 @@ func (w *IndexServiceWorker) AddDoc(ctx context.Context, doc *types.Document) (*
 //   - error: 如果检索操作中发生错误，则返回相应的错误。
 func (w *IndexServiceWorker) Search(ctx context.Context, request *SearchRequest) (*SearchResult, error) {
 	// 调用Indexer的Search方法进行检索，并返回检索结果
-	result := w.Indexer.Search(request.Query, request.OnFlag, request.OffFlag, request.OrFlags)
+	var result []*types.Document
+	err := w.Indexer.SearchInChunks(request.Query, request.OnFlag, request.OffFlag, request.OrFlags, 0, int(request.Limit), func(docs []*types.Document) error {
+		result = docs
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 	return &SearchResult{
 		Results: result,
 	}, nil
@@ -189,3 +489,92 @@ func (w *IndexServiceWorker) Count(ctx context.Context, request *CountRequest) (
 		Count: int32(w.Indexer.Count()),
 	}, nil
 }
+
+// Stats 返回本 worker 的索引统计信息。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 统计请求。
+//
+// 返回值:
+//   - *IndexStats: 本 worker 的索引统计信息。
+//   - error: 如果统计过程中发生错误，则返回相应的错误。
+func (w *IndexServiceWorker) Stats(ctx context.Context, request *StatsRequest) (*IndexStats, error) {
+	return w.Indexer.Stats()
+}
+
+// BulkAdd 客户端流式批量写入文档。
+// 每收到 batchSize 个文档就调用一次 LocalIndexer.BatchAddDoc，写完当前批次之后才继续从流中读取，
+// 借助 gRPC 的流量控制，服务端处理不过来时客户端的 Send 会被阻塞，从而形成背压。
+//
+// 参数:
+//   - stream: 客户端发送文档的流。
+//
+// 返回值:
+//   - error: 如果读取流或返回结果时发生错误，则返回相应的错误。单个文档的写入失败记录在 BulkAddResult.Statuses 中。
+func (w *IndexServiceWorker) BulkAdd(stream IndexService_BulkAddServer) error {
+	batchSize := w.bulkBatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBulkBatchSize
+	}
+
+	result := new(BulkAddResult)
+	batch := make([]types.Document, 0, batchSize)
+	// flush 将当前批次写入索引，并记录每个文档的写入状态
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		n, errs := w.batchAddDoc(batch)
+		result.Count += int32(n)
+		for i, doc := range batch {
+			status := &DocStatus{DocId: doc.Id, Ok: errs[i] == nil}
+			if errs[i] != nil {
+				status.Error = errs[i].Error()
+			}
+			result.Statuses = append(result.Statuses, status)
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		doc, err := stream.Recv()
+		if err == io.EOF {
+			// 客户端发送完毕，写入最后一批并返回结果
+			flush()
+			utils.Log.Printf("批量写入完成，共成功写入 %d 个文档", result.Count)
+			return stream.SendAndClose(result)
+		}
+		if err != nil {
+			utils.Log.Printf("接收批量写入的文档失败: %v", err)
+			return err
+		}
+		batch = append(batch, *doc)
+		if len(batch) >= batchSize {
+			flush()
+		}
+	}
+}
+
+// SearchStream 服务端流式检索，文档从正排索引中按分片解码后立即发送，单条消息的大小不会随命中数量无限增长。
+// 客户端取消请求（例如哨兵已收集到足够多的结果）后，剩余的分片不再读取和发送。
+//
+// 参数:
+//   - request: 包含检索查询的请求对象，ChunkSize 指定每个分片的文档数，Limit 指定最多返回的文档数。
+//   - stream: 向客户端发送检索结果的流。
+//
+// 返回值:
+//   - error: 如果检索或发送过程中发生错误，则返回相应的错误。
+func (w *IndexServiceWorker) SearchStream(request *SearchRequest, stream IndexService_SearchStreamServer) error {
+	chunkSize := int(request.ChunkSize)
+	if chunkSize <= 0 {
+		chunkSize = DefaultSearchChunk
+	}
+	return w.Indexer.SearchInChunks(request.Query, request.OnFlag, request.OffFlag, request.OrFlags, chunkSize, int(request.Limit), func(docs []*types.Document) error {
+		// 客户端已取消，提前结束
+		if err := stream.Context().Err(); err != nil {
+			return err
+		}
+		return stream.Send(&SearchResult{Results: docs})
+	})
+}
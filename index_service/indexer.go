@@ -1,12 +1,47 @@
 package index_service
 
-import "github.com/jmh000527/criker-search/types"
+import (
+	"errors"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
 
 // Indexer Sentinel（分布式grpc的哨兵）和 LocalIndexer（单机索引）都实现了该接口
 type Indexer interface {
 	AddDoc(doc types.Document) (int, error)
+	AddDocWithOptions(doc types.Document, opts WriteOptions) (int64, error) // 带版本检查的写入，返回写入后的版本号
+	UpdateDoc(patch *DocPatch) (int64, error)                               // 局部更新文档，返回更新后的版本号
+	BatchAddDoc(docs []types.Document) (int, []error)                       // 批量添加文档，返回成功数量以及与 docs 一一对应的错误（nil 表示成功）
 	DeleteDoc(docId string) int
+	BatchDelete(docIds []string) (*DeleteResult, error)                                                    // 批量删除文档
+	DeleteByQuery(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) (*DeleteResult, error) // 删除所有符合检索条件的文档
 	Search(query *types.TermQuery, onFlag uint64, offFlag uint64, orFlags []uint64) []*types.Document
 	Count() int
+	Stats() (*IndexStats, error)                                              // 索引的统计信息，Sentinel 返回整个集群汇总后的结果
+	DidYouMean(field, word string, limit int) ([]*Correction, error)          // 从词典中查找与 word 相近的词，用于拼写纠错
+	Suggest(prefix string, fields []string, limit int) ([]*Suggestion, error) // 输入时的自动补全，返回以 prefix 开头、文档数量最多的词
 	Close() error
 }
+
+// WriteOptions 写入文档时的版本检查选项，零值表示不检查版本、版本号在旧版本号的基础上加1。
+type WriteOptions struct {
+	IfVersion       int64 // >0 时只有文档的当前版本号等于 IfVersion 才写入；<0 时只有文档不存在才写入；0 表示不检查
+	ExternalVersion bool  // 使用文档携带的版本号（由业务侧维护，例如数据库中的更新时间），只有大于当前版本号才写入，旧的更新重放时不会覆盖新的更新
+}
+
+// DeleteResult 批量删除的结果
+type DeleteResult struct {
+	Count   int            // 实际删除的文档总数
+	Workers map[string]int // 每个 worker 删除的文档数量，key 为 worker 的地址。LocalIndexer 返回的结果中为 nil
+}
+
+// IsVersionConflict 判断写入失败是否是因为版本冲突，既适用于 LocalIndexer 返回的错误，也适用于经过 gRPC 返回的错误。
+func IsVersionConflict(err error) bool {
+	return errors.Is(err, ErrVersionConflict) || status.Code(err) == codes.Aborted
+}
+
+// IsDocNotFound 判断更新失败是否是因为文档不存在，既适用于 LocalIndexer 返回的错误，也适用于经过 gRPC 返回的错误。
+func IsDocNotFound(err error) bool {
+	return errors.Is(err, ErrDocNotFound) || status.Code(err) == codes.NotFound
+}
@@ -0,0 +1,92 @@
+package load_balancer
+
+import (
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultVirtualNodes 一致性哈希中每个端点默认的虚拟节点数
+const DefaultVirtualNodes = 100
+
+// ConsistentHash 负载均衡算法：一致性哈希
+// 每个端点在哈希环上放置若干个虚拟节点，key顺时针找到的第一个虚拟节点所属的端点即为选中的端点。
+// 端点上下线时只有少部分key会换到别的端点上。
+type ConsistentHash struct {
+	RoundRobin // 没有key时退化为轮询
+
+	virtualNodes int
+
+	mu      sync.RWMutex
+	members string            // 构建哈希环时的端点列表，端点列表变化时重建哈希环
+	ring    []uint32          // 有序的虚拟节点哈希值
+	owners  map[uint32]string // 虚拟节点哈希值 -> 端点
+}
+
+// NewConsistentHash 创建一个一致性哈希负载均衡
+//
+// 参数:
+//   - virtualNodes: 每个端点的虚拟节点数，<=0 时使用 DefaultVirtualNodes。
+//
+// 返回值:
+//   - *ConsistentHash: 新创建的一致性哈希负载均衡。
+func NewConsistentHash(virtualNodes int) *ConsistentHash {
+	if virtualNodes <= 0 {
+		virtualNodes = DefaultVirtualNodes
+	}
+	return &ConsistentHash{virtualNodes: virtualNodes}
+}
+
+// TakeByKey 根据key选择一个Endpoint
+func (b *ConsistentHash) TakeByKey(endpoints []string, key string) string {
+	if len(endpoints) == 0 {
+		return ""
+	}
+	b.mu.RLock()
+	ring, owners := b.ring, b.owners
+	if b.members != membersOf(endpoints) {
+		b.mu.RUnlock()
+		ring, owners = b.rebuild(endpoints)
+	} else {
+		b.mu.RUnlock()
+	}
+
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(ring), func(i int) bool { return ring[i] >= h })
+	if i == len(ring) {
+		i = 0
+	}
+	return owners[ring[i]]
+}
+
+// rebuild 根据端点列表重建哈希环
+func (b *ConsistentHash) rebuild(endpoints []string) ([]uint32, map[uint32]string) {
+	ring := make([]uint32, 0, len(endpoints)*b.virtualNodes)
+	owners := make(map[uint32]string, len(endpoints)*b.virtualNodes)
+	for _, endpoint := range endpoints {
+		for i := 0; i < b.virtualNodes; i++ {
+			h := crc32.ChecksumIEEE([]byte(endpoint + "#" + strconv.Itoa(i)))
+			if _, exists := owners[h]; exists {
+				continue
+			}
+			owners[h] = endpoint
+			ring = append(ring, h)
+		}
+	}
+	sort.Slice(ring, func(i, j int) bool { return ring[i] < ring[j] })
+
+	b.mu.Lock()
+	b.members, b.ring, b.owners = membersOf(endpoints), ring, owners
+	b.mu.Unlock()
+	return ring, owners
+}
+
+// membersOf 把端点列表转成与顺序无关的字符串，用于判断端点列表是否变化
+func membersOf(endpoints []string) string {
+	sorted := make([]string, len(endpoints))
+	copy(sorted, endpoints)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,89 @@
+package load_balancer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// endpointStats 单个端点的在途请求数和延迟统计
+type endpointStats struct {
+	outstanding int64 // 在途请求数
+	ewma        int64 // 请求延迟的指数加权移动平均，单位纳秒，0表示还没有样本
+}
+
+// statsTable 按端点保存在途请求数和延迟，供依赖请求反馈的负载均衡使用
+type statsTable struct {
+	stats sync.Map // endpoint -> *endpointStats
+}
+
+// ewmaDecay 延迟指数加权移动平均的衰减系数，越大越看重最近的请求
+const ewmaDecay = 0.2
+
+// get 获取端点的统计，不存在则创建
+func (t *statsTable) get(endpoint string) *endpointStats {
+	if v, exists := t.stats.Load(endpoint); exists {
+		return v.(*endpointStats)
+	}
+	v, _ := t.stats.LoadOrStore(endpoint, &endpointStats{})
+	return v.(*endpointStats)
+}
+
+// Begin 向端点发出一个请求之前调用，在途请求数加1
+func (t *statsTable) Begin(endpoint string) {
+	atomic.AddInt64(&t.get(endpoint).outstanding, 1)
+}
+
+// Done 请求结束之后调用，在途请求数减1并更新延迟
+func (t *statsTable) Done(endpoint string, latency time.Duration, err error) {
+	s := t.get(endpoint)
+	if atomic.AddInt64(&s.outstanding, -1) < 0 {
+		// Begin和Done不配对时（例如统计开始之前就已发出的请求），不让在途请求数变为负数
+		atomic.StoreInt64(&s.outstanding, 0)
+	}
+	// 失败的请求通常很快返回，其延迟不代表端点的处理能力，不参与统计
+	if err != nil {
+		return
+	}
+	for {
+		old := atomic.LoadInt64(&s.ewma)
+		value := int64(latency)
+		if old > 0 {
+			value = int64(ewmaDecay*float64(latency) + (1-ewmaDecay)*float64(old))
+		}
+		if atomic.CompareAndSwapInt64(&s.ewma, old, value) {
+			return
+		}
+	}
+}
+
+// LeastOutstanding 负载均衡算法：最少在途请求法
+// 选择当前在途请求数最少的端点，处理得慢的端点会积压请求，从而自动少分配请求。
+// 在途请求数相同时轮流选择，避免总是选中列表中靠前的端点。
+type LeastOutstanding struct {
+	statsTable
+	acc int64 // 记录累计请求次数，用于在途请求数相同时轮流选择
+}
+
+// NewLeastOutstanding 创建一个最少在途请求负载均衡
+func NewLeastOutstanding() *LeastOutstanding {
+	return &LeastOutstanding{}
+}
+
+// Take 选择一个Endpoint，根据最少在途请求算法
+func (b *LeastOutstanding) Take(endpoints []string) string {
+	if len(endpoints) == 0 {
+		return ""
+	}
+	offset := int(atomic.AddInt64(&b.acc, 1) % int64(len(endpoints)))
+	best := ""
+	var least int64
+	for i := range endpoints {
+		endpoint := endpoints[(offset+i)%len(endpoints)]
+		outstanding := atomic.LoadInt64(&b.get(endpoint).outstanding)
+		if best == "" || outstanding < least {
+			best, least = endpoint, outstanding
+		}
+	}
+	return best
+}
@@ -1,7 +1,75 @@
 package load_balancer
 
+import (
+	"fmt"
+	"time"
+)
+
 // LoadBalancer 负载均衡接口，定义选择Endpoint的方法
 type LoadBalancer interface {
 	// Take 从给定的端点列表中选择一个
 	Take(endpoints []string) string
 }
+
+// KeyedLoadBalancer 支持按key选择Endpoint的负载均衡，相同的key总是落到同一个端点上（端点列表不变时）
+type KeyedLoadBalancer interface {
+	LoadBalancer
+	// TakeByKey 根据key从给定的端点列表中选择一个
+	TakeByKey(endpoints []string, key string) string
+}
+
+// WeightedLoadBalancer 需要知道各个端点权重的负载均衡
+type WeightedLoadBalancer interface {
+	LoadBalancer
+	// SetWeight 设置端点的权重，weight<=0 时使用默认权重
+	SetWeight(endpoint string, weight int)
+}
+
+// FeedbackLoadBalancer 需要根据请求结果做决策的负载均衡，例如统计在途请求数和延迟
+type FeedbackLoadBalancer interface {
+	LoadBalancer
+	// Begin 向端点发出一个请求之前调用
+	Begin(endpoint string)
+	// Done 请求结束之后调用，latency为请求耗时，err为请求返回的错误
+	Done(endpoint string, latency time.Duration, err error)
+}
+
+// 负载均衡策略的名称，用于在配置中指定使用哪种负载均衡
+const (
+	StrategyRoundRobin         = "round_robin"          // 轮询
+	StrategyRandom             = "random"               // 随机
+	StrategyWeightedRoundRobin = "weighted_round_robin" // 加权轮询，权重由worker注册到etcd时发布
+	StrategyLeastOutstanding   = "least_outstanding"    // 最少在途请求
+	StrategyP2C                = "p2c"                  // 随机选两个端点，取负载（延迟×在途请求）较小的一个
+	StrategyConsistentHash     = "consistent_hash"      // 一致性哈希，相同的key总是落到同一个端点
+)
+
+// DefaultWeight 未发布权重的端点使用的默认权重
+const DefaultWeight = 1
+
+// NewLoadBalancer 根据策略名称创建负载均衡。
+//
+// 参数:
+//   - strategy: 负载均衡策略的名称，为空时使用轮询。
+//
+// 返回值:
+//   - LoadBalancer: 新创建的负载均衡。
+//   - error: 策略名称不合法时返回错误。
+func NewLoadBalancer(strategy string) (LoadBalancer, error) {
+	switch strategy {
+	case "", StrategyRoundRobin:
+		return &RoundRobin{}, nil
+	case StrategyRandom:
+		return &RandomSelect{}, nil
+	case StrategyWeightedRoundRobin:
+		return NewWeightedRoundRobin(), nil
+	case StrategyLeastOutstanding:
+		return NewLeastOutstanding(), nil
+	case StrategyP2C:
+		return NewP2C(), nil
+	case StrategyConsistentHash:
+		return NewConsistentHash(DefaultVirtualNodes), nil
+	default:
+		return nil, fmt.Errorf("不支持的负载均衡策略: %s", strategy)
+	}
+}
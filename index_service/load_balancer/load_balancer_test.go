@@ -0,0 +1,87 @@
+package load_balancer
+
+import (
+	"testing"
+	"time"
+)
+
+var endpoints = []string{"127.0.0.1:5000", "127.0.0.1:5001", "127.0.0.1:5002"}
+
+func TestWeightedRoundRobin(t *testing.T) {
+	lb := NewWeightedRoundRobin()
+	lb.SetWeight(endpoints[0], 3)
+	counts := make(map[string]int)
+	for i := 0; i < 50; i++ {
+		counts[lb.Take(endpoints)]++
+	}
+	// 权重 3:1:1
+	if counts[endpoints[0]] != 30 || counts[endpoints[1]] != 10 || counts[endpoints[2]] != 10 {
+		t.Fatalf("加权轮询的分配比例不正确: %v", counts)
+	}
+}
+
+func TestLeastOutstanding(t *testing.T) {
+	lb := NewLeastOutstanding()
+	lb.Begin(endpoints[0])
+	lb.Begin(endpoints[1])
+	for i := 0; i < 10; i++ {
+		if endpoint := lb.Take(endpoints); endpoint != endpoints[2] {
+			t.Fatalf("应选择在途请求最少的 %s，实际选择了 %s", endpoints[2], endpoint)
+		}
+	}
+	lb.Done(endpoints[0], time.Millisecond, nil)
+	lb.Done(endpoints[1], time.Millisecond, nil)
+}
+
+func TestP2C(t *testing.T) {
+	lb := NewP2C()
+	for i := 0; i < 10; i++ {
+		lb.Begin(endpoints[0])
+		lb.Done(endpoints[0], time.Second, nil)
+		lb.Begin(endpoints[1])
+		lb.Done(endpoints[1], time.Millisecond, nil)
+	}
+	// 只有两个端点时每次都会比较这两个，应总是选择延迟低的
+	for i := 0; i < 10; i++ {
+		if endpoint := lb.Take(endpoints[:2]); endpoint != endpoints[1] {
+			t.Fatalf("应选择延迟较低的 %s，实际选择了 %s", endpoints[1], endpoint)
+		}
+	}
+}
+
+func TestConsistentHash(t *testing.T) {
+	lb := NewConsistentHash(DefaultVirtualNodes)
+	keys := []string{"doc1", "doc2", "doc3", "doc4", "doc5", "doc6", "doc7", "doc8"}
+	before := make(map[string]string)
+	for _, key := range keys {
+		before[key] = lb.TakeByKey(endpoints, key)
+		// 端点顺序不影响结果
+		if endpoint := lb.TakeByKey([]string{endpoints[2], endpoints[0], endpoints[1]}, key); endpoint != before[key] {
+			t.Fatalf("key %s 在端点顺序变化后从 %s 换到了 %s", key, before[key], endpoint)
+		}
+	}
+	// 下线一个端点后，原本不在该端点上的key不应移动
+	for _, key := range keys {
+		if before[key] == endpoints[2] {
+			continue
+		}
+		if endpoint := lb.TakeByKey(endpoints[:2], key); endpoint != before[key] {
+			t.Fatalf("key %s 在端点下线后从 %s 换到了 %s", key, before[key], endpoint)
+		}
+	}
+}
+
+func TestNewLoadBalancer(t *testing.T) {
+	for _, strategy := range []string{"", StrategyRoundRobin, StrategyRandom, StrategyWeightedRoundRobin, StrategyLeastOutstanding, StrategyP2C, StrategyConsistentHash} {
+		lb, err := NewLoadBalancer(strategy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if endpoint := lb.Take(endpoints); endpoint == "" {
+			t.Fatalf("策略 %s 未选出端点", strategy)
+		}
+	}
+	if _, err := NewLoadBalancer("unknown"); err == nil {
+		t.Fatal("不合法的策略名称应返回错误")
+	}
+}
@@ -0,0 +1,45 @@
+package load_balancer
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// P2C 负载均衡算法：Power of Two Choices
+// 随机选两个端点，取负载较小的一个。负载为 延迟的指数加权移动平均 × (在途请求数+1)，
+// 既能避开慢节点，又不会像总是选最优节点那样让所有请求同时涌向同一个端点。
+type P2C struct {
+	statsTable
+}
+
+// NewP2C 创建一个P2C负载均衡
+func NewP2C() *P2C {
+	return &P2C{}
+}
+
+// Take 选择一个Endpoint，根据P2C算法
+func (b *P2C) Take(endpoints []string) string {
+	switch len(endpoints) {
+	case 0:
+		return ""
+	case 1:
+		return endpoints[0]
+	}
+	i := rand.Intn(len(endpoints))
+	j := rand.Intn(len(endpoints) - 1)
+	if j >= i {
+		j++
+	}
+	if b.load(endpoints[j]) < b.load(endpoints[i]) {
+		return endpoints[j]
+	}
+	return endpoints[i]
+}
+
+// load 计算端点的负载。还没有延迟样本的端点负载为0，以便尽快获得样本。
+func (b *P2C) load(endpoint string) float64 {
+	s := b.get(endpoint)
+	ewma := atomic.LoadInt64(&s.ewma)
+	outstanding := atomic.LoadInt64(&s.outstanding)
+	return float64(ewma) * float64(outstanding+1)
+}
@@ -0,0 +1,69 @@
+package load_balancer
+
+import "sync"
+
+// WeightedRoundRobin 负载均衡算法：平滑加权轮询法
+// 每次选择时，每个端点的当前权重加上它的权重，选出当前权重最大的端点，再把它的当前权重减去总权重。
+// 权重为3:1时，选择序列为 a a b a，而不是 a a a b，避免短时间内集中把请求打到同一个端点。
+type WeightedRoundRobin struct {
+	mu      sync.Mutex
+	weights map[string]int // 端点的权重
+	current map[string]int // 端点的当前权重
+}
+
+// NewWeightedRoundRobin 创建一个平滑加权轮询负载均衡
+func NewWeightedRoundRobin() *WeightedRoundRobin {
+	return &WeightedRoundRobin{
+		weights: make(map[string]int),
+		current: make(map[string]int),
+	}
+}
+
+// SetWeight 设置端点的权重，weight<=0 时使用默认权重
+func (b *WeightedRoundRobin) SetWeight(endpoint string, weight int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if weight <= 0 {
+		delete(b.weights, endpoint)
+	} else {
+		b.weights[endpoint] = weight
+	}
+}
+
+// Take 选择一个Endpoint，根据平滑加权轮询算法
+func (b *WeightedRoundRobin) Take(endpoints []string) string {
+	if len(endpoints) == 0 {
+		return ""
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	total := 0
+	best := ""
+	for _, endpoint := range endpoints {
+		weight, exists := b.weights[endpoint]
+		if !exists {
+			weight = DefaultWeight
+		}
+		total += weight
+		b.current[endpoint] += weight
+		if best == "" || b.current[endpoint] > b.current[best] {
+			best = endpoint
+		}
+	}
+	b.current[best] -= total
+
+	// 已下线端点的当前权重不再有意义，避免map无限增长
+	if len(b.current) > len(endpoints) {
+		alive := make(map[string]struct{}, len(endpoints))
+		for _, endpoint := range endpoints {
+			alive[endpoint] = struct{}{}
+		}
+		for endpoint := range b.current {
+			if _, exists := alive[endpoint]; !exists {
+				delete(b.current, endpoint)
+			}
+		}
+	}
+	return best
+}
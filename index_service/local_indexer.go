@@ -1,14 +1,19 @@
 package index_service
 
 import (
-	"bytes"
-	"encoding/gob"
+	"errors"
 	"fmt"
+	"github.com/jmh000527/criker-search/index/doc_codec"
 	invertedIndex "github.com/jmh000527/criker-search/index/inverted_index"
 	kvDb "github.com/jmh000527/criker-search/index/kv_db"
 	"github.com/jmh000527/criker-search/types"
 	"github.com/jmh000527/criker-search/utils"
+	farmhash "github.com/leemcloughlin/gofarmhash"
+	"io"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"sync/atomic"
 )
 
@@ -22,23 +27,47 @@ import (
 //     这个索引用于实现关键词到文档ID的映射，支持高效的文档检索。
 //   - maxIntId: 当前最大文档ID，类型为 uint64。
 //     这个值用于跟踪已分配的最大文档ID，以便生成新的唯一ID。
+//   - docCount: 正排索引中的文档数量，打开数据库时统计一次，之后随写入和删除增量维护。
+//   - codec: 写入正排索引时文档的编码方式，默认为 protobuf。
+//   - docLocks: 文档锁，保证同一文档的并发写入按顺序进行，版本检查不会被其他写入打断。
+//   - expiry: 带过期时间的文档，由 StartReaper 启动的后台任务到期删除。
+//   - maintenance: 由 StartMaintenance 启动的后台任务定期整理正排索引，回收已删除文档占用的磁盘空间。
 type LocalIndexer struct {
 	forwardIndex kvDb.KeyValueDB               // 正排索引数据库实例
 	reverseIndex invertedIndex.InvertedIndexer // 倒排索引实例
 	maxIntId     uint64                        // 当前最大文档ID
+	docCount     int64                         // 文档数量
+
+	docNumEstimate   int      // 预估的文档数量，从备份恢复时用于重建倒排索引
+	completionFields []string // 需要自动补全的字段，重建倒排索引时同样设置
+
+	codec       doc_codec.DocumentCodec  // 写入正排索引时文档的编码方式，读取时根据数据中的格式标记解码
+	docLocks    [docLockCount]sync.Mutex // 文档锁，同一文档的读取旧文档、检查版本和写入需要互斥
+	expiry                               // 文档过期
+	maintenance                          // 正排索引整理
 }
 
+// docLockCount 文档锁的数量，文档按业务侧ID的哈希值分配到其中一把锁上
+const docLockCount = 256
+
+var (
+	// ErrVersionConflict 写入时文档的当前版本不满足版本条件
+	ErrVersionConflict = errors.New("版本冲突")
+	// ErrDocNotFound 更新的文档不存在
+	ErrDocNotFound = errors.New("文档不存在")
+)
+
 // Init 初始化索引器，包括正排索引和倒排索引。
 // 该方法会创建或打开数据库实例，并初始化倒排索引。
 //
 // 参数:
 //   - docNumEstimate: 预估的文档数量，用于初始化倒排索引的容量。
-//   - dbType: 数据库类型，用于选择和创建相应的数据库实例。
+//   - dbType: 数据库类型的名称（例如 kv_db.BOLT），用于选择和创建相应的数据库实例。
 //   - dataDir: 数据存储目录，指定数据库文件的位置。
 //
 // 返回值:
 //   - error: 如果在创建数据库或初始化索引时发生错误，则返回相应的错误。
-func (indexer *LocalIndexer) Init(docNumEstimate, dbType int, dataDir string) error {
+func (indexer *LocalIndexer) Init(docNumEstimate int, dbType string, dataDir string) error {
 	// 调用 GetKvDB 工厂方法创建或打开数据库实例
 	db, err := kvDb.GetKvDB(dbType, dataDir)
 	if err != nil {
@@ -47,9 +76,18 @@ func (indexer *LocalIndexer) Init(docNumEstimate, dbType int, dataDir string) er
 
 	// 设置正排索引数据库实例
 	indexer.forwardIndex = db
+	indexer.docNumEstimate = docNumEstimate
+
+	// 统计已有的文档数量，之后由写入和删除增量维护，Count 不再需要遍历数据库
+	n, err := db.IterKey(func(k []byte) error { return nil })
+	if err != nil {
+		db.Close()
+		return err
+	}
+	indexer.docCount = n
 
 	// 初始化倒排索引
-	indexer.reverseIndex = invertedIndex.NewSkipListInvertedIndexer(docNumEstimate)
+	indexer.reverseIndex = invertedIndex.NewSkipListInvertedIndexer(docNumEstimate).WithCompletion(indexer.completionFields...)
 
 	return nil
 }
@@ -60,11 +98,15 @@ func (indexer *LocalIndexer) Init(docNumEstimate, dbType int, dataDir string) er
 // 返回值:
 //   - error: 如果在关闭正排索引数据库时发生错误，则返回相应的错误。
 func (indexer *LocalIndexer) Close() error {
+	// 停止删除过期文档的后台任务
+	indexer.closeReaper()
+	// 停止整理正排索引的后台任务，正在进行的整理完成后才返回
+	indexer.closeMaintenance()
 	// 关闭正排索引数据库实例
 	return indexer.forwardIndex.Close()
 }
 
-// AddDoc 向索引中添加文档（如果文档已存在，会先删除再覆盖）。
+// AddDoc 向索引中添加文档（如果文档已存在，会覆盖旧文档），文档的版本号在旧版本号的基础上加1。
 //
 // 参数:
 //   - doc: 需要添加到索引中的文档，包含业务侧ID和其他相关信息。
@@ -73,6 +115,25 @@ func (indexer *LocalIndexer) Close() error {
 //   - int: 成功添加的文档数量，正常情况下应为 1。
 //   - error: 如果添加过程中发生错误，返回相应的错误。
 func (indexer *LocalIndexer) AddDoc(doc types.Document) (int, error) {
+	if _, err := indexer.AddDocWithOptions(doc, WriteOptions{}); err != nil {
+		return 0, err
+	}
+	// 返回成功添加的文档数量
+	return 1, nil
+}
+
+// AddDocWithOptions 带版本检查地向索引中添加文档。
+// 读取旧文档、检查版本和写入在同一把文档锁内、同一个正排索引事务（KeyValueDB.Update）中完成，
+// 同一文档的并发写入不会互相覆盖，版本冲突或写入失败时正排索引保持不变。
+//
+// 参数:
+//   - doc: 需要添加到索引中的文档。使用外部版本号时 doc.Version 是要写入的版本号。
+//   - opts: 版本检查的选项，见 WriteOptions。
+//
+// 返回值:
+//   - int64: 写入后文档的版本号。
+//   - error: 版本检查不通过时返回包装了 ErrVersionConflict 的错误，其他错误原样返回。
+func (indexer *LocalIndexer) AddDocWithOptions(doc types.Document, opts WriteOptions) (int64, error) {
 	// 获取并修剪文档的业务侧ID（docId）
 	docId := strings.TrimSpace(doc.Id)
 	// 如果文档ID为空，返回错误
@@ -80,31 +141,240 @@ func (indexer *LocalIndexer) AddDoc(doc types.Document) (int, error) {
 		return 0, fmt.Errorf("业务侧ID不能为空")
 	}
 
-	// 将文档ID从正排索引和倒排索引中删除（如果已存在）
-	indexer.DeleteDoc(docId)
+	unlock := indexer.lockDocs(docId)
+	defer unlock()
 
-	// 为新文档自动生成一个唯一的IntId
-	doc.IntId = atomic.AddUint64(&indexer.maxIntId, 1)
+	// 在一个事务中读取旧文档、检查版本并写入新文档，版本冲突或写入失败时正排索引保持不变
+	var old *types.Document
+	err := indexer.forwardIndex.Update(func(tx kvDb.KVTxn) error {
+		var err error
+		if old, err = readDoc(tx, docId); err != nil {
+			return err
+		}
+		if doc.Version, err = nextVersion(old, doc.Version, opts); err != nil {
+			return err
+		}
 
-	// 将文档写入正排索引
-	var value bytes.Buffer
-	encoder := gob.NewEncoder(&value)
-	// 对文档进行编码并存储到正排索引中
-	if err := encoder.Encode(doc); err == nil {
-		err := indexer.forwardIndex.Set([]byte(docId), value.Bytes())
+		// 为新文档自动生成一个唯一的IntId
+		doc.IntId = atomic.AddUint64(&indexer.maxIntId, 1)
+
+		// 将文档写入正排索引，旧记录被直接覆盖
+		value, err := indexer.encodeDoc(&doc)
 		if err != nil {
-			return 0, err
+			// 如果编码失败，返回错误
+			return err
 		}
-	} else {
-		// 如果编码失败，返回错误
+		return indexer.setDocIn(tx, []byte(docId), value, doc.ExpireAt)
+	})
+	if err != nil {
 		return 0, err
 	}
 
-	// 将文档添加到倒排索引
+	// 正排索引写入成功后，再用新文档替换倒排索引中的旧文档
+	if old != nil {
+		for _, keyword := range old.Keywords {
+			indexer.reverseIndex.Delete(keyword, old.IntId)
+		}
+	} else {
+		atomic.AddInt64(&indexer.docCount, 1)
+	}
 	indexer.reverseIndex.Add(doc)
+	indexer.trackExpiry(doc)
+	return doc.Version, nil
+}
 
-	// 返回成功添加的文档数量
-	return 1, nil
+// BatchAddDoc 批量向索引中添加文档（已存在的文档会被覆盖），每个文档的版本号在旧版本号的基础上加1。
+// 与逐个调用 AddDoc 不同，整批文档只读取一次旧文档、只写一次正排索引（KeyValueDB.BatchSet），
+// 倒排索引也按 key 分组批量更新，适合大批量导入数据。
+// 同一批次中出现重复的业务侧ID时，以最后一次出现的文档为准。
+// 写入成功的文档的 Version 字段会被设置为写入后的版本号。
+//
+// 参数:
+//   - docs: 需要添加到索引中的文档列表。
+//
+// 返回值:
+//   - int: 成功添加的文档数量。
+//   - []error: 与 docs 一一对应的错误列表，nil 表示该文档写入成功。
+func (indexer *LocalIndexer) BatchAddDoc(docs []types.Document) (int, []error) {
+	errs := make([]error, len(docs))
+	// 业务侧ID -> 该ID在本批次中最后一次出现的位置
+	latest := make(map[string]int, len(docs))
+	for i := range docs {
+		docId := strings.TrimSpace(docs[i].Id)
+		if len(docId) == 0 {
+			errs[i] = fmt.Errorf("业务侧ID不能为空")
+			continue
+		}
+		latest[docId] = i
+	}
+	if len(latest) == 0 {
+		return 0, errs
+	}
+
+	keys := make([][]byte, 0, len(latest))
+	docIds := make([]string, 0, len(latest))
+	for docId := range latest {
+		keys = append(keys, []byte(docId))
+		docIds = append(docIds, docId)
+	}
+	unlock := indexer.lockDocs(docIds...)
+	defer unlock()
+
+	// 读取已存在的旧文档，用于计算版本号和从倒排索引中删除旧文档
+	// 读取失败时无法从倒排索引中删除被覆盖的旧文档，整批文档都视为写入失败
+	oldDocs, err := indexer.forwardIndex.BatchGet(keys)
+	if err != nil {
+		utils.Log.Printf("批量读取旧文档失败: %v", err)
+		for i := range docs {
+			if errs[i] == nil {
+				errs[i] = err
+			}
+		}
+		return 0, errs
+	}
+	olds := make(map[string]*types.Document, len(oldDocs)) // 业务侧ID -> 旧文档
+	for _, docBytes := range oldDocs {
+		if len(docBytes) == 0 {
+			continue
+		}
+		old := new(types.Document)
+		if err := doc_codec.Decode(docBytes, old); err != nil {
+			utils.Log.Printf("解码旧文档失败: %v", err)
+			continue
+		}
+		olds[strings.TrimSpace(old.Id)] = old
+	}
+
+	// 为新文档生成IntId和版本号并编码
+	written := make([]types.Document, 0, len(keys))
+	positions := make([]int, 0, len(keys))
+	values := make([][]byte, 0, len(keys))
+	writeKeys := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		i := latest[string(key)]
+		doc := docs[i]
+		doc.IntId = atomic.AddUint64(&indexer.maxIntId, 1)
+		doc.Version, _ = nextVersion(olds[string(key)], doc.Version, WriteOptions{})
+		value, err := indexer.encodeDoc(&doc)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		written = append(written, doc)
+		positions = append(positions, i)
+		writeKeys = append(writeKeys, key)
+		values = append(values, value)
+	}
+
+	// 整批写入正排索引，失败时整批文档都视为写入失败
+	expireAts := make([]int64, len(written))
+	for j := range written {
+		expireAts[j] = written[j].ExpireAt
+	}
+	if err := indexer.batchSetDocs(writeKeys, values, expireAts); err != nil {
+		for _, i := range positions {
+			errs[i] = err
+		}
+		return 0, errs
+	}
+
+	// 正排索引写入成功后，从倒排索引中删除被覆盖的旧文档，再批量写入新文档
+	for j, key := range writeKeys {
+		if old, exists := olds[string(key)]; exists {
+			for _, keyword := range old.Keywords {
+				indexer.reverseIndex.Delete(keyword, old.IntId)
+			}
+		} else {
+			atomic.AddInt64(&indexer.docCount, 1)
+		}
+		docs[positions[j]].Version = written[j].Version
+	}
+	indexer.reverseIndex.BatchAdd(written)
+	indexer.trackExpiry(written...)
+
+	// 被同批次后续文档覆盖的文档，其写入结果与最终生效的文档一致
+	n := 0
+	for i := range docs {
+		if j, exists := latest[strings.TrimSpace(docs[i].Id)]; exists && j != i {
+			errs[i] = errs[j]
+			docs[i].Version = docs[j].Version
+		}
+		if errs[i] == nil {
+			n++
+		}
+	}
+	return n, errs
+}
+
+// UpdateDoc 局部更新文档：增删关键词、设置或清除位特征、替换 Bytes，不需要业务侧重新发送整个文档。
+// 文档的 IntId 保持不变，倒排索引中只有受影响的倒排链会被修改。
+//
+// 参数:
+//   - patch: 要对文档做的修改，先删除 RemoveKeywords 再添加 AddKeywords，先清除 ClearBits 再设置 SetBits。
+//
+// 返回值:
+//   - int64: 更新后文档的版本号。
+//   - error: 文档不存在时返回包装了 ErrDocNotFound 的错误，版本检查不通过时返回包装了 ErrVersionConflict 的错误。
+func (indexer *LocalIndexer) UpdateDoc(patch *DocPatch) (int64, error) {
+	docId := strings.TrimSpace(patch.DocId)
+	if len(docId) == 0 {
+		return 0, fmt.Errorf("业务侧ID不能为空")
+	}
+
+	unlock := indexer.lockDocs(docId)
+	defer unlock()
+
+	old, err := indexer.getDoc(docId)
+	if err != nil {
+		return 0, err
+	}
+	if old == nil {
+		return 0, fmt.Errorf("%w: %s", ErrDocNotFound, docId)
+	}
+	version, err := nextVersion(old, 0, WriteOptions{IfVersion: patch.IfVersion})
+	if err != nil {
+		return 0, err
+	}
+
+	// 在旧文档的基础上应用修改
+	doc := *old
+	doc.Version = version
+	doc.BitsFeature = old.BitsFeature&^patch.ClearBits | patch.SetBits
+	if patch.ReplaceBytes {
+		doc.Bytes = patch.Bytes
+	}
+	removed := make(map[string]struct{}, len(patch.RemoveKeywords))
+	for _, keyword := range patch.RemoveKeywords {
+		removed[keyword.ToString()] = struct{}{}
+	}
+	doc.Keywords = make([]*types.Keyword, 0, len(old.Keywords)+len(patch.AddKeywords))
+	existing := make(map[string]struct{}, len(old.Keywords)+len(patch.AddKeywords))
+	for _, keyword := range old.Keywords {
+		key := keyword.ToString()
+		if _, exists := removed[key]; exists {
+			continue
+		}
+		existing[key] = struct{}{}
+		doc.Keywords = append(doc.Keywords, keyword)
+	}
+	for _, keyword := range patch.AddKeywords {
+		key := keyword.ToString()
+		if _, exists := existing[key]; exists {
+			continue
+		}
+		existing[key] = struct{}{}
+		doc.Keywords = append(doc.Keywords, keyword)
+	}
+
+	value, err := indexer.encodeDoc(&doc)
+	if err != nil {
+		return 0, err
+	}
+	if err := indexer.setDoc([]byte(docId), value, doc.ExpireAt); err != nil {
+		return 0, err
+	}
+	indexer.reverseIndex.Update(*old, doc)
+	return version, nil
 }
 
 // DeleteDoc 从索引中删除文档，接受业务侧文档ID（docId）作为参数。
@@ -121,65 +391,262 @@ func (indexer *LocalIndexer) DeleteDoc(docId string) int {
 		return 0
 	}
 
-	forwardKey := []byte(docId)
+	unlock := indexer.lockDocs(docId)
+	defer unlock()
 
-	// 从正排索引中读取文档的bytes数据
-	docBytes, err := indexer.forwardIndex.Get(forwardKey)
+	// 从正排索引中读取文档
+	doc, err := indexer.getDoc(docId)
 	if err != nil {
-		// 如果发生读取错误，记录日志并返回0
+		// 如果发生读取或解码错误，记录日志并返回0
 		utils.Log.Printf("读取文档失败: %s, 错误: %v\n", docId, err)
 		return 0
 	}
-
 	// 如果正排索引中不存在该文档，直接返回0
-	if len(docBytes) == 0 {
+	if doc == nil {
 		utils.Log.Printf("文档不存在于索引中: %s\n", docId)
 		return 0
 	}
 
-	// 将bytes数据解码成文档结构
-	reader := bytes.NewReader(docBytes)
-	var doc types.Document
-	if err := gob.NewDecoder(reader).Decode(&doc); err != nil {
-		// 解码失败时记录日志
-		utils.Log.Printf("解码文档失败: %s, 错误: %v\n", docId, err)
-		return 0
-	}
-
 	// 遍历文档中的每一个Keyword，从倒排索引中删除
 	for _, keyword := range doc.Keywords {
 		indexer.reverseIndex.Delete(keyword, doc.IntId)
 	}
 
 	// 从正排索引中删除文档的正排记录
-	if err := indexer.forwardIndex.Delete(forwardKey); err != nil {
+	if err := indexer.forwardIndex.Delete([]byte(docId)); err != nil {
 		// 删除失败时记录日志
 		utils.Log.Printf("删除文档失败: %s, 错误: %v\n", docId, err)
 		return 0
 	}
+	atomic.AddInt64(&indexer.docCount, -1)
 
 	// 返回成功删除的文档数量
 	return 1
 }
 
+// BatchDelete 批量删除文档，整批文档只读取和删除一次正排索引。不存在的文档会被忽略。
+//
+// 参数:
+//   - docIds: 要删除的业务侧文档ID列表。
+//
+// 返回值:
+//   - *DeleteResult: 实际删除的文档数量。
+//   - error: 读取或删除正排索引失败时返回错误。
+func (indexer *LocalIndexer) BatchDelete(docIds []string) (*DeleteResult, error) {
+	deleted, err := indexer.batchDeleteWhere(docIds, nil)
+	return &DeleteResult{Count: len(deleted)}, err
+}
+
+// DeleteByQuery 删除所有符合检索条件的文档。
+// 先从倒排索引中检索出命中的文档再批量删除，检索之后、删除之前新写入的文档不会被删除；
+// 检索之后被修改为不再符合条件的文档也不会被删除。
+//
+// 参数:
+//   - query: 检索条件。
+//   - onFlag: 需要匹配的位特征。
+//   - offFlag: 需要排除的位特征。
+//   - orFlags: 需要至少命中一个bit的位特征集合。
+//
+// 返回值:
+//   - *DeleteResult: 实际删除的文档数量。
+//   - error: 读取或删除正排索引失败时返回错误。
+func (indexer *LocalIndexer) DeleteByQuery(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) (*DeleteResult, error) {
+	deleted, err := indexer.deleteByQuery(query, onFlag, offFlag, orFlags)
+	return &DeleteResult{Count: len(deleted)}, err
+}
+
+// deleteByQuery 删除所有符合检索条件的文档，返回实际删除的文档ID
+func (indexer *LocalIndexer) deleteByQuery(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) ([]string, error) {
+	if query == nil {
+		return nil, fmt.Errorf("检索条件不能为空")
+	}
+	// 检索和加锁之间文档可能被并发修改，在文档锁内按最新的内容重新检查一遍检索条件
+	return indexer.batchDeleteWhere(indexer.reverseIndex.Search(query, onFlag, offFlag, orFlags), func(doc *types.Document) bool {
+		return invertedIndex.FilterByBits(doc.BitsFeature, onFlag, offFlag, orFlags) && query.Matches(doc.Keywords)
+	})
+}
+
+// batchDeleteWhere 批量删除文档，返回实际删除的文档ID。cond 不为 nil 时只删除 cond 返回 true 的文档，
+// 判断在文档锁内进行，不会与同一文档的并发写入交错。
+func (indexer *LocalIndexer) batchDeleteWhere(docIds []string, cond func(doc *types.Document) bool) ([]string, error) {
+	keys := make([][]byte, 0, len(docIds))
+	unique := make([]string, 0, len(docIds))
+	seen := make(map[string]struct{}, len(docIds))
+	for _, docId := range docIds {
+		docId = strings.TrimSpace(docId)
+		if _, exists := seen[docId]; exists || len(docId) == 0 {
+			continue
+		}
+		seen[docId] = struct{}{}
+		unique = append(unique, docId)
+		keys = append(keys, []byte(docId))
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	unlock := indexer.lockDocs(unique...)
+	defer unlock()
+
+	// 读取要删除的文档，只有存在的文档需要从倒排索引中删除
+	values, err := indexer.forwardIndex.BatchGet(keys)
+	if err != nil {
+		return nil, err
+	}
+	docs := make([]*types.Document, 0, len(values))
+	deleteKeys := make([][]byte, 0, len(values))
+	for _, docBytes := range values {
+		if len(docBytes) == 0 {
+			continue
+		}
+		doc := new(types.Document)
+		if err := doc_codec.Decode(docBytes, doc); err != nil {
+			utils.Log.Printf("解码文档失败: %v", err)
+			continue
+		}
+		if cond != nil && !cond(doc) {
+			continue
+		}
+		docs = append(docs, doc)
+		deleteKeys = append(deleteKeys, []byte(doc.Id))
+	}
+
+	// 先从倒排索引中删除，再整批删除正排记录
+	for _, doc := range docs {
+		for _, keyword := range doc.Keywords {
+			indexer.reverseIndex.Delete(keyword, doc.IntId)
+		}
+	}
+	if err := indexer.forwardIndex.BatchDelete(deleteKeys); err != nil {
+		return nil, err
+	}
+	atomic.AddInt64(&indexer.docCount, -int64(len(docs)))
+
+	deleted := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		deleted = append(deleted, doc.Id)
+	}
+	return deleted, nil
+}
+
+// WithCodec 设置写入正排索引时文档的编码方式，需要在 Init 之前调用，不设置时使用 doc_codec.Default。
+// 已有的数据不需要迁移即可读取，可以用 admin migrate 把已有数据改写为新的编码方式。
+func (indexer *LocalIndexer) WithCodec(codec doc_codec.DocumentCodec) *LocalIndexer {
+	indexer.codec = codec
+	return indexer
+}
+
+// WithCompletion 设置需要自动补全的字段，需要在 Init 之前调用。倒排索引为这些字段维护带文档数量的前缀树，
+// 会额外占用与这些字段的词典大小相当的内存。
+func (indexer *LocalIndexer) WithCompletion(fields ...string) *LocalIndexer {
+	indexer.completionFields = fields
+	return indexer
+}
+
+// encodeDoc 用配置的编码方式编码文档
+func (indexer *LocalIndexer) encodeDoc(doc *types.Document) ([]byte, error) {
+	codec := indexer.codec
+	if codec == nil {
+		codec = doc_codec.Default
+	}
+	return doc_codec.Encode(codec, doc)
+}
+
+// getDoc 从正排索引中读取并解码文档，文档不存在时返回 nil
+func (indexer *LocalIndexer) getDoc(docId string) (*types.Document, error) {
+	return readDoc(indexer.forwardIndex, docId)
+}
+
+// docGetter 正排索引（kvDb.KeyValueDB）和正排索引的事务（kvDb.KVTxn）共有的读取方法
+type docGetter interface {
+	Get(k []byte) ([]byte, error)
+}
+
+// readDoc 从正排索引或正排索引的事务中读取并解码文档，文档不存在时返回 nil
+func readDoc(reader docGetter, docId string) (*types.Document, error) {
+	docBytes, err := reader.Get([]byte(docId))
+	if errors.Is(err, kvDb.NoDataError) || (err == nil && len(docBytes) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc := new(types.Document)
+	if err := doc_codec.Decode(docBytes, doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// lockDocs 锁住给定文档对应的文档锁，返回解锁函数。
+// 多个文档按锁的下标从小到大加锁，避免批量写入之间互相等待造成死锁。
+func (indexer *LocalIndexer) lockDocs(docIds ...string) func() {
+	indexes := make([]int, 0, len(docIds))
+	seen := make(map[int]struct{}, len(docIds))
+	for _, docId := range docIds {
+		i := int(farmhash.Hash32WithSeed([]byte(docId), 0) % docLockCount)
+		if _, exists := seen[i]; !exists {
+			seen[i] = struct{}{}
+			indexes = append(indexes, i)
+		}
+	}
+	sort.Ints(indexes)
+	for _, i := range indexes {
+		indexer.docLocks[i].Lock()
+	}
+	return func() {
+		for _, i := range indexes {
+			indexer.docLocks[i].Unlock()
+		}
+	}
+}
+
+// nextVersion 检查写入是否满足版本条件，并计算写入后的版本号。
+//
+// 参数:
+//   - old: 旧文档，不存在时为 nil。没有版本号的旧文档（版本号功能上线之前写入的）视为版本 0。
+//   - version: 新文档携带的版本号，只在使用外部版本号时有意义。
+//   - opts: 版本检查的选项。
+//
+// 返回值:
+//   - int64: 写入后的版本号。
+//   - error: 版本检查不通过时返回包装了 ErrVersionConflict 的错误。
+func nextVersion(old *types.Document, version int64, opts WriteOptions) (int64, error) {
+	var current int64
+	if old != nil {
+		current = old.Version
+	}
+	switch {
+	case opts.IfVersion > 0 && old == nil:
+		return 0, fmt.Errorf("%w: 文档不存在，期望的版本为 %d", ErrVersionConflict, opts.IfVersion)
+	case opts.IfVersion > 0 && current != opts.IfVersion:
+		return 0, fmt.Errorf("%w: 当前版本为 %d，期望的版本为 %d", ErrVersionConflict, current, opts.IfVersion)
+	case opts.IfVersion < 0 && old != nil:
+		return 0, fmt.Errorf("%w: 文档已存在，当前版本为 %d", ErrVersionConflict, current)
+	}
+	if !opts.ExternalVersion {
+		return current + 1, nil
+	}
+	if version <= 0 {
+		return 0, fmt.Errorf("使用外部版本号时版本号必须大于0，实际为 %d", version)
+	}
+	if old != nil && version <= current {
+		return 0, fmt.Errorf("%w: 当前版本为 %d，写入的版本 %d 不是更新的版本", ErrVersionConflict, current, version)
+	}
+	return version, nil
+}
+
 // LoadFromIndexFile 系统重启时，直接从索引文件里加载数据
 //
 // 返回值:
 //   - int: 成功加载的文档数量
 func (indexer *LocalIndexer) LoadFromIndexFile() int {
-	// 创建一个bytes读取器
-	reader := bytes.NewReader([]byte{})
-
 	// 遍历正排索引数据库中的所有记录
 	n, err := indexer.forwardIndex.IterDB(func(k, v []byte) error {
-		// 重置读取器的内容
-		reader.Reset(v)
-		// 创建解码器
-		decoder := gob.NewDecoder(reader)
 		var doc types.Document
 
-		// 解码bytes数据为文档结构
-		err := decoder.Decode(&doc)
+		// 解码bytes数据为文档结构，新旧编码格式的数据都可以解码
+		err := doc_codec.Decode(v, &doc)
 		if err != nil {
 			// 解码失败，记录错误日志（中文输出）
 			utils.Log.Printf("解码文档出错: %v", err)
@@ -188,6 +655,9 @@ func (indexer *LocalIndexer) LoadFromIndexFile() int {
 
 		// 将文档添加到倒排索引中
 		indexer.reverseIndex.Add(doc)
+		indexer.trackExpiry(doc)
+		// 之后新写入的文档从已有的最大IntId继续编号，否则会与已加载的文档冲突，在倒排链中互相覆盖
+		indexer.raiseMaxIntId(doc.IntId)
 		return err
 	})
 
@@ -201,6 +671,58 @@ func (indexer *LocalIndexer) LoadFromIndexFile() int {
 	return int(n)
 }
 
+// raiseMaxIntId 把 maxIntId 提高到 intId，maxIntId 已经不小于 intId 时不变
+func (indexer *LocalIndexer) raiseMaxIntId(intId uint64) {
+	for {
+		current := atomic.LoadUint64(&indexer.maxIntId)
+		if current >= intId || atomic.CompareAndSwapUint64(&indexer.maxIntId, current, intId) {
+			return
+		}
+	}
+}
+
+// Backup 在线备份正排索引，备份期间可以继续读写。倒排索引可以由正排索引重建，不需要备份。
+//
+// 参数:
+//   - w: 备份写入的位置。
+//
+// 返回值:
+//   - error: 备份失败时返回错误。
+func (indexer *LocalIndexer) Backup(w io.Writer) error {
+	return indexer.forwardIndex.Backup(w)
+}
+
+// Restore 用 Backup 产生的备份替换正排索引，并由正排索引重建倒排索引。调用方需保证恢复期间没有其他读写。
+// 删除过期文档和整理正排索引的后台任务同样会读写索引，恢复期间暂停，恢复结束后按原来的配置重新启动。
+//
+// 参数:
+//   - r: 备份的内容，必须由相同类型的 KeyValueDB 产生。
+//
+// 返回值:
+//   - int: 恢复的文档数量。
+//   - error: 恢复失败时返回错误。
+func (indexer *LocalIndexer) Restore(r io.Reader) (int, error) {
+	reaperInterval, maintenanceOpts := indexer.closeReaper(), indexer.closeMaintenance()
+	defer func() {
+		indexer.StartReaper(reaperInterval)
+		indexer.StartMaintenance(maintenanceOpts)
+	}()
+
+	if err := indexer.forwardIndex.Restore(r); err != nil {
+		return 0, err
+	}
+	// 旧的倒排索引已经与正排索引不一致，直接丢弃后重建
+	indexer.reverseIndex = invertedIndex.NewSkipListInvertedIndexer(indexer.docNumEstimate).WithCompletion(indexer.completionFields...)
+	indexer.resetExpiry()
+	n, err := indexer.forwardIndex.IterKey(func(k []byte) error { return nil })
+	if err != nil {
+		return 0, err
+	}
+	atomic.StoreInt64(&indexer.docCount, n)
+	// 重建倒排索引的同时把 maxIntId 提高到备份中最大的 IntId，之后写入的文档不会与恢复的文档冲突
+	return indexer.LoadFromIndexFile(), nil
+}
+
 // Search 检索，返回文档列表
 //
 // 参数:
@@ -212,56 +734,122 @@ func (indexer *LocalIndexer) LoadFromIndexFile() int {
 // 返回值:
 //   - []*types.Document: 符合查询条件的文档列表。
 func (indexer *LocalIndexer) Search(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) []*types.Document {
+	var result []*types.Document
+	// 不分片，一次性从正排索引中读出全部文档
+	err := indexer.SearchInChunks(query, onFlag, offFlag, orFlags, 0, 0, func(docs []*types.Document) error {
+		result = docs
+		return nil
+	})
+	if err != nil {
+		return nil
+	}
+	return result
+}
+
+// SearchInChunks 分片检索，每从正排索引中解码出 chunkSize 个文档就回调一次 fn，
+// 避免一次性把所有命中文档都读进内存。fn 返回错误时立即停止检索并返回该错误。
+//
+// 参数:
+//   - query: *types.TermQuery，表示要检索的查询条件。
+//   - onFlag: uint64，表示需要匹配的位特征。
+//   - offFlag: uint64，表示需要排除的位特征。
+//   - orFlags: []uint64，表示需要至少命中一个bit的位特征集合。
+//   - chunkSize: 每个分片包含的文档数，<=0 时不分片。
+//   - limit: 最多返回的文档数，<=0 表示不限制。
+//   - fn: 处理每个分片的回调函数。
+//
+// 返回值:
+//   - error: 读取正排索引失败或 fn 返回错误时，返回相应的错误。
+func (indexer *LocalIndexer) SearchInChunks(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64, chunkSize, limit int, fn func(docs []*types.Document) error) error {
 	// 从倒排索引中获取符合条件的业务侧ID集合
 	docIds := indexer.reverseIndex.Search(query, onFlag, offFlag, orFlags)
 	if len(docIds) == 0 {
 		return nil
 	}
-
-	// 构建正排索引的关键字集合，用于批量获取文档
-	keys := make([][]byte, 0, len(docIds))
-	for _, docId := range docIds {
-		keys = append(keys, []byte(docId))
+	if limit > 0 && len(docIds) > limit {
+		docIds = docIds[:limit]
 	}
-
-	// 批量获取文档的二进制数据
-	docBytes, err := indexer.forwardIndex.BatchGet(keys)
-	if err != nil {
-		// 批量获取正排索引中的文档失败，记录错误日志（中文输出）
-		utils.Log.Printf("从正排索引批量获取文档出错: %v", err)
-		return nil
+	if chunkSize <= 0 {
+		chunkSize = len(docIds)
 	}
 
-	// 解码每个文档的二进制数据，构造返回结果
-	result := make([]*types.Document, 0, len(docIds))
-	reader := bytes.NewReader([]byte{}) // 用于读取二进制数据的字节读取器
-	for _, docByte := range docBytes {
-		reader.Reset(docByte)             // 重置读取器
-		decoder := gob.NewDecoder(reader) // 创建Gob解码器
-		var doc types.Document
-		err = decoder.Decode(&doc) // 解码文档
-		if err == nil {
-			result = append(result, &doc) // 将解码后的文档添加到结果集中
+	for begin := 0; begin < len(docIds); begin += chunkSize {
+		end := begin + chunkSize
+		if end > len(docIds) {
+			end = len(docIds)
+		}
+
+		// 构建正排索引的关键字集合，用于批量获取文档
+		keys := make([][]byte, 0, end-begin)
+		for _, docId := range docIds[begin:end] {
+			keys = append(keys, []byte(docId))
+		}
+
+		// 批量获取文档的二进制数据
+		docBytes, err := indexer.forwardIndex.BatchGet(keys)
+		if err != nil {
+			// 批量获取正排索引中的文档失败，记录错误日志（中文输出）
+			utils.Log.Printf("从正排索引批量获取文档出错: %v", err)
+			return err
+		}
+
+		// 解码每个文档的二进制数据，构造当前分片
+		docs := make([]*types.Document, 0, len(docBytes))
+		for _, docByte := range docBytes {
+			var doc types.Document
+			if err := doc_codec.Decode(docByte, &doc); err == nil {
+				docs = append(docs, &doc) // 将解码后的文档添加到当前分片中
+			}
+		}
+		if len(docs) == 0 {
+			continue
+		}
+		if err := fn(docs); err != nil {
+			return err
 		}
 	}
-	return result
+	return nil
 }
 
-// Count 索引里有几个document
+// Count 索引里有几个document。文档数量由写入和删除增量维护，不需要遍历正排索引。
 //
 // 返回值:
 //   - int: 索引中文档的数量。
 func (indexer *LocalIndexer) Count() int {
-	// 通过遍历正排索引中的键来统计文档数量
-	n, err := indexer.forwardIndex.IterKey(func(k []byte) error {
-		// 遍历时不需要具体操作，只需要返回 nil 表示继续
-		return nil
-	})
+	return int(atomic.LoadInt64(&indexer.docCount))
+}
+
+// Stats 返回索引的统计信息：文档数量、keyword数量、倒排链长度分布、正排索引占用的磁盘空间、进程的内存使用量、正排索引的整理情况和字段目录。
+//
+// 返回值:
+//   - *IndexStats: 索引的统计信息。
+//   - error: 统计正排索引的磁盘空间失败时返回错误。
+func (indexer *LocalIndexer) Stats() (*IndexStats, error) {
+	postingStats := indexer.reverseIndex.Stats()
+	diskBytes, err := kvDb.DiskUsage(indexer.forwardIndex.GetDbPath())
 	if err != nil {
-		// 如果遍历过程中出现错误，记录错误日志（中文输出）
-		utils.Log.Printf("遍历键时出错: %v", err)
-		return 0
+		return nil, err
 	}
-	// 返回文档的数量
-	return int(n)
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	maintenanceStats := indexer.MaintenanceStats()
+	fields := indexer.reverseIndex.Fields()
+	fieldTerms := make([]*FieldTerms, 0, len(fields))
+	for _, field := range fields {
+		fieldTerms = append(fieldTerms, &FieldTerms{Field: field.Field, TermCount: int64(field.TermCount)})
+	}
+	return &IndexStats{
+		DocCount:             atomic.LoadInt64(&indexer.docCount),
+		KeywordCount:         int64(postingStats.KeywordCount),
+		PostingListHistogram: postingStats.Histogram,
+		MaxPostingListLength: int64(postingStats.MaxLength),
+		ForwardIndexBytes:    diskBytes,
+		MemoryBytes:          memStats.HeapAlloc,
+		Workers:              1,
+		CompactRuns:          maintenanceStats.Runs,
+		CompactErrors:        maintenanceStats.Errors,
+		ReclaimedBytes:       maintenanceStats.ReclaimedBytes,
+		LastCompactAt:        maintenanceStats.LastRunAt,
+		Fields:               fieldTerms,
+	}, nil
 }
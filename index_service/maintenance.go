@@ -0,0 +1,177 @@
+package index_service
+
+import (
+	"context"
+	"errors"
+	kvDb "github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/utils"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCompactThreshold 可回收空间占正排索引的比例达到该值时才整理
+const DefaultCompactThreshold = 0.5
+
+// ErrCompactUnsupported 正排索引使用的数据库不支持整理（没有实现 kvDb.Compactor）
+var ErrCompactUnsupported = errors.New("正排索引不支持整理")
+
+// MaintenanceOptions 后台整理正排索引的选项。整理回收已删除、已覆盖的文档占用的磁盘空间：
+// Badger 对 value log 做垃圾回收，Bolt 把数据拷贝到新文件中（拷贝期间读写都要等待）。
+type MaintenanceOptions struct {
+	Interval  time.Duration // 检查的间隔，<=0 表示不启动后台整理
+	Threshold float64       // 可回收空间的比例达到该值才整理，<=0 时使用 DefaultCompactThreshold
+	// 只在每天本地时间 [QuietStartHour, QuietEndHour) 点之间整理，可以跨零点（例如 22 到 6）。两者相等表示不限制
+	QuietStartHour int
+	QuietEndHour   int
+}
+
+// inQuietHours 判断 now 是否在允许整理的时段内
+func (opts MaintenanceOptions) inQuietHours(now time.Time) bool {
+	start, end, hour := opts.QuietStartHour, opts.QuietEndHour, now.Hour()
+	if start == end {
+		return true
+	}
+	if start < end {
+		return hour >= start && hour < end
+	}
+	return hour >= start || hour < end
+}
+
+// MaintenanceStats 整理正排索引的统计信息
+type MaintenanceStats struct {
+	Runs           int64 // 执行了整理的次数（可回收空间没有达到阈值的检查不计入）
+	Errors         int64 // 整理失败的次数
+	ReclaimedBytes int64 // 累计回收的磁盘空间
+	LastRunAt      int64 // 最近一次执行整理的时间(Unix时间戳，单位秒)
+}
+
+// maintenance LocalIndexer 后台整理正排索引的状态
+type maintenance struct {
+	compactMu        sync.Mutex // 串行化整理，后台任务和手动触发不会同时整理
+	compactRuns      int64
+	compactErrors    int64
+	reclaimedBytes   int64
+	lastCompactAt    int64
+	stopMaintenance  chan struct{}      // 关闭后后台任务退出，未启动时为 nil
+	maintenanceDone  chan struct{}      // 后台任务退出后关闭
+	maintenanceMutex sync.Mutex         // 保护 stopMaintenance 和 maintenanceDone
+	maintenanceOpts  MaintenanceOptions // 后台任务的选项，暂停后按原来的选项重新启动
+}
+
+// StartMaintenance 启动后台任务，每隔 opts.Interval 检查一次，在允许的时段内且可回收空间达到阈值时整理正排索引。
+// 重复调用不会启动多个任务，正排索引不支持整理时不启动。
+//
+// 参数:
+//   - opts: 整理的选项，见 MaintenanceOptions。
+func (indexer *LocalIndexer) StartMaintenance(opts MaintenanceOptions) {
+	if _, ok := indexer.forwardIndex.(kvDb.Compactor); !ok || opts.Interval <= 0 {
+		return
+	}
+	indexer.maintenanceMutex.Lock()
+	defer indexer.maintenanceMutex.Unlock()
+	if indexer.stopMaintenance != nil {
+		return
+	}
+	indexer.stopMaintenance = make(chan struct{})
+	indexer.maintenanceDone = make(chan struct{})
+	indexer.maintenanceOpts = opts
+	go func(stop <-chan struct{}, done chan<- struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if opts.inQuietHours(now) {
+					indexer.Compact(opts.Threshold)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}(indexer.stopMaintenance, indexer.maintenanceDone)
+}
+
+// Compact 立即整理正排索引，不受 MaintenanceOptions 中允许整理的时段限制。
+//
+// 参数:
+//   - threshold: 可回收空间的比例达到该值才整理，<=0 时使用 DefaultCompactThreshold。
+//
+// 返回值:
+//   - kvDb.CompactResult: 整理前后正排索引占用的磁盘空间，以及是否执行了整理。
+//   - error: 正排索引不支持整理时返回 ErrCompactUnsupported，整理失败时返回相应的错误。
+func (indexer *LocalIndexer) Compact(threshold float64) (kvDb.CompactResult, error) {
+	compactor, ok := indexer.forwardIndex.(kvDb.Compactor)
+	if !ok {
+		return kvDb.CompactResult{}, ErrCompactUnsupported
+	}
+	if threshold <= 0 {
+		threshold = DefaultCompactThreshold
+	}
+	indexer.compactMu.Lock()
+	defer indexer.compactMu.Unlock()
+
+	begin := time.Now()
+	result, err := compactor.Compact(threshold)
+	if err != nil {
+		atomic.AddInt64(&indexer.compactErrors, 1)
+		utils.Log.Printf("整理正排索引失败: %v", err)
+		return result, err
+	}
+	if result.Compacted {
+		atomic.AddInt64(&indexer.compactRuns, 1)
+		atomic.StoreInt64(&indexer.lastCompactAt, begin.Unix())
+		if reclaimed := result.BeforeBytes - result.AfterBytes; reclaimed > 0 {
+			atomic.AddInt64(&indexer.reclaimedBytes, reclaimed)
+		}
+		utils.Log.Printf("整理正排索引用时 %v，占用空间从 %d 字节变为 %d 字节", time.Since(begin), result.BeforeBytes, result.AfterBytes)
+	}
+	return result, nil
+}
+
+// MaintenanceStats 返回整理正排索引的统计信息
+func (indexer *LocalIndexer) MaintenanceStats() MaintenanceStats {
+	return MaintenanceStats{
+		Runs:           atomic.LoadInt64(&indexer.compactRuns),
+		Errors:         atomic.LoadInt64(&indexer.compactErrors),
+		ReclaimedBytes: atomic.LoadInt64(&indexer.reclaimedBytes),
+		LastRunAt:      atomic.LoadInt64(&indexer.lastCompactAt),
+	}
+}
+
+// closeMaintenance 停止后台任务并等待其退出，返回后台任务的选项，未启动时返回零值。用返回值调用 StartMaintenance 即可恢复后台任务
+func (indexer *LocalIndexer) closeMaintenance() MaintenanceOptions {
+	indexer.maintenanceMutex.Lock()
+	stop, done, opts := indexer.stopMaintenance, indexer.maintenanceDone, indexer.maintenanceOpts
+	indexer.stopMaintenance = nil
+	indexer.maintenanceMutex.Unlock()
+	if stop == nil {
+		return MaintenanceOptions{}
+	}
+	close(stop)
+	<-done
+	return opts
+}
+
+// Compact 立即整理本 worker 的正排索引，供运维在业务低峰期手动触发。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 整理的阈值，<=0 时使用 DefaultCompactThreshold。
+//
+// 返回值:
+//   - *CompactResult: 整理前后正排索引占用的磁盘空间，以及是否执行了整理。
+//   - error: 正排索引不支持整理时返回 FailedPrecondition，其他错误原样返回。
+func (w *IndexServiceWorker) Compact(ctx context.Context, request *CompactRequest) (*CompactResult, error) {
+	result, err := w.Indexer.Compact(request.Threshold)
+	if errors.Is(err, ErrCompactUnsupported) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &CompactResult{BeforeBytes: result.BeforeBytes, AfterBytes: result.AfterBytes, Compacted: result.Compacted}, nil
+}
@@ -0,0 +1,366 @@
+package index_service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jmh000527/criker-search/types"
+	"github.com/jmh000527/criker-search/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"strings"
+	"sync"
+	"time"
+)
+
+// followRetryInterval 从副本与主副本断开后，重新订阅之前等待的时间
+const followRetryInterval = time.Second
+
+// replication IndexServiceWorker 的主从复制状态。
+// 主副本把每次写入按顺序追加到变更日志中，从副本通过 Subscribe RPC 订阅变更并应用到自己的索引上。
+type replication struct {
+	changeLogRetain int        // 变更日志保留的变更数量，0 表示不开启变更日志
+	changeLog       *changeLog // 变更日志，未开启时为 nil
+	writeMu         sync.Mutex // 串行化写索引和追加变更日志，保证日志中的顺序与索引的写入顺序一致
+
+	primary       string             // 跟随的主副本地址，为空表示本 worker 不是从副本，由 sharding.shardMu 保护
+	stopFollowing context.CancelFunc // 停止跟随主副本，由 sharding.followMu 保护
+	followDone    chan struct{}      // 跟随协程退出后关闭
+}
+
+// WithChangeLog 开启变更日志，需要在 Init 之前调用。变更日志保存在数据目录旁边（DataDir + ".changelog"）。
+// 主副本开启后才能被从副本订阅；从副本开启后才能跟随主副本，并在重启后从上次同步到的位置继续。
+//
+// 参数:
+//   - retain: 保留最近多少条变更，<=0 时使用 DefaultChangeLogRetain。从副本落后超过这么多条变更后无法增量同步。
+func (w *IndexServiceWorker) WithChangeLog(retain int) *IndexServiceWorker {
+	if retain <= 0 {
+		retain = DefaultChangeLogRetain
+	}
+	w.changeLogRetain = retain
+	return w
+}
+
+// ChangeLogSeq 本 worker 已写入变更日志的最大序号。主副本上是最后一次写入的序号，从副本上是已同步到的序号。
+func (w *IndexServiceWorker) ChangeLogSeq() uint64 {
+	if w.changeLog == nil {
+		return 0
+	}
+	return w.changeLog.LastSeq()
+}
+
+// Follow 作为从副本跟随主副本：订阅主副本的变更并应用到本地索引，与主副本断开后自动重连，直到 Close。
+// 跟随期间本 worker 拒绝直接写入，所有写入都应发给主副本。
+//
+// 参数:
+//   - primary: 主副本的地址（host:port）。
+//
+// 返回值:
+//   - error: 未开启变更日志或已经在跟随主副本时返回错误。
+func (w *IndexServiceWorker) Follow(primary string) error {
+	w.followMu.Lock()
+	defer w.followMu.Unlock()
+	if current := w.primaryAddr(); len(current) > 0 {
+		return fmt.Errorf("已经在跟随主副本 %s", current)
+	}
+	return w.startFollowing(primary)
+}
+
+// startFollowing 开始跟随主副本，调用方需持有 followMu
+func (w *IndexServiceWorker) startFollowing(primary string) error {
+	if w.changeLog == nil {
+		return errors.New("跟随主副本需要先通过 WithChangeLog 开启变更日志")
+	}
+	w.shardMu.Lock()
+	w.primary = primary
+	w.shardMu.Unlock()
+	w.resumeFollowing(primary)
+	return nil
+}
+
+// stopFollowingPrimary 停止跟随主副本并开始接受写入，用于从副本被提升为主副本。调用方需持有 followMu
+func (w *IndexServiceWorker) stopFollowingPrimary() {
+	w.pauseFollowing()
+	w.shardMu.Lock()
+	w.primary = ""
+	w.shardMu.Unlock()
+}
+
+// pauseFollowing 停止跟随主副本的协程并等待其退出，不改变跟随的主副本地址。调用方需持有 followMu
+func (w *IndexServiceWorker) pauseFollowing() {
+	if w.stopFollowing != nil {
+		w.stopFollowing()
+		<-w.followDone
+		w.stopFollowing = nil
+	}
+}
+
+// resumeFollowing 启动跟随主副本的协程，primary 为空时什么都不做
+func (w *IndexServiceWorker) resumeFollowing(primary string) {
+	if len(primary) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.stopFollowing = cancel
+	w.followDone = make(chan struct{})
+	go w.follow(ctx, primary)
+}
+
+// Subscribe 从副本订阅本 worker 的文档变更。先发送变更日志中序号大于 FromSeq 的变更，之后持续推送新的变更，
+// 直到从副本取消订阅或本 worker 下线。
+//
+// 参数:
+//   - request: FromSeq 是从副本已应用的最大序号。
+//   - stream: 发送变更的流。
+//
+// 返回值:
+//   - error: 未开启变更日志时返回 FailedPrecondition；FromSeq 之后的变更已被清理，或 FromSeq 超过本 worker 的最大序号时返回 OutOfRange，
+//     此时从副本无法增量同步，需要从备份或原始数据重建索引。
+func (w *IndexServiceWorker) Subscribe(request *SubscribeRequest, stream IndexService_SubscribeServer) error {
+	if w.changeLog == nil {
+		return status.Error(codes.FailedPrecondition, "未开启变更日志")
+	}
+	fromSeq := request.FromSeq
+	if lastSeq := w.changeLog.LastSeq(); fromSeq > lastSeq {
+		return status.Errorf(codes.OutOfRange, "订阅的序号 %d 超过了最大序号 %d", fromSeq, lastSeq)
+	}
+	utils.Log.Printf("从副本开始订阅序号 %d 之后的变更", fromSeq)
+	for {
+		// 先获取通知再读取，避免错过读取之后、等待之前追加的变更
+		notify := w.changeLog.wait()
+		err := w.changeLog.read(fromSeq, func(event *ChangeEvent) error {
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			fromSeq = event.Seq
+			return nil
+		})
+		if errors.Is(err, errChangeLogTruncated) {
+			return status.Errorf(codes.OutOfRange, "序号 %d 之后的变更已被清理", fromSeq)
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-notify:
+		case <-stream.Context().Done():
+			return nil
+		case <-w.changeLog.done:
+			return status.Error(codes.Unavailable, "服务正在下线")
+		}
+	}
+}
+
+// openChangeLog 按 WithChangeLog 的配置打开变更日志
+func (w *IndexServiceWorker) openChangeLog(dataDir string) error {
+	if w.changeLogRetain <= 0 {
+		return nil
+	}
+	log, err := openChangeLog(dataDir+".changelog", w.changeLogRetain)
+	if err != nil {
+		return err
+	}
+	w.changeLog = log
+	return nil
+}
+
+// checkWritable 从副本拒绝直接写入
+func (w *IndexServiceWorker) checkWritable() error {
+	if primary := w.primaryAddr(); len(primary) > 0 {
+		return status.Errorf(codes.FailedPrecondition, "从副本不接受写入，请写入主副本 %s", primary)
+	}
+	return nil
+}
+
+// addDoc 带版本检查地写入一个文档，并把带有写入后版本号的文档追加到变更日志，返回写入后的版本号
+func (w *IndexServiceWorker) addDoc(doc types.Document, opts WriteOptions) (int64, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	version, err := w.Indexer.AddDocWithOptions(doc, opts)
+	if err == nil {
+		doc.Version = version
+		w.logChange(&ChangeEvent{Op: ChangeOp_ADD, Doc: &doc})
+	}
+	return version, err
+}
+
+// updateDoc 局部更新一个文档，并把更新后的完整文档追加到变更日志，返回更新后的版本号。
+// 变更日志中记录的是完整文档而不是补丁，从副本按外部版本号覆盖写入，重复应用也不会出错。
+func (w *IndexServiceWorker) updateDoc(patch *DocPatch) (int64, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	version, err := w.Indexer.UpdateDoc(patch)
+	if err == nil && w.changeLog != nil {
+		// 持有 writeMu，读取到的就是这次更新之后的文档
+		doc, err := w.Indexer.getDoc(strings.TrimSpace(patch.DocId))
+		if err != nil || doc == nil {
+			utils.Log.Printf("读取更新后的文档 %s 失败，从副本将缺少该变更: %v", patch.DocId, err)
+		} else {
+			w.logChange(&ChangeEvent{Op: ChangeOp_ADD, Doc: doc})
+		}
+	}
+	return version, err
+}
+
+// batchAddDoc 批量写入文档，写入成功的文档按顺序追加到变更日志
+func (w *IndexServiceWorker) batchAddDoc(docs []types.Document) (int, []error) {
+	if err := w.checkWritable(); err != nil {
+		errs := make([]error, len(docs))
+		for i := range errs {
+			errs[i] = err
+		}
+		return 0, errs
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	n, errs := w.Indexer.BatchAddDoc(docs)
+	for i := range docs {
+		if errs[i] == nil {
+			w.logChange(&ChangeEvent{Op: ChangeOp_ADD, Doc: &docs[i]})
+		}
+	}
+	return n, errs
+}
+
+// deleteDoc 删除一个文档，实际删除了文档时追加到变更日志
+func (w *IndexServiceWorker) deleteDoc(docId string) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	n := w.Indexer.DeleteDoc(docId)
+	if n > 0 {
+		w.logChange(&ChangeEvent{Op: ChangeOp_DELETE, DocId: docId})
+	}
+	return n, nil
+}
+
+// batchDelete 批量删除文档，实际删除的文档逐个作为 DELETE 变更追加到变更日志
+func (w *IndexServiceWorker) batchDelete(docIds []string) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	deleted, err := w.Indexer.batchDeleteWhere(docIds, nil)
+	w.logDeletes(deleted)
+	return len(deleted), err
+}
+
+// deleteByQuery 删除所有符合检索条件的文档，实际删除的文档逐个作为 DELETE 变更追加到变更日志
+func (w *IndexServiceWorker) deleteByQuery(request *DeleteByQueryRequest) (int, error) {
+	if err := w.checkWritable(); err != nil {
+		return 0, err
+	}
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	deleted, err := w.Indexer.deleteByQuery(request.Query, request.OnFlag, request.OffFlag, request.OrFlags)
+	w.logDeletes(deleted)
+	return len(deleted), err
+}
+
+// logDeletes 把删除的文档逐个追加到变更日志
+func (w *IndexServiceWorker) logDeletes(docIds []string) {
+	for _, docId := range docIds {
+		w.logChange(&ChangeEvent{Op: ChangeOp_DELETE, DocId: docId})
+	}
+}
+
+// logChange 把变更追加到变更日志。索引已经写入成功，追加失败时只能记录日志，从副本会缺少这条变更。
+func (w *IndexServiceWorker) logChange(event *ChangeEvent) {
+	if w.changeLog == nil {
+		return
+	}
+	if err := w.changeLog.append(event); err != nil {
+		utils.Log.Printf("追加变更日志失败，从副本将缺少该变更: %v", err)
+	}
+}
+
+// applyChange 从副本应用主副本的一条变更，并按原序号追加到自己的变更日志。已经应用过的变更会被跳过。
+func (w *IndexServiceWorker) applyChange(event *ChangeEvent) error {
+	w.writeMu.Lock()
+	defer w.writeMu.Unlock()
+	if event.Seq <= w.changeLog.LastSeq() {
+		return nil
+	}
+	switch event.Op {
+	case ChangeOp_ADD:
+		if event.Doc == nil {
+			return fmt.Errorf("变更 %d 缺少文档", event.Seq)
+		}
+		// 使用主副本分配的版本号，使从副本上的版本号与主副本一致
+		_, err := w.Indexer.AddDocWithOptions(*event.Doc, WriteOptions{ExternalVersion: event.Doc.Version > 0})
+		if IsVersionConflict(err) {
+			// 从副本上已有更新的版本（例如从备份恢复的快照中已经包含了这次变更），跳过写入
+			utils.Log.Printf("跳过变更 %d: %v", event.Seq, err)
+		} else if err != nil {
+			return err
+		}
+	case ChangeOp_DELETE:
+		w.Indexer.DeleteDoc(event.DocId)
+	default:
+		return fmt.Errorf("变更 %d 的操作类型 %v 不合法", event.Seq, event.Op)
+	}
+	return w.changeLog.appendAt(event)
+}
+
+// follow 持续订阅主副本的变更并应用，断开后重新订阅，ctx 被取消后返回
+func (w *IndexServiceWorker) follow(ctx context.Context, primary string) {
+	defer close(w.followDone)
+	utils.Log.Printf("开始跟随主副本 %s，已同步到序号 %d", primary, w.changeLog.LastSeq())
+	for ctx.Err() == nil {
+		if err := w.syncFrom(ctx, primary); err != nil && ctx.Err() == nil {
+			if status.Code(err) == codes.OutOfRange {
+				utils.Log.Printf("无法从主副本 %s 增量同步，需要从备份或原始数据重建索引: %v", primary, err)
+			} else {
+				utils.Log.Printf("从主副本 %s 同步变更中断，%v 后重试: %v", primary, followRetryInterval, err)
+			}
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(followRetryInterval):
+		}
+	}
+	utils.Log.Printf("停止跟随主副本 %s，已同步到序号 %d", primary, w.changeLog.LastSeq())
+}
+
+// syncFrom 订阅一次主副本的变更，直到流中断
+func (w *IndexServiceWorker) syncFrom(ctx context.Context, primary string) error {
+	conn, err := grpc.DialContext(ctx, primary, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	stream, err := NewIndexServiceClient(conn).Subscribe(ctx, &SubscribeRequest{FromSeq: w.changeLog.LastSeq()})
+	if err != nil {
+		return err
+	}
+	for {
+		event, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		if err := w.applyChange(event); err != nil {
+			return fmt.Errorf("应用变更 %d 失败: %v", event.Seq, err)
+		}
+	}
+}
+
+// closeReplication 停止跟随主副本，并通知所有订阅者退出，使 drain 不必等待长期存在的订阅流
+func (w *IndexServiceWorker) closeReplication() {
+	w.followMu.Lock()
+	w.pauseFollowing()
+	w.followMu.Unlock()
+	if w.changeLog != nil {
+		w.changeLog.shutdown()
+	}
+}
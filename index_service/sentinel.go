@@ -2,13 +2,21 @@ package index_service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/jmh000527/criker-search/index_service/coordinator"
+	"github.com/jmh000527/criker-search/index_service/endpoint_selector"
+	"github.com/jmh000527/criker-search/index_service/load_balancer"
 	"github.com/jmh000527/criker-search/index_service/service_hub"
 	"github.com/jmh000527/criker-search/types"
 	"github.com/jmh000527/criker-search/utils"
+	etcdv3 "go.etcd.io/etcd/client/v3"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/connectivity"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"io"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,25 +24,67 @@ import (
 
 // Sentinel 哨兵前台，与外部系统对接的接口。
 type Sentinel struct {
-	hub      service_hub.ServiceHub // 从 Hub 中获取 IndexServiceWorker 的集合。可以直接访问 ServiceHub，也可能通过代理模式进行访问。
-	connPool sync.Map               // 与各个 IndexServiceWorker 建立的 gRPC 连接池。缓存连接以避免每次请求都重新建立连接，提升效率。
+	hub      service_hub.ServiceHub              // 从 Hub 中获取 IndexServiceWorker 的集合。可以直接访问 ServiceHub，也可能通过代理模式进行访问。
+	selector *endpoint_selector.EndpointSelector // 按负载均衡策略选择 worker，并根据每次调用的结果熔断不健康的 worker
+	connPool sync.Map                            // 与各个 IndexServiceWorker 建立的 gRPC 连接池。缓存连接以避免每次请求都重新建立连接，提升效率。
+
+	subscribeOnce sync.Once // 第一次服务发现时订阅 hub 的实例变化通知
+	notified      bool      // hub 会在实例变化时通知，权重由通知同步，服务发现时不必再比较
+
+	shardMap     atomic.Pointer[coordinator.ShardMap] // coordinator 维护的分片表，为 nil 时按 worker 发布的元数据路由
+	readSeq      uint64                               // 在多个从副本之间轮流选择读请求的目标
+	stopWatching context.CancelFunc                   // 停止监视分片表
+	watchDone    chan struct{}                        // 监视分片表的协程退出后关闭
 }
 
 // NewSentinel 创建并返回一个 Sentinel 实例。
 //
 // 参数:
 //   - etcdServers: 一个字符串数组，包含了 etcd 服务器的地址。
+//   - lbStrategy: 负载均衡策略的名称，见 load_balancer.Strategy* 常量。为空或不合法时使用轮询。
+//
+// 返回值:
+//   - *Sentinel: 一个新的 Sentinel 实例。
+func NewSentinel(etcdServers []string, lbStrategy string) *Sentinel {
+	// hub := GetServiceHub(etcdServers, 10) // 直接访问 ServiceHub
+	hub := service_hub.GetServiceHubProxy(etcdServers, 3, 100) // 使用代理模式访问 ServiceHub
+	loadBalancer, err := load_balancer.NewLoadBalancer(lbStrategy)
+	if err != nil {
+		utils.Log.Printf("%v，使用轮询负载均衡", err)
+		loadBalancer = &load_balancer.RoundRobin{}
+	}
+	return NewSentinelWithHub(hub).WithLoadBalancer(loadBalancer)
+}
+
+// NewSentinelWithHub 使用指定的 ServiceHub 创建 Sentinel。
+// 通过传入 StaticServiceHub、FileServiceHub 或 MemoryServiceHub，Sentinel 可以不依赖 etcd 集群运行。
+// 默认使用轮询负载均衡，可通过 WithLoadBalancer 设置其他策略。
+//
+// 参数:
+//   - hub: 用于发现 IndexServiceWorker 的服务注册中心。
 //
 // 返回值:
 //   - *Sentinel: 一个新的 Sentinel 实例。
-func NewSentinel(etcdServers []string) *Sentinel {
+func NewSentinelWithHub(hub service_hub.ServiceHub) *Sentinel {
 	return &Sentinel{
-		// hub: GetServiceHub(etcdServers, 10), // 直接访问 ServiceHub
-		hub:      service_hub.GetServiceHubProxy(etcdServers, 3, 100), // 使用代理模式访问 ServiceHub
-		connPool: sync.Map{},                                          // 初始化 gRPC 连接池
+		hub:      hub,
+		selector: endpoint_selector.NewEndpointSelector(nil),
+		connPool: sync.Map{}, // 初始化 gRPC 连接池
 	}
 }
 
+// WithLoadBalancer 设置选择 worker 时使用的负载均衡策略，应在发出请求之前调用，之后 worker 的权重才会同步给新的负载均衡。
+//
+// 参数:
+//   - loadBalancer: 负载均衡策略，可通过 load_balancer.NewLoadBalancer 按名称创建。
+//
+// 返回值:
+//   - *Sentinel: Sentinel 本身，便于链式调用。
+func (sentinel *Sentinel) WithLoadBalancer(loadBalancer load_balancer.LoadBalancer) *Sentinel {
+	sentinel.selector = endpoint_selector.NewEndpointSelector(loadBalancer)
+	return sentinel
+}
+
 // GetGrpcConn 向指定的 endpoint 建立 gRPC 连接。
 // 如果连接已经存在于缓存中且状态可用，则直接返回缓存的连接。
 // 如果连接状态不可用或不存在，则重新建立连接并存储到缓存中。
@@ -59,7 +109,8 @@ func (sentinel *Sentinel) GetGrpcConn(endpoint string) *grpc.ClientConn {
 		}
 	}
 
-	// 连接到服务，控制连接超时
+	// 连接到服务，控制连接超时。建立连接不是一次请求，不占用负载均衡的在途计数和半开状态的试探名额
+	begin := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
 	defer cancel()
 	// 获取 gRPC 连接
@@ -69,6 +120,8 @@ func (sentinel *Sentinel) GetGrpcConn(endpoint string) *grpc.ClientConn {
 	grpcConn, err := grpc.DialContext(ctx, endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
 	if err != nil {
 		utils.Log.Printf("连接到 %s 的 gRPC 失败，错误: %s", endpoint, err.Error())
+		// 建立连接失败同样说明节点不健康
+		sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
 		return nil
 	}
 	utils.Log.Printf("连接到 %s 的 gRPC 成功", endpoint)
@@ -77,6 +130,100 @@ func (sentinel *Sentinel) GetGrpcConn(endpoint string) *grpc.ClientConn {
 	return grpcConn
 }
 
+// ServiceInstances 返回所有 IndexServiceWorker 实例及其发布的元数据（版本、分片、角色、文档数量等），
+// 供路由决策和运维查看集群状态使用。
+// worker 发布的权重需要同步给负载均衡，已下线的 worker 不再统计健康状况：hub 能通知实例变化时（如 HubProxy）只在变化时同步，
+// 否则在这里与上次同步的结果比较。
+func (sentinel *Sentinel) ServiceInstances() []service_hub.ServiceInstance {
+	sentinel.subscribeOnce.Do(func() {
+		if notifier, ok := sentinel.hub.(service_hub.InstanceNotifier); ok {
+			notifier.OnInstanceChange(sentinel.onInstanceChange)
+			sentinel.notified = true
+		}
+	})
+	instances := sentinel.hub.GetServiceInstances(IndexService)
+	if !sentinel.notified {
+		sentinel.selector.Sync(instances)
+	}
+	return instances
+}
+
+// onInstanceChange 把 hub 通知的实例变化同步给 selector：上线或元数据变化时更新权重，下线时删除权重和健康状况
+func (sentinel *Sentinel) onInstanceChange(service string, instance service_hub.ServiceInstance, removed bool) {
+	if service != IndexService {
+		return
+	}
+	if removed {
+		sentinel.selector.Forget(instance.Endpoint)
+	} else {
+		sentinel.selector.SetWeight(instance.Endpoint, instance.Weight)
+	}
+}
+
+// beginRequest 在向 endpoint 发出请求之前调用，返回请求的开始时间。
+// 每次成功的 beginRequest 都必须对应一次 selector.ReportResult，负载均衡据此统计在途请求数和延迟。
+// 节点被熔断，或处于半开状态且试探请求已达上限时返回错误，调用方不应再发出请求。
+func (sentinel *Sentinel) beginRequest(endpoint string) (time.Time, error) {
+	if !sentinel.selector.BeginRequest(endpoint) {
+		return time.Time{}, fmt.Errorf("worker %s 已被熔断或试探请求已达上限", endpoint)
+	}
+	return time.Now(), nil
+}
+
+// getEndpoints 获取执行读请求（检索、计数、统计）的 endpoints：每个分片只选一个副本，避免同一份数据被重复检索、重复计数。
+// 优先选择未被熔断的从副本，分担主副本的压力；从副本都不可用时选择主副本。
+// 被熔断的节点即使收到请求大概率也会失败或超时，跳过它们可以避免拖慢整个请求；分片的所有副本都被熔断时仍选择其中一个。
+// 从副本的数据可能略落后于主副本。
+func (sentinel *Sentinel) getEndpoints() []string {
+	groups := sentinel.shardGroups()
+	endpoints := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if endpoint := sentinel.pickReader(group); len(endpoint) > 0 {
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints
+}
+
+// pickReader 为一个分片选择执行读请求的副本，多个从副本可用时轮流选择
+func (sentinel *Sentinel) pickReader(group shardGroup) string {
+	available := make([]string, 0, len(group.replicas))
+	for _, replica := range group.replicas {
+		if sentinel.selector.IsAvailable(replica) {
+			available = append(available, replica)
+		}
+	}
+	if len(available) > 0 {
+		return available[atomic.AddUint64(&sentinel.readSeq, 1)%uint64(len(available))]
+	}
+	if len(group.primary) > 0 {
+		if !sentinel.selector.IsAvailable(group.primary) {
+			utils.Log.Printf("worker %s 及其从副本均已被熔断，仍向其发送请求", group.primary)
+		}
+		return group.primary
+	}
+	if len(group.replicas) > 0 {
+		return group.replicas[0]
+	}
+	return ""
+}
+
+// documentOwner 返回文档所在的主副本，用于必须在文档所在节点上执行的写入（版本检查、局部更新）。
+// 有多个主副本时只有按 key 路由的负载均衡策略能保证找到文档所在的节点；
+// 轮询等策略下文档可能写在任意一个主副本上，版本检查和局部更新会在错误的节点上执行，因此直接拒绝。
+func (sentinel *Sentinel) documentOwner(docId string) (string, error) {
+	primaries := sentinel.primaryEndpoints()
+	if len(primaries) > 1 && !sentinel.selector.KeyedRouting() {
+		return "", status.Error(codes.FailedPrecondition, "有多个主副本时，带版本检查的写入和局部更新需要按 key 路由的负载均衡策略（例如 consistent_hash）")
+	}
+	return sentinel.selector.SelectEndpointByKey(primaries, docId), nil
+}
+
+// primaryByKey 按 key 从各个分片的主副本中选择一个。写请求只能发给主副本，从副本会拒绝写入
+func (sentinel *Sentinel) primaryByKey(key string) string {
+	return sentinel.selector.SelectEndpointByKey(sentinel.primaryEndpoints(), key)
+}
+
 // AddDoc 向集群中的 IndexService 添加文档。如果文档已存在，会先删除旧文档再添加新文档。
 //
 // 参数:
@@ -86,8 +233,9 @@ func (sentinel *Sentinel) GetGrpcConn(endpoint string) *grpc.ClientConn {
 //   - int: 成功添加的文档数量。
 //   - error: 如果在添加文档时出现错误，返回相应的错误信息。
 func (sentinel *Sentinel) AddDoc(doc types.Document) (int, error) {
-	// 根据负载均衡策略，选择一个 IndexService 节点，将文档添加到该节点
-	endpoint := sentinel.hub.GetServiceEndpoint(IndexService)
+	// 根据负载均衡策略，从各个分片的主副本中选择一个 IndexService 节点，将文档添加到该节点。
+	// 以文档 ID 作为路由 key，使用一致性哈希时同一文档的更新总是落到同一个节点
+	endpoint := sentinel.primaryByKey(doc.Id)
 	if len(endpoint) == 0 {
 		return 0, fmt.Errorf("未找到服务 %s 的有效节点", IndexService)
 	}
@@ -98,7 +246,12 @@ func (sentinel *Sentinel) AddDoc(doc types.Document) (int, error) {
 	}
 	// 创建 gRPC 客户端并进行调用
 	client := NewIndexServiceClient(grpcConn)
+	begin, err := sentinel.beginRequest(endpoint)
+	if err != nil {
+		return 0, err
+	}
 	affected, err := client.AddDoc(context.Background(), &doc)
+	sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
 	if err != nil {
 		return 0, err
 	}
@@ -106,6 +259,167 @@ func (sentinel *Sentinel) AddDoc(doc types.Document) (int, error) {
 	return int(affected.Count), nil
 }
 
+// AddDocWithOptions 带版本检查地向集群中添加文档，按文档 ID 路由到与 AddDoc 相同的节点。
+// 版本检查在节点上完成，只有同一文档的写入总是落到同一个节点时版本号才有意义，
+// 因此有多个主副本时要求使用按 key 路由的负载均衡策略（例如一致性哈希），否则拒绝写入。
+//
+// 参数:
+//   - doc: 要添加的文档，使用外部版本号时 doc.Version 是要写入的版本号。
+//   - opts: 版本检查的选项，见 WriteOptions。
+//
+// 返回值:
+//   - int64: 写入后文档的版本号。
+//   - error: 版本冲突时返回 gRPC 状态码为 Aborted 的错误，可以用 IsVersionConflict 判断；
+//     负载均衡策略不支持按 key 路由时返回状态码为 FailedPrecondition 的错误。
+func (sentinel *Sentinel) AddDocWithOptions(doc types.Document, opts WriteOptions) (int64, error) {
+	endpoint, err := sentinel.documentOwner(doc.Id)
+	if err != nil {
+		return 0, err
+	}
+	if len(endpoint) == 0 {
+		return 0, fmt.Errorf("未找到服务 %s 的有效节点", IndexService)
+	}
+	grpcConn := sentinel.GetGrpcConn(endpoint)
+	if grpcConn == nil {
+		return 0, fmt.Errorf("连接到 %s 的 gRPC 失败", endpoint)
+	}
+	client := NewIndexServiceClient(grpcConn)
+	begin, err := sentinel.beginRequest(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	result, err := client.AddDocWithOptions(context.Background(), &AddDocRequest{
+		Doc:             &doc,
+		IfVersion:       opts.IfVersion,
+		ExternalVersion: opts.ExternalVersion,
+	})
+	// 版本冲突是业务上的失败，状态码 Aborted 不会被计入节点的故障
+	sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+	if err != nil {
+		return 0, err
+	}
+	utils.Log.Printf("成功向 worker %s 添加文档 %s，版本号 %d", endpoint, doc.Id, result.Version)
+	return result.Version, nil
+}
+
+// UpdateDoc 局部更新集群中的文档，按文档 ID 路由到与 AddDoc 相同的节点。
+// 与 AddDocWithOptions 相同，有多个主副本时要求使用按 key 路由的负载均衡策略，否则拒绝更新。
+//
+// 参数:
+//   - patch: 要对文档做的修改。
+//
+// 返回值:
+//   - int64: 更新后文档的版本号。
+//   - error: 文档不存在时返回状态码为 NotFound 的错误（可以用 IsDocNotFound 判断），版本冲突时返回状态码为 Aborted 的错误，
+//     负载均衡策略不支持按 key 路由时返回状态码为 FailedPrecondition 的错误。
+func (sentinel *Sentinel) UpdateDoc(patch *DocPatch) (int64, error) {
+	endpoint, err := sentinel.documentOwner(patch.DocId)
+	if err != nil {
+		return 0, err
+	}
+	if len(endpoint) == 0 {
+		return 0, fmt.Errorf("未找到服务 %s 的有效节点", IndexService)
+	}
+	grpcConn := sentinel.GetGrpcConn(endpoint)
+	if grpcConn == nil {
+		return 0, fmt.Errorf("连接到 %s 的 gRPC 失败", endpoint)
+	}
+	client := NewIndexServiceClient(grpcConn)
+	begin, err := sentinel.beginRequest(endpoint)
+	if err != nil {
+		return 0, err
+	}
+	result, err := client.UpdateDoc(context.Background(), patch)
+	sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+	if err != nil {
+		return 0, err
+	}
+	utils.Log.Printf("成功更新 worker %s 上的文档 %s，版本号 %d", endpoint, patch.DocId, result.Version)
+	return result.Version, nil
+}
+
+// BatchAddDoc 向集群中的 IndexService 批量添加文档。
+// 每个文档按负载均衡策略选择一个 IndexService 节点，发往同一节点的文档通过一个 BulkAdd 流发送，
+// 各节点之间并行写入。节点处理不过来时 stream.Send 会阻塞，不会无限制地占用内存。
+//
+// 参数:
+//   - docs: 要添加的文档列表。
+//
+// 返回值:
+//   - int: 成功添加的文档数量。
+//   - []error: 与 docs 一一对应的错误列表，nil 表示该文档写入成功。
+func (sentinel *Sentinel) BatchAddDoc(docs []types.Document) (int, []error) {
+	errs := make([]error, len(docs))
+	// endpoint -> 发往该节点的文档在 docs 中的位置
+	groups := make(map[string][]int)
+	for i := range docs {
+		endpoint := sentinel.primaryByKey(docs[i].Id)
+		if len(endpoint) == 0 {
+			errs[i] = fmt.Errorf("未找到服务 %s 的有效节点", IndexService)
+			continue
+		}
+		groups[endpoint] = append(groups[endpoint], i)
+	}
+
+	var n int32
+	var wg sync.WaitGroup
+	wg.Add(len(groups))
+	for endpoint, positions := range groups {
+		go func(endpoint string, positions []int) {
+			defer wg.Done()
+			// fail 将发往该节点的所有文档标记为失败
+			fail := func(err error) {
+				for _, i := range positions {
+					errs[i] = err
+				}
+			}
+			grpcConn := sentinel.GetGrpcConn(endpoint)
+			if grpcConn == nil {
+				fail(fmt.Errorf("连接到 %s 的 gRPC 失败", endpoint))
+				return
+			}
+			client := NewIndexServiceClient(grpcConn)
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				fail(err)
+				return
+			}
+			stream, err := client.BulkAdd(context.Background())
+			if err != nil {
+				sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+				utils.Log.Printf("向 worker %s 发起批量写入失败，错误: %s", endpoint, err)
+				fail(err)
+				return
+			}
+			for _, i := range positions {
+				if err := stream.Send(&docs[i]); err != nil {
+					// 发送失败时流已不可用，真正的错误由 CloseAndRecv 返回
+					break
+				}
+			}
+			result, err := stream.CloseAndRecv()
+			sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+			if err != nil {
+				utils.Log.Printf("向 worker %s 批量写入失败，错误: %s", endpoint, err)
+				fail(err)
+				return
+			}
+			// Statuses 与发送顺序一致
+			for j, i := range positions {
+				if j >= len(result.Statuses) {
+					errs[i] = fmt.Errorf("worker %s 未返回文档 %s 的写入状态", endpoint, docs[i].Id)
+				} else if !result.Statuses[j].Ok {
+					errs[i] = errors.New(result.Statuses[j].Error)
+				}
+			}
+			atomic.AddInt32(&n, result.Count)
+			utils.Log.Printf("成功向 worker %s 批量添加 %d 个文档", endpoint, result.Count)
+		}(endpoint, positions)
+	}
+	wg.Wait()
+	return int(atomic.LoadInt32(&n)), errs
+}
+
 // DeleteDoc 从集群中删除与 docId 对应的文档，返回成功删除的文档数量（通常不会超过 1）。
 //
 // 参数:
@@ -114,8 +428,8 @@ func (sentinel *Sentinel) AddDoc(doc types.Document) (int, error) {
 // 返回值:
 //   - int: 成功删除的文档数量。
 func (sentinel *Sentinel) DeleteDoc(docId string) int {
-	// 获取该服务的所有 endpoints
-	endpoints := sentinel.hub.GetServiceEndpoints(IndexService)
+	// 获取每个分片的主副本，从副本的数据由主副本同步过去
+	endpoints := sentinel.primaryEndpoints()
 	if len(endpoints) == 0 {
 		return 0
 	}
@@ -133,7 +447,13 @@ func (sentinel *Sentinel) DeleteDoc(docId string) int {
 				return
 			}
 			client := NewIndexServiceClient(grpcConn)
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 删除文档 %s 失败，错误: %s", endpoint, docId, err)
+				return
+			}
 			affected, err := client.DeleteDoc(context.Background(), &DocId{docId})
+			sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
 			if err != nil {
 				utils.Log.Printf("从 worker %s 删除文档 %s 失败，错误: %s", endpoint, docId, err)
 				return
@@ -148,6 +468,211 @@ func (sentinel *Sentinel) DeleteDoc(docId string) int {
 	return int(atomic.LoadInt32(&n))
 }
 
+// BatchDelete 从集群中批量删除文档。文档可能位于任意分片，因此所有文档ID都发给每个分片的主副本，
+// 由各主副本删除自己持有的文档，从副本通过主从复制同步删除。
+//
+// 参数:
+//   - docIds: 要删除的文档ID列表。
+//
+// 返回值:
+//   - *DeleteResult: 删除的文档总数以及每个 worker 删除的数量。
+//   - error: 部分 worker 删除失败时返回这些错误，此时 DeleteResult 中只包含成功的 worker。
+func (sentinel *Sentinel) BatchDelete(docIds []string) (*DeleteResult, error) {
+	request := &BatchDeleteRequest{DocIds: docIds}
+	return sentinel.deleteOnPrimaries(func(client IndexServiceClient) (*AffectedCount, error) {
+		return client.BatchDelete(context.Background(), request)
+	})
+}
+
+// DeleteByQuery 从集群中删除所有符合检索条件的文档，每个分片的主副本各自检索并删除。
+//
+// 参数:
+//   - query: 检索条件。
+//   - onFlag: 需要匹配的位特征。
+//   - offFlag: 需要排除的位特征。
+//   - orFlags: 需要至少命中一个bit的位特征集合。
+//
+// 返回值:
+//   - *DeleteResult: 删除的文档总数以及每个 worker 删除的数量。
+//   - error: 部分 worker 删除失败时返回这些错误，此时 DeleteResult 中只包含成功的 worker。
+func (sentinel *Sentinel) DeleteByQuery(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) (*DeleteResult, error) {
+	request := &DeleteByQueryRequest{
+		Query:   query,
+		OnFlag:  onFlag,
+		OffFlag: offFlag,
+		OrFlags: orFlags,
+	}
+	return sentinel.deleteOnPrimaries(func(client IndexServiceClient) (*AffectedCount, error) {
+		return client.DeleteByQuery(context.Background(), request)
+	})
+}
+
+// deleteOnPrimaries 并行地在每个分片的主副本上执行删除，汇总每个 worker 删除的数量
+func (sentinel *Sentinel) deleteOnPrimaries(call func(client IndexServiceClient) (*AffectedCount, error)) (*DeleteResult, error) {
+	endpoints := sentinel.primaryEndpoints()
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("未找到服务 %s 的有效节点", IndexService)
+	}
+	result := &DeleteResult{Workers: make(map[string]int, len(endpoints))}
+	var errs []error
+	var mu sync.Mutex
+	wg := sync.WaitGroup{}
+	wg.Add(len(endpoints))
+	for _, endpoint := range endpoints {
+		go func(endpoint string) {
+			defer wg.Done()
+			grpcConn := sentinel.GetGrpcConn(endpoint)
+			if grpcConn == nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("连接到 %s 的 gRPC 失败", endpoint))
+				mu.Unlock()
+				return
+			}
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			affected, err := call(NewIndexServiceClient(grpcConn))
+			sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				utils.Log.Printf("在 worker %s 上删除文档失败，错误: %s", endpoint, err)
+				errs = append(errs, fmt.Errorf("worker %s: %w", endpoint, err))
+				return
+			}
+			result.Workers[endpoint] = int(affected.Count)
+			result.Count += int(affected.Count)
+			utils.Log.Printf("从 worker %s 删除了 %d 个文档", endpoint, affected.Count)
+		}(endpoint)
+	}
+	wg.Wait()
+	return result, errors.Join(errs...)
+}
+
+// primaryEndpoints 获取每个分片的主副本，写请求只需发给主副本。
+// 没有发布角色的 worker（不区分主从）同样视为主副本。与 getEndpoints 不同，被熔断的节点不会被跳过，
+// 否则删除会在调用方不知情的情况下漏掉该节点上的文档。
+func (sentinel *Sentinel) primaryEndpoints() []string {
+	groups := sentinel.shardGroups()
+	endpoints := make([]string, 0, len(groups))
+	for _, group := range groups {
+		if len(group.primary) > 0 {
+			endpoints = append(endpoints, group.primary)
+		}
+	}
+	return endpoints
+}
+
+// shardGroup 同一份数据的主副本和从副本
+type shardGroup struct {
+	primary  string   // 主副本，为空表示主副本不可用
+	replicas []string // 从副本
+}
+
+// shardGroups 按分片对存活的 worker 分组。
+// 监视了分片表时以分片表为准，只保留存活的 worker，未被分配的备用 worker 不参与路由；
+// 否则按 worker 发布的元数据分组：声明为从副本的 worker 归入同一分片的主副本，未声明角色的 worker 各自作为一组。
+func (sentinel *Sentinel) shardGroups() []shardGroup {
+	instances := sentinel.ServiceInstances()
+	if shardMap := sentinel.shardMap.Load(); shardMap != nil {
+		alive := make(map[string]struct{}, len(instances))
+		for _, instance := range instances {
+			alive[instance.Endpoint] = struct{}{}
+		}
+		groups := make([]shardGroup, 0, len(shardMap.Shards))
+		for _, shard := range shardMap.Shards {
+			var group shardGroup
+			if _, exists := alive[shard.Primary]; exists {
+				group.primary = shard.Primary
+			}
+			for _, replica := range shard.Replicas {
+				if _, exists := alive[replica]; exists {
+					group.replicas = append(group.replicas, replica)
+				}
+			}
+			if len(group.primary) > 0 || len(group.replicas) > 0 {
+				groups = append(groups, group)
+			}
+		}
+		return groups
+	}
+
+	groups := make([]shardGroup, 0, len(instances))
+	primaryOfShard := make(map[int]int) // 分片编号 -> 该分片主副本所在的组
+	for _, instance := range instances {
+		if instance.Role != service_hub.RoleReplica {
+			if _, exists := primaryOfShard[instance.ShardId]; !exists && instance.Role == service_hub.RolePrimary {
+				primaryOfShard[instance.ShardId] = len(groups)
+			}
+			groups = append(groups, shardGroup{primary: instance.Endpoint})
+		}
+	}
+	orphans := make(map[int]int) // 分片编号 -> 没有主副本的从副本所在的组
+	for _, instance := range instances {
+		if instance.Role != service_hub.RoleReplica {
+			continue
+		}
+		i, exists := primaryOfShard[instance.ShardId]
+		if !exists {
+			if i, exists = orphans[instance.ShardId]; !exists {
+				i = len(groups)
+				orphans[instance.ShardId] = i
+				groups = append(groups, shardGroup{})
+			}
+		}
+		groups[i].replicas = append(groups[i].replicas, instance.Endpoint)
+	}
+	return groups
+}
+
+// WatchShardMap 监视 coordinator 维护的分片表，之后按分片表选择各个分片的主副本和从副本，直到 Close。
+// 收到分片表之前，以及没有 coordinator 时，按 worker 发布的分片和角色路由。
+//
+// 参数:
+//   - etcdServers: 保存分片表的 etcd 服务器地址列表。
+//
+// 返回值:
+//   - error: 连接 etcd 失败时返回错误。
+func (sentinel *Sentinel) WatchShardMap(etcdServers []string) error {
+	client, err := etcdv3.New(etcdv3.Config{
+		Endpoints:   etcdServers,
+		DialTimeout: 3 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	sentinel.stopWatching = cancel
+	sentinel.watchDone = make(chan struct{})
+	go func() {
+		defer close(sentinel.watchDone)
+		defer client.Close()
+		coordinator.WatchShardMap(ctx, client, sentinel.ApplyShardMap)
+	}()
+	return nil
+}
+
+// ApplyShardMap 按分片表路由之后的请求，不比当前分片表更新（Epoch 不大于当前）的分片表会被忽略。
+//
+// 参数:
+//   - shardMap: coordinator 维护的分片表。
+func (sentinel *Sentinel) ApplyShardMap(shardMap *coordinator.ShardMap) {
+	for {
+		current := sentinel.shardMap.Load()
+		if current != nil && current.Epoch >= shardMap.Epoch {
+			return
+		}
+		if sentinel.shardMap.CompareAndSwap(current, shardMap) {
+			utils.Log.Printf("按分片表 epoch %d 路由请求", shardMap.Epoch)
+			return
+		}
+	}
+}
+
 // Search 执行检索操作，并返回文档列表。
 //
 // 参数:
@@ -158,21 +683,40 @@ func (sentinel *Sentinel) DeleteDoc(docId string) int {
 //
 // 返回值:
 //   - []*types.Document: 经过检索的文档列表，可能为空。
+func (sentinel *Sentinel) Search(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) []*types.Document {
+	return sentinel.SearchWithLimit(query, onFlag, offFlag, orFlags, 0)
+}
+
+// SearchWithLimit 执行检索操作，最多返回 limit 个文档。
+//
+// 参数:
+//   - query: 指定的检索查询条件，类型为 *types.TermQuery。
+//   - onFlag: 开启的标志位，类型为 uint64。
+//   - offFlag: 关闭的标志位，类型为 uint64。
+//   - orFlags: OR 标志位的切片，类型为 []uint64。
+//   - limit: 最多返回的文档数，<=0 表示不限制。
+//
+// 返回值:
+//   - []*types.Document: 经过检索的文档列表，可能为空。
 //
 // 详细描述:
 //  1. 从服务中心获取所有的 endpoints。
-//  2. 使用 goroutines 并行地对每个 endpoint 执行检索操作。
-//  3. 将每个检索结果发送到 resultChan 通道中。
-//  4. 在另一个 goroutine 中，从 resultChan 通道中读取结果，并将其存储在 docs 切片中。
-//  5. 等待所有的检索操作完成后，关闭 resultChan，并等待从 resultChan 中读取完所有结果。
-//  6. 返回存储的文档列表。
-func (sentinel *Sentinel) Search(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64) []*types.Document {
+//  2. 使用 goroutines 并行地对每个 endpoint 发起 SearchStream 流式检索，每收到一个分片就把其中的文档发送到 resultChan 通道中。
+//  3. 在另一个 goroutine 中，从 resultChan 通道中读取结果，并将其存储在 docs 切片中。
+//     收集到 limit 个文档后取消 ctx，各个 worker 不再继续读取和发送剩余的分片。
+//  4. 等待所有的检索操作完成后，关闭 resultChan，并等待从 resultChan 中读取完所有结果。
+//  5. 返回存储的文档列表。
+func (sentinel *Sentinel) SearchWithLimit(query *types.TermQuery, onFlag, offFlag uint64, orFlags []uint64, limit int) []*types.Document {
 	// 获取该服务所有的 endpoints
-	endpoints := sentinel.hub.GetServiceEndpoints(IndexService)
+	endpoints := sentinel.getEndpoints()
 	if len(endpoints) == 0 {
 		return nil
 	}
 
+	// 收集到足够多的结果后，通过 ctx 取消所有仍在进行的流
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
 	// 用于存储检索结果的切片和通道
 	docs := make([]*types.Document, 0, 1000)
 	resultChan := make(chan *types.Document, 1000)
@@ -192,23 +736,48 @@ func (sentinel *Sentinel) Search(query *types.TermQuery, onFlag, offFlag uint64,
 			}
 			client := NewIndexServiceClient(grpcConn)
 
-			// 执行检索请求
-			searchResult, err := client.Search(context.Background(), &SearchRequest{
+			// 发起流式检索请求
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				utils.Log.Printf("向 worker %s 执行查询 %s 失败，错误: %s", endpoint, query, err)
+				return
+			}
+			stream, err := client.SearchStream(ctx, &SearchRequest{
 				Query:   query,
 				OnFlag:  onFlag,
 				OffFlag: offFlag,
 				OrFlags: orFlags,
+				Limit:   int32(limit),
 			})
 			if err != nil {
+				sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
 				utils.Log.Printf("向 worker %s 执行查询 %s 失败，错误: %s", endpoint, query, err)
 				return
 			}
-			if len(searchResult.Results) > 0 {
-				utils.Log.Printf("向 worker %s 执行查询 %s 成功，获取到 %v 个文档", endpoint, query, len(searchResult.Results))
+			total := 0
+			for {
+				searchResult, err := stream.Recv()
+				if err == io.EOF {
+					sentinel.selector.ReportResult(endpoint, time.Since(begin), nil)
+					break
+				}
+				if err != nil {
+					// 主动取消导致的错误不需要记录，也不说明节点不健康（Canceled 不计为失败），
+					// 但仍需上报以结束本次请求的在途统计
+					sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+					if ctx.Err() == nil {
+						utils.Log.Printf("从 worker %s 接收查询 %s 的结果失败，错误: %s", endpoint, query, err)
+					}
+					break
+				}
+				total += len(searchResult.Results)
 				for _, result := range searchResult.Results {
 					resultChan <- result
 				}
 			}
+			if total > 0 {
+				utils.Log.Printf("向 worker %s 执行查询 %s 成功，获取到 %v 个文档", endpoint, query, total)
+			}
 		}(endpoint)
 	}
 
@@ -216,7 +785,14 @@ func (sentinel *Sentinel) Search(query *types.TermQuery, onFlag, offFlag uint64,
 	signalChan := make(chan struct{})
 	go func() {
 		for doc := range resultChan {
+			// 已经收集够了，丢弃取消之前已在途的文档
+			if limit > 0 && len(docs) >= limit {
+				continue
+			}
 			docs = append(docs, doc)
+			if limit > 0 && len(docs) >= limit {
+				cancel()
+			}
 		}
 		// 读取完成，通知主 goroutine
 		signalChan <- struct{}{}
@@ -248,7 +824,7 @@ func (sentinel *Sentinel) Search(query *types.TermQuery, onFlag, offFlag uint64,
 func (sentinel *Sentinel) Count() int {
 	var n int32
 	// 获取所有服务的 endpoints
-	endpoints := sentinel.hub.GetServiceEndpoints(IndexService)
+	endpoints := sentinel.getEndpoints()
 	if len(endpoints) == 0 {
 		return 0
 	}
@@ -263,9 +839,16 @@ func (sentinel *Sentinel) Count() int {
 			if grpcConn != nil {
 				client := NewIndexServiceClient(grpcConn)
 				// 执行计数请求
+				begin, err := sentinel.beginRequest(endpoint)
+				if err != nil {
+					utils.Log.Printf("从 worker %s 获取文档数量失败: %s", endpoint, err)
+					return
+				}
 				affected, err := client.Count(context.Background(), new(CountRequest))
+				sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
 				if err != nil {
 					utils.Log.Printf("从 worker %s 获取文档数量失败: %s", endpoint, err)
+					return
 				}
 				if affected.Count > 0 {
 					// 累加计数
@@ -280,8 +863,254 @@ func (sentinel *Sentinel) Count() int {
 	return int(atomic.LoadInt32(&n))
 }
 
+// Stats 获取所有 worker 的索引统计信息并汇总：文档数量、keyword 数量、倒排链长度分布、磁盘和内存占用按 worker 求和，
+// 最长倒排链取最大值。获取失败的 worker 不计入汇总结果，IndexStats.Workers 是成功汇总的 worker 数量。
+//
+// 返回值:
+//   - *IndexStats: 汇总后的统计信息。
+//   - error: 所有 worker 都获取失败时返回错误。
+func (sentinel *Sentinel) Stats() (*IndexStats, error) {
+	endpoints := sentinel.getEndpoints()
+	if len(endpoints) == 0 {
+		return nil, errors.New("没有可用的 worker")
+	}
+
+	total := new(IndexStats)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(endpoints))
+	for _, endpoint := range endpoints {
+		go func(endpoint string) {
+			defer wg.Done()
+			grpcConn := sentinel.GetGrpcConn(endpoint)
+			if grpcConn == nil {
+				return
+			}
+			client := NewIndexServiceClient(grpcConn)
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 获取统计信息失败: %s", endpoint, err)
+				return
+			}
+			stats, err := client.Stats(context.Background(), new(StatsRequest))
+			sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 获取统计信息失败: %s", endpoint, err)
+				return
+			}
+			mu.Lock()
+			mergeIndexStats(total, stats)
+			mu.Unlock()
+		}(endpoint)
+	}
+	wg.Wait()
+
+	if total.Workers == 0 {
+		return nil, errors.New("从所有 worker 获取统计信息都失败了")
+	}
+	return total, nil
+}
+
+// mergeIndexStats 把一个 worker 的统计信息累加到 total
+func mergeIndexStats(total, stats *IndexStats) {
+	total.DocCount += stats.DocCount
+	total.KeywordCount += stats.KeywordCount
+	for i, n := range stats.PostingListHistogram {
+		if i >= len(total.PostingListHistogram) {
+			total.PostingListHistogram = append(total.PostingListHistogram, 0)
+		}
+		total.PostingListHistogram[i] += n
+	}
+	if stats.MaxPostingListLength > total.MaxPostingListLength {
+		total.MaxPostingListLength = stats.MaxPostingListLength
+	}
+	total.ForwardIndexBytes += stats.ForwardIndexBytes
+	total.MemoryBytes += stats.MemoryBytes
+	total.Workers += stats.Workers
+	total.CompactRuns += stats.CompactRuns
+	total.CompactErrors += stats.CompactErrors
+	total.ReclaimedBytes += stats.ReclaimedBytes
+	if stats.LastCompactAt > total.LastCompactAt {
+		total.LastCompactAt = stats.LastCompactAt
+	}
+	total.Fields = mergeFieldTerms(total.Fields, stats.Fields)
+}
+
+// mergeFieldTerms 合并两个按字段名排序的字段目录，同一字段的keyword数量相加
+func mergeFieldTerms(a, b []*FieldTerms) []*FieldTerms {
+	merged := make([]*FieldTerms, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && a[i].Field < b[j].Field):
+			merged = append(merged, a[i])
+			i++
+		case i == len(a) || b[j].Field < a[i].Field:
+			merged = append(merged, b[j])
+			j++
+		default:
+			merged = append(merged, &FieldTerms{Field: a[i].Field, TermCount: a[i].TermCount + b[j].TermCount})
+			i++
+			j++
+		}
+	}
+	return merged
+}
+
+// DidYouMean 从所有 worker 的词典中查找与 word 相近的词并合并：同一个词的文档数量求和，合并后重新排序再截取前 limit 个。
+// 为了按整个集群的文档数量排序，向 worker 请求时不限制候选数量（编辑距离的限制已经让候选很少）。
+// 获取失败的 worker 不计入结果。
+//
+// 参数:
+//   - field: 在哪个字段的词典中查找。
+//   - word: 待纠正的词。
+//   - limit: 最多返回的候选数量，<=0 表示不限制。
+//
+// 返回值:
+//   - []*Correction: 按编辑距离从小到大、文档数量从多到少排序的候选词。
+//   - error: 所有 worker 都获取失败时返回错误。
+func (sentinel *Sentinel) DidYouMean(field, word string, limit int) ([]*Correction, error) {
+	endpoints := sentinel.getEndpoints()
+	if len(endpoints) == 0 {
+		return nil, errors.New("没有可用的 worker")
+	}
+
+	merged := make(map[string]*Correction)
+	succeeded := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(endpoints))
+	for _, endpoint := range endpoints {
+		go func(endpoint string) {
+			defer wg.Done()
+			grpcConn := sentinel.GetGrpcConn(endpoint)
+			if grpcConn == nil {
+				return
+			}
+			client := NewIndexServiceClient(grpcConn)
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 获取纠错候选失败: %s", endpoint, err)
+				return
+			}
+			result, err := client.DidYouMean(context.Background(), &DidYouMeanRequest{Field: field, Word: word})
+			sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 获取纠错候选失败: %s", endpoint, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			succeeded++
+			for _, correction := range result.Corrections {
+				if exist, ok := merged[correction.Word]; ok {
+					exist.DocFreq += correction.DocFreq
+				} else {
+					merged[correction.Word] = correction
+				}
+			}
+		}(endpoint)
+	}
+	wg.Wait()
+
+	if succeeded == 0 {
+		return nil, errors.New("从所有 worker 获取纠错候选都失败了")
+	}
+	corrections := make([]*Correction, 0, len(merged))
+	for _, correction := range merged {
+		corrections = append(corrections, correction)
+	}
+	return sortCorrections(corrections, limit), nil
+}
+
+// suggestTimeout 自动补全时等待每个 worker 的最长时间，超时的 worker 不计入结果，避免个别慢节点拖慢用户输入时的响应
+const suggestTimeout = 200 * time.Millisecond
+
+// Suggest 从所有 worker 获取以 prefix 开头的词并合并：同一字段的同一个词的文档数量求和，合并后重新排序再截取前 limit 个。
+// 每个 worker 只返回本地文档数量最多的词，为了减少合并后的误差，向每个 worker 请求 2*limit 个候选（不超过 MaxSuggestLimit）。
+// 获取失败或超时的 worker 不计入结果。
+//
+// 参数:
+//   - prefix: 用户已经输入的前缀，为空时不返回结果。
+//   - fields: 在哪些字段中补全，为空表示 worker 上所有设置了自动补全的字段。
+//   - limit: 最多返回的候选数量，<=0 时使用 DefaultSuggestLimit，超过 MaxSuggestLimit 时使用 MaxSuggestLimit。
+//
+// 返回值:
+//   - []*Suggestion: 按文档数量从多到少排序的候选词。
+//   - error: 所有 worker 都获取失败时返回错误。
+func (sentinel *Sentinel) Suggest(prefix string, fields []string, limit int) ([]*Suggestion, error) {
+	if len(prefix) == 0 {
+		return nil, nil
+	}
+	limit = suggestLimit(limit)
+	endpoints := sentinel.getEndpoints()
+	if len(endpoints) == 0 {
+		return nil, errors.New("没有可用的 worker")
+	}
+
+	// worker 拒绝超过 MaxSuggestLimit 的请求
+	perWorker := 2 * limit
+	if perWorker > MaxSuggestLimit {
+		perWorker = MaxSuggestLimit
+	}
+	request := &SuggestRequest{Prefix: prefix, Fields: fields, Limit: int32(perWorker)}
+	merged := make(map[string]*Suggestion)
+	succeeded := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wg.Add(len(endpoints))
+	for _, endpoint := range endpoints {
+		go func(endpoint string) {
+			defer wg.Done()
+			grpcConn := sentinel.GetGrpcConn(endpoint)
+			if grpcConn == nil {
+				return
+			}
+			client := NewIndexServiceClient(grpcConn)
+			begin, err := sentinel.beginRequest(endpoint)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 获取补全候选失败: %s", endpoint, err)
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), suggestTimeout)
+			defer cancel()
+			result, err := client.Suggest(ctx, request)
+			sentinel.selector.ReportResult(endpoint, time.Since(begin), err)
+			if err != nil {
+				utils.Log.Printf("从 worker %s 获取补全候选失败: %s", endpoint, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			succeeded++
+			for _, suggestion := range result.Suggestions {
+				key := suggestion.Field + "\001" + suggestion.Word
+				if exist, ok := merged[key]; ok {
+					exist.Weight += suggestion.Weight
+				} else {
+					merged[key] = suggestion
+				}
+			}
+		}(endpoint)
+	}
+	wg.Wait()
+
+	if succeeded == 0 {
+		return nil, errors.New("从所有 worker 获取补全候选都失败了")
+	}
+	suggestions := make([]*Suggestion, 0, len(merged))
+	for _, suggestion := range merged {
+		suggestions = append(suggestions, suggestion)
+	}
+	return sortSuggestions(suggestions, limit), nil
+}
+
 // Close 关闭各个grpc client连接，关闭etcd client连接
 func (sentinel *Sentinel) Close() (err error) {
+	if sentinel.stopWatching != nil {
+		sentinel.stopWatching()
+		<-sentinel.watchDone
+	}
 	sentinel.connPool.Range(func(key, value any) bool {
 		conn := value.(*grpc.ClientConn)
 		err = conn.Close()
@@ -3,7 +3,7 @@ package service_hub
 import (
 	"context"
 	"errors"
-	"github.com/jmh000527/criker-search/index_service/load_balancer"
+	"fmt"
 	"github.com/jmh000527/criker-search/utils"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	etcdv3 "go.etcd.io/etcd/client/v3"
@@ -15,10 +15,8 @@ import (
 // EtcdServiceHub 服务注册中心，使用单例模式构造。
 // 该服务用于与etcd进行交互，管理服务的注册、注销以及心跳续约等功能。
 type EtcdServiceHub struct {
-	client             *etcdv3.Client             // etcd客户端，用于与etcd进行操作
-	heartbeatFrequency int64                      // 服务续约的心跳频率，单位：秒
-	watched            sync.Map                   // 存储已经监视的服务，以避免重复监视
-	loadBalancer       load_balancer.LoadBalancer // 负载均衡策略的接口，支持多种负载均衡实现
+	client             *etcdv3.Client // etcd客户端，用于与etcd进行操作
+	heartbeatFrequency int64          // 服务续约的心跳频率，单位：秒
 }
 
 const (
@@ -55,9 +53,8 @@ func GetServiceHub(etcdServers []string, heartbeatFrequency int64) *EtcdServiceH
 
 			// 初始化一个新的EtcdServiceHub实例
 			etcdServiceHub = &EtcdServiceHub{
-				client:             client,                      // 设置etcd客户端
-				heartbeatFrequency: heartbeatFrequency,          // 设置心跳频率
-				loadBalancer:       &load_balancer.RoundRobin{}, // 使用Round-Robin负载均衡策略
+				client:             client,             // 设置etcd客户端
+				heartbeatFrequency: heartbeatFrequency, // 设置心跳频率
 			}
 		})
 	}
@@ -66,7 +63,7 @@ func GetServiceHub(etcdServers []string, heartbeatFrequency int64) *EtcdServiceH
 	return etcdServiceHub
 }
 
-// RegisterService 注册服务。
+// RegisterService 注册服务，使用默认的元数据。
 // 第一次注册时，会向etcd写入一个key，并创建一个租约；后续注册仅进行续约。
 //
 // 参数:
@@ -75,9 +72,25 @@ func GetServiceHub(etcdServers []string, heartbeatFrequency int64) *EtcdServiceH
 //   - leaseId: 租约ID，第一次注册时应置为0。
 //
 // 返回值:
-//   - etcdv3.LeaseID: 返回租约ID。
+//   - int64: 返回租约ID。
 //   - error: 返回错误信息，如果操作成功则为nil。
-func (hub *EtcdServiceHub) RegisterService(service, endpoint string, leaseId etcdv3.LeaseID) (etcdv3.LeaseID, error) {
+func (hub *EtcdServiceHub) RegisterService(service, endpoint string, leaseId int64) (int64, error) {
+	return hub.RegisterServiceWithMeta(service, endpoint, leaseId, ServiceMeta{})
+}
+
+// RegisterServiceWithMeta 注册服务，并把元数据（如权重）以JSON格式写入key对应的value。
+// 第一次注册时，会向etcd写入一个key，并创建一个租约；后续注册仅进行续约。
+//
+// 参数:
+//   - service: 微服务的名称。
+//   - endpoint: 微服务服务器的地址。
+//   - leaseId: 租约ID，第一次注册时应置为0。
+//   - meta: 服务的元数据，租约丢失重新注册时会再次写入。
+//
+// 返回值:
+//   - int64: 返回租约ID。
+//   - error: 返回错误信息，如果操作成功则为nil。
+func (hub *EtcdServiceHub) RegisterServiceWithMeta(service, endpoint string, leaseId int64, meta ServiceMeta) (int64, error) {
 	// 检查是否为首次注册（租约ID是否小于等于0）
 	if leaseId <= 0 {
 		// 首次注册: 创建一个新的租约，租约的有效期为heartbeatFrequency秒
@@ -90,22 +103,22 @@ func (hub *EtcdServiceHub) RegisterService(service, endpoint string, leaseId etc
 		// 构建服务在etcd中的key，路径形如: /{ServiceRootPath}/{service}/{endpoint}
 		key := strings.TrimRight(ServiceRootPath, "/") + "/" + service + "/" + endpoint
 		// 将服务注册到etcd中，并将租约与该服务绑定
-		_, err = hub.client.Put(context.Background(), key, "", etcdv3.WithLease(leaseGrantResponse.ID))
+		_, err = hub.client.Put(context.Background(), key, meta.encode(), etcdv3.WithLease(leaseGrantResponse.ID))
 		if err != nil {
 			// 如果注册服务失败，记录错误并返回
 			utils.Log.Printf("服务注册失败: %v", err)
-			return leaseGrantResponse.ID, err
+			return int64(leaseGrantResponse.ID), err
 		}
 		utils.Log.Printf("成功注册服务: %v", key)
 		// 返回新的租约ID
-		return leaseGrantResponse.ID, nil
+		return int64(leaseGrantResponse.ID), nil
 	} else {
 		// 续约: 通过租约ID进行续租操作
-		_, err := hub.client.KeepAliveOnce(context.Background(), leaseId)
+		_, err := hub.client.KeepAliveOnce(context.Background(), etcdv3.LeaseID(leaseId))
 		if errors.Is(err, rpctypes.ErrLeaseNotFound) {
 			// 如果续租时发现租约不存在，则重新注册服务，将leaseID置为0重新进行注册
 			utils.Log.Printf("未找到租约，重新注册服务")
-			return hub.RegisterService(service, endpoint, 0)
+			return hub.RegisterServiceWithMeta(service, endpoint, 0, meta)
 		} else if err != nil {
 			// 如果续租过程中发生其他错误，记录错误并返回
 			utils.Log.Printf("续租失败: %v", err)
@@ -142,51 +155,124 @@ func (hub *EtcdServiceHub) UnregisterService(service string, endpoint string) er
 	return nil
 }
 
-// GetServiceEndpoints 服务发现。
-// 从etcd中查询指定服务的所有endpoint，并返回这些endpoint的列表。
+// KeepAlive 使用etcd的流式KeepAlive持续为租约续约，直到ctx被取消或租约丢失。
+// 与反复调用KeepAliveOnce相比，只需维持一个gRPC流，续约时机由etcd客户端根据租约的TTL自动控制。
+//
+// 参数:
+//   - ctx: 取消ctx即停止续约，租约会在TTL之后过期。
+//   - leaseId: RegisterService返回的租约ID。
+//
+// 返回值:
+//   - error: ctx被取消时返回nil；租约已过期、被撤销或续约流中断时返回ErrLeaseLost，调用方应重新注册。
+func (hub *EtcdServiceHub) KeepAlive(ctx context.Context, leaseId int64) error {
+	keepAliveChan, err := hub.client.KeepAlive(ctx, etcdv3.LeaseID(leaseId))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		utils.Log.Printf("续约租约 %d 失败: %v", leaseId, err)
+		return ErrLeaseLost
+	}
+	// 消费续约响应，channel关闭说明ctx被取消或者租约已失效
+	for range keepAliveChan {
+	}
+	if ctx.Err() != nil {
+		return nil
+	}
+	utils.Log.Printf("租约 %d 的续约中断", leaseId)
+	return ErrLeaseLost
+}
+
+// UpdateServiceMeta 更新已注册服务的元数据，例如定期刷新文档数量。
+//
 // 参数:
 //   - service: 微服务的名称。
+//   - endpoint: 微服务服务器的地址。
+//   - leaseId: 注册时返回的租约ID，更新后的key仍绑定在该租约上。
+//   - meta: 新的元数据。
 //
 // 返回值:
-//   - []string: 包含所有服务endpoint的列表。如果查询失败，则返回nil。
-func (hub *EtcdServiceHub) GetServiceEndpoints(service string) []string {
-	// 构造服务的key前缀，用于获取服务的所有endpoint
-	prefix := strings.TrimRight(ServiceRootPath, "/") + "/" + service + "/"
+//   - error: 返回错误信息，如果操作成功则为nil。
+func (hub *EtcdServiceHub) UpdateServiceMeta(service, endpoint string, leaseId int64, meta ServiceMeta) error {
+	if leaseId <= 0 {
+		return fmt.Errorf("服务 %s/%s 尚未注册，无法更新元数据", service, endpoint)
+	}
+	key := strings.TrimRight(ServiceRootPath, "/") + "/" + service + "/" + endpoint
+	_, err := hub.client.Put(context.Background(), key, meta.encode(), etcdv3.WithLease(etcdv3.LeaseID(leaseId)))
+	if err != nil {
+		utils.Log.Printf("更新服务元数据失败: %v", err)
+		return err
+	}
+	return nil
+}
 
-	// 从etcd中获取以指定前缀为开头的所有key-value对
-	getResponse, err := hub.client.Get(context.Background(), prefix, etcdv3.WithPrefix())
+// GetServiceInstances 服务发现。
+// 从etcd中查询指定服务的所有实例，包括endpoint以及注册时发布的元数据（版本、分片、角色、权重、文档数量等）。
+//
+// 参数:
+//   - service: 微服务的名称。
+//
+// 返回值:
+//   - []ServiceInstance: 所有服务实例的列表。如果查询失败，则返回nil。
+func (hub *EtcdServiceHub) GetServiceInstances(service string) []ServiceInstance {
+	instances, _, err := hub.fetchServiceInstances(service)
 	if err != nil {
 		// 如果获取服务endpoint失败，记录错误并返回nil
 		utils.Log.Printf("从etcd获取服务端点失败: %v", err)
 		return nil
 	}
+	return instances
+}
+
+// fetchServiceInstances 从etcd中查询指定服务的所有实例，同时返回查询时etcd的revision，
+// 从revision+1开始watch即可不遗漏、不重复地获得之后的所有变化。
+func (hub *EtcdServiceHub) fetchServiceInstances(service string) ([]ServiceInstance, int64, error) {
+	// 构造服务的key前缀，用于获取服务的所有endpoint
+	prefix := servicePrefix(service)
+
+	// 从etcd中获取以指定前缀为开头的所有key-value对
+	getResponse, err := hub.client.Get(context.Background(), prefix, etcdv3.WithPrefix())
+	if err != nil {
+		return nil, 0, err
+	}
 
-	// 构造返回的endpoint列表
-	endpoints := make([]string, 0, len(getResponse.Kvs))
+	// 构造返回的实例列表
+	instances := make([]ServiceInstance, 0, len(getResponse.Kvs))
 	for _, kv := range getResponse.Kvs {
-		// 从key中提取endpoint
-		path := strings.Split(string(kv.Key), "/")
-		endpoints = append(endpoints, path[len(path)-1])
+		// 从key中提取endpoint，从value中解析元数据
+		instances = append(instances, ServiceInstance{Endpoint: endpointOfKey(kv.Key), ServiceMeta: decodeServiceMeta(kv.Value)})
 	}
+	return instances, getResponse.Header.Revision, nil
+}
 
-	// 记录获取到的服务endpoint
-	utils.Log.Printf("最新的服务端点: %v", endpoints)
-	return endpoints
+// servicePrefix 服务在etcd中的key前缀，形如: /{ServiceRootPath}/{service}/
+func servicePrefix(service string) string {
+	return strings.TrimRight(ServiceRootPath, "/") + "/" + service + "/"
 }
 
-// GetServiceEndpoint 根据负载均衡策略从服务端点中选择一个。
-// 通过调用负载均衡策略的Take方法，从获取的服务端点列表中选择一个。
-//
+// endpointOfKey 从etcd的key中提取endpoint
+func endpointOfKey(key []byte) string {
+	path := strings.Split(string(key), "/")
+	return path[len(path)-1]
+}
+
+// GetServiceEndpoints 服务发现。
+// 从etcd中查询指定服务的所有endpoint，并返回这些endpoint的列表。
 // 参数:
 //   - service: 微服务的名称。
 //
 // 返回值:
-//   - string: 选择的服务端点地址。
-func (hub *EtcdServiceHub) GetServiceEndpoint(service string) string {
-	// 获取指定服务的所有端点
-	endpoints := hub.GetServiceEndpoints(service)
-	// 使用负载均衡策略选择一个端点
-	return hub.loadBalancer.Take(endpoints)
+//   - []string: 包含所有服务endpoint的列表。如果查询失败，则返回nil。
+func (hub *EtcdServiceHub) GetServiceEndpoints(service string) []string {
+	instances := hub.GetServiceInstances(service)
+	if instances == nil {
+		return nil
+	}
+	endpoints := endpointsOf(instances)
+
+	// 记录获取到的服务endpoint
+	utils.Log.Printf("最新的服务端点: %v", endpoints)
+	return endpoints
 }
 
 // Close 关闭etcd客户端连接。
@@ -0,0 +1,179 @@
+package service_hub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/jmh000527/criker-search/utils"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileEndpoint endpoint文件中的一项
+type FileEndpoint struct {
+	Endpoint string `json:"endpoint" yaml:"endpoint"`                 // 服务地址
+	Weight   int    `json:"weight,omitempty" yaml:"weight,omitempty"` // 权重，<=0 表示使用默认权重
+	ShardId  int    `json:"shard_id" yaml:"shard_id"`                 // 分片编号
+	Role     string `json:"role,omitempty" yaml:"role,omitempty"`     // 副本角色，RolePrimary或RoleReplica
+}
+
+// meta 文件中可配置的元数据
+func (item FileEndpoint) meta() ServiceMeta {
+	return ServiceMeta{ShardId: item.ShardId, Role: item.Role, Weight: item.Weight}
+}
+
+// FileServiceHub 从文件读取endpoint的服务注册中心，文件变化后自动重新加载。
+// 文件为JSON或YAML格式（按扩展名.json/.yaml/.yml区分），内容为 服务名称 -> endpoint列表，例如:
+//
+//	index_service:
+//	  - endpoint: 127.0.0.1:5600
+//	    weight: 2
+//	  - endpoint: 127.0.0.1:5601
+//
+// endpoint列表由运维维护，worker的注册和注销不会修改文件。
+type FileServiceHub struct {
+	path     string
+	mu       sync.RWMutex
+	services map[string][]FileEndpoint // 最近一次成功加载的内容
+	modTime  time.Time                 // 最近一次加载时文件的修改时间
+	size     int64                     // 最近一次加载时文件的大小
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFileServiceHub 创建一个从文件读取endpoint的服务注册中心，并在后台定期检查文件是否变化。
+//
+// 参数:
+//   - path: endpoint文件的路径。
+//   - reloadInterval: 检查文件变化的间隔，<=0 时为1秒。
+//
+// 返回值:
+//   - *FileServiceHub: 新创建的FileServiceHub实例。
+//   - error: 首次加载文件失败时返回错误。
+func NewFileServiceHub(path string, reloadInterval time.Duration) (*FileServiceHub, error) {
+	if reloadInterval <= 0 {
+		reloadInterval = time.Second
+	}
+	hub := &FileServiceHub{
+		path: path,
+		stop: make(chan struct{}),
+	}
+	if err := hub.reload(); err != nil {
+		return nil, err
+	}
+	go hub.watch(reloadInterval)
+	return hub, nil
+}
+
+// watch 定期检查文件的修改时间和大小，变化时重新加载
+func (hub *FileServiceHub) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-hub.stop:
+			return
+		case <-ticker.C:
+			info, err := os.Stat(hub.path)
+			if err != nil {
+				utils.Log.Printf("读取endpoint文件 %s 失败: %v", hub.path, err)
+				continue
+			}
+			hub.mu.RLock()
+			changed := !info.ModTime().Equal(hub.modTime) || info.Size() != hub.size
+			hub.mu.RUnlock()
+			if changed {
+				// 加载失败时继续使用上一次成功加载的内容
+				if err := hub.reload(); err != nil {
+					utils.Log.Printf("重新加载endpoint文件失败，继续使用旧的endpoint列表: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// reload 读取并解析endpoint文件
+func (hub *FileServiceHub) reload() error {
+	info, err := os.Stat(hub.path)
+	if err != nil {
+		return fmt.Errorf("读取endpoint文件 %s 失败: %v", hub.path, err)
+	}
+	content, err := os.ReadFile(hub.path)
+	if err != nil {
+		return fmt.Errorf("读取endpoint文件 %s 失败: %v", hub.path, err)
+	}
+	services := make(map[string][]FileEndpoint)
+	switch strings.ToLower(filepath.Ext(hub.path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &services)
+	default:
+		err = json.Unmarshal(content, &services)
+	}
+	if err != nil {
+		return fmt.Errorf("解析endpoint文件 %s 失败: %v", hub.path, err)
+	}
+
+	hub.mu.Lock()
+	hub.services = services
+	hub.modTime = info.ModTime()
+	hub.size = info.Size()
+	hub.mu.Unlock()
+	utils.Log.Printf("从文件 %s 加载endpoint: %v", hub.path, services)
+	return nil
+}
+
+// RegisterService endpoint列表由文件维护，注册不做任何操作，原样返回leaseID
+func (hub *FileServiceHub) RegisterService(service, endpoint string, leaseID int64) (int64, error) {
+	return hub.RegisterServiceWithMeta(service, endpoint, leaseID, ServiceMeta{})
+}
+
+// RegisterServiceWithMeta endpoint列表由文件维护，注册不做任何操作，原样返回leaseID
+func (hub *FileServiceHub) RegisterServiceWithMeta(service, endpoint string, leaseID int64, meta ServiceMeta) (int64, error) {
+	if leaseID <= 0 {
+		utils.Log.Printf("endpoint列表由文件 %s 维护，忽略服务注册: %s/%s", hub.path, service, endpoint)
+	}
+	return leaseID, nil
+}
+
+// UnregisterService endpoint列表由文件维护，注销不做任何操作
+func (hub *FileServiceHub) UnregisterService(service, endpoint string) error {
+	return nil
+}
+
+// KeepAlive endpoint列表由文件维护，无需续约，阻塞直到ctx被取消
+func (hub *FileServiceHub) KeepAlive(ctx context.Context, leaseID int64) error {
+	<-ctx.Done()
+	return nil
+}
+
+// UpdateServiceMeta endpoint列表由文件维护，不保存worker发布的元数据
+func (hub *FileServiceHub) UpdateServiceMeta(service, endpoint string, leaseID int64, meta ServiceMeta) error {
+	return nil
+}
+
+// GetServiceInstances 服务发现，返回最近一次成功加载的服务实例，元数据取自文件
+func (hub *FileServiceHub) GetServiceInstances(service string) []ServiceInstance {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	instances := make([]ServiceInstance, 0, len(hub.services[service]))
+	for _, item := range hub.services[service] {
+		instances = append(instances, ServiceInstance{Endpoint: item.Endpoint, ServiceMeta: item.meta()})
+	}
+	return instances
+}
+
+// GetServiceEndpoints 服务发现，返回最近一次成功加载的endpoint列表
+func (hub *FileServiceHub) GetServiceEndpoints(service string) []string {
+	return endpointsOf(hub.GetServiceInstances(service))
+}
+
+// Close 停止检查文件变化
+func (hub *FileServiceHub) Close() {
+	hub.stopOnce.Do(func() {
+		close(hub.stop)
+	})
+}
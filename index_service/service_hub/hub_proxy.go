@@ -3,9 +3,10 @@ package service_hub
 import (
 	"context"
 	"github.com/jmh000527/criker-search/utils"
+	"go.etcd.io/etcd/api/v3/mvccpb"
 	etcdv3 "go.etcd.io/etcd/client/v3"
 	"golang.org/x/time/rate"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 )
@@ -15,14 +16,31 @@ import (
 //
 // 成员变量:
 //   - EtcdServiceHub: 真实的ServiceHub实例，用于实际的服务发现和注册。
-//   - endpointCache: 用于缓存服务端点的同步映射。
-//   - limiter: 限流器，用于控制每秒请求的最大次数。
+//   - endpointCache: 用于缓存服务实例的同步映射，由watch事件增量更新。
+//   - limiter: 限流器，用于控制每秒访问etcd的最大次数。
+//   - listeners: 服务实例变化时的回调，只在watch事件或全量同步改变了缓存时调用。
 type HubProxy struct {
-	*EtcdServiceHub               // 真实的ServiceHub实例
-	endpointCache   sync.Map      // 缓存服务端点
-	limiter         *rate.Limiter // 限流器
+	*EtcdServiceHub                    // 真实的ServiceHub实例
+	endpointCache   sync.Map           // 缓存服务实例，service -> *serviceCache
+	limiter         *rate.Limiter      // 限流器
+	ctx             context.Context    // 所有watch共用的context，Close时取消
+	cancel          context.CancelFunc // 取消所有watch
+
+	listenerMu sync.RWMutex
+	listeners  []InstanceListener // 服务实例变化时的回调
+}
+
+// serviceCache 单个服务的实例缓存，以及缓存对应的etcd revision
+type serviceCache struct {
+	mu        sync.RWMutex
+	loaded    bool                   // 是否已经从etcd加载过
+	instances map[string]ServiceMeta // endpoint -> 元数据
+	revision  int64                  // 缓存已包含revision及之前的所有变化
 }
 
+// watchRetryInterval watch失败或etcd不可用时，重新同步之前等待的时间
+const watchRetryInterval = time.Second
+
 var (
 	hubProxy  *HubProxy
 	proxyOnce sync.Once
@@ -40,12 +58,15 @@ var (
 func GetServiceHubProxy(etcdServers []string, heartbeatFrequency int64, qps int) *HubProxy {
 	if hubProxy == nil {
 		proxyOnce.Do(func() {
+			ctx, cancel := context.WithCancel(context.Background())
 			// 初始化HubProxy实例
 			hubProxy = &HubProxy{
 				EtcdServiceHub: GetServiceHub(etcdServers, heartbeatFrequency),
 				endpointCache:  sync.Map{},
 				// 配置限流器：每秒产生qps个令牌
 				limiter: rate.NewLimiter(rate.Every(time.Duration(1e9/qps)*time.Nanosecond), qps),
+				ctx:     ctx,
+				cancel:  cancel,
 			}
 
 		})
@@ -56,7 +77,7 @@ func GetServiceHubProxy(etcdServers []string, heartbeatFrequency int64, qps int)
 // 以下方法由EtcdServiceHub匿名变量提供
 
 //// RegisterService 注册服务
-//func (p *HubProxy) RegisterService(service, endpoint string, leaseId etcdv3.LeaseID) (etcdv3.LeaseID, error) {
+//func (p *HubProxy) RegisterService(service, endpoint string, leaseId int64) (int64, error) {
 //	return p.EtcdServiceHub.RegisterService(service, endpoint, leaseId)
 //}
 //
@@ -64,85 +85,236 @@ func GetServiceHubProxy(etcdServers []string, heartbeatFrequency int64, qps int)
 //func (p *HubProxy) UnregisterService(service, endpoint string) error {
 //	return p.EtcdServiceHub.UnregisterService(service, endpoint)
 //}
+
+// GetServiceInstances 服务发现。第一次查询etcd后把结果缓存起来，并安装一个Watcher，按etcd的PUT/DELETE事件增量更新本地缓存，
+// 这样可以降低etcd的访问压力，同时加上限流保护。
+// 缓存加载之后直接返回缓存，不受限流影响；只有缓存尚未加载且被限流时才返回nil。
 //
-//// GetServiceEndpoint 根据负载均衡策略，从众多endpoint里选择一个
-//func (p *HubProxy) GetServiceEndpoint(service string) string {
-//	return p.EtcdServiceHub.GetServiceEndpoint(service)
-//}
+// 参数:
+//   - service: 需要获取实例的服务名称。
+//
+// 返回值:
+//   - []ServiceInstance: 按endpoint排序的服务实例列表。
+func (p *HubProxy) GetServiceInstances(service string) []ServiceInstance {
+	cache := p.cacheOf(service)
+	if instances, loaded := p.snapshot(cache); loaded {
+		return instances
+	}
 
-// GetServiceEndpoints 服务发现。把第一次查询etcd的结果缓存起来，然后安装一个Watcher，仅etcd数据变化时更新本地缓存，这样可以降低etcd的访问压力，同时加上限流保护。
+	// 缓存尚未加载，需要查询etcd，受限流保护
+	if !p.limiter.Allow() {
+		utils.Log.Printf("服务 %s 的端点缓存尚未加载，查询etcd被限流", service)
+		return nil
+	}
+	if _, err := p.resync(service, cache); err != nil {
+		utils.Log.Printf("从etcd获取服务端点失败: %v", err)
+		return nil
+	}
+	instances, _ := p.snapshot(cache)
+	return instances
+}
+
+// GetServiceEndpoints 服务发现，返回缓存中的endpoint列表，详见GetServiceInstances。
 //
 // 参数:
 //   - service: 需要获取端点的服务名称。
 //
 // 返回值:
-//   - []string: 返回服务端点的列表。如果限流未通过或发生错误，则返回nil。
+//   - []string: 返回服务端点的列表。如果缓存尚未加载且被限流或查询etcd失败，则返回nil。
 func (p *HubProxy) GetServiceEndpoints(service string) []string {
-	// 限流检查：如果限流器不允许请求，则直接返回nil
-	if !p.limiter.Allow() {
+	instances := p.GetServiceInstances(service)
+	if instances == nil {
 		return nil
 	}
+	return endpointsOf(instances)
+}
+
+// OnInstanceChange 注册服务实例变化时的回调，注册时先用已缓存的实例调用一次。
+// 之后只有watch事件或全量同步真正改变了缓存（实例上线、下线或元数据变化）时才调用，读取缓存不会触发回调。
+//
+// 参数:
+//   - listener: 回调函数，在持有缓存的锁时被调用，不能再访问HubProxy。
+func (p *HubProxy) OnInstanceChange(listener InstanceListener) {
+	p.listenerMu.Lock()
+	p.listeners = append(p.listeners, listener)
+	p.listenerMu.Unlock()
+	p.endpointCache.Range(func(key, value any) bool {
+		cache := value.(*serviceCache)
+		cache.mu.RLock()
+		defer cache.mu.RUnlock()
+		for endpoint, meta := range cache.instances {
+			listener(key.(string), ServiceInstance{Endpoint: endpoint, ServiceMeta: meta}, false)
+		}
+		return true
+	})
+}
+
+// notify 通知所有监听函数服务实例发生了变化，调用方需持有缓存的锁
+func (p *HubProxy) notify(service string, instance ServiceInstance, removed bool) {
+	p.listenerMu.RLock()
+	defer p.listenerMu.RUnlock()
+	for _, listener := range p.listeners {
+		listener(service, instance, removed)
+	}
+}
+
+// Close 停止所有watch，并关闭etcd客户端连接
+func (p *HubProxy) Close() {
+	p.cancel()
+	p.EtcdServiceHub.Close()
+}
+
+// cacheOf 获取服务的缓存。第一次获取时创建缓存，并启动一个协程监视该服务的变化。
+func (p *HubProxy) cacheOf(service string) *serviceCache {
+	if v, exists := p.endpointCache.Load(service); exists {
+		return v.(*serviceCache)
+	}
+	v, exists := p.endpointCache.LoadOrStore(service, &serviceCache{instances: make(map[string]ServiceMeta)})
+	cache := v.(*serviceCache)
+	if !exists {
+		go p.watchEndpointsOfService(service, cache)
+	}
+	return cache
+}
 
-	// 更新服务端点缓存的Watcher
-	p.watchEndpointsOfService(service)
+// snapshot 返回缓存中按endpoint排序的服务实例，以及缓存是否已经加载
+func (p *HubProxy) snapshot(cache *serviceCache) ([]ServiceInstance, bool) {
+	cache.mu.RLock()
+	defer cache.mu.RUnlock()
+	if !cache.loaded {
+		return nil, false
+	}
+	instances := make([]ServiceInstance, 0, len(cache.instances))
+	for endpoint, meta := range cache.instances {
+		instances = append(instances, ServiceInstance{Endpoint: endpoint, ServiceMeta: meta})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Endpoint < instances[j].Endpoint })
+	return instances, true
+}
 
-	// 尝试从缓存中加载服务端点
-	cachedEndpoints, ok := p.endpointCache.Load(service)
-	if !ok {
-		// 如果缓存中没有服务端点，查询etcd获取最新端点
-		endpoints := p.EtcdServiceHub.GetServiceEndpoints(service)
-		if len(endpoints) > 0 {
-			// 如果查询到端点，将其存入缓存
-			p.endpointCache.Store(service, endpoints)
+// resync 从etcd全量加载服务的实例，替换缓存，返回加载时的revision。
+// 缓存已包含更新的revision时（watch事件先于本次查询到达）不替换。
+func (p *HubProxy) resync(service string, cache *serviceCache) (int64, error) {
+	instances, revision, err := p.fetchServiceInstances(service)
+	if err != nil {
+		return 0, err
+	}
+	return p.replace(service, cache, instances, revision), nil
+}
+
+// replace 用revision时的全量实例替换缓存，返回缓存的revision。与旧缓存相比上线、下线或元数据变化的实例会通知监听函数。
+// 缓存已包含更新的revision时不替换。
+func (p *HubProxy) replace(service string, cache *serviceCache, instances []ServiceInstance, revision int64) int64 {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.loaded && cache.revision >= revision {
+		return cache.revision
+	}
+	old := cache.instances
+	cache.instances = make(map[string]ServiceMeta, len(instances))
+	for _, instance := range instances {
+		cache.instances[instance.Endpoint] = instance.ServiceMeta
+		if meta, exists := old[instance.Endpoint]; !exists || meta != instance.ServiceMeta {
+			p.notify(service, instance, false)
 		}
-		return endpoints
 	}
-	// 如果缓存中已有服务端点，直接返回缓存结果。缓存的一致性由watchEndpointsOfService()函数保证。
-	return cachedEndpoints.([]string)
+	for endpoint, meta := range old {
+		if _, exists := cache.instances[endpoint]; !exists {
+			p.notify(service, ServiceInstance{Endpoint: endpoint, ServiceMeta: meta}, true)
+		}
+	}
+	cache.revision = revision
+	cache.loaded = true
+	utils.Log.Printf("服务 %s 的端点缓存已同步到revision %d: %v", service, revision, endpointsOf(instances))
+	return revision
+}
+
+// apply 把watch到的事件增量应用到缓存，已包含在缓存中的事件（revision不大于缓存的revision）会被跳过。
+// 上线、下线或元数据变化的实例会通知监听函数，重复的PUT（例如续约之外内容不变的更新）不会
+func (p *HubProxy) apply(service string, cache *serviceCache, response etcdv3.WatchResponse) {
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for _, event := range response.Events {
+		if event.Kv.ModRevision <= cache.revision {
+			continue
+		}
+		endpoint := endpointOfKey(event.Kv.Key)
+		switch event.Type {
+		case mvccpb.PUT:
+			meta := decodeServiceMeta(event.Kv.Value)
+			if old, exists := cache.instances[endpoint]; exists && old == meta {
+				continue
+			}
+			cache.instances[endpoint] = meta
+			p.notify(service, ServiceInstance{Endpoint: endpoint, ServiceMeta: meta}, false)
+			utils.Log.Printf("服务 %s 的端点 %s 上线或更新", service, endpoint)
+		case mvccpb.DELETE:
+			meta, exists := cache.instances[endpoint]
+			if !exists {
+				continue
+			}
+			delete(cache.instances, endpoint)
+			// 通知调用方节点已下线，例如不再统计其健康状况
+			p.notify(service, ServiceInstance{Endpoint: endpoint, ServiceMeta: meta}, true)
+			utils.Log.Printf("服务 %s 的端点 %s 下线", service, endpoint)
+		}
+	}
+	if response.Header.Revision > cache.revision {
+		cache.revision = response.Header.Revision
+	}
 }
 
 // watchEndpointsOfService 监视服务端点的变化，确保本地缓存与etcd中的数据保持同步。
+// 从缓存的revision+1开始watch，逐个应用PUT/DELETE事件；revision被压缩或watch出错时，全量重新同步后再继续watch。
 //
 // 参数:
 //   - service: 需要监视的服务名称。
-//
-// 该函数将设置一个Watcher来监听etcd中对应服务的变化，并在检测到变化时更新本地缓存。
-func (p *HubProxy) watchEndpointsOfService(service string) {
-	// 检查当前服务是否已经被监听
-	_, ok := p.watched.LoadOrStore(service, true)
-	if ok {
-		// 如果已经监听过，直接返回
-		return
-	}
-
-	// 构建服务的前缀路径
-	prefix := strings.TrimRight(ServiceRootPath, "/") + "/" + service + "/"
-	// 设置etcd Watcher，监视指定前缀的所有键值对的变化
-	watchChan := p.EtcdServiceHub.client.Watch(context.Background(), prefix, etcdv3.WithPrefix())
+//   - cache: 服务的缓存。
+func (p *HubProxy) watchEndpointsOfService(service string, cache *serviceCache) {
+	prefix := servicePrefix(service)
 	utils.Log.Printf("开始监视服务端点: %s", prefix)
+	needResync := false
+	for p.ctx.Err() == nil {
+		cache.mu.RLock()
+		loaded, revision := cache.loaded, cache.revision
+		cache.mu.RUnlock()
+		if !loaded || needResync {
+			// 全量同步。失败时保留旧的缓存，继续对外提供服务
+			r, err := p.resync(service, cache)
+			if err != nil {
+				utils.Log.Printf("同步服务 %s 的端点失败，%v 后重试: %v", service, watchRetryInterval, err)
+				p.sleep(watchRetryInterval)
+				continue
+			}
+			revision, needResync = r, false
+		}
 
-	// 启动一个 goroutine 来异步处理 Watcher 事件
-	go func() {
+		// 设置etcd Watcher，从缓存之后的revision开始监视指定前缀的所有键值对的变化
+		ctx, cancel := context.WithCancel(etcdv3.WithRequireLeader(p.ctx))
+		watchChan := p.client.Watch(ctx, prefix, etcdv3.WithPrefix(), etcdv3.WithRev(revision+1))
 		for response := range watchChan {
-			for _, event := range response.Events {
-				// 记录事件类型（PUT或DELETE）
-				utils.Log.Printf("etcd事件类型: %s", event.Type)
-
-				// 提取服务名称
-				path := strings.Split(string(event.Kv.Key), "/")
-				if len(path) > 2 {
-					service := path[len(path)-2]
-					// 从etcd中获取最新的服务端点列表
-					endpoints := p.EtcdServiceHub.GetServiceEndpoints(service)
-					if len(endpoints) > 0 {
-						// 如果获取到服务端点，更新本地缓存
-						p.endpointCache.Store(service, endpoints)
-					} else {
-						// 如果服务下没有端点，删除本地缓存
-						p.endpointCache.Delete(service)
-					}
-				}
+			if response.CompactRevision != 0 {
+				// 需要的revision已被压缩，事件有缺失，只能全量重新同步
+				utils.Log.Printf("服务 %s 的watch revision %d 已被压缩，重新同步", service, revision+1)
+				break
 			}
+			if err := response.Err(); err != nil {
+				utils.Log.Printf("监视服务 %s 的端点出错，重新同步: %v", service, err)
+				break
+			}
+			p.apply(service, cache, response)
 		}
-	}()
+		cancel()
+		// watch结束（出错、被压缩或与etcd断开）之后可能遗漏了事件，需要全量重新同步
+		needResync = true
+	}
+	utils.Log.Printf("停止监视服务端点: %s", prefix)
+}
+
+// sleep 等待一段时间，Close时提前返回
+func (p *HubProxy) sleep(d time.Duration) {
+	select {
+	case <-p.ctx.Done():
+	case <-time.After(d):
+	}
 }
@@ -2,8 +2,13 @@ package service_hub
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 	"time"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	etcdv3 "go.etcd.io/etcd/client/v3"
 )
 
 var (
@@ -45,3 +50,135 @@ func TestGetServiceEndpointsByProxy(t *testing.T) {
 		fmt.Printf("%d endpoints %v\n", i, endpoints)
 	}
 }
+
+func TestEndpointCacheByProxy(t *testing.T) {
+	const qps = 10
+	p := GetServiceHubProxy(etcdServers, 3, qps)
+
+	endpoint := "127.0.0.4:5000"
+	p.RegisterService(serviceName, endpoint, 0)
+	defer p.UnregisterService(serviceName, endpoint)
+	time.Sleep(100 * time.Millisecond) // 等待watch事件到达
+
+	// 缓存加载之后，即使超过限流也返回缓存的结果
+	for i := 0; i < qps+5; i++ {
+		endpoints := p.GetServiceEndpoints(serviceName)
+		if len(endpoints) == 0 {
+			t.Fatalf("第 %d 次查询返回了空的端点列表", i)
+		}
+	}
+
+	// 注销之后缓存增量更新
+	p.UnregisterService(serviceName, endpoint)
+	time.Sleep(100 * time.Millisecond)
+	for _, e := range p.GetServiceEndpoints(serviceName) {
+		if e == endpoint {
+			t.Fatalf("端点 %s 注销后仍在缓存中", endpoint)
+		}
+	}
+}
+
+// instanceChange 监听函数收到的一次通知
+type instanceChange struct {
+	endpoint string
+	weight   int
+	removed  bool
+}
+
+// recordChanges 在 p 上注册监听函数，返回收到的通知
+func recordChanges(p *HubProxy) *[]instanceChange {
+	changes := new([]instanceChange)
+	p.OnInstanceChange(func(service string, instance ServiceInstance, removed bool) {
+		*changes = append(*changes, instanceChange{instance.Endpoint, instance.Weight, removed})
+	})
+	return changes
+}
+
+// watchEvent 构造一个修改于 revision 的 watch 事件
+func watchEvent(eventType mvccpb.Event_EventType, endpoint string, meta ServiceMeta, revision int64) *etcdv3.Event {
+	kv := &mvccpb.KeyValue{Key: []byte(servicePrefix(serviceName) + endpoint), ModRevision: revision}
+	if eventType == mvccpb.PUT {
+		kv.Value = []byte(meta.encode())
+	}
+	return &etcdv3.Event{Type: eventType, Kv: kv}
+}
+
+// watchResponse 构造一个 revision 时的 watch 响应
+func watchResponse(revision int64, events ...*etcdv3.Event) etcdv3.WatchResponse {
+	return etcdv3.WatchResponse{Header: etcdserverpb.ResponseHeader{Revision: revision}, Events: events}
+}
+
+func TestApply(t *testing.T) {
+	p := &HubProxy{}
+	cache := &serviceCache{instances: make(map[string]ServiceMeta)}
+	p.endpointCache.Store(serviceName, cache)
+	p.replace(serviceName, cache, []ServiceInstance{{Endpoint: "127.0.0.1:5000", ServiceMeta: ServiceMeta{Weight: 1}}}, 10)
+	changes := recordChanges(p)
+	if expect := []instanceChange{{"127.0.0.1:5000", 1, false}}; !reflect.DeepEqual(*changes, expect) {
+		t.Fatalf("注册时应收到已缓存的实例 %v，实际为 %v", expect, *changes)
+	}
+
+	// revision 不大于缓存的事件已包含在缓存中，被跳过
+	*changes = nil
+	p.apply(serviceName, cache, watchResponse(10,
+		watchEvent(mvccpb.PUT, "127.0.0.1:5001", ServiceMeta{Weight: 2}, 9),
+		watchEvent(mvccpb.DELETE, "127.0.0.1:5000", ServiceMeta{}, 10),
+	))
+	if len(cache.instances) != 1 || len(*changes) != 0 || cache.revision != 10 {
+		t.Fatalf("过期的事件不应改变缓存，缓存: %v，通知: %v", cache.instances, *changes)
+	}
+
+	// PUT 增加或更新实例，内容不变的 PUT 不通知；DELETE 删除实例并通知下线
+	p.apply(serviceName, cache, watchResponse(13,
+		watchEvent(mvccpb.PUT, "127.0.0.1:5001", ServiceMeta{Weight: 2}, 11),
+		watchEvent(mvccpb.PUT, "127.0.0.1:5000", ServiceMeta{Weight: 1}, 12),
+		watchEvent(mvccpb.PUT, "127.0.0.1:5001", ServiceMeta{Weight: 3}, 12),
+		watchEvent(mvccpb.DELETE, "127.0.0.1:5000", ServiceMeta{}, 13),
+		watchEvent(mvccpb.DELETE, "127.0.0.1:5002", ServiceMeta{}, 13),
+	))
+	if expect := map[string]ServiceMeta{"127.0.0.1:5001": {Weight: 3}}; !reflect.DeepEqual(cache.instances, expect) {
+		t.Fatalf("缓存应为 %v，实际为 %v", expect, cache.instances)
+	}
+	expect := []instanceChange{{"127.0.0.1:5001", 2, false}, {"127.0.0.1:5001", 3, false}, {"127.0.0.1:5000", 1, true}}
+	if !reflect.DeepEqual(*changes, expect) {
+		t.Fatalf("通知应为 %v，实际为 %v", expect, *changes)
+	}
+	if cache.revision != 13 {
+		t.Fatalf("缓存的 revision 应为 13，实际为 %d", cache.revision)
+	}
+
+	// 没有事件的响应（如进度通知）同样推进 revision
+	p.apply(serviceName, cache, watchResponse(20))
+	if cache.revision != 20 {
+		t.Fatalf("缓存的 revision 应为 20，实际为 %d", cache.revision)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	p := &HubProxy{}
+	changes := recordChanges(p)
+	cache := &serviceCache{instances: make(map[string]ServiceMeta)}
+	p.replace(serviceName, cache, []ServiceInstance{
+		{Endpoint: "127.0.0.1:5000", ServiceMeta: ServiceMeta{Weight: 1}},
+		{Endpoint: "127.0.0.1:5001", ServiceMeta: ServiceMeta{Weight: 2}},
+	}, 10)
+	if len(*changes) != 2 || !cache.loaded {
+		t.Fatalf("首次同步应通知所有实例，实际为 %v", *changes)
+	}
+
+	// 只通知与旧缓存相比有变化的实例
+	*changes = nil
+	p.replace(serviceName, cache, []ServiceInstance{
+		{Endpoint: "127.0.0.1:5001", ServiceMeta: ServiceMeta{Weight: 2}},
+		{Endpoint: "127.0.0.1:5002", ServiceMeta: ServiceMeta{Weight: 3}},
+	}, 20)
+	if expect := []instanceChange{{"127.0.0.1:5002", 3, false}, {"127.0.0.1:5000", 1, true}}; !reflect.DeepEqual(*changes, expect) {
+		t.Fatalf("通知应为 %v，实际为 %v", expect, *changes)
+	}
+
+	// 缓存已包含更新的 revision 时不替换
+	*changes = nil
+	if revision := p.replace(serviceName, cache, nil, 15); revision != 20 || len(cache.instances) != 2 || len(*changes) != 0 {
+		t.Fatalf("旧的全量结果不应替换缓存，revision: %d，缓存: %v", revision, cache.instances)
+	}
+}
@@ -0,0 +1,122 @@
+package service_hub
+
+import (
+	"context"
+	"fmt"
+	"github.com/jmh000527/criker-search/utils"
+	"sort"
+	"sync"
+)
+
+// MemoryServiceHub 进程内的服务注册中心，注册信息只保存在内存中。
+// 适用于单进程内同时运行Sentinel和多个IndexServiceWorker的场景，主要用于测试。租约不会过期，注销需要显式调用UnregisterService。
+type MemoryServiceHub struct {
+	mu        sync.RWMutex
+	services  map[string]map[string]ServiceMeta // service -> endpoint -> 元数据
+	leases    map[int64]struct{}                // 有效的租约
+	nextLease int64                             // 下一个租约ID
+}
+
+// NewMemoryServiceHub 创建一个空的进程内服务注册中心
+//
+// 返回值:
+//   - *MemoryServiceHub: 新创建的MemoryServiceHub实例。
+func NewMemoryServiceHub() *MemoryServiceHub {
+	return &MemoryServiceHub{
+		services:  make(map[string]map[string]ServiceMeta),
+		leases:    make(map[int64]struct{}),
+		nextLease: 1,
+	}
+}
+
+// RegisterService 注册服务，使用默认的元数据
+func (hub *MemoryServiceHub) RegisterService(service, endpoint string, leaseID int64) (int64, error) {
+	return hub.RegisterServiceWithMeta(service, endpoint, leaseID, ServiceMeta{})
+}
+
+// RegisterServiceWithMeta 注册服务并保存元数据。leaseID有效时视为续约，否则分配一个新的租约。
+//
+// 参数:
+//   - service: 微服务的名称。
+//   - endpoint: 微服务服务器的地址。
+//   - leaseID: 租约ID，第一次注册时应置为0。
+//   - meta: 服务的元数据。
+//
+// 返回值:
+//   - int64: 租约ID。
+//   - error: 始终为nil。
+func (hub *MemoryServiceHub) RegisterServiceWithMeta(service, endpoint string, leaseID int64, meta ServiceMeta) (int64, error) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if _, exists := hub.leases[leaseID]; exists {
+		// 续约：租约不会过期，无需任何操作
+		if _, registered := hub.services[service][endpoint]; registered {
+			return leaseID, nil
+		}
+	} else {
+		leaseID = hub.nextLease
+		hub.nextLease++
+		hub.leases[leaseID] = struct{}{}
+	}
+	if hub.services[service] == nil {
+		hub.services[service] = make(map[string]ServiceMeta)
+	}
+	hub.services[service][endpoint] = meta
+	utils.Log.Printf("成功注册服务: %s/%s", service, endpoint)
+	return leaseID, nil
+}
+
+// UnregisterService 注销服务
+func (hub *MemoryServiceHub) UnregisterService(service, endpoint string) error {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	delete(hub.services[service], endpoint)
+	if len(hub.services[service]) == 0 {
+		delete(hub.services, service)
+	}
+	utils.Log.Printf("成功注销服务: %s/%s", service, endpoint)
+	return nil
+}
+
+// KeepAlive 进程内的租约不会过期，阻塞直到ctx被取消。租约不存在时返回ErrLeaseLost。
+func (hub *MemoryServiceHub) KeepAlive(ctx context.Context, leaseID int64) error {
+	hub.mu.RLock()
+	_, exists := hub.leases[leaseID]
+	hub.mu.RUnlock()
+	if !exists {
+		return ErrLeaseLost
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// UpdateServiceMeta 更新已注册服务的元数据
+func (hub *MemoryServiceHub) UpdateServiceMeta(service, endpoint string, leaseID int64, meta ServiceMeta) error {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	if _, registered := hub.services[service][endpoint]; !registered {
+		return fmt.Errorf("服务 %s/%s 尚未注册，无法更新元数据", service, endpoint)
+	}
+	hub.services[service][endpoint] = meta
+	return nil
+}
+
+// GetServiceInstances 服务发现，返回按地址排序的服务实例列表
+func (hub *MemoryServiceHub) GetServiceInstances(service string) []ServiceInstance {
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	instances := make([]ServiceInstance, 0, len(hub.services[service]))
+	for endpoint, meta := range hub.services[service] {
+		instances = append(instances, ServiceInstance{Endpoint: endpoint, ServiceMeta: meta})
+	}
+	sort.Slice(instances, func(i, j int) bool { return instances[i].Endpoint < instances[j].Endpoint })
+	return instances
+}
+
+// GetServiceEndpoints 服务发现，返回按地址排序的endpoint列表
+func (hub *MemoryServiceHub) GetServiceEndpoints(service string) []string {
+	return endpointsOf(hub.GetServiceInstances(service))
+}
+
+// Close 进程内的注册中心没有需要释放的资源
+func (hub *MemoryServiceHub) Close() {}
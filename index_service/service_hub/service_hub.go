@@ -1,11 +1,33 @@
 package service_hub
 
-import etcdv3 "go.etcd.io/etcd/client/v3"
+import (
+	"context"
+	"errors"
+)
 
+// ErrLeaseLost 租约已失效（过期或被撤销），需要重新注册服务
+var ErrLeaseLost = errors.New("租约已失效")
+
+// ServiceHub 服务注册与发现，只负责提供服务的endpoint及其元数据，选择哪个endpoint、熔断哪个endpoint由调用方
+// （如Sentinel使用的endpoint_selector.EndpointSelector）决定。除了基于etcd的EtcdServiceHub，还有固定列表的StaticServiceHub、
+// 从文件读取endpoint的FileServiceHub和进程内的MemoryServiceHub，后两者不依赖etcd集群，便于部署和测试。
 type ServiceHub interface {
-	RegisterService(service string, endpoint string, leaseID etcdv3.LeaseID) (etcdv3.LeaseID, error) // 注册服务
-	UnregisterService(service string, endpoint string) error                                         // 注销服务
-	GetServiceEndpoints(service string) []string                                                     // 服务发现
-	GetServiceEndpoint(service string) string                                                        // 选择服务的一个endpoint
-	Close()                                                                                          // 关闭etcd客户端连接
+	RegisterService(service string, endpoint string, leaseID int64) (int64, error)                           // 注册服务，首次注册时leaseID为0，之后用返回的leaseID续约
+	RegisterServiceWithMeta(service string, endpoint string, leaseID int64, meta ServiceMeta) (int64, error) // 注册服务并发布元数据（如权重）
+	KeepAlive(ctx context.Context, leaseID int64) error                                                      // 持续续约，直到ctx取消（返回nil）或租约丢失（返回ErrLeaseLost）
+	UpdateServiceMeta(service string, endpoint string, leaseID int64, meta ServiceMeta) error                // 更新已注册服务的元数据
+	UnregisterService(service string, endpoint string) error                                                 // 注销服务
+	GetServiceInstances(service string) []ServiceInstance                                                    // 服务发现，返回endpoint及其元数据
+	GetServiceEndpoints(service string) []string                                                             // 服务发现，只返回endpoint
+	Close()                                                                                                  // 释放ServiceHub占用的资源
+}
+
+// InstanceListener 服务实例变化时的回调：实例上线或元数据变化时removed为false，下线时removed为true
+type InstanceListener func(service string, instance ServiceInstance, removed bool)
+
+// InstanceNotifier 能在服务实例变化时主动通知调用方的ServiceHub，例如按etcd的watch事件增量更新缓存的HubProxy。
+// 调用方据此只在实例或元数据变化时同步负载均衡的权重，不必每次服务发现都做比较。
+type InstanceNotifier interface {
+	// OnInstanceChange 注册监听函数，注册时先用已缓存的实例调用一次。监听函数在持有缓存的锁时被调用，不能再访问ServiceHub
+	OnInstanceChange(listener InstanceListener)
 }
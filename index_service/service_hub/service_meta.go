@@ -0,0 +1,57 @@
+package service_hub
+
+import (
+	"encoding/json"
+	"github.com/jmh000527/criker-search/utils"
+)
+
+// 副本角色
+const (
+	RolePrimary = "primary" // 主副本，接收写请求
+	RoleReplica = "replica" // 从副本，只提供查询
+)
+
+// ServiceMeta 服务注册时写入etcd value的元数据，以JSON格式存储。
+// 早期版本注册时value为空，解析时按默认值处理。
+type ServiceMeta struct {
+	Version    string `json:"version,omitempty"`    // 构建版本，见utils.Version，用于灰度发布时区分新旧版本的节点
+	ShardId    int    `json:"shard_id"`             // 分片编号，从0开始
+	Role       string `json:"role,omitempty"`       // 副本角色，RolePrimary或RoleReplica，为空表示不区分主从
+	Weight     int    `json:"weight,omitempty"`     // 权重，供加权负载均衡使用，<=0 表示使用默认权重
+	DocCount   int    `json:"doc_count"`            // 文档数量，定期刷新，不是实时值
+	Generation int64  `json:"generation,omitempty"` // 索引的代数，每次重建索引后递增，用于判断节点上的索引是否为最新
+}
+
+// ServiceInstance 一个服务实例，即endpoint及其发布的元数据
+type ServiceInstance struct {
+	Endpoint string `json:"endpoint"`
+	ServiceMeta
+}
+
+// encode 把元数据编码为etcd value
+func (meta ServiceMeta) encode() string {
+	bs, _ := json.Marshal(meta)
+	return string(bs)
+}
+
+// decodeServiceMeta 从etcd value解析元数据，value为空或格式不合法时返回零值
+func decodeServiceMeta(value []byte) ServiceMeta {
+	var meta ServiceMeta
+	if len(value) == 0 {
+		return meta
+	}
+	if err := json.Unmarshal(value, &meta); err != nil {
+		utils.Log.Printf("解析服务元数据失败: %s, 错误: %v", string(value), err)
+		return ServiceMeta{}
+	}
+	return meta
+}
+
+// endpointsOf 提取服务实例的endpoint列表
+func endpointsOf(instances []ServiceInstance) []string {
+	endpoints := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		endpoints = append(endpoints, instance.Endpoint)
+	}
+	return endpoints
+}
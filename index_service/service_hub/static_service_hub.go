@@ -0,0 +1,73 @@
+package service_hub
+
+import (
+	"context"
+	"github.com/jmh000527/criker-search/utils"
+)
+
+// StaticServiceHub 固定endpoint列表的服务注册中心，endpoint在创建时给定且不会变化。
+// 适用于没有etcd集群、worker地址固定的部署。worker的注册和注销不会改变endpoint列表。
+type StaticServiceHub struct {
+	services map[string][]string // service -> endpoint列表
+}
+
+// NewStaticServiceHub 创建一个固定endpoint列表的服务注册中心
+//
+// 参数:
+//   - services: 各个服务的endpoint列表，key为服务名称。
+//
+// 返回值:
+//   - *StaticServiceHub: 新创建的StaticServiceHub实例。
+func NewStaticServiceHub(services map[string][]string) *StaticServiceHub {
+	copied := make(map[string][]string, len(services))
+	for service, endpoints := range services {
+		copied[service] = append([]string(nil), endpoints...)
+	}
+	return &StaticServiceHub{services: copied}
+}
+
+// RegisterService endpoint列表是固定的，注册不做任何操作，原样返回leaseID
+func (hub *StaticServiceHub) RegisterService(service, endpoint string, leaseID int64) (int64, error) {
+	return hub.RegisterServiceWithMeta(service, endpoint, leaseID, ServiceMeta{})
+}
+
+// RegisterServiceWithMeta endpoint列表是固定的，注册不做任何操作，原样返回leaseID
+func (hub *StaticServiceHub) RegisterServiceWithMeta(service, endpoint string, leaseID int64, meta ServiceMeta) (int64, error) {
+	if leaseID <= 0 {
+		utils.Log.Printf("使用固定的endpoint列表，忽略服务注册: %s/%s", service, endpoint)
+	}
+	return leaseID, nil
+}
+
+// UnregisterService endpoint列表是固定的，注销不做任何操作
+func (hub *StaticServiceHub) UnregisterService(service, endpoint string) error {
+	return nil
+}
+
+// KeepAlive endpoint列表是固定的，无需续约，阻塞直到ctx被取消
+func (hub *StaticServiceHub) KeepAlive(ctx context.Context, leaseID int64) error {
+	<-ctx.Done()
+	return nil
+}
+
+// UpdateServiceMeta endpoint列表是固定的，不保存元数据
+func (hub *StaticServiceHub) UpdateServiceMeta(service, endpoint string, leaseID int64, meta ServiceMeta) error {
+	return nil
+}
+
+// GetServiceInstances 服务发现，返回创建时给定的endpoint，元数据均为默认值
+func (hub *StaticServiceHub) GetServiceInstances(service string) []ServiceInstance {
+	instances := make([]ServiceInstance, 0, len(hub.services[service]))
+	for _, endpoint := range hub.services[service] {
+		instances = append(instances, ServiceInstance{Endpoint: endpoint})
+	}
+	return instances
+}
+
+// GetServiceEndpoints 服务发现，返回创建时给定的endpoint列表
+func (hub *StaticServiceHub) GetServiceEndpoints(service string) []string {
+	return hub.services[service]
+}
+
+// Close 固定列表的注册中心没有需要释放的资源
+func (hub *StaticServiceHub) Close() {}
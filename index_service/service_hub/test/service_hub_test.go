@@ -0,0 +1,110 @@
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+)
+
+const serviceName = "test_service"
+
+func TestMemoryServiceHub(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	defer hub.Close()
+
+	leaseID, err := hub.RegisterService(serviceName, "127.0.0.1:5001", 0)
+	if err != nil || leaseID <= 0 {
+		t.Fatalf("注册服务失败，租约ID: %d, 错误: %v", leaseID, err)
+	}
+	// 续约返回相同的租约ID
+	if renewed, _ := hub.RegisterService(serviceName, "127.0.0.1:5001", leaseID); renewed != leaseID {
+		t.Fatalf("续约应返回相同的租约ID %d，实际为 %d", leaseID, renewed)
+	}
+	hub.RegisterService(serviceName, "127.0.0.1:5000", 0)
+
+	endpoints := hub.GetServiceEndpoints(serviceName)
+	if !reflect.DeepEqual(endpoints, []string{"127.0.0.1:5000", "127.0.0.1:5001"}) {
+		t.Fatalf("服务发现结果不正确: %v", endpoints)
+	}
+
+	// 元数据随注册发布，并可以更新
+	meta := service_hub.ServiceMeta{Version: "v1", ShardId: 1, Role: service_hub.RolePrimary, DocCount: 10}
+	if err := hub.UpdateServiceMeta(serviceName, "127.0.0.1:5001", leaseID, meta); err != nil {
+		t.Fatal(err)
+	}
+	instances := hub.GetServiceInstances(serviceName)
+	if len(instances) != 2 || instances[1].Endpoint != "127.0.0.1:5001" || instances[1].ServiceMeta != meta {
+		t.Fatalf("服务实例的元数据不正确: %+v", instances)
+	}
+	if err := hub.UpdateServiceMeta(serviceName, "127.0.0.1:5002", leaseID, meta); err == nil {
+		t.Fatal("未注册的服务不应能更新元数据")
+	}
+
+	hub.UnregisterService(serviceName, "127.0.0.1:5000")
+	if endpoints := hub.GetServiceEndpoints(serviceName); !reflect.DeepEqual(endpoints, []string{"127.0.0.1:5001"}) {
+		t.Fatalf("注销后应只剩 127.0.0.1:5001，实际为 %v", endpoints)
+	}
+}
+
+func TestStaticServiceHub(t *testing.T) {
+	hub := service_hub.NewStaticServiceHub(map[string][]string{
+		serviceName: {"127.0.0.1:5000", "127.0.0.1:5001"},
+	})
+	defer hub.Close()
+
+	// 注册和注销不改变固定的endpoint列表
+	hub.RegisterService(serviceName, "127.0.0.1:5002", 0)
+	hub.UnregisterService(serviceName, "127.0.0.1:5000")
+	if endpoints := hub.GetServiceEndpoints(serviceName); len(endpoints) != 2 {
+		t.Fatalf("固定的endpoint列表不应变化: %v", endpoints)
+	}
+
+}
+
+func TestFileServiceHub(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints.yaml")
+	content := serviceName + ":\n  - endpoint: 127.0.0.1:5000\n    weight: 3\n  - endpoint: 127.0.0.1:5001\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	hub, err := service_hub.NewFileServiceHub(path, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hub.Close()
+
+	// 文件中的权重作为元数据发布
+	instances := hub.GetServiceInstances(serviceName)
+	if len(instances) != 2 || instances[0].Weight != 3 || instances[1].Weight != 0 {
+		t.Fatalf("服务实例的权重不正确: %+v", instances)
+	}
+
+	// 修改文件后自动重新加载
+	content = serviceName + ":\n  - endpoint: 127.0.0.1:5002\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if reflect.DeepEqual(hub.GetServiceEndpoints(serviceName), []string{"127.0.0.1:5002"}) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if endpoints := hub.GetServiceEndpoints(serviceName); !reflect.DeepEqual(endpoints, []string{"127.0.0.1:5002"}) {
+		t.Fatalf("文件修改后未重新加载: %v", endpoints)
+	}
+
+	// 文件格式错误时继续使用旧的endpoint列表
+	if err := os.WriteFile(path, []byte("{{{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if endpoints := hub.GetServiceEndpoints(serviceName); !reflect.DeepEqual(endpoints, []string{"127.0.0.1:5002"}) {
+		t.Fatalf("文件格式错误时应继续使用旧的endpoint列表: %v", endpoints)
+	}
+}
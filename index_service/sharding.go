@@ -0,0 +1,137 @@
+package index_service
+
+import (
+	"context"
+	"github.com/jmh000527/criker-search/index_service/coordinator"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/utils"
+	etcdv3 "go.etcd.io/etcd/client/v3"
+	"sync"
+	"time"
+)
+
+// sharding IndexServiceWorker 的分片和副本角色。可以通过 WithShard 静态配置，
+// 也可以通过 WatchShardMap 按 coordinator 维护的分片表切换分片和角色：成为从副本时跟随分片的主副本，被提升为主副本时停止跟随。
+type sharding struct {
+	shardMu sync.RWMutex // 保护 shardId、role 和 replication.primary
+	shardId int          // 本worker负责的分片编号
+	role    string       // 副本角色，service_hub.RolePrimary或service_hub.RoleReplica
+
+	followMu      sync.Mutex    // 串行化开始、停止跟随主副本的操作（Follow、ApplyShardMap、Restore）
+	shardMapEpoch int64         // 最近一次应用的分片表的 epoch
+	metaChanged   chan struct{} // 分片或角色变化后通知续约协程立即刷新元数据，未注册服务时为 nil
+
+	stopWatching context.CancelFunc // 停止监视分片表
+	watchDone    chan struct{}      // 监视分片表的协程退出后关闭
+}
+
+// WithShard 设置本worker负责的分片编号和副本角色，随服务注册一起发布。
+func (w *IndexServiceWorker) WithShard(shardId int, role string) *IndexServiceWorker {
+	w.shardMu.Lock()
+	defer w.shardMu.Unlock()
+	w.shardId = shardId
+	w.role = role
+	return w
+}
+
+// WatchShardMap 监视 coordinator 维护的分片表，按分片表切换本 worker 的分片和角色，直到 Close。
+// 需要在注册服务之后调用，分片表按注册的地址查找本 worker。
+//
+// 参数:
+//   - etcdServers: 保存分片表的 etcd 服务器地址列表。
+//
+// 返回值:
+//   - error: 连接 etcd 失败时返回错误。
+func (w *IndexServiceWorker) WatchShardMap(etcdServers []string) error {
+	client, err := etcdv3.New(etcdv3.Config{
+		Endpoints:   etcdServers,
+		DialTimeout: 3 * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.stopWatching = cancel
+	w.watchDone = make(chan struct{})
+	go func() {
+		defer close(w.watchDone)
+		defer client.Close()
+		coordinator.WatchShardMap(ctx, client, w.ApplyShardMap)
+	}()
+	return nil
+}
+
+// ApplyShardMap 按分片表切换本 worker 的分片和角色，并立即刷新注册的元数据。
+//   - 作为从副本时跟随分片的主副本，主副本变化时改为跟随新的主副本；
+//   - 被提升为主副本时停止跟随，开始接受写入，从副本从它同步到的序号继续订阅；
+//   - 未被分配（备用）时保持原来的状态。
+//
+// 不比已应用的分片表更新（Epoch 不大于上一次）的分片表会被忽略。
+//
+// 参数:
+//   - shardMap: coordinator 维护的分片表。
+func (w *IndexServiceWorker) ApplyShardMap(shardMap *coordinator.ShardMap) {
+	if len(w.selfAddr) == 0 {
+		utils.Log.Printf("尚未注册服务，忽略分片表 epoch %d", shardMap.Epoch)
+		return
+	}
+	w.followMu.Lock()
+	defer w.followMu.Unlock()
+	if shardMap.Epoch <= w.shardMapEpoch {
+		return
+	}
+	w.shardMapEpoch = shardMap.Epoch
+
+	shardId, isPrimary := shardMap.ShardOf(w.selfAddr)
+	if shardId < 0 {
+		utils.Log.Printf("分片表 epoch %d 中没有为本worker分配分片，作为备用", shardMap.Epoch)
+		return
+	}
+	role, primary := service_hub.RolePrimary, ""
+	if !isPrimary {
+		role, primary = service_hub.RoleReplica, shardMap.Shards[shardId].Primary
+	}
+
+	if current := w.primaryAddr(); current != primary {
+		if len(current) > 0 {
+			w.stopFollowingPrimary()
+		}
+		if len(primary) > 0 {
+			if err := w.startFollowing(primary); err != nil {
+				utils.Log.Printf("分片表要求本worker作为分片 %d 的从副本，但无法跟随主副本 %s: %v", shardId, primary, err)
+			}
+		}
+	}
+	w.shardMu.Lock()
+	w.shardId, w.role = shardId, role
+	w.shardMu.Unlock()
+	utils.Log.Printf("按分片表 epoch %d 作为分片 %d 的 %s", shardMap.Epoch, shardId, role)
+
+	// 立即发布新的角色，Sentinel 据此把写入发往新的主副本
+	select {
+	case w.metaChanged <- struct{}{}:
+	default:
+	}
+}
+
+// shard 返回本worker负责的分片编号和副本角色
+func (w *IndexServiceWorker) shard() (int, string) {
+	w.shardMu.RLock()
+	defer w.shardMu.RUnlock()
+	return w.shardId, w.role
+}
+
+// primaryAddr 返回跟随的主副本地址，为空表示本 worker 不是从副本
+func (w *IndexServiceWorker) primaryAddr() string {
+	w.shardMu.RLock()
+	defer w.shardMu.RUnlock()
+	return w.primary
+}
+
+// closeSharding 停止监视分片表
+func (w *IndexServiceWorker) closeSharding() {
+	if w.stopWatching != nil {
+		w.stopWatching()
+		<-w.watchDone
+	}
+}
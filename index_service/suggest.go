@@ -0,0 +1,150 @@
+package index_service
+
+import (
+	"context"
+	"github.com/jmh000527/criker-search/index/suggest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sort"
+)
+
+const (
+	DefaultSuggestLimit = 10  // 自动补全默认返回的候选数量
+	MaxSuggestLimit     = 100 // 自动补全最多返回的候选数量，更大的 limit 按该值处理，gRPC 和 HTTP 接口直接拒绝
+)
+
+// suggestLimit 规范自动补全的候选数量：<=0 时使用 DefaultSuggestLimit，超过 MaxSuggestLimit 时使用 MaxSuggestLimit
+func suggestLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		return MaxSuggestLimit
+	}
+	return limit
+}
+
+// DidYouMean 在字段 field 的词典中查找与 word 相近的词，用于检索没有结果时提示 "您是不是要找"。
+// word 本身在词典中时也会作为编辑距离为0的候选返回。
+//
+// 参数:
+//   - field: 在哪个字段的词典中查找。
+//   - word: 待纠正的词。
+//   - limit: 最多返回的候选数量，<=0 表示不限制。
+//
+// 返回值:
+//   - []*Correction: 按编辑距离从小到大、文档数量从多到少排序的候选词。
+//   - error: 总是返回 nil，与 Sentinel 的实现保持一致。
+func (indexer *LocalIndexer) DidYouMean(field, word string, limit int) ([]*Correction, error) {
+	candidates := suggest.Correct(indexer.reverseIndex, field, word, 0, limit)
+	corrections := make([]*Correction, 0, len(candidates))
+	for _, candidate := range candidates {
+		corrections = append(corrections, &Correction{
+			Word:     candidate.Word,
+			DocFreq:  int64(candidate.DocFreq),
+			Distance: int32(candidate.Distance),
+		})
+	}
+	return corrections, nil
+}
+
+// sortCorrections 按编辑距离从小到大、文档数量从多到少、词的字典序对候选词排序，并截取前 limit 个（<=0 表示不限制）
+func sortCorrections(corrections []*Correction, limit int) []*Correction {
+	sort.Slice(corrections, func(i, j int) bool {
+		a, b := corrections[i], corrections[j]
+		if a.Distance != b.Distance {
+			return a.Distance < b.Distance
+		}
+		if a.DocFreq != b.DocFreq {
+			return a.DocFreq > b.DocFreq
+		}
+		return a.Word < b.Word
+	})
+	if limit > 0 && len(corrections) > limit {
+		corrections = corrections[:limit]
+	}
+	return corrections
+}
+
+// DidYouMean 在本 worker 的词典中查找与给定词相近的词。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 字段、待纠正的词和最多返回的候选数量。
+//
+// 返回值:
+//   - *DidYouMeanResult: 排好序的候选词。
+//   - error: 查找失败时返回错误。
+func (w *IndexServiceWorker) DidYouMean(ctx context.Context, request *DidYouMeanRequest) (*DidYouMeanResult, error) {
+	corrections, err := w.Indexer.DidYouMean(request.Field, request.Word, int(request.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return &DidYouMeanResult{Corrections: corrections}, nil
+}
+
+// Suggest 返回以 prefix 开头、包含它的文档数量最多的 limit 个词，用于输入时的自动补全。
+// 多个字段的候选放在一起按文档数量排序，同一个词在不同字段中分别返回。
+//
+// 参数:
+//   - prefix: 用户已经输入的前缀，为空时不返回结果。
+//   - fields: 在哪些字段中补全，为空表示所有通过 WithCompletion 设置了自动补全的字段。
+//   - limit: 最多返回的候选数量，<=0 时使用 DefaultSuggestLimit，超过 MaxSuggestLimit 时使用 MaxSuggestLimit。
+//
+// 返回值:
+//   - []*Suggestion: 按文档数量从多到少排序的候选词。
+//   - error: 总是返回 nil，与 Sentinel 的实现保持一致。
+func (indexer *LocalIndexer) Suggest(prefix string, fields []string, limit int) ([]*Suggestion, error) {
+	if len(prefix) == 0 {
+		return nil, nil
+	}
+	limit = suggestLimit(limit)
+	if len(fields) == 0 {
+		fields = indexer.reverseIndex.CompletionFields()
+	}
+	var suggestions []*Suggestion
+	for _, field := range fields {
+		for _, completion := range indexer.reverseIndex.Complete(field, prefix, limit) {
+			suggestions = append(suggestions, &Suggestion{Field: field, Word: completion.Word, Weight: completion.Weight})
+		}
+	}
+	return sortSuggestions(suggestions, limit), nil
+}
+
+// sortSuggestions 按文档数量从多到少、词的字典序、字段名对候选词排序，并截取前 limit 个
+func sortSuggestions(suggestions []*Suggestion, limit int) []*Suggestion {
+	sort.Slice(suggestions, func(i, j int) bool {
+		a, b := suggestions[i], suggestions[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if a.Word != b.Word {
+			return a.Word < b.Word
+		}
+		return a.Field < b.Field
+	})
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+	return suggestions
+}
+
+// Suggest 在本 worker 的索引中查找以给定前缀开头的词。
+//
+// 参数:
+//   - ctx: 上下文，用于处理请求的生命周期和取消操作。
+//   - request: 前缀、字段和最多返回的候选数量，候选数量为0时使用 DefaultSuggestLimit。
+//
+// 返回值:
+//   - *SuggestResult: 排好序的候选词。
+//   - error: 候选数量为负数或超过 MaxSuggestLimit 时返回 InvalidArgument，查找失败时返回错误。
+func (w *IndexServiceWorker) Suggest(ctx context.Context, request *SuggestRequest) (*SuggestResult, error) {
+	if request.Limit < 0 || request.Limit > MaxSuggestLimit {
+		return nil, status.Errorf(codes.InvalidArgument, "候选数量必须在 0 到 %d 之间", MaxSuggestLimit)
+	}
+	suggestions, err := w.Indexer.Suggest(request.Prefix, request.Fields, int(request.Limit))
+	if err != nil {
+		return nil, err
+	}
+	return &SuggestResult{Suggestions: suggestions}, nil
+}
@@ -0,0 +1,185 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// errBatchGet failingBatchGetDB 的 BatchGet 返回的错误
+var errBatchGet = errors.New("batch get failed")
+
+// failingBatchGetDB 除 BatchGet 总是失败之外与内存数据库相同
+type failingBatchGetDB struct {
+	*kv_db.Memory
+}
+
+func (db failingBatchGetDB) BatchGet(keys [][]byte) ([][]byte, error) {
+	return nil, errBatchGet
+}
+
+const failingBatchGet = "failing_batch_get"
+
+func init() {
+	kv_db.Register(failingBatchGet, func(path string) kv_db.KeyValueDB {
+		return failingBatchGetDB{new(kv_db.Memory).WithDataPath(path)}
+	})
+}
+
+// keywordDoc 构造只有一个 content 关键词的文档
+func keywordDoc(id, word string) types.Document {
+	return types.Document{Id: id, Keywords: []*types.Keyword{{Field: "content", Word: word}}}
+}
+
+// errorStrings 把错误列表转换为字符串，nil 转换为空字符串
+func errorStrings(errs []error) []string {
+	result := make([]string, len(errs))
+	for i, err := range errs {
+		if err != nil {
+			result[i] = err.Error()
+		}
+	}
+	return result
+}
+
+func TestLocalBatchAddDoc(t *testing.T) {
+	for _, c := range []struct {
+		name       string
+		dbType     string
+		docs       []types.Document
+		expectN    int
+		expectErrs []string
+		expectHits map[string][]string // 关键词 -> 检索到的文档
+	}{
+		{
+			name:       "全部成功",
+			dbType:     kv_db.BOLT,
+			docs:       []types.Document{keywordDoc("a", "go"), keywordDoc("b", "go")},
+			expectN:    2,
+			expectErrs: []string{"", ""},
+			expectHits: map[string][]string{"go": {"a", "b"}},
+		},
+		{
+			name:       "重复ID以最后一次出现的为准",
+			dbType:     kv_db.BOLT,
+			docs:       []types.Document{keywordDoc("a", "go"), keywordDoc("b", "go"), keywordDoc("a", "rust")},
+			expectN:    3,
+			expectErrs: []string{"", "", ""},
+			expectHits: map[string][]string{"go": {"b"}, "rust": {"a"}},
+		},
+		{
+			name:       "空ID被拒绝，不影响其他文档",
+			dbType:     kv_db.BOLT,
+			docs:       []types.Document{keywordDoc(" ", "go"), keywordDoc("a", "go"), keywordDoc("", "go")},
+			expectN:    1,
+			expectErrs: []string{"业务侧ID不能为空", "", "业务侧ID不能为空"},
+			expectHits: map[string][]string{"go": {"a"}},
+		},
+		{
+			name:       "读取旧文档失败时整批失败",
+			dbType:     failingBatchGet,
+			docs:       []types.Document{keywordDoc("a", "go"), keywordDoc("", "go"), keywordDoc("a", "rust")},
+			expectN:    0,
+			expectErrs: []string{errBatchGet.Error(), "业务侧ID不能为空", errBatchGet.Error()},
+			expectHits: map[string][]string{"go": nil, "rust": nil},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			indexer := new(index_service.LocalIndexer)
+			if err := indexer.Init(100, c.dbType, filepath.Join(t.TempDir(), "batch")); err != nil {
+				t.Fatal(err)
+			}
+			defer indexer.Close()
+
+			n, errs := indexer.BatchAddDoc(c.docs)
+			if n != c.expectN {
+				t.Errorf("应成功写入 %d 个文档，实际为 %d", c.expectN, n)
+			}
+			if got := errorStrings(errs); !reflect.DeepEqual(got, c.expectErrs) {
+				t.Errorf("错误应为 %q，实际为 %q", c.expectErrs, got)
+			}
+			for word, expect := range c.expectHits {
+				var got []string
+				for _, doc := range indexer.Search(types.NewTermQuery("content", word), 0, 0, nil) {
+					got = append(got, doc.Id)
+				}
+				if !reflect.DeepEqual(got, expect) {
+					t.Errorf("检索 %s 应返回 %v，实际为 %v", word, expect, got)
+				}
+			}
+		})
+	}
+}
+
+func TestLocalBatchAddDocDuplicateVersion(t *testing.T) {
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "batch")); err != nil {
+		t.Fatal(err)
+	}
+	defer indexer.Close()
+
+	// 被同批次后续文档覆盖的位置得到与最终生效的文档相同的版本号
+	docs := []types.Document{keywordDoc("a", "go"), keywordDoc("a", "rust")}
+	if n, errs := indexer.BatchAddDoc(docs); n != 2 {
+		t.Fatalf("应成功写入 2 个文档，实际为 %d，错误: %v", n, errs)
+	}
+	if docs[0].Version != 1 || docs[1].Version != 1 {
+		t.Fatalf("版本号都应为 1，实际为 %d 和 %d", docs[0].Version, docs[1].Version)
+	}
+	if count := indexer.Count(); count != 1 {
+		t.Fatalf("应只有 1 个文档，实际为 %d", count)
+	}
+}
+
+func TestBulkAddStatuses(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	// 每 2 个文档写一批，重复ID和空ID跨越批次边界
+	startConfiguredWorker(t, hub, 0, new(index_service.IndexServiceWorker).WithBulkBatchSize(2))
+	endpoints := hub.GetServiceEndpoints(index_service.IndexService)
+	conn, err := grpc.Dial(endpoints[0], grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	stream, err := index_service.NewIndexServiceClient(conn).BulkAdd(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []types.Document{keywordDoc("a", "go"), keywordDoc("", "go"), keywordDoc("b", "go"), keywordDoc("b", "rust"), keywordDoc("c", "go")}
+	for i := range docs {
+		if err := stream.Send(&docs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Count != 4 {
+		t.Errorf("应成功写入 4 个文档，实际为 %d", result.Count)
+	}
+	type status struct {
+		DocId string
+		Ok    bool
+		Error string
+	}
+	expect := []status{{"a", true, ""}, {"", false, "业务侧ID不能为空"}, {"b", true, ""}, {"b", true, ""}, {"c", true, ""}}
+	got := make([]status, 0, len(result.Statuses))
+	for _, s := range result.Statuses {
+		got = append(got, status{s.DocId, s.Ok, s.Error})
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("逐文档的写入状态应为 %v，实际为 %v", expect, got)
+	}
+}
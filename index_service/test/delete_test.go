@@ -0,0 +1,87 @@
+package test
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+)
+
+// authorDocs 生成 n 个文档，偶数编号的作者为 a、带有第 1 个 bit，奇数编号的作者为 b
+func authorDocs(n int) []types.Document {
+	docs := make([]types.Document, 0, n)
+	for i := 0; i < n; i++ {
+		doc := types.Document{Id: "doc" + strconv.Itoa(i)}
+		if i%2 == 0 {
+			doc.BitsFeature = 1
+			doc.Keywords = []*types.Keyword{{Field: "author", Word: "a"}}
+		} else {
+			doc.Keywords = []*types.Keyword{{Field: "author", Word: "b"}}
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestLocalBatchDelete(t *testing.T) {
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "delete")); err != nil {
+		t.Fatal(err)
+	}
+	defer indexer.Close()
+	if n, errs := indexer.BatchAddDoc(authorDocs(10)); n != 10 {
+		t.Fatalf("应写入 10 个文档，实际为 %d，错误: %v", n, errs)
+	}
+
+	// 重复的ID和不存在的ID不计入删除数量
+	result, err := indexer.BatchDelete([]string{"doc1", "doc1", "doc3", "missing"})
+	if err != nil || result.Count != 2 {
+		t.Fatalf("应删除 2 个文档，实际为 %v，错误: %v", result, err)
+	}
+	// 只删除作者为 a 且带有第 1 个 bit 的文档
+	result, err = indexer.DeleteByQuery(types.NewTermQuery("author", "a"), 1, 0, nil)
+	if err != nil || result.Count != 5 {
+		t.Fatalf("应删除 5 个文档，实际为 %v，错误: %v", result, err)
+	}
+	if count := indexer.Count(); count != 3 {
+		t.Fatalf("应剩余 3 个文档，实际为 %d", count)
+	}
+	if docs := indexer.Search(types.NewTermQuery("author", "b"), 0, 0, nil); len(docs) != 3 {
+		t.Fatalf("作者为 b 的文档应剩余 3 个，实际为 %d", len(docs))
+	}
+}
+
+func TestSentinelBatchDelete(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	startWorker(t, hub, 0)
+	startWorker(t, hub, 1)
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+	if n, errs := sentinel.BatchAddDoc(authorDocs(20)); n != 20 {
+		t.Fatalf("应写入 20 个文档，实际为 %d，错误: %v", n, errs)
+	}
+
+	result, err := sentinel.DeleteByQuery(types.NewTermQuery("author", "a"), 0, 0, nil)
+	if err != nil || result.Count != 10 || len(result.Workers) != 2 {
+		t.Fatalf("应在 2 个 worker 上共删除 10 个文档，实际为 %+v，错误: %v", result, err)
+	}
+	total := 0
+	for _, n := range result.Workers {
+		total += n
+	}
+	if total != result.Count {
+		t.Fatalf("各 worker 删除数量之和 %d 应等于总数 %d", total, result.Count)
+	}
+
+	result, err = sentinel.BatchDelete([]string{"doc1", "doc3", "doc0"})
+	if err != nil || result.Count != 2 {
+		t.Fatalf("应删除 2 个文档，实际为 %+v，错误: %v", result, err)
+	}
+	if count := sentinel.Count(); count != 8 {
+		t.Fatalf("应剩余 8 个文档，实际为 %d", count)
+	}
+}
@@ -0,0 +1,53 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/types"
+)
+
+func TestDocExpiry(t *testing.T) {
+	for _, dbType := range []string{kv_db.BOLT, kv_db.BADGER} {
+		indexer := new(index_service.LocalIndexer)
+		if err := indexer.Init(100, dbType, filepath.Join(t.TempDir(), "expiry")); err != nil {
+			t.Fatal(err)
+		}
+		now := time.Now().Unix()
+		keywords := []*types.Keyword{{Field: "topic", Word: "hot"}}
+		docs := []types.Document{
+			{Id: "expired", Keywords: keywords, ExpireAt: now - 10},
+			{Id: "alive", Keywords: keywords, ExpireAt: now + 3600},
+			{Id: "forever", Keywords: keywords},
+			{Id: "renewed", Keywords: keywords, ExpireAt: now - 10},
+		}
+		if n, errs := indexer.BatchAddDoc(docs); n != len(docs) {
+			t.Fatalf("应写入 %d 个文档，实际为 %d，错误: %v", len(docs), n, errs)
+		}
+		// 到期之前重新写入，延长了过期时间，不应被删除
+		if _, err := indexer.AddDoc(types.Document{Id: "renewed", Keywords: keywords, ExpireAt: now + 3600}); err != nil {
+			t.Fatal(err)
+		}
+
+		// 过期但尚未删除的文档检索不到
+		if result := indexer.Search(types.NewTermQuery("topic", "hot"), 0, 0, nil); len(result) != 3 {
+			t.Fatalf("应检索到 3 个未过期的文档，实际为 %d", len(result))
+		}
+		if count := indexer.Count(); count != 4 {
+			t.Fatalf("删除之前应有 4 个文档，实际为 %d", count)
+		}
+		if n := indexer.ReapExpired(); n != 1 {
+			t.Fatalf("应删除 1 个过期文档，实际为 %d", n)
+		}
+		if count := indexer.Count(); count != 3 {
+			t.Fatalf("删除之后应有 3 个文档，实际为 %d", count)
+		}
+		if n := indexer.ReapExpired(); n != 0 {
+			t.Fatalf("不应再删除文档，实际删除了 %d 个", n)
+		}
+		indexer.Close()
+	}
+}
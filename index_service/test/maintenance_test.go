@@ -0,0 +1,108 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newCompactableIndexer 打开一个 Bolt 正排索引，写入一批文档后删除大部分，留出可回收的空间
+func newCompactableIndexer(t *testing.T) *index_service.LocalIndexer {
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "compact")); err != nil {
+		t.Fatal(err)
+	}
+	docs := make([]types.Document, 1000)
+	ids := make([]string, len(docs))
+	for i := range docs {
+		ids[i] = fmt.Sprintf("doc%d", i)
+		docs[i] = types.Document{Id: ids[i], Bytes: bytes.Repeat([]byte{'x'}, 1024), Keywords: []*types.Keyword{{Field: "topic", Word: "go"}}}
+	}
+	if n, errs := indexer.BatchAddDoc(docs); n != len(docs) {
+		t.Fatalf("应写入 %d 个文档，实际为 %d，错误: %v", len(docs), n, errs)
+	}
+	if result, err := indexer.BatchDelete(ids[10:]); err != nil || result.Count != len(ids)-10 {
+		t.Fatalf("应删除 %d 个文档，实际为 %+v，错误: %v", len(ids)-10, result, err)
+	}
+	return indexer
+}
+
+func TestLocalCompact(t *testing.T) {
+	indexer := newCompactableIndexer(t)
+	defer indexer.Close()
+
+	result, err := indexer.Compact(0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Compacted || result.AfterBytes >= result.BeforeBytes {
+		t.Fatalf("应回收已删除文档占用的空间，实际为 %+v", result)
+	}
+	if result := indexer.Search(types.NewTermQuery("topic", "go"), 0, 0, nil); len(result) != 10 {
+		t.Fatalf("整理之后应检索到 10 个文档，实际为 %d", len(result))
+	}
+	stats, err := indexer.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CompactRuns != 1 || stats.ReclaimedBytes != result.BeforeBytes-result.AfterBytes || stats.LastCompactAt == 0 {
+		t.Fatalf("统计信息中的整理情况不正确: %+v", stats)
+	}
+
+	// 已经没有可回收的空间，不再整理
+	if result, err := indexer.Compact(0.1); err != nil || result.Compacted {
+		t.Fatalf("不应再次整理，实际为 %+v，错误: %v", result, err)
+	}
+}
+
+func TestCompactUnsupported(t *testing.T) {
+	worker := new(index_service.IndexServiceWorker)
+	if err := worker.Init(100, kv_db.MEMORY, "compact_memory"); err != nil {
+		t.Fatal(err)
+	}
+	defer worker.Close()
+	if _, err := worker.Indexer.Compact(0); !errors.Is(err, index_service.ErrCompactUnsupported) {
+		t.Fatalf("内存数据库应返回 ErrCompactUnsupported，实际为 %v", err)
+	}
+	if _, err := worker.Compact(context.Background(), new(index_service.CompactRequest)); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("内存数据库应返回 FailedPrecondition，实际为 %v", err)
+	}
+}
+
+func TestMaintenanceQuietHours(t *testing.T) {
+	hour := time.Now().Hour()
+	for _, c := range []struct {
+		name       string
+		start, end int
+		expect     int64
+	}{
+		{"不限制时段", 0, 0, 1},
+		{"当前在时段内", hour, (hour + 1) % 24, 1},
+		{"当前不在时段内", (hour + 1) % 24, (hour + 2) % 24, 0},
+	} {
+		indexer := newCompactableIndexer(t)
+		indexer.StartMaintenance(index_service.MaintenanceOptions{
+			Interval:       10 * time.Millisecond,
+			Threshold:      0.1,
+			QuietStartHour: c.start,
+			QuietEndHour:   c.end,
+		})
+		time.Sleep(200 * time.Millisecond)
+		// Close 等待后台任务退出之后统计信息不再变化
+		runs := indexer.MaintenanceStats().Runs
+		indexer.Close()
+		if runs != c.expect {
+			t.Errorf("%s: 应整理 %d 次，实际为 %d", c.name, c.expect, runs)
+		}
+	}
+}
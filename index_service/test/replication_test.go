@@ -0,0 +1,285 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// serveWorker 打开 dataDir 上开启了变更日志的 worker，并在随机端口上启动 gRPC 服务，返回 worker 和它的地址
+func serveWorker(t *testing.T, dataDir string) (*index_service.IndexServiceWorker, string, *grpc.Server) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker := new(index_service.IndexServiceWorker).WithChangeLog(100)
+	if err := worker.Init(1000, kv_db.BOLT, dataDir); err != nil {
+		t.Fatal(err)
+	}
+	worker.LoadFromIndexFile()
+	server := grpc.NewServer(worker.ServerOptions()...)
+	index_service.RegisterIndexServiceServer(server, worker)
+	go server.Serve(listener)
+	return worker, listener.Addr().String(), server
+}
+
+// waitFor 等待 cond 成立，超时则测试失败
+func waitFor(t *testing.T, what string, cond func() bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("等待%s超时", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestReplication(t *testing.T) {
+	dir := t.TempDir()
+	primary, primaryAddr, primaryServer := serveWorker(t, filepath.Join(dir, "primary"))
+	defer func() {
+		primary.Close()
+		primaryServer.Stop()
+	}()
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := primary.AddDoc(ctx, &types.Document{
+			Id:       "doc" + strconv.Itoa(i),
+			Keywords: []*types.Keyword{{Field: "content", Word: "go"}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := primary.DeleteDoc(ctx, &index_service.DocId{DocId: "doc0"}); err != nil {
+		t.Fatal(err)
+	}
+	if seq := primary.ChangeLogSeq(); seq != 4 {
+		t.Fatalf("主副本的变更序号应为 4，实际为 %d", seq)
+	}
+
+	// 从副本同步已有的变更
+	replicaDir := filepath.Join(dir, "replica")
+	replica, _, replicaServer := serveWorker(t, replicaDir)
+	if err := replica.Follow(primaryAddr); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "从副本同步", func() bool { return replica.ChangeLogSeq() == 4 })
+	if count := replica.Indexer.Count(); count != 2 {
+		t.Fatalf("从副本应有 2 个文档，实际为 %d", count)
+	}
+	// 从副本拒绝直接写入
+	if _, err := replica.AddDoc(ctx, &types.Document{Id: "doc9"}); err == nil {
+		t.Fatal("从副本不应接受写入")
+	}
+	replica.Close()
+	replicaServer.Stop()
+
+	// 从副本下线期间主副本继续写入，从副本重启后从上次的位置继续同步。
+	// 重启后新写入的文档不能复用已加载文档的 IntId，否则会在倒排链中覆盖它们
+	for i, word := range []string{"rust", "go"} {
+		if _, err := primary.AddDoc(ctx, &types.Document{
+			Id:       "doc" + strconv.Itoa(3+i),
+			Keywords: []*types.Keyword{{Field: "content", Word: word}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	replica, _, replicaServer = serveWorker(t, replicaDir)
+	defer func() {
+		replica.Close()
+		replicaServer.Stop()
+	}()
+	if seq := replica.ChangeLogSeq(); seq != 4 {
+		t.Fatalf("从副本重启后应从序号 4 继续，实际为 %d", seq)
+	}
+	if err := replica.Follow(primaryAddr); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "从副本继续同步", func() bool { return replica.ChangeLogSeq() == 6 })
+	if count := replica.Indexer.Count(); count != 4 {
+		t.Fatalf("从副本应有 4 个文档，实际为 %d", count)
+	}
+	if result := replica.Indexer.Search(types.NewTermQuery("content", "rust"), 0, 0, nil); len(result) != 1 {
+		t.Fatalf("从副本应检索到 1 个文档，实际为 %d", len(result))
+	}
+	if result := replica.Indexer.Search(types.NewTermQuery("content", "go"), 0, 0, nil); len(result) != 3 {
+		t.Fatalf("从副本应检索到 3 个文档，实际为 %d", len(result))
+	}
+}
+
+func TestLoadFromIndexFileKeepsIntIds(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reopen")
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, dir); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"a", "b"} {
+		if _, err := indexer.AddDoc(keywordDoc(id, "go")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	indexer.Close()
+
+	// 重新打开之后新文档从已有的最大 IntId 继续编号
+	indexer = new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, dir); err != nil {
+		t.Fatal(err)
+	}
+	defer indexer.Close()
+	if n := indexer.LoadFromIndexFile(); n != 2 {
+		t.Fatalf("应加载 2 个文档，实际为 %d", n)
+	}
+	if _, err := indexer.AddDoc(keywordDoc("c", "go")); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, doc := range indexer.Search(types.NewTermQuery("content", "go"), 0, 0, nil) {
+		ids = append(ids, doc.Id)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Fatalf("应检索到 [a b c]，实际为 %v", ids)
+	}
+}
+
+// dialWorker 连接 worker 的 gRPC 服务
+func dialWorker(t *testing.T, addr string) index_service.IndexServiceClient {
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return index_service.NewIndexServiceClient(conn)
+}
+
+func TestBackupRestore(t *testing.T) {
+	dir := t.TempDir()
+	primary, primaryAddr, primaryServer := serveWorker(t, filepath.Join(dir, "primary"))
+	defer func() {
+		primary.Close()
+		primaryServer.Stop()
+	}()
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		if _, err := primary.AddDoc(ctx, &types.Document{
+			Id:       "doc" + strconv.Itoa(i),
+			Keywords: []*types.Keyword{{Field: "content", Word: "go"}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 在线备份主副本
+	stream, err := dialWorker(t, primaryAddr).Backup(ctx, new(index_service.BackupRequest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var chunks []*index_service.BackupChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	if len(chunks) < 2 || chunks[0].ChangeLogSeq != 3 {
+		t.Fatalf("备份应以变更日志序号 3 开头，实际收到 %d 个分片", len(chunks))
+	}
+
+	// 备份之后主副本继续写入
+	if _, err := primary.DeleteDoc(ctx, &index_service.DocId{DocId: "doc0"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 用备份为新的从副本准备数据，之后从备份对应的序号继续同步
+	replica, replicaAddr, replicaServer := serveWorker(t, filepath.Join(dir, "replica"))
+	defer func() {
+		replica.Close()
+		replicaServer.Stop()
+	}()
+	restore, err := dialWorker(t, replicaAddr).Restore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, chunk := range chunks {
+		if err := restore.Send(chunk); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result, err := restore.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Count != 3 || replica.ChangeLogSeq() != 3 {
+		t.Fatalf("应恢复 3 个文档、序号为 3，实际为 %d 个文档、序号 %d", result.Count, replica.ChangeLogSeq())
+	}
+	if docs := replica.Indexer.Search(types.NewTermQuery("content", "go"), 0, 0, nil); len(docs) != 3 {
+		t.Fatalf("恢复后应检索到 3 个文档，实际为 %d", len(docs))
+	}
+
+	if err := replica.Follow(primaryAddr); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "从副本同步", func() bool { return replica.ChangeLogSeq() == 4 })
+	if count := replica.Indexer.Count(); count != 2 {
+		t.Fatalf("从副本应有 2 个文档，实际为 %d", count)
+	}
+}
+
+func TestLocalRestore(t *testing.T) {
+	source := new(index_service.LocalIndexer)
+	if err := source.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "source")); err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	expired := keywordDoc("expired", "go")
+	expired.ExpireAt = time.Now().Unix() - 10
+	if n, errs := source.BatchAddDoc([]types.Document{keywordDoc("a", "go"), keywordDoc("b", "go"), expired}); n != 3 {
+		t.Fatalf("应写入 3 个文档，实际为 %d，错误: %v", n, errs)
+	}
+	var backup bytes.Buffer
+	if err := source.Backup(&backup); err != nil {
+		t.Fatal(err)
+	}
+
+	// 恢复到一个已经启动了后台任务的空索引上
+	target := new(index_service.LocalIndexer)
+	if err := target.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "target")); err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	target.StartReaper(10 * time.Millisecond)
+	target.StartMaintenance(index_service.MaintenanceOptions{Interval: 10 * time.Millisecond})
+	if n, err := target.Restore(&backup); err != nil || n != 3 {
+		t.Fatalf("应恢复 3 个文档，实际为 %d，错误: %v", n, err)
+	}
+
+	// 恢复之后写入的文档不与恢复的文档冲突
+	if _, err := target.AddDoc(keywordDoc("c", "go")); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, doc := range target.Search(types.NewTermQuery("content", "go"), 0, 0, nil) {
+		ids = append(ids, doc.Id)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "b", "c"}) {
+		t.Fatalf("应检索到 [a b c]，实际为 %v", ids)
+	}
+	// 恢复结束后后台任务重新启动，删除备份中已过期的文档
+	waitFor(t, "删除过期文档", func() bool { return target.Count() == 3 })
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc"
+)
+
+// newChunkIndexer 创建一个包含 n 个命中 content:go 的文档的 LocalIndexer
+func newChunkIndexer(t *testing.T, n int) *index_service.LocalIndexer {
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "chunks")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { indexer.Close() })
+	docs := make([]types.Document, 0, n)
+	for i := 0; i < n; i++ {
+		docs = append(docs, keywordDoc("doc"+strconv.Itoa(i), "go"))
+	}
+	if written, errs := indexer.BatchAddDoc(docs); written != n {
+		t.Fatalf("应写入 %d 个文档，实际为 %d，错误: %v", n, written, errs)
+	}
+	return indexer
+}
+
+func TestSearchInChunks(t *testing.T) {
+	indexer := newChunkIndexer(t, 7)
+	for _, c := range []struct {
+		name      string
+		chunkSize int
+		limit     int
+		expect    []int // 每个分片的文档数量
+	}{
+		{"分片大小不能整除命中数", 3, 0, []int{3, 3, 1}},
+		{"限制数量后再分片", 3, 5, []int{3, 2}},
+		{"不分片", 0, 0, []int{7}},
+		{"分片大于命中数", 10, 0, []int{7}},
+	} {
+		var sizes []int
+		seen := make(map[string]struct{})
+		err := indexer.SearchInChunks(types.NewTermQuery("content", "go"), 0, 0, nil, c.chunkSize, c.limit, func(docs []*types.Document) error {
+			sizes = append(sizes, len(docs))
+			for _, doc := range docs {
+				seen[doc.Id] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		if !reflect.DeepEqual(sizes, c.expect) {
+			t.Errorf("%s: 分片大小应为 %v，实际为 %v", c.name, c.expect, sizes)
+		}
+		total := 0
+		for _, size := range c.expect {
+			total += size
+		}
+		if len(seen) != total {
+			t.Errorf("%s: 应返回 %d 个不同的文档，实际为 %d", c.name, total, len(seen))
+		}
+	}
+}
+
+func TestSearchInChunksStopsOnError(t *testing.T) {
+	indexer := newChunkIndexer(t, 7)
+	errStop := errors.New("stop")
+	calls := 0
+	err := indexer.SearchInChunks(types.NewTermQuery("content", "go"), 0, 0, nil, 2, 0, func(docs []*types.Document) error {
+		calls++
+		if calls == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatalf("应返回回调函数的错误，实际为 %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("回调函数返回错误后应停止检索，实际调用了 %d 次", calls)
+	}
+}
+
+// endlessStreamServer 的 SearchStream 不理会请求中的 Limit，一直发送文档直到客户端取消
+type endlessStreamServer struct {
+	index_service.UnimplementedIndexServiceServer
+	canceled chan struct{} // 服务端观察到客户端取消后关闭
+}
+
+func (s *endlessStreamServer) SearchStream(request *index_service.SearchRequest, stream index_service.IndexService_SearchStreamServer) error {
+	for i := 0; ; i++ {
+		if err := stream.Send(&index_service.SearchResult{Results: []*types.Document{{Id: "doc" + strconv.Itoa(i)}}}); err != nil {
+			break
+		}
+		select {
+		case <-stream.Context().Done():
+			close(s.canceled)
+			return stream.Context().Err()
+		case <-time.After(time.Millisecond):
+		}
+	}
+	<-stream.Context().Done()
+	close(s.canceled)
+	return stream.Context().Err()
+}
+
+func TestSentinelSearchWithLimitCancelsStreams(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub := &endlessStreamServer{canceled: make(chan struct{})}
+	server := grpc.NewServer()
+	index_service.RegisterIndexServiceServer(server, stub)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	hub := service_hub.NewMemoryServiceHub()
+	if _, err := hub.RegisterService(index_service.IndexService, listener.Addr().String(), 0); err != nil {
+		t.Fatal(err)
+	}
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+
+	// worker 不会主动结束流，只有收集够结果后取消才能返回
+	result := make(chan []*types.Document, 1)
+	go func() {
+		result <- sentinel.SearchWithLimit(types.NewTermQuery("content", "go"), 0, 0, nil, 3)
+	}()
+	select {
+	case docs := <-result:
+		if len(docs) != 3 {
+			t.Fatalf("应检索到 3 个文档，实际为 %d", len(docs))
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("收集够结果后没有取消 worker 的流")
+	}
+	select {
+	case <-stub.canceled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker 没有观察到流被取消")
+	}
+}
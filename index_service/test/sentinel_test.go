@@ -0,0 +1,136 @@
+package test
+
+import (
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+	"github.com/jmh000527/criker-search/utils"
+	"google.golang.org/grpc"
+)
+
+// startWorker 在随机端口上启动一个 IndexServiceWorker，并注册到 hub
+func startWorker(t *testing.T, hub service_hub.ServiceHub, index int) *index_service.IndexServiceWorker {
+	return startConfiguredWorker(t, hub, index, new(index_service.IndexServiceWorker))
+}
+
+// startConfiguredWorker 在随机端口上启动已经设置好选项（需要在 Init 之前设置的选项）的 worker，并注册到 hub
+func startConfiguredWorker(t *testing.T, hub service_hub.ServiceHub, index int, worker *index_service.IndexServiceWorker) *index_service.IndexServiceWorker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	worker.
+		WithAdvertiseAddr(listener.Addr().String()).
+		WithShard(index, service_hub.RolePrimary)
+	if err := worker.Init(1000, kv_db.BOLT, filepath.Join(t.TempDir(), "worker"+strconv.Itoa(index))); err != nil {
+		t.Fatal(err)
+	}
+	worker.LoadFromIndexFile()
+	server := grpc.NewServer(worker.ServerOptions()...)
+	index_service.RegisterIndexServiceServer(server, worker)
+	go server.Serve(listener)
+	if err := worker.RegisterServiceWithHub(hub, listener.Addr().(*net.TCPAddr).Port); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		worker.Close()
+		server.Stop()
+	})
+	return worker
+}
+
+func TestWorkerLifecycle(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+
+	// 索引加载完成之前不能注册
+	worker := new(index_service.IndexServiceWorker)
+	if err := worker.Init(1000, kv_db.BOLT, filepath.Join(t.TempDir(), "worker")); err != nil {
+		t.Fatal(err)
+	}
+	if err := worker.RegisterServiceWithHub(hub, 5000); err == nil {
+		t.Fatal("索引加载完成之前不应能注册服务")
+	}
+	worker.MarkReady()
+	if err := worker.WithAdvertiseAddr("10.0.0.1:5000").RegisterServiceWithHub(hub, 5000); err != nil {
+		t.Fatal(err)
+	}
+	if endpoints := hub.GetServiceEndpoints(index_service.IndexService); len(endpoints) != 1 || endpoints[0] != "10.0.0.1:5000" {
+		t.Fatalf("应以配置的地址注册，实际为 %v", endpoints)
+	}
+
+	// 下线时注销服务
+	if err := worker.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if endpoints := hub.GetServiceEndpoints(index_service.IndexService); len(endpoints) != 0 {
+		t.Fatalf("下线后应已注销，实际为 %v", endpoints)
+	}
+}
+
+func TestSentinelWithMemoryHub(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	startWorker(t, hub, 0)
+	startWorker(t, hub, 1)
+	if endpoints := hub.GetServiceEndpoints(index_service.IndexService); len(endpoints) != 2 {
+		t.Fatalf("应有 2 个 worker，实际为 %v", endpoints)
+	}
+
+	sentinel := index_service.NewSentinelWithHub(hub)
+	for _, instance := range sentinel.ServiceInstances() {
+		if instance.Version != utils.Version || instance.Role != service_hub.RolePrimary {
+			t.Fatalf("worker 发布的元数据不正确: %+v", instance)
+		}
+	}
+	docs := make([]types.Document, 0, 10)
+	for i := 0; i < 10; i++ {
+		docs = append(docs, types.Document{
+			Id:       "doc" + strconv.Itoa(i),
+			Keywords: []*types.Keyword{{Field: "content", Word: "go"}},
+		})
+	}
+	if n, errs := sentinel.BatchAddDoc(docs); n != len(docs) {
+		t.Fatalf("应写入 %d 个文档，实际写入 %d 个，错误: %v", len(docs), n, errs)
+	}
+	if n, err := sentinel.AddDoc(types.Document{
+		Id:       "doc10",
+		Keywords: []*types.Keyword{{Field: "content", Word: "rust"}},
+	}); n != 1 || err != nil {
+		t.Fatalf("写入文档失败: %v", err)
+	}
+
+	if count := sentinel.Count(); count != 11 {
+		t.Fatalf("应有 11 个文档，实际为 %d", count)
+	}
+	stats, err := sentinel.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// keyword go 分布在两个 worker 上，汇总时计数两次
+	if stats.Workers != 2 || stats.DocCount != 11 || stats.KeywordCount != 3 || stats.MaxPostingListLength < 5 {
+		t.Fatalf("汇总的统计信息不正确: %+v", stats)
+	}
+	if stats.ForwardIndexBytes <= 0 || stats.MemoryBytes == 0 {
+		t.Fatalf("应统计磁盘和内存占用: %+v", stats)
+	}
+	if result := sentinel.Search(types.NewTermQuery("content", "go"), 0, 0, nil); len(result) != 10 {
+		t.Fatalf("应检索到 10 个文档，实际为 %d", len(result))
+	}
+	if result := sentinel.SearchWithLimit(types.NewTermQuery("content", "go"), 0, 0, nil, 3); len(result) != 3 {
+		t.Fatalf("限制数量时应检索到 3 个文档，实际为 %d", len(result))
+	}
+	if n := sentinel.DeleteDoc("doc10"); n != 1 {
+		t.Fatalf("应删除 1 个文档，实际为 %d", n)
+	}
+	if result := sentinel.Search(types.NewTermQuery("content", "rust"), 0, 0, nil); len(result) != 0 {
+		t.Fatalf("删除后不应检索到文档，实际为 %d", len(result))
+	}
+	if count := sentinel.Count(); count != 10 {
+		t.Fatalf("删除后应有 10 个文档，实际为 %d", count)
+	}
+}
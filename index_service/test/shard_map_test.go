@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/coordinator"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+)
+
+// registerWorker 以 addr 把 worker 注册到 hub
+func registerWorker(t *testing.T, worker *index_service.IndexServiceWorker, hub service_hub.ServiceHub, addr string) {
+	_, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+	if err := worker.WithAdvertiseAddr(addr).RegisterServiceWithHub(hub, port); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// roleOf 返回 endpoint 在 hub 中发布的分片和角色
+func roleOf(hub service_hub.ServiceHub, endpoint string) (int, string) {
+	for _, instance := range hub.GetServiceInstances(index_service.IndexService) {
+		if instance.Endpoint == endpoint {
+			return instance.ShardId, instance.Role
+		}
+	}
+	return -1, ""
+}
+
+func TestApplyShardMap(t *testing.T) {
+	dir := t.TempDir()
+	hub := service_hub.NewMemoryServiceHub()
+	defer hub.Close()
+	first, firstAddr, firstServer := serveWorker(t, filepath.Join(dir, "first"))
+	defer firstServer.Stop()
+	second, secondAddr, secondServer := serveWorker(t, filepath.Join(dir, "second"))
+	defer func() {
+		second.Close()
+		secondServer.Stop()
+	}()
+	registerWorker(t, first, hub, firstAddr)
+	registerWorker(t, second, hub, secondAddr)
+
+	// 按分片表，first 是分片 1 的主副本，second 跟随 first
+	shardMap := coordinator.NewShardMap(2)
+	shardMap.Epoch = 1
+	shardMap.Shards[1].Primary = firstAddr
+	shardMap.Shards[1].Replicas = []string{secondAddr}
+	first.ApplyShardMap(shardMap)
+	second.ApplyShardMap(shardMap)
+	waitFor(t, "发布从副本角色", func() bool {
+		shardId, role := roleOf(hub, secondAddr)
+		return shardId == 1 && role == service_hub.RoleReplica
+	})
+
+	ctx := context.Background()
+	doc := &types.Document{Id: "doc", Keywords: []*types.Keyword{{Field: "content", Word: "go"}}}
+	if _, err := first.AddDoc(ctx, doc); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "从副本同步", func() bool { return second.ChangeLogSeq() == 1 })
+	if _, err := second.AddDoc(ctx, doc); err == nil {
+		t.Fatal("从副本不应接受写入")
+	}
+
+	// first 下线，second 被提升为主副本，停止跟随并开始接受写入
+	first.Close()
+	promoted := coordinator.NewShardMap(2)
+	promoted.Epoch = 2
+	promoted.Shards[1].Primary = secondAddr
+	second.ApplyShardMap(promoted)
+	if _, err := second.AddDoc(ctx, &types.Document{Id: "doc2"}); err != nil {
+		t.Fatalf("被提升为主副本后应接受写入: %v", err)
+	}
+	if seq := second.ChangeLogSeq(); seq != 2 {
+		t.Fatalf("新的主副本应从序号 1 之后继续写变更日志，实际序号为 %d", seq)
+	}
+	waitFor(t, "发布主副本角色", func() bool {
+		_, role := roleOf(hub, secondAddr)
+		return role == service_hub.RolePrimary
+	})
+
+	// 过期的分片表被忽略
+	second.ApplyShardMap(shardMap)
+	if _, err := second.AddDoc(ctx, &types.Document{Id: "doc3"}); err != nil {
+		t.Fatalf("过期的分片表不应改变角色: %v", err)
+	}
+}
+
+func TestSentinelRoutesByShard(t *testing.T) {
+	dir := t.TempDir()
+	hub := service_hub.NewMemoryServiceHub()
+	defer hub.Close()
+	primary, primaryAddr, primaryServer := serveWorker(t, filepath.Join(dir, "primary"))
+	replica, replicaAddr, replicaServer := serveWorker(t, filepath.Join(dir, "replica"))
+	defer func() {
+		replica.Close()
+		replicaServer.Stop()
+		primary.Close()
+		primaryServer.Stop()
+	}()
+	registerWorker(t, primary.WithShard(0, service_hub.RolePrimary), hub, primaryAddr)
+	registerWorker(t, replica.WithShard(0, service_hub.RoleReplica), hub, replicaAddr)
+	if err := replica.Follow(primaryAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	// 写请求只发给主副本，轮询负载均衡下也不会落到拒绝写入的从副本上
+	sentinel := index_service.NewSentinelWithHub(hub)
+	for i := 0; i < 4; i++ {
+		if _, err := sentinel.AddDoc(types.Document{
+			Id:       "doc" + strconv.Itoa(i),
+			Keywords: []*types.Keyword{{Field: "content", Word: "go"}},
+		}); err != nil {
+			t.Fatalf("写入文档失败: %v", err)
+		}
+	}
+	waitFor(t, "从副本同步", func() bool { return replica.ChangeLogSeq() == 4 })
+
+	// 读请求每个分片只发给一个副本，文档不会重复
+	check := func(what string) {
+		if count := sentinel.Count(); count != 4 {
+			t.Fatalf("%s: 应有 4 个文档，实际为 %d", what, count)
+		}
+		if result := sentinel.Search(types.NewTermQuery("content", "go"), 0, 0, nil); len(result) != 4 {
+			t.Fatalf("%s: 应检索到 4 个文档，实际为 %d", what, len(result))
+		}
+	}
+	check("按发布的角色路由")
+
+	// 按分片表路由时结果相同，未被分配的备用 worker 不参与路由
+	shardMap := coordinator.NewShardMap(1)
+	shardMap.Epoch = 1
+	shardMap.Shards[0].Primary = primaryAddr
+	shardMap.Shards[0].Replicas = []string{replicaAddr}
+	sentinel.ApplyShardMap(shardMap)
+	standby, standbyAddr, standbyServer := serveWorker(t, filepath.Join(dir, "standby"))
+	defer func() {
+		standby.Close()
+		standbyServer.Stop()
+	}()
+	registerWorker(t, standby, hub, standbyAddr)
+	if _, err := standby.AddDoc(context.Background(), &types.Document{
+		Id:       "stray",
+		Keywords: []*types.Keyword{{Field: "content", Word: "go"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	check("按分片表路由")
+}
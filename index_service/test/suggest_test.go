@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// corrections 把候选词转换为便于比较的 "词:文档数量:编辑距离" 形式
+func corrections(result []*index_service.Correction) []string {
+	words := make([]string, 0, len(result))
+	for _, c := range result {
+		words = append(words, c.Word+":"+strconv.FormatInt(c.DocFreq, 10)+":"+strconv.Itoa(int(c.Distance)))
+	}
+	return words
+}
+
+func TestSentinelDidYouMean(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	startWorker(t, hub, 0)
+	startWorker(t, hub, 1)
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+
+	docs := make([]types.Document, 0, 10)
+	for i := 0; i < 10; i++ {
+		word := "golang"
+		if i == 0 {
+			word = "goland"
+		}
+		docs = append(docs, types.Document{
+			Id:       "doc" + strconv.Itoa(i),
+			Keywords: []*types.Keyword{{Field: "content", Word: word}, {Field: "author", Word: "golang"}},
+		})
+	}
+	if n, errs := sentinel.BatchAddDoc(docs); n != len(docs) {
+		t.Fatalf("应写入 %d 个文档，实际写入 %d 个，错误: %v", len(docs), n, errs)
+	}
+
+	// 分布在两个 worker 上的同一个词，文档数量求和
+	result, err := sentinel.DidYouMean("content", "golnag", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := corrections(result), []string{"golang:9:1", "goland:1:2"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	// 合并之后再截取
+	result, err = sentinel.DidYouMean("content", "golang", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := corrections(result), []string{"golang:9:0"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	// 只在指定字段的词典中查找
+	result, err = sentinel.DidYouMean("author", "goland", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := corrections(result), []string{"golang:10:1"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+}
+
+// suggestions 把候选词转换为便于比较的 "字段:词:文档数量" 形式
+func suggestions(result []*index_service.Suggestion) []string {
+	words := make([]string, 0, len(result))
+	for _, s := range result {
+		words = append(words, s.Field+":"+s.Word+":"+strconv.FormatInt(s.Weight, 10))
+	}
+	return words
+}
+
+func TestSentinelSuggest(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	for i := 0; i < 2; i++ {
+		startConfiguredWorker(t, hub, i, new(index_service.IndexServiceWorker).WithCompletion("content", "author"))
+	}
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+
+	docs := make([]types.Document, 0, 10)
+	for i := 0; i < 10; i++ {
+		keywords := []*types.Keyword{{Field: "content", Word: "golang"}, {Field: "title", Word: "go"}}
+		if i < 3 {
+			keywords = append(keywords, &types.Keyword{Field: "author", Word: "gopher"})
+		}
+		if i < 2 {
+			keywords = append(keywords, &types.Keyword{Field: "content", Word: "go"})
+		}
+		docs = append(docs, types.Document{Id: "doc" + strconv.Itoa(i), Keywords: keywords})
+	}
+	if n, errs := sentinel.BatchAddDoc(docs); n != len(docs) {
+		t.Fatalf("应写入 %d 个文档，实际写入 %d 个，错误: %v", len(docs), n, errs)
+	}
+
+	// 两个 worker 上的文档数量求和，多个字段的候选放在一起排序，没有设置自动补全的 title 字段不返回
+	result, err := sentinel.Suggest("go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := suggestions(result), []string{"content:golang:10", "author:gopher:3", "content:go:2"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	// 指定字段和数量
+	result, err = sentinel.Suggest("go", []string{"content"}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := suggestions(result), []string{"content:golang:10"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+	// 删除文档之后权重随之减少
+	if n := sentinel.DeleteDoc("doc0"); n != 1 {
+		t.Fatalf("应删除 1 个文档，实际为 %d", n)
+	}
+	result, err = sentinel.Suggest("gop", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := suggestions(result), []string{"author:gopher:2"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("应返回 %v，实际为 %v", expect, got)
+	}
+}
+
+func TestSuggestLimit(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	for i := 0; i < 2; i++ {
+		startConfiguredWorker(t, hub, i, new(index_service.IndexServiceWorker).WithCompletion("content"))
+	}
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+
+	n := index_service.MaxSuggestLimit + 20
+	docs := make([]types.Document, 0, n)
+	for i := 0; i < n; i++ {
+		docs = append(docs, keywordDoc("doc"+strconv.Itoa(i), "go"+strconv.Itoa(i)))
+	}
+	if written, errs := sentinel.BatchAddDoc(docs); written != n {
+		t.Fatalf("应写入 %d 个文档，实际写入 %d 个，错误: %v", n, written, errs)
+	}
+
+	// 超过上限的 limit 按上限处理，转发给 worker 的数量同样不超过上限
+	result, err := sentinel.Suggest("go", nil, 10*index_service.MaxSuggestLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != index_service.MaxSuggestLimit {
+		t.Fatalf("应返回 %d 个候选，实际为 %d", index_service.MaxSuggestLimit, len(result))
+	}
+
+	// worker 拒绝负数和超过上限的 limit
+	client := dialWorker(t, hub.GetServiceEndpoints(index_service.IndexService)[0])
+	for _, limit := range []int32{-1, index_service.MaxSuggestLimit + 1} {
+		_, err := client.Suggest(context.Background(), &index_service.SuggestRequest{Prefix: "go", Limit: limit})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("limit 为 %d 时应返回 InvalidArgument，实际为 %v", limit, err)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/types"
+)
+
+func TestUpdateDoc(t *testing.T) {
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "update")); err != nil {
+		t.Fatal(err)
+	}
+	defer indexer.Close()
+
+	if _, err := indexer.UpdateDoc(&index_service.DocPatch{DocId: "doc"}); !index_service.IsDocNotFound(err) {
+		t.Fatalf("更新不存在的文档应返回文档不存在，实际为 %v", err)
+	}
+	if _, err := indexer.AddDoc(types.Document{
+		Id:          "doc",
+		BitsFeature: 0b01,
+		Keywords:    []*types.Keyword{{Field: "tag", Word: "go"}, {Field: "tag", Word: "java"}},
+		Bytes:       []byte("v1"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := indexer.UpdateDoc(&index_service.DocPatch{
+		DocId:          "doc",
+		AddKeywords:    []*types.Keyword{{Field: "tag", Word: "rust"}, {Field: "tag", Word: "go"}},
+		RemoveKeywords: []*types.Keyword{{Field: "tag", Word: "java"}},
+		SetBits:        0b10,
+		ClearBits:      0b01,
+		IfVersion:      1,
+	})
+	if err != nil || version != 2 {
+		t.Fatalf("更新后的版本号应为 2，实际为 %d，错误: %v", version, err)
+	}
+	if result := indexer.Search(types.NewTermQuery("tag", "java"), 0, 0, nil); len(result) != 0 {
+		t.Fatalf("删除的关键词不应再检索到文档，实际检索到 %d 个", len(result))
+	}
+	// 保留的关键词的倒排链中的位特征也要更新
+	if result := indexer.Search(types.NewTermQuery("tag", "go"), 0b10, 0b01, nil); len(result) != 1 {
+		t.Fatalf("按新的位特征应检索到 1 个文档，实际为 %d", len(result))
+	}
+	result := indexer.Search(types.NewTermQuery("tag", "rust"), 0, 0, nil)
+	if len(result) != 1 || len(result[0].Keywords) != 2 || string(result[0].Bytes) != "v1" {
+		t.Fatalf("新增的关键词应检索到更新后的文档，实际为 %v", result)
+	}
+
+	if _, err := indexer.UpdateDoc(&index_service.DocPatch{DocId: "doc", IfVersion: 1}); !index_service.IsVersionConflict(err) {
+		t.Fatalf("版本号不一致时应返回版本冲突，实际为 %v", err)
+	}
+	if _, err := indexer.UpdateDoc(&index_service.DocPatch{DocId: "doc", Bytes: []byte("v2"), ReplaceBytes: true}); err != nil {
+		t.Fatal(err)
+	}
+	if result := indexer.Search(types.NewTermQuery("tag", "go"), 0, 0, nil); len(result) != 1 || string(result[0].Bytes) != "v2" {
+		t.Fatalf("Bytes 应被替换，实际为 %v", result)
+	}
+	if count := indexer.Count(); count != 1 {
+		t.Fatalf("应有 1 个文档，实际为 %d", count)
+	}
+}
+
+func TestReplicateUpdateDoc(t *testing.T) {
+	dir := t.TempDir()
+	primary, primaryAddr, primaryServer := serveWorker(t, filepath.Join(dir, "primary"))
+	defer func() {
+		primary.Close()
+		primaryServer.Stop()
+	}()
+	replica, _, replicaServer := serveWorker(t, filepath.Join(dir, "replica"))
+	defer func() {
+		replica.Close()
+		replicaServer.Stop()
+	}()
+	if err := replica.Follow(primaryAddr); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	if _, err := primary.AddDoc(ctx, &types.Document{
+		Id:       "doc",
+		Keywords: []*types.Keyword{{Field: "tag", Word: "go"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := primary.UpdateDoc(ctx, &index_service.DocPatch{
+		DocId:          "doc",
+		AddKeywords:    []*types.Keyword{{Field: "tag", Word: "rust"}},
+		RemoveKeywords: []*types.Keyword{{Field: "tag", Word: "go"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "从副本同步", func() bool { return replica.ChangeLogSeq() == 2 })
+	result := replica.Indexer.Search(types.NewTermQuery("tag", "rust"), 0, 0, nil)
+	if len(result) != 1 || result[0].Version != 2 {
+		t.Fatalf("从副本应检索到版本号为 2 的文档，实际为 %v", result)
+	}
+	if result := replica.Indexer.Search(types.NewTermQuery("tag", "go"), 0, 0, nil); len(result) != 0 {
+		t.Fatalf("从副本上删除的关键词不应再检索到文档，实际检索到 %d 个", len(result))
+	}
+}
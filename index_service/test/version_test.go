@@ -0,0 +1,125 @@
+package test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jmh000527/criker-search/index/kv_db"
+	"github.com/jmh000527/criker-search/index_service"
+	"github.com/jmh000527/criker-search/index_service/load_balancer"
+	"github.com/jmh000527/criker-search/index_service/service_hub"
+	"github.com/jmh000527/criker-search/types"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDocVersion(t *testing.T) {
+	indexer := new(index_service.LocalIndexer)
+	if err := indexer.Init(100, kv_db.BOLT, filepath.Join(t.TempDir(), "version")); err != nil {
+		t.Fatal(err)
+	}
+	defer indexer.Close()
+
+	doc := types.Document{Id: "doc", Keywords: []*types.Keyword{{Field: "content", Word: "go"}}}
+	// 不存在时才写入
+	if version, err := indexer.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: -1}); err != nil || version != 1 {
+		t.Fatalf("首次写入的版本号应为 1，实际为 %d，错误: %v", version, err)
+	}
+	if _, err := indexer.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: -1}); !index_service.IsVersionConflict(err) {
+		t.Fatalf("文档已存在时应返回版本冲突，实际为 %v", err)
+	}
+	// 不检查版本时版本号加1
+	if _, err := indexer.AddDoc(doc); err != nil {
+		t.Fatal(err)
+	}
+	// 版本号不一致时拒绝写入
+	if _, err := indexer.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: 1}); !index_service.IsVersionConflict(err) {
+		t.Fatalf("版本号不一致时应返回版本冲突，实际为 %v", err)
+	}
+	doc.Keywords = []*types.Keyword{{Field: "content", Word: "rust"}}
+	if version, err := indexer.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: 2}); err != nil || version != 3 {
+		t.Fatalf("版本号一致时应写入成功，版本号为 3，实际为 %d，错误: %v", version, err)
+	}
+	if result := indexer.Search(types.NewTermQuery("content", "go"), 0, 0, nil); len(result) != 0 {
+		t.Fatalf("旧文档应从倒排索引中删除，实际检索到 %d 个", len(result))
+	}
+	if result := indexer.Search(types.NewTermQuery("content", "rust"), 0, 0, nil); len(result) != 1 || result[0].Version != 3 {
+		t.Fatalf("应检索到版本号为 3 的新文档，实际为 %v", result)
+	}
+
+	// 外部版本号：只有更新的版本才能写入
+	doc.Version = 10
+	if version, err := indexer.AddDocWithOptions(doc, index_service.WriteOptions{ExternalVersion: true}); err != nil || version != 10 {
+		t.Fatalf("外部版本号应写入成功，版本号为 10，实际为 %d，错误: %v", version, err)
+	}
+	doc.Version = 5
+	if _, err := indexer.AddDocWithOptions(doc, index_service.WriteOptions{ExternalVersion: true}); !index_service.IsVersionConflict(err) {
+		t.Fatalf("过期的外部版本号应返回版本冲突，实际为 %v", err)
+	}
+	if count := indexer.Count(); count != 1 {
+		t.Fatalf("应有 1 个文档，实际为 %d", count)
+	}
+
+	// 批量写入同样分配版本号
+	docs := []types.Document{{Id: "doc"}, {Id: "doc2"}}
+	if n, errs := indexer.BatchAddDoc(docs); n != 2 {
+		t.Fatalf("应写入 2 个文档，实际为 %d，错误: %v", n, errs)
+	}
+	if docs[0].Version != 11 || docs[1].Version != 1 {
+		t.Fatalf("批量写入的版本号应为 11 和 1，实际为 %d 和 %d", docs[0].Version, docs[1].Version)
+	}
+}
+
+func TestSentinelVersionConflict(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	startWorker(t, hub, 0)
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+
+	doc := types.Document{Id: "doc", Keywords: []*types.Keyword{{Field: "content", Word: "go"}}}
+	version, err := sentinel.AddDocWithOptions(doc, index_service.WriteOptions{})
+	if err != nil || version != 1 {
+		t.Fatalf("写入的版本号应为 1，实际为 %d，错误: %v", version, err)
+	}
+	_, err = sentinel.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: 2})
+	if status.Code(err) != codes.Aborted || !index_service.IsVersionConflict(err) {
+		t.Fatalf("版本冲突应返回 Aborted，实际为 %v", err)
+	}
+	if version, err := sentinel.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: 1}); err != nil || version != 2 {
+		t.Fatalf("写入的版本号应为 2，实际为 %d，错误: %v", version, err)
+	}
+	// 版本冲突不计入节点故障，节点仍然可用
+	if endpoints := hub.GetServiceEndpoints(index_service.IndexService); len(endpoints) != 1 {
+		t.Fatalf("worker 应仍然可用，实际为 %v", endpoints)
+	}
+}
+
+func TestSentinelVersionedWriteNeedsKeyedRouting(t *testing.T) {
+	hub := service_hub.NewMemoryServiceHub()
+	startWorker(t, hub, 0)
+	startWorker(t, hub, 1)
+	sentinel := index_service.NewSentinelWithHub(hub)
+	defer sentinel.Close()
+
+	// 轮询时文档可能写在任意一个主副本上，版本检查和局部更新无法保证在文档所在节点上执行
+	doc := types.Document{Id: "doc", Keywords: []*types.Keyword{{Field: "content", Word: "go"}}}
+	if _, err := sentinel.AddDocWithOptions(doc, index_service.WriteOptions{}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("轮询时带版本检查的写入应返回 FailedPrecondition，实际为 %v", err)
+	}
+	if _, err := sentinel.UpdateDoc(&index_service.DocPatch{DocId: "doc"}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("轮询时局部更新应返回 FailedPrecondition，实际为 %v", err)
+	}
+
+	// 一致性哈希总是把同一个文档路由到同一个主副本
+	keyedHub := service_hub.NewMemoryServiceHub()
+	startWorker(t, keyedHub, 2)
+	startWorker(t, keyedHub, 3)
+	sentinel = index_service.NewSentinelWithHub(keyedHub).WithLoadBalancer(load_balancer.NewConsistentHash(load_balancer.DefaultVirtualNodes))
+	defer sentinel.Close()
+	if version, err := sentinel.AddDocWithOptions(doc, index_service.WriteOptions{}); err != nil || version != 1 {
+		t.Fatalf("写入的版本号应为 1，实际为 %d，错误: %v", version, err)
+	}
+	if version, err := sentinel.AddDocWithOptions(doc, index_service.WriteOptions{IfVersion: 1}); err != nil || version != 2 {
+		t.Fatalf("写入的版本号应为 2，实际为 %d，错误: %v", version, err)
+	}
+}
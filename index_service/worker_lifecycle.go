@@ -0,0 +1,101 @@
+package index_service
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sync/atomic"
+	"time"
+)
+
+// lifecycle IndexServiceWorker 的生命周期状态：注册续约、就绪状态和在途请求统计。
+type lifecycle struct {
+	ready    atomic.Bool // 索引是否已加载完成，未就绪时不注册服务、拒绝请求
+	draining atomic.Bool // 是否正在下线，下线期间拒绝新的请求
+	inflight int64       // 在途请求数
+
+	stopRegistration context.CancelFunc // 停止续约
+	registrationDone chan struct{}      // 续约协程退出后关闭
+}
+
+// WithAdvertiseAddr 设置注册到服务中心的地址（host:port）。
+// worker 监听的地址与其他节点访问它的地址不同时（例如容器、NAT、多网卡）需要显式指定。
+func (w *IndexServiceWorker) WithAdvertiseAddr(addr string) *IndexServiceWorker {
+	w.advertiseAddr = addr
+	return w
+}
+
+// WithDrainTimeout 设置下线时等待在途请求完成的最长时间。
+func (w *IndexServiceWorker) WithDrainTimeout(timeout time.Duration) *IndexServiceWorker {
+	w.drainTimeout = timeout
+	return w
+}
+
+// MarkReady 标记索引已加载完成，之后才能注册服务、处理请求。
+// LoadFromIndexFile 会自动调用；通过其他方式（例如从原始数据重建）构建索引后需要手动调用。
+func (w *IndexServiceWorker) MarkReady() {
+	w.ready.Store(true)
+}
+
+// ServerOptions 返回创建 gRPC 服务器时需要的选项。
+// 其中的拦截器在索引就绪之前和下线期间拒绝请求，并统计在途请求，使 Close 能够等待在途请求完成。
+//
+// 返回值:
+//   - []grpc.ServerOption: 传给 grpc.NewServer 的选项。
+func (w *IndexServiceWorker) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(w.unaryInterceptor),
+		grpc.ChainStreamInterceptor(w.streamInterceptor),
+	}
+}
+
+// unaryInterceptor 一元 RPC 的拦截器
+func (w *IndexServiceWorker) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := w.acquire(); err != nil {
+		return nil, err
+	}
+	defer w.release()
+	return handler(ctx, req)
+}
+
+// streamInterceptor 流式 RPC 的拦截器
+func (w *IndexServiceWorker) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := w.acquire(); err != nil {
+		return err
+	}
+	defer w.release()
+	return handler(srv, stream)
+}
+
+// acquire 开始处理一个请求。未就绪（包括从备份恢复期间）或正在下线时返回 Unavailable，Sentinel 会把它计为节点不可用。
+func (w *IndexServiceWorker) acquire() error {
+	atomic.AddInt64(&w.inflight, 1)
+	// 先计数再检查，保证 drain 或 Restore 看到在途请求数降下来之后不会再有请求开始处理
+	if !w.ready.Load() {
+		atomic.AddInt64(&w.inflight, -1)
+		return status.Error(codes.Unavailable, "索引尚未就绪")
+	}
+	if w.draining.Load() {
+		atomic.AddInt64(&w.inflight, -1)
+		return status.Error(codes.Unavailable, "服务正在下线")
+	}
+	return nil
+}
+
+// release 结束处理一个请求
+func (w *IndexServiceWorker) release() {
+	atomic.AddInt64(&w.inflight, -1)
+}
+
+// drain 拒绝新的请求，并等待在途请求完成。
+//
+// 参数:
+//   - timeout: 最长等待时间。
+//
+// 返回值:
+//   - bool: 在途请求全部完成时返回 true，超时返回 false。
+func (w *IndexServiceWorker) drain(timeout time.Duration) bool {
+	w.draining.Store(true)
+	return w.waitInflight(0, timeout)
+}
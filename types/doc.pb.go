@@ -80,6 +80,8 @@ type Document struct {
 	BitsFeature uint64     `protobuf:"varint,3,opt,name=BitsFeature,proto3" json:"BitsFeature,omitempty"`
 	Keywords    []*Keyword `protobuf:"bytes,4,rep,name=Keywords,proto3" json:"Keywords,omitempty"`
 	Bytes       []byte     `protobuf:"bytes,5,opt,name=Bytes,proto3" json:"Bytes,omitempty"`
+	Version     int64      `protobuf:"varint,6,opt,name=Version,proto3" json:"Version,omitempty"`
+	ExpireAt    int64      `protobuf:"varint,7,opt,name=ExpireAt,proto3" json:"ExpireAt,omitempty"`
 }
 
 func (m *Document) Reset()         { *m = Document{} }
@@ -150,6 +152,20 @@ func (m *Document) GetBytes() []byte {
 	return nil
 }
 
+func (m *Document) GetVersion() int64 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *Document) GetExpireAt() int64 {
+	if m != nil {
+		return m.ExpireAt
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Keyword)(nil), "types.Keyword")
 	proto.RegisterType((*Document)(nil), "types.Document")
@@ -158,21 +174,23 @@ func init() {
 func init() { proto.RegisterFile("doc.proto", fileDescriptor_37cb16cf10c66117) }
 
 var fileDescriptor_37cb16cf10c66117 = []byte{
-	// 212 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0xe2, 0x4c, 0xc9, 0x4f, 0xd6,
-	0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2d, 0xa9, 0x2c, 0x48, 0x2d, 0x56, 0x32, 0xe6, 0x62,
-	0xf7, 0x4e, 0xad, 0x2c, 0xcf, 0x2f, 0x4a, 0x11, 0x12, 0xe1, 0x62, 0x75, 0xcb, 0x4c, 0xcd, 0x49,
-	0x91, 0x60, 0x54, 0x60, 0xd4, 0xe0, 0x0c, 0x82, 0x70, 0x84, 0x84, 0xb8, 0x58, 0xc2, 0xf3, 0x8b,
-	0x52, 0x24, 0x98, 0xc0, 0x82, 0x60, 0xb6, 0xd2, 0x14, 0x46, 0x2e, 0x0e, 0x97, 0xfc, 0xe4, 0xd2,
-	0xdc, 0xd4, 0xbc, 0x12, 0x21, 0x3e, 0x2e, 0x26, 0x4f, 0x98, 0x1e, 0x26, 0x4f, 0xb0, 0x31, 0x9e,
-	0x79, 0x25, 0x9e, 0x10, 0x1d, 0x2c, 0x41, 0x10, 0x8e, 0x90, 0x02, 0x17, 0xb7, 0x53, 0x66, 0x49,
-	0xb1, 0x5b, 0x6a, 0x62, 0x49, 0x69, 0x51, 0xaa, 0x04, 0x33, 0x58, 0x0e, 0x59, 0x48, 0x48, 0x8b,
-	0x8b, 0x03, 0xea, 0x92, 0x62, 0x09, 0x16, 0x05, 0x66, 0x0d, 0x6e, 0x23, 0x3e, 0x3d, 0xb0, 0x1b,
-	0xf5, 0xa0, 0xc2, 0x41, 0x70, 0x79, 0x90, 0x1d, 0x4e, 0x95, 0x25, 0xa9, 0xc5, 0x12, 0xac, 0x0a,
-	0x8c, 0x1a, 0x3c, 0x41, 0x10, 0x8e, 0x93, 0xc4, 0x89, 0x47, 0x72, 0x8c, 0x17, 0x1e, 0xc9, 0x31,
-	0x3e, 0x78, 0x24, 0xc7, 0x38, 0xe1, 0xb1, 0x1c, 0xc3, 0x85, 0xc7, 0x72, 0x0c, 0x37, 0x1e, 0xcb,
-	0x31, 0x24, 0xb1, 0x81, 0xfd, 0x6c, 0x0c, 0x08, 0x00, 0x00, 0xff, 0xff, 0x35, 0xf4, 0xa5, 0x58,
-	0x00, 0x01, 0x00, 0x00,
+	// 246 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x4c, 0x90, 0xbf, 0x4e, 0xc3, 0x30,
+	0x10, 0xc6, 0xe3, 0xfc, 0x69, 0xd2, 0x2b, 0xea, 0x60, 0x31, 0x9c, 0x18, 0x2c, 0xab, 0x53, 0xc4,
+	0x90, 0x81, 0x3e, 0x01, 0x11, 0x54, 0x8a, 0xd8, 0x3c, 0xc0, 0x0c, 0xcd, 0x0d, 0x91, 0x20, 0x8e,
+	0x6c, 0x57, 0x90, 0xb7, 0xe0, 0xb1, 0x10, 0x53, 0x47, 0x46, 0x94, 0xbc, 0x08, 0xc2, 0x69, 0xab,
+	0x6e, 0xf7, 0xfb, 0x3e, 0x7d, 0x77, 0x9f, 0x0e, 0xe6, 0xb5, 0xde, 0x16, 0x9d, 0xd1, 0x4e, 0xf3,
+	0xc4, 0xf5, 0x1d, 0xd9, 0xd5, 0x1a, 0xd2, 0x07, 0xea, 0xdf, 0xb5, 0xa9, 0xf9, 0x25, 0x24, 0x9b,
+	0x86, 0x5e, 0x6b, 0x64, 0x92, 0xe5, 0x73, 0x35, 0x01, 0xe7, 0x10, 0x3f, 0x69, 0x53, 0x63, 0xe8,
+	0x45, 0x3f, 0xaf, 0xbe, 0x19, 0x64, 0x77, 0x7a, 0xbb, 0x7b, 0xa3, 0xd6, 0xf1, 0x25, 0x84, 0xd5,
+	0x31, 0x13, 0x56, 0x7e, 0x4d, 0xd5, 0xba, 0x6a, 0x4a, 0xc4, 0x6a, 0x02, 0x2e, 0x61, 0x51, 0x36,
+	0xce, 0x6e, 0xe8, 0xd9, 0xed, 0x0c, 0x61, 0xe4, 0xbd, 0x73, 0x89, 0x5f, 0x43, 0x76, 0x68, 0x62,
+	0x31, 0x96, 0x51, 0xbe, 0xb8, 0x59, 0x16, 0xbe, 0x63, 0x71, 0x90, 0xd5, 0xc9, 0xff, 0xbf, 0x51,
+	0xf6, 0x8e, 0x2c, 0x26, 0x92, 0xe5, 0x17, 0x6a, 0x02, 0x8e, 0x90, 0x3e, 0x92, 0xb1, 0x8d, 0x6e,
+	0x71, 0x26, 0x59, 0x1e, 0xa9, 0x23, 0xf2, 0x2b, 0xc8, 0xee, 0x3f, 0xba, 0xc6, 0xd0, 0xad, 0xc3,
+	0xd4, 0x5b, 0x27, 0x2e, 0xf1, 0x6b, 0x10, 0x6c, 0x3f, 0x08, 0xf6, 0x3b, 0x08, 0xf6, 0x39, 0x8a,
+	0x60, 0x3f, 0x8a, 0xe0, 0x67, 0x14, 0xc1, 0xcb, 0xcc, 0x7f, 0x6a, 0xfd, 0x37, 0x00, 0x03, 0xa7,
+	0xbf, 0x17, 0x36, 0x01, 0x00, 0x00,
 }
 
 func (m *Keyword) Marshal() (dAtA []byte, err error) {
@@ -232,6 +250,16 @@ func (m *Document) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.ExpireAt != 0 {
+		i = encodeVarintDoc(dAtA, i, uint64(m.ExpireAt))
+		i--
+		dAtA[i] = 0x38
+	}
+	if m.Version != 0 {
+		i = encodeVarintDoc(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x30
+	}
 	if len(m.Bytes) > 0 {
 		i -= len(m.Bytes)
 		copy(dAtA[i:], m.Bytes)
@@ -327,6 +355,12 @@ func (m *Document) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovDoc(uint64(l))
 	}
+	if m.Version != 0 {
+		n += 1 + sovDoc(uint64(m.Version))
+	}
+	if m.ExpireAt != 0 {
+		n += 1 + sovDoc(uint64(m.ExpireAt))
+	}
 	return n
 }
 
@@ -617,6 +651,44 @@ func (m *Document) Unmarshal(dAtA []byte) error {
 				m.Bytes = []byte{}
 			}
 			iNdEx = postIndex
+		case 6:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDoc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpireAt", wireType)
+			}
+			m.ExpireAt = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowDoc
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ExpireAt |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipDoc(dAtA[iNdEx:])
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"strconv"
 	"strings"
 )
 
@@ -25,6 +26,49 @@ func NewTermQuery(field, keyword string) *TermQuery {
 	}
 }
 
+// NewMultiFieldQuery 创建一个多字段查询：在 fields 中的每个字段上检索 word，文档命中任意一个字段即匹配，
+// 检索结果按命中字段的权重之和从高到低排序。
+//
+// 参数:
+//   - word: 查询的关键词。
+//   - fields: 检索的字段及其权重，权重<=0时按1计算。
+//
+// 返回值:
+//   - *TermQuery: 一个新的 TermQuery 实例。fields 为空时返回空的查询。
+func NewMultiFieldQuery(word string, fields ...*FieldBoost) *TermQuery {
+	if len(fields) == 0 {
+		return new(TermQuery)
+	}
+	return &TermQuery{
+		Keyword: &Keyword{Word: word},
+		Fields:  fields,
+	}
+}
+
+// Weight 返回命中该字段时文档的得分，Boost<=0时为1
+func (fb *FieldBoost) Weight() float64 {
+	if fb.Boost <= 0 {
+		return 1
+	}
+	return float64(fb.Boost)
+}
+
+// FieldKeywords 返回叶子节点实际检索的关键词：多字段查询时为每个字段上的 Keyword.Word，否则只有 Keyword 本身。
+// 非叶子节点返回 nil。
+func (q *TermQuery) FieldKeywords() []*Keyword {
+	if q.Keyword == nil {
+		return nil
+	}
+	if len(q.Fields) == 0 {
+		return []*Keyword{q.Keyword}
+	}
+	keywords := make([]*Keyword, 0, len(q.Fields))
+	for _, field := range q.Fields {
+		keywords = append(keywords, &Keyword{Field: field.Field, Word: q.Keyword.Word})
+	}
+	return keywords
+}
+
 // Empty 检查 TermQuery 是否为空。
 // 一个 TermQuery 被认为是空的，当且仅当其 Keyword 为 nil，并且 Must 和 Should 列表都为空。
 //
@@ -95,7 +139,8 @@ func (q *TermQuery) Or(queries ...*TermQuery) *TermQuery {
 }
 
 // ToString 返回 TermQuery 的字符串表示形式。
-// 如果 TermQuery 的 Keyword 成员非空，则返回 Keyword 的字符串表示。
+// 如果 TermQuery 的 Keyword 成员非空，则返回 Keyword 的字符串表示；多字段查询返回各个字段上的关键词用逻辑或（|）连接的形式，
+// 权重不为1的字段在后面加上“^权重”。
 // 如果 TermQuery 的 Must 列表非空，则返回所有 Must 查询的组合表示形式，用逻辑与（&）连接。
 // 如果 TermQuery 的 Should 列表非空，则返回所有 Should 查询的组合表示形式，用逻辑或（|）连接。
 // 如果 TermQuery 既没有 Keyword，也没有 Must 或 Should 列表，则返回空字符串。
@@ -103,7 +148,22 @@ func (q *TermQuery) Or(queries ...*TermQuery) *TermQuery {
 // 返回值:
 //   - string: TermQuery 的字符串表示形式。
 func (q *TermQuery) ToString() string {
-	if q.Keyword != nil {
+	if q.Keyword != nil && len(q.Fields) > 0 {
+		// 多字段查询，展开为各个字段上的关键词
+		sb := strings.Builder{}
+		sb.WriteByte('(')
+		for i, field := range q.Fields {
+			if i > 0 {
+				sb.WriteByte('|')
+			}
+			sb.WriteString(field.Field + "\001" + q.Keyword.Word)
+			if weight := field.Weight(); weight != 1 {
+				sb.WriteString("^" + strconv.FormatFloat(weight, 'g', -1, 32))
+			}
+		}
+		sb.WriteByte(')')
+		return sb.String()
+	} else if q.Keyword != nil {
 		// 如果 Keyword 非空，直接返回 Keyword 的字符串表示。
 		return q.Keyword.ToString()
 	} else if len(q.Must) > 0 {
@@ -150,3 +210,39 @@ func (q *TermQuery) ToString() string {
 	// 如果 TermQuery 既没有 Keyword 也没有 Must 或 Should 列表，返回空字符串。
 	return ""
 }
+
+// Matches 判断包含关键词 keywords 的文档是否符合查询条件：Keyword 要求文档包含该关键词（多字段查询时在任意一个字段上包含），
+// Must 要求满足所有子查询，Should 要求至少满足一个子查询。空的查询不匹配任何文档。
+//
+// 参数:
+//   - keywords: 文档的关键词列表。
+//
+// 返回值:
+//   - bool: 文档符合查询条件时返回 true。
+func (q *TermQuery) Matches(keywords []*Keyword) bool {
+	if q.Keyword != nil {
+		for _, target := range q.FieldKeywords() {
+			key := target.ToString()
+			for _, keyword := range keywords {
+				if keyword.ToString() == key {
+					return true
+				}
+			}
+		}
+		return false
+	} else if len(q.Must) > 0 {
+		for _, query := range q.Must {
+			if !query.Matches(keywords) {
+				return false
+			}
+		}
+		return true
+	} else if len(q.Should) > 0 {
+		for _, query := range q.Should {
+			if query.Matches(keywords) {
+				return true
+			}
+		}
+	}
+	return false
+}
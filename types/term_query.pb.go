@@ -4,6 +4,7 @@
 package types
 
 import (
+	encoding_binary "encoding/binary"
 	fmt "fmt"
 	proto "github.com/gogo/protobuf/proto"
 	io "io"
@@ -22,17 +23,70 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type FieldBoost struct {
+	Field string  `protobuf:"bytes,1,opt,name=Field,proto3" json:"Field,omitempty"`
+	Boost float32 `protobuf:"fixed32,2,opt,name=Boost,proto3" json:"Boost,omitempty"`
+}
+
+func (m *FieldBoost) Reset()         { *m = FieldBoost{} }
+func (m *FieldBoost) String() string { return proto.CompactTextString(m) }
+func (*FieldBoost) ProtoMessage()    {}
+func (*FieldBoost) Descriptor() ([]byte, []int) {
+	return fileDescriptor_cbb9280914c3e3fe, []int{0}
+}
+func (m *FieldBoost) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *FieldBoost) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_FieldBoost.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *FieldBoost) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FieldBoost.Merge(m, src)
+}
+func (m *FieldBoost) XXX_Size() int {
+	return m.Size()
+}
+func (m *FieldBoost) XXX_DiscardUnknown() {
+	xxx_messageInfo_FieldBoost.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FieldBoost proto.InternalMessageInfo
+
+func (m *FieldBoost) GetField() string {
+	if m != nil {
+		return m.Field
+	}
+	return ""
+}
+
+func (m *FieldBoost) GetBoost() float32 {
+	if m != nil {
+		return m.Boost
+	}
+	return 0
+}
+
 type TermQuery struct {
-	Keyword *Keyword     `protobuf:"bytes,1,opt,name=Keyword,proto3" json:"Keyword,omitempty"`
-	Must    []*TermQuery `protobuf:"bytes,2,rep,name=Must,proto3" json:"Must,omitempty"`
-	Should  []*TermQuery `protobuf:"bytes,3,rep,name=Should,proto3" json:"Should,omitempty"`
+	Keyword *Keyword      `protobuf:"bytes,1,opt,name=Keyword,proto3" json:"Keyword,omitempty"`
+	Must    []*TermQuery  `protobuf:"bytes,2,rep,name=Must,proto3" json:"Must,omitempty"`
+	Should  []*TermQuery  `protobuf:"bytes,3,rep,name=Should,proto3" json:"Should,omitempty"`
+	Fields  []*FieldBoost `protobuf:"bytes,4,rep,name=Fields,proto3" json:"Fields,omitempty"`
 }
 
 func (m *TermQuery) Reset()         { *m = TermQuery{} }
 func (m *TermQuery) String() string { return proto.CompactTextString(m) }
 func (*TermQuery) ProtoMessage()    {}
 func (*TermQuery) Descriptor() ([]byte, []int) {
-	return fileDescriptor_cbb9280914c3e3fe, []int{0}
+	return fileDescriptor_cbb9280914c3e3fe, []int{1}
 }
 func (m *TermQuery) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -82,26 +136,72 @@ func (m *TermQuery) GetShould() []*TermQuery {
 	return nil
 }
 
+func (m *TermQuery) GetFields() []*FieldBoost {
+	if m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
 func init() {
+	proto.RegisterType((*FieldBoost)(nil), "types.FieldBoost")
 	proto.RegisterType((*TermQuery)(nil), "types.TermQuery")
 }
 
 func init() { proto.RegisterFile("term_query.proto", fileDescriptor_cbb9280914c3e3fe) }
 
 var fileDescriptor_cbb9280914c3e3fe = []byte{
-	// 177 bytes of a gzipped FileDescriptorProto
+	// 219 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x28, 0x49, 0x2d, 0xca,
 	0x8d, 0x2f, 0x2c, 0x4d, 0x2d, 0xaa, 0xd4, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x62, 0x2d, 0xa9,
-	0x2c, 0x48, 0x2d, 0x96, 0x92, 0x00, 0x53, 0xfa, 0x29, 0xf9, 0xc9, 0xa5, 0xb9, 0xa9, 0x79, 0x25,
-	0x20, 0x06, 0x44, 0x81, 0x52, 0x2b, 0x23, 0x17, 0x67, 0x48, 0x6a, 0x51, 0x6e, 0x20, 0x48, 0x93,
-	0x90, 0x06, 0x17, 0xbb, 0x77, 0x6a, 0x65, 0x79, 0x7e, 0x51, 0x8a, 0x04, 0xa3, 0x02, 0xa3, 0x06,
-	0xb7, 0x11, 0x9f, 0x1e, 0x58, 0xa7, 0x1e, 0x54, 0x34, 0x08, 0x26, 0x2d, 0xa4, 0xc2, 0xc5, 0xe2,
-	0x5b, 0x5a, 0x5c, 0x22, 0xc1, 0xa4, 0xc0, 0xac, 0xc1, 0x6d, 0x24, 0x00, 0x55, 0x06, 0x37, 0x29,
-	0x08, 0x2c, 0x2b, 0xa4, 0xc1, 0xc5, 0x16, 0x9c, 0x91, 0x5f, 0x9a, 0x93, 0x22, 0xc1, 0x8c, 0x43,
-	0x1d, 0x54, 0xde, 0x49, 0xe2, 0xc4, 0x23, 0x39, 0xc6, 0x0b, 0x8f, 0xe4, 0x18, 0x1f, 0x3c, 0x92,
-	0x63, 0x9c, 0xf0, 0x58, 0x8e, 0xe1, 0xc2, 0x63, 0x39, 0x86, 0x1b, 0x8f, 0xe5, 0x18, 0x92, 0xd8,
-	0xc0, 0x0e, 0x35, 0x06, 0x04, 0x00, 0x00, 0xff, 0xff, 0x7c, 0xf7, 0x2a, 0x3a, 0xdd, 0x00, 0x00,
-	0x00,
+	0x2c, 0x48, 0x2d, 0x96, 0xe2, 0x4c, 0xc9, 0x4f, 0x86, 0x88, 0x28, 0x59, 0x70, 0x71, 0xb9, 0x65,
+	0xa6, 0xe6, 0xa4, 0x38, 0xe5, 0xe7, 0x17, 0x97, 0x08, 0x89, 0x70, 0xb1, 0x82, 0x79, 0x12, 0x8c,
+	0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x10, 0x0e, 0x48, 0x14, 0x2c, 0x2d, 0xc1, 0xa4, 0xc0, 0xa8, 0xc1,
+	0x14, 0x04, 0xe1, 0x28, 0x6d, 0x60, 0xe4, 0xe2, 0x0c, 0x49, 0x2d, 0xca, 0x0d, 0x04, 0x99, 0x2f,
+	0xa4, 0xc1, 0xc5, 0xee, 0x9d, 0x5a, 0x59, 0x9e, 0x5f, 0x04, 0xd1, 0xcb, 0x6d, 0xc4, 0xa7, 0x07,
+	0xb6, 0x4b, 0x0f, 0x2a, 0x1a, 0x04, 0x93, 0x16, 0x52, 0xe1, 0x62, 0xf1, 0x2d, 0x05, 0x1b, 0xc6,
+	0xac, 0xc1, 0x6d, 0x24, 0x00, 0x55, 0x06, 0x37, 0x29, 0x08, 0x2c, 0x2b, 0xa4, 0xc1, 0xc5, 0x16,
+	0x9c, 0x91, 0x5f, 0x9a, 0x93, 0x22, 0xc1, 0x8c, 0x43, 0x1d, 0x54, 0x5e, 0x48, 0x93, 0x8b, 0x0d,
+	0xec, 0xcc, 0x62, 0x09, 0x16, 0xb0, 0x4a, 0x41, 0xa8, 0x4a, 0x84, 0xb7, 0x82, 0xa0, 0x0a, 0x9c,
+	0x24, 0x4e, 0x3c, 0x92, 0x63, 0xbc, 0xf0, 0x48, 0x8e, 0xf1, 0xc1, 0x23, 0x39, 0xc6, 0x09, 0x8f,
+	0xe5, 0x18, 0x2e, 0x3c, 0x96, 0x63, 0xb8, 0xf1, 0x58, 0x8e, 0x21, 0x89, 0x0d, 0x1c, 0x1a, 0xc6,
+	0x80, 0x01, 0x00, 0x5d, 0xac, 0xee, 0xe4, 0x33, 0x01, 0x00, 0x00,
+}
+
+func (m *FieldBoost) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *FieldBoost) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *FieldBoost) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Boost != 0 {
+		i -= 4
+		encoding_binary.LittleEndian.PutUint32(dAtA[i:], uint32(math.Float32bits(float32(m.Boost))))
+		i--
+		dAtA[i] = 0x15
+	}
+	if len(m.Field) > 0 {
+		i -= len(m.Field)
+		copy(dAtA[i:], m.Field)
+		i = encodeVarintTermQuery(dAtA, i, uint64(len(m.Field)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
 }
 
 func (m *TermQuery) Marshal() (dAtA []byte, err error) {
@@ -124,6 +224,20 @@ func (m *TermQuery) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if len(m.Fields) > 0 {
+		for iNdEx := len(m.Fields) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Fields[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintTermQuery(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x22
+		}
+	}
 	if len(m.Should) > 0 {
 		for iNdEx := len(m.Should) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -178,6 +292,22 @@ func encodeVarintTermQuery(dAtA []byte, offset int, v uint64) int {
 	dAtA[offset] = uint8(v)
 	return base
 }
+func (m *FieldBoost) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Field)
+	if l > 0 {
+		n += 1 + l + sovTermQuery(uint64(l))
+	}
+	if m.Boost != 0 {
+		n += 5
+	}
+	return n
+}
+
 func (m *TermQuery) Size() (n int) {
 	if m == nil {
 		return 0
@@ -200,6 +330,12 @@ func (m *TermQuery) Size() (n int) {
 			n += 1 + l + sovTermQuery(uint64(l))
 		}
 	}
+	if len(m.Fields) > 0 {
+		for _, e := range m.Fields {
+			l = e.Size()
+			n += 1 + l + sovTermQuery(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -209,6 +345,99 @@ func sovTermQuery(x uint64) (n int) {
 func sozTermQuery(x uint64) (n int) {
 	return sovTermQuery(uint64((x << 1) ^ uint64((int64(x) >> 63))))
 }
+func (m *FieldBoost) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowTermQuery
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: FieldBoost: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: FieldBoost: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Field", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTermQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthTermQuery
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthTermQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Field = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 5 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Boost", wireType)
+			}
+			var v uint32
+			if (iNdEx + 4) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint32(encoding_binary.LittleEndian.Uint32(dAtA[iNdEx:]))
+			iNdEx += 4
+			m.Boost = float32(math.Float32frombits(v))
+		default:
+			iNdEx = preIndex
+			skippy, err := skipTermQuery(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthTermQuery
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func (m *TermQuery) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
@@ -342,6 +571,40 @@ func (m *TermQuery) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Fields", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowTermQuery
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthTermQuery
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthTermQuery
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Fields = append(m.Fields, &FieldBoost{})
+			if err := m.Fields[len(m.Fields)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipTermQuery(dAtA[iNdEx:])
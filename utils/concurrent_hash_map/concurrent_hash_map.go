@@ -86,9 +86,11 @@ func (m *ConcurrentHashMap) Get(key string) (any, bool) {
 func (m *ConcurrentHashMap) CreateIterator() *ConcurrentHashMapIterator {
 	// 创建一个二维字符串切片，用于存储所有分片中的keys
 	keys := make([][]string, 0, len(m.mps))
-	for _, mp := range m.mps {
-		// 获取每个分片中的所有key
+	for i, mp := range m.mps {
+		// 获取每个分片中的所有key，需要加读锁，否则与并发写入冲突
+		m.locks[i].RLock()
 		row := maps.Keys(mp)
+		m.locks[i].RUnlock()
 		keys = append(keys, row)
 	}
 
@@ -7,6 +7,8 @@ import (
 
 var (
 	RootPath string //项目根目录
+	// Version 构建版本，编译时通过 -ldflags "-X github.com/jmh000527/criker-search/utils.Version=v1.0.0" 注入
+	Version = "dev"
 )
 
 func init() {